@@ -1,32 +1,224 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// TimeoutConfig configures TimeoutMiddlewareWithConfig.
+type TimeoutConfig struct {
+	// Timeout is the time a request may take (required)
+	Timeout time.Duration
+
+	// Routes overrides Timeout per route, keyed by "METHOD /route/:param" or by the route path
+	// alone for every method (e.g. longer for uploads, shorter for sign-in). Routes with a
+	// zero timeout are neither limited nor buffered, e.g. file downloads (optional)
+	Routes map[string]time.Duration
+
+	// StatusCode is the status of timed out requests, http.StatusServiceUnavailable or
+	// http.StatusGatewayTimeout (default: http.StatusServiceUnavailable)
+	StatusCode int
+}
+
+// TimeoutMiddleware fails requests not handled within timeout, see
+// TimeoutMiddlewareWithConfig.
 func TimeoutMiddleware(timeout time.Duration) echo.MiddlewareFunc {
+	return TimeoutMiddlewareWithConfig(TimeoutConfig{Timeout: timeout})
+}
+
+// TimeoutMiddlewareWithConfig fails requests not handled within the timeout of their route.
+// The request context carries the deadline, so database queries and outgoing calls of the
+// handler are cancelled when it passes.
+//
+// The handler writes its response to a buffer, which is sent once the handler returns in
+// time. Otherwise the buffer is discarded and a problem+json (RFC 9457) response with
+// StatusCode is flushed to the client right away; the middleware still waits for the
+// handler to return before releasing the request, as the handler keeps using it.
+func TimeoutMiddlewareWithConfig(config TimeoutConfig) echo.MiddlewareFunc {
+	if config.Timeout <= 0 {
+		panic("timeout middleware requires a positive Timeout")
+	}
+	switch config.StatusCode {
+	case 0:
+		config.StatusCode = http.StatusServiceUnavailable
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		panic(fmt.Sprintf("timeout middleware: unsupported status code %d", config.StatusCode))
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			timeout := config.routeTimeout(c)
+			if timeout <= 0 {
+				return next(c)
+			}
+
 			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
 			defer cancel()
-
 			c.SetRequest(c.Request().WithContext(ctx))
 
-			done := make(chan error, 1)
+			res := c.Response()
+			original := res.Writer
+			buffer := newTimeoutWriter(original.Header())
+			res.Writer = buffer
+
+			done := make(chan handlerResult, 1)
 			go func() {
-				done <- next(c)
+				defer func() {
+					// Panics are raised again below, where the recover middleware catches them
+					if r := recover(); r != nil {
+						done <- handlerResult{panicked: true, recovered: r}
+					}
+				}()
+				done <- handlerResult{err: next(c)}
 			}()
 
 			select {
-			case err := <-done:
-				return err
+			case result := <-done:
+				res.Writer = original
+				if result.panicked {
+					panic(result.recovered)
+				}
+				buffer.flushTo(original)
+				return result.err
 			case <-ctx.Done():
-				return echo.NewHTTPError(http.StatusRequestTimeout, "Request timeout")
 			}
+
+			// The handler may still write to the buffer, so the timeout response goes
+			// straight to the client and the echo response is only updated once it returned
+			buffer.discard()
+			size := writeTimeoutProblem(original, c.Request(), config.StatusCode, timeout)
+			result := <-done
+			res.Writer = original
+			res.Status = config.StatusCode
+			res.Size = int64(size)
+			res.Committed = true
+			if result.panicked {
+				panic(result.recovered)
+			}
+			return nil
 		}
 	}
 }
+
+// routeTimeout returns the timeout of the route of c.
+func (config TimeoutConfig) routeTimeout(c echo.Context) time.Duration {
+	if timeout, ok := config.Routes[c.Request().Method+" "+c.Path()]; ok {
+		return timeout
+	}
+	if timeout, ok := config.Routes[c.Path()]; ok {
+		return timeout
+	}
+	return config.Timeout
+}
+
+// handlerResult is the outcome of a handler run by the timeout middleware.
+type handlerResult struct {
+	err       error
+	panicked  bool
+	recovered any
+}
+
+// timeoutWriter buffers the response of a handler until it is sent or discarded.
+type timeoutWriter struct {
+	mu        sync.Mutex
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	discarded bool
+}
+
+func newTimeoutWriter(header http.Header) *timeoutWriter {
+	// Headers set by earlier middleware (e.g. the request ID) are kept
+	return &timeoutWriter{header: header.Clone()}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.discarded {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.body.Write(b)
+}
+
+// Flush is a no-op: the response is sent once the handler returned.
+func (w *timeoutWriter) Flush() {}
+
+// discard drops the buffered response; later writes fail with http.ErrHandlerTimeout.
+func (w *timeoutWriter) discard() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.discarded = true
+	w.body.Reset()
+}
+
+// flushTo sends the buffered response to rw. Nothing is sent when the handler wrote nothing,
+// so the error handler can still respond.
+func (w *timeoutWriter) flushTo(rw http.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return
+	}
+	dst := rw.Header()
+	clear(dst)
+	for key, values := range w.header {
+		dst[key] = values
+	}
+	rw.WriteHeader(w.status)
+	_, _ = rw.Write(w.body.Bytes())
+}
+
+// timeoutProblem is the RFC 9457 problem details body of timed out requests.
+type timeoutProblem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// writeTimeoutProblem writes and flushes the timeout response to rw and returns the body
+// size.
+func writeTimeoutProblem(rw http.ResponseWriter, req *http.Request, status int, timeout time.Duration) int {
+	body, _ := json.Marshal(timeoutProblem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   fmt.Sprintf("The request was not handled within %s.", timeout),
+		Instance: req.URL.Path,
+	})
+	header := rw.Header()
+	header.Set(echo.HeaderContentType, "application/problem+json")
+	header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+	header.Del(echo.HeaderContentEncoding)
+	rw.WriteHeader(status)
+	n, _ := rw.Write(body)
+	// The client gets the response now, not once the handler returns
+	_ = http.NewResponseController(rw).Flush()
+	return n
+}
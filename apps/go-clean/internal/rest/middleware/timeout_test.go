@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-clean/internal/rest/middleware"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	e := echo.New()
+	e.Use(middleware.TimeoutMiddlewareWithConfig(middleware.TimeoutConfig{
+		Timeout: 20 * time.Millisecond,
+		Routes: map[string]time.Duration{
+			"POST /slow": time.Second,
+		},
+	}))
+	e.GET("/fast", func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]string{"ok": "yes"})
+	})
+	slow := func(c echo.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	}
+	e.POST("/slow", slow)
+	e.GET("/slow", slow)
+	e.GET("/stuck", func(c echo.Context) error {
+		// Ignores the deadline until the test received the response
+		<-release
+		return c.String(http.StatusOK, "too late")
+	})
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	do := func(method, path string) (*http.Response, []byte) {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	t.Run("buffered_response_is_sent", func(t *testing.T) {
+		res, body := do(http.MethodGet, "/fast")
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.JSONEq(t, `{"ok":"yes"}`, string(body))
+	})
+
+	t.Run("route_overrides", func(t *testing.T) {
+		res, body := do(http.MethodPost, "/slow")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "done", string(body))
+
+		res, _ = do(http.MethodGet, "/slow")
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	})
+
+	t.Run("responds_while_handler_is_blocked", func(t *testing.T) {
+		res, body := do(http.MethodGet, "/stuck")
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, "application/problem+json", res.Header.Get(echo.HeaderContentType))
+		assert.NotContains(t, string(body), "too late")
+
+		var problem map[string]any
+		require.NoError(t, json.Unmarshal(body, &problem))
+		assert.Equal(t, float64(http.StatusServiceUnavailable), problem["status"])
+		assert.Equal(t, "/stuck", problem["instance"])
+	})
+}
@@ -57,7 +57,12 @@ func main() {
 	e.Use(middleware.SecurityHeadersMiddleware())
 	e.Use(middleware.CompressionMiddleware())
 	e.Use(middleware.RateLimitMiddleware(10.0, 20))
-	e.Use(middleware.TimeoutMiddleware(30 * time.Second))
+	e.Use(middleware.TimeoutMiddlewareWithConfig(middleware.TimeoutConfig{
+		Timeout: 30 * time.Second,
+		Routes: map[string]time.Duration{
+			"POST /api/v1/auth/login": 10 * time.Second,
+		},
+	}))
 
 	// Register the routes
 	e.GET("/", func(c echo.Context) error {
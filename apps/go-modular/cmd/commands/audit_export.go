@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"go-modular/internal/adapter"
+	"go-modular/internal/config"
+	"go-modular/modules/audit/handler"
+	"go-modular/modules/audit/models"
+	"go-modular/modules/audit/repository"
+	"go-modular/modules/audit/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var argOutput string
+	var argLimit int
+	var req models.ListAuditEventsRequest
+
+	auditExportCmd := &cobra.Command{
+		Use:   "audit:export",
+		Short: "Export security audit events as JSON lines",
+		Long:  `Export security audit events (newest first) as JSON lines, one event per line, to stdout or a file.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Get()
+
+			if err := validator.New().Struct(req); err != nil {
+				log.Fatalf("Invalid filter: %v", err)
+			}
+			filter, err := handler.FilterFromRequest(req)
+			if err != nil {
+				log.Fatalf("Invalid filter: %v", err)
+			}
+			filter.Limit = argLimit
+
+			pg, err := adapter.NewPostgres(adapter.PostgresConfig{URL: cfg.GetDatabaseURL()})
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+			defer pg.Close()
+
+			// Logs go to stderr so stdout only contains exported events
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+			auditService := services.NewAuditService(services.AuditServiceOpts{
+				AuditRepo: repository.NewAuditRepository(pg.Pool, logger),
+				Logger:    logger,
+			})
+
+			out := os.Stdout
+			if argOutput != "" && argOutput != "-" {
+				f, err := os.Create(argOutput)
+				if err != nil {
+					log.Fatalf("Failed to create output file: %v", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			w := bufio.NewWriter(out)
+
+			count, err := auditService.ExportEvents(cmd.Context(), filter, w)
+			if err != nil {
+				log.Fatalf("Failed to export audit events: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				log.Fatalf("Failed to write audit events: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d audit events\n", count)
+		},
+	}
+
+	auditExportCmd.Flags().StringVarP(&argOutput, "output", "o", "", "Output file (default: stdout)")
+	auditExportCmd.Flags().StringVar(&req.ActorID, "actor", "", "Filter by actor user ID")
+	auditExportCmd.Flags().StringVar(&req.Action, "action", "", "Filter by action, or prefix ending with * (e.g. auth.*)")
+	auditExportCmd.Flags().StringVar(&req.TargetType, "target-type", "", "Filter by target type")
+	auditExportCmd.Flags().StringVar(&req.TargetID, "target-id", "", "Filter by target ID")
+	auditExportCmd.Flags().StringVar(&req.Since, "since", "", "Only events at or after this RFC3339 time")
+	auditExportCmd.Flags().StringVar(&req.Until, "until", "", "Only events before this RFC3339 time")
+	auditExportCmd.Flags().IntVar(&argLimit, "limit", 0, "Maximum number of events (default: all)")
+
+	RootCmd.AddCommand(auditExportCmd)
+}
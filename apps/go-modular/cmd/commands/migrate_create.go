@@ -11,15 +11,18 @@ import (
 	"go-modular/internal/config"
 )
 
+var argMigrationModule string
+
 var migrateCreateCmd = &cobra.Command{
 	Use:   "migrate:create [migration_name]",
 	Short: "Create new database migration file",
+	Long:  "Create a new database migration file in database/migrations, or in modules/<module>/migrations with --module.",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg := config.Get()
 		migrationName := args[0]
 		migrator := database.NewMigrator(cfg.GetDatabaseURL())
-		if err := migrator.MigrateCreate(cmd.Context(), migrationName); err != nil {
+		if err := migrator.MigrateCreate(cmd.Context(), migrationName, argMigrationModule); err != nil {
 			log.Fatalf("Failed to create new migration: %v", err)
 		}
 		if err := migrator.Close(); err != nil {
@@ -29,5 +32,6 @@ var migrateCreateCmd = &cobra.Command{
 }
 
 func init() {
+	migrateCreateCmd.Flags().StringVar(&argMigrationModule, "module", "", "Create the migration in the migrations of this module")
 	RootCmd.AddCommand(migrateCreateCmd)
 }
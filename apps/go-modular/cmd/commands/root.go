@@ -7,6 +7,9 @@ import (
 	"go-modular/internal"
 	"go-modular/internal/config"
 
+	// Register the application modules, for the server and the migrations
+	_ "go-modular/modules"
+
 	"github.com/spf13/cobra"
 )
 
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"go-modular/internal/adapter"
+	"go-modular/internal/config"
+	"go-modular/modules/auth/repository"
+	"go-modular/modules/auth/services"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var argBatchSize int
+
+	tokensPruneCmd := &cobra.Command{
+		Use:   "tokens:prune",
+		Short: "Delete expired one-time tokens, refresh tokens and sessions",
+		Long:  `Delete expired one-time tokens, refresh tokens and sessions in batches. This is the same job the server runs periodically when AUTH_TOKEN_SWEEP_INTERVAL is set.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Get()
+
+			batchSize := argBatchSize
+			if batchSize <= 0 {
+				batchSize = cfg.Auth.TokenSweepBatchSize
+			}
+
+			pg, err := adapter.NewPostgres(adapter.PostgresConfig{URL: cfg.GetDatabaseURL()})
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+			defer pg.Close()
+
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+			sweeper := services.NewTokenSweeper(services.TokenSweeperOpts{
+				AuthRepo:  repository.NewAuthRepository(pg.Pool, logger),
+				Logger:    logger,
+				BatchSize: batchSize,
+			})
+
+			result, err := sweeper.Sweep(cmd.Context())
+			if err != nil {
+				log.Fatalf("Failed to prune expired tokens: %v", err)
+			}
+			fmt.Printf("Pruned %d one-time tokens, %d refresh tokens, %d sessions\n",
+				result.OneTimeTokens, result.RefreshTokens, result.Sessions)
+		},
+	}
+
+	tokensPruneCmd.Flags().IntVar(&argBatchSize, "batch-size", 0, "Maximum rows deleted per statement (default: AUTH_TOKEN_SWEEP_BATCH_SIZE)")
+
+	RootCmd.AddCommand(tokensPruneCmd)
+}
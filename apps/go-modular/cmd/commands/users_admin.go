@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"go-modular/internal/adapter"
+	"go-modular/internal/config"
+
+	"github.com/spf13/cobra"
+
+	auditRepo "go-modular/modules/audit/repository"
+	auditSvc "go-modular/modules/audit/services"
+	userRepo "go-modular/modules/user/repository"
+	userSvc "go-modular/modules/user/services"
+)
+
+func init() {
+	var argRevoke bool
+
+	usersSetAdminCmd := &cobra.Command{
+		Use:   "users:set-admin [email]",
+		Short: "Grant or revoke administrator privileges",
+		Long:  `Grant administrator privileges to a user, or revoke them with --revoke. Admins can use the /api/v1/admin endpoints such as user impersonation. The change is recorded in the security audit log.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Get()
+
+			pg, err := adapter.NewPostgres(adapter.PostgresConfig{URL: cfg.GetDatabaseURL()})
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+			defer pg.Close()
+
+			logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+			users := userSvc.NewUserService(userSvc.UserServiceOpts{
+				UserRepo: userRepo.NewUserRepository(pg.Pool, logger),
+				Auditor: auditSvc.NewAuditService(auditSvc.AuditServiceOpts{
+					AuditRepo: auditRepo.NewAuditRepository(pg.Pool, logger),
+					Logger:    logger,
+				}),
+			})
+
+			user, err := users.GetUserByEmail(cmd.Context(), args[0])
+			if err != nil {
+				log.Fatalf("Failed to find user %s: %v", args[0], err)
+			}
+			if err := users.SetUserAdmin(cmd.Context(), user.ID, !argRevoke); err != nil {
+				log.Fatalf("Failed to update user: %v", err)
+			}
+
+			if argRevoke {
+				fmt.Printf("Revoked administrator privileges of %s (%s)\n", user.Email, user.ID)
+			} else {
+				fmt.Printf("Granted administrator privileges to %s (%s)\n", user.Email, user.ID)
+			}
+		},
+	}
+	usersSetAdminCmd.Flags().BoolVar(&argRevoke, "revoke", false, "Revoke administrator privileges instead of granting them")
+
+	RootCmd.AddCommand(usersSetAdminCmd)
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+
+	"go-modular/internal/adapter"
+	"go-modular/internal/config"
+	"go-modular/modules/user/models"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var argOutput string
+	var argFormat string
+	var argSearch string
+
+	usersExportCmd := &cobra.Command{
+		Use:   "users:export",
+		Short: "Export users as CSV or NDJSON",
+		Long:  `Export users (newest first) as CSV with a header row or as NDJSON (one JSON object per line), to stdout or a file. The output can be imported again with users:import.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Get()
+
+			format := models.BulkFormat(argFormat)
+			if format == "" && argOutput != "" && argOutput != "-" {
+				format = bulkFormatFromPath(argOutput)
+			}
+			if format == "" {
+				format = models.BulkFormatCSV
+			}
+			if !format.IsValid() {
+				log.Fatalf("Unknown file format, use --format csv or --format ndjson")
+			}
+
+			pg, err := adapter.NewPostgres(adapter.PostgresConfig{URL: cfg.GetDatabaseURL()})
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+			defer pg.Close()
+
+			users := newBulkUserService(cfg, pg, false)
+
+			out := os.Stdout
+			if argOutput != "" && argOutput != "-" {
+				f, err := os.Create(argOutput)
+				if err != nil {
+					log.Fatalf("Failed to create output file: %v", err)
+				}
+				defer f.Close()
+				out = f
+			}
+			w := bufio.NewWriter(out)
+
+			filter := &models.FilterUser{}
+			if argSearch != "" {
+				filter.Search = &argSearch
+			}
+			count, err := users.ExportUsers(cmd.Context(), filter, format, w)
+			if err != nil {
+				log.Fatalf("Failed to export users: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				log.Fatalf("Failed to write users: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d users\n", count)
+		},
+	}
+
+	usersExportCmd.Flags().StringVarP(&argOutput, "output", "o", "", "Output file (default: stdout)")
+	usersExportCmd.Flags().StringVar(&argFormat, "format", "", "File format: csv or ndjson (default: from the output file extension, else csv)")
+	usersExportCmd.Flags().StringVar(&argSearch, "search", "", "Only users whose display name or username contains this text")
+
+	RootCmd.AddCommand(usersExportCmd)
+}
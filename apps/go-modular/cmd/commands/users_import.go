@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-modular/internal/adapter"
+	"go-modular/internal/config"
+	"go-modular/internal/eventbus"
+	"go-modular/internal/notification"
+	"go-modular/modules/user/models"
+
+	"github.com/spf13/cobra"
+
+	templateFS "go-modular/templates"
+
+	auditRepo "go-modular/modules/audit/repository"
+	auditSvc "go-modular/modules/audit/services"
+	authRepo "go-modular/modules/auth/repository"
+	authSvc "go-modular/modules/auth/services"
+	userRepo "go-modular/modules/user/repository"
+	userSvc "go-modular/modules/user/services"
+)
+
+// newBulkUserService creates the user service used by the users:import and users:export
+// commands. Logs go to stderr so stdout only contains exported users. With invitations the
+// auth service handles the UserInvited events of the import synchronously, so failed
+// invitations show in the import report.
+func newBulkUserService(cfg *config.Config, pg *adapter.PostgresDB, withInvitations bool) *userSvc.UserService {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	var mailer *notification.Mailer
+	if withInvitations {
+		m, err := notification.NewMailer(notification.MailerOptions{
+			SMTPHost:     cfg.Mailer.SMTPHost,
+			SMTPPort:     cfg.Mailer.SMTPPort,
+			SMTPUsername: cfg.Mailer.SMTPUsername,
+			SMTPPassword: cfg.Mailer.SMTPPassword,
+			FromName:     cfg.Mailer.SenderName,
+			FromAddress:  cfg.Mailer.SenderEmail,
+			TemplateFS:   templateFS.TemplateDir,
+			Logger:       logger,
+		})
+		if err != nil {
+			log.Fatalf("Mailer is required to send invitations: %v", err)
+		}
+		mailer = m
+	}
+
+	var metadataSchema *userSvc.MetadataSchema
+	if path := cfg.App.UserMetadataSchemaFile; path != "" {
+		schema, err := userSvc.LoadMetadataSchema(path)
+		if err != nil {
+			log.Fatalf("Failed to load user metadata schema: %v", err)
+		}
+		metadataSchema = schema
+	}
+
+	auditor := auditSvc.NewAuditService(auditSvc.AuditServiceOpts{
+		AuditRepo: auditRepo.NewAuditRepository(pg.Pool, logger),
+		Logger:    logger,
+	})
+	bus := eventbus.NewBus(eventbus.BusOpts{Logger: logger})
+	users := userSvc.NewUserService(userSvc.UserServiceOpts{
+		UserRepo:       userRepo.NewUserRepository(pg.Pool, logger),
+		Auditor:        auditor,
+		MetadataSchema: metadataSchema,
+		Events:         bus,
+		Logger:         logger,
+	})
+	if withInvitations {
+		auth := authSvc.NewAuthService(authSvc.AuthServiceOpts{
+			AuthRepo:     authRepo.NewAuthRepository(pg.Pool, logger),
+			UserService:  users,
+			JWTSecretKey: []byte(cfg.App.JWTSecretKey),
+			Mailer:       mailer,
+			BaseURL:      cfg.GetAppBaseURL(),
+			Auditor:      auditor,
+			Events:       bus,
+			Logger:       logger,
+		})
+		eventbus.Subscribe(bus, "auth.send_invitation", auth.SendInvitation)
+	}
+	return users
+}
+
+// bulkFormatFromPath returns the format of a file from its extension.
+func bulkFormatFromPath(path string) models.BulkFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return models.BulkFormatCSV
+	case ".ndjson", ".jsonl":
+		return models.BulkFormatNDJSON
+	}
+	return ""
+}
+
+func init() {
+	var argFormat string
+	var argDryRun bool
+	var argInvite bool
+	var argReport string
+
+	usersImportCmd := &cobra.Command{
+		Use:   "users:import [file]",
+		Short: "Import users from a CSV or NDJSON file",
+		Long: `Import users from a CSV file (header row with email, display_name and optionally username,
+avatar_url, timezone) or an NDJSON file (one JSON object per line), read from a file or stdin ("-").
+Files written by users:export can be imported again.
+
+Every record is validated; invalid records are reported and skipped. Valid users are inserted in
+batches with COPY. Missing usernames are generated from the email address. Use --dry-run to only
+validate the file and --invite to email every created user a link to set their password.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg := config.Get()
+
+			format := models.BulkFormat(argFormat)
+			if format == "" {
+				format = bulkFormatFromPath(args[0])
+			}
+			if !format.IsValid() {
+				log.Fatalf("Unknown file format, use --format csv or --format ndjson")
+			}
+
+			var in io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					log.Fatalf("Failed to open import file: %v", err)
+				}
+				defer f.Close()
+				in = f
+			}
+
+			pg, err := adapter.NewPostgres(adapter.PostgresConfig{URL: cfg.GetDatabaseURL()})
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+			defer pg.Close()
+
+			users := newBulkUserService(cfg, pg, argInvite && !argDryRun)
+			report, err := users.ImportUsers(cmd.Context(), in, models.ImportOptions{
+				Format: format,
+				DryRun: argDryRun,
+				Invite: argInvite,
+			})
+			if err != nil {
+				log.Printf("Import stopped: %v", err)
+			}
+
+			if argReport != "" {
+				b, mErr := json.MarshalIndent(report, "", "  ")
+				if mErr != nil {
+					log.Fatalf("Failed to encode report: %v", mErr)
+				}
+				if wErr := os.WriteFile(argReport, b, 0o644); wErr != nil {
+					log.Fatalf("Failed to write report: %v", wErr)
+				}
+			} else {
+				for _, rowErr := range report.Errors {
+					fields := make([]string, 0, len(rowErr.Errors))
+					for field, msg := range rowErr.Errors {
+						fields = append(fields, field+": "+msg)
+					}
+					fmt.Fprintf(os.Stderr, "row %d %s: %s\n", rowErr.Row, rowErr.Email, strings.Join(fields, "; "))
+				}
+				if report.ErrorsTruncated {
+					fmt.Fprintln(os.Stderr, "... more rows failed, use --report to write the full report")
+				}
+			}
+
+			if argDryRun {
+				fmt.Printf("Dry run: %d records, %d valid, %d invalid\n", report.Total, report.Valid, report.Failed)
+			} else {
+				fmt.Printf("Imported %d of %d users, %d failed, %d invited\n", report.Created, report.Total, report.Failed, report.Invited)
+			}
+			if err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	usersImportCmd.Flags().StringVar(&argFormat, "format", "", "File format: csv or ndjson (default: from the file extension)")
+	usersImportCmd.Flags().BoolVar(&argDryRun, "dry-run", false, "Validate the file without creating users")
+	usersImportCmd.Flags().BoolVar(&argInvite, "invite", false, "Email every created user a link to set their password")
+	usersImportCmd.Flags().StringVar(&argReport, "report", "", "Write the JSON import report to this file")
+
+	RootCmd.AddCommand(usersImportCmd)
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+// migrationsDir is the directory goose reads migrations from, in MigrationFS and in the
+// merged migrations.
+const migrationsDir = "migrations"
+
+// coreMigrations is the source name of the migrations of MigrationFS.
+const coreMigrations = "core"
+
+// migrationFile is a migration of one of the merged sources.
+type migrationFile struct {
+	source string // "core" or module name
+	fsys   fs.FS  // FS holding the file at its root
+	info   fs.FileInfo
+}
+
+// mergedMigrations presents the SQL migrations of several sources as the single
+// migrations directory goose reads, so all migrations share one version sequence.
+type mergedMigrations struct {
+	files map[string]migrationFile // by file name
+}
+
+// Ensure mergedMigrations implements the FS interfaces goose relies on
+var (
+	_ fs.StatFS    = (*mergedMigrations)(nil)
+	_ fs.ReadDirFS = (*mergedMigrations)(nil)
+)
+
+// mergeMigrations merges the *.sql files at the root of each source FS, by source name. It
+// fails when two files have the same name or the same version.
+func mergeMigrations(sources map[string]fs.FS) (*mergedMigrations, error) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	merged := &mergedMigrations{files: make(map[string]migrationFile)}
+	versions := make(map[int64]string)
+	for _, source := range names {
+		fsys := sources[source]
+		matches, err := fs.Glob(fsys, "*.sql")
+		if err != nil {
+			return nil, fmt.Errorf("list %s migrations: %w", source, err)
+		}
+		for _, name := range matches {
+			version, err := goose.NumericComponent(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s migration %s: %w", source, name, err)
+			}
+			if other, ok := versions[version]; ok {
+				return nil, fmt.Errorf("migration version %d used by %s and %s", version, other, source+"/"+name)
+			}
+			info, err := fs.Stat(fsys, name)
+			if err != nil {
+				return nil, fmt.Errorf("%s migration %s: %w", source, name, err)
+			}
+			versions[version] = source + "/" + name
+			merged.files[name] = migrationFile{source: source, fsys: fsys, info: info}
+		}
+	}
+	return merged, nil
+}
+
+// lastVersion returns the highest version of all migrations, 0 when there are none.
+func (m *mergedMigrations) lastVersion() int64 {
+	var last int64
+	for name := range m.files {
+		if v, err := goose.NumericComponent(name); err == nil && v > last {
+			last = v
+		}
+	}
+	return last
+}
+
+// source returns the source of the migration whose name (without version) is name.
+func (m *mergedMigrations) source(name string) (string, bool) {
+	for file, f := range m.files {
+		if _, rest, ok := strings.Cut(file, "_"); ok && strings.TrimSuffix(rest, ".sql") == name {
+			return f.source + "/" + file, true
+		}
+	}
+	return "", false
+}
+
+func (m *mergedMigrations) Open(name string) (fs.File, error) {
+	if name == "." || name == migrationsDir {
+		entries, _ := m.ReadDir(name)
+		return &mergedDir{name: name, entries: entries}, nil
+	}
+	dir, file := path.Split(name)
+	f, ok := m.files[file]
+	if !ok || path.Clean(dir) != migrationsDir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return f.fsys.Open(file)
+}
+
+func (m *mergedMigrations) Stat(name string) (fs.FileInfo, error) {
+	if name == "." || name == migrationsDir {
+		return dirInfo(path.Base(name)), nil
+	}
+	dir, file := path.Split(name)
+	f, ok := m.files[file]
+	if !ok || path.Clean(dir) != migrationsDir {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return f.info, nil
+}
+
+func (m *mergedMigrations) ReadDir(name string) ([]fs.DirEntry, error) {
+	switch name {
+	case ".":
+		return []fs.DirEntry{fs.FileInfoToDirEntry(dirInfo(migrationsDir))}, nil
+	case migrationsDir:
+		entries := make([]fs.DirEntry, 0, len(m.files))
+		for _, f := range m.files {
+			entries = append(entries, fs.FileInfoToDirEntry(f.info))
+		}
+		slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+		return entries, nil
+	}
+	return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+}
+
+// dirInfo is the FileInfo of a directory of mergedMigrations.
+type dirInfo string
+
+func (d dirInfo) Name() string       { return string(d) }
+func (d dirInfo) Size() int64        { return 0 }
+func (d dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (d dirInfo) ModTime() time.Time { return time.Time{} }
+func (d dirInfo) IsDir() bool        { return true }
+func (d dirInfo) Sys() any           { return nil }
+
+// mergedDir is an open directory of mergedMigrations.
+type mergedDir struct {
+	name    string
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *mergedDir) Stat() (fs.FileInfo, error) { return dirInfo(path.Base(d.name)), nil }
+func (d *mergedDir) Close() error               { return nil }
+
+func (d *mergedDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *mergedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
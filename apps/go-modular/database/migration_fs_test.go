@@ -0,0 +1,71 @@
+package database
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	// Register the application modules and their migrations
+	_ "go-modular/modules"
+)
+
+func sqlFile(up string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte("-- +goose Up\n" + up + "\n-- +goose Down\nSELECT 1;\n")}
+}
+
+func TestMergeMigrations(t *testing.T) {
+	merged, err := mergeMigrations(map[string]fs.FS{
+		coreMigrations: fstest.MapFS{"00001_init.sql": sqlFile("SELECT 1;"), "README.md": {}},
+		"user":         fstest.MapFS{"00002_create_users.sql": sqlFile("SELECT 2;")},
+		"webhook":      fstest.MapFS{"00003_create_webhooks.sql": sqlFile("SELECT 3;")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), merged.lastVersion())
+
+	source, ok := merged.source("create_users")
+	require.True(t, ok)
+	assert.Equal(t, "user/00002_create_users.sql", source)
+
+	// goose reads all sources as one directory
+	goose.SetBaseFS(merged)
+	defer goose.SetBaseFS(nil)
+	migrations, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version)
+	}
+	data, err := fs.ReadFile(merged, migrationsDir+"/00003_create_webhooks.sql")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "SELECT 3;")
+
+	// Versions are shared by all sources
+	_, err = mergeMigrations(map[string]fs.FS{
+		coreMigrations: fstest.MapFS{"00001_init.sql": sqlFile("SELECT 1;")},
+		"user":         fstest.MapFS{"00001_create_users.sql": sqlFile("SELECT 2;")},
+	})
+	assert.EqualError(t, err, "migration version 1 used by core/00001_init.sql and user/00001_create_users.sql")
+}
+
+func TestLoadMigrations(t *testing.T) {
+	merged, err := loadMigrations()
+	require.NoError(t, err)
+
+	// Core and module migrations form one gapless sequence
+	entries, err := merged.ReadDir(migrationsDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		version, err := goose.NumericComponent(entry.Name())
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), version, entry.Name())
+	}
+
+	source, ok := merged.source("create_webhook_tables")
+	require.True(t, ok)
+	assert.Equal(t, "webhook/00017_create_webhook_tables.sql", source)
+}
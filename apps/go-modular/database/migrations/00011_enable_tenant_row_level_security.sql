@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Tenant role and settings for row-level security (defense in depth)
+-- Tenant-scoped transactions (adapter.PostgresDB.WithTenantTx) switch to the
+-- restricted app_tenant role and set app.tenant_id / app.user_id with SET LOCAL
+-- semantics. The role has no table privileges here: modules grant it the tables
+-- they protect with policies, so every other table stays out of its reach. The
+-- application owner role keeps bypassing RLS for cross-tenant work (sign-in,
+-- sweeper, migrations).
+-- ============================================================================
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_tenant') THEN
+        CREATE ROLE app_tenant NOLOGIN;
+    END IF;
+    -- allow the connecting role to SET ROLE app_tenant
+    EXECUTE format('GRANT app_tenant TO %I', current_user);
+END $$;
+
+GRANT USAGE ON SCHEMA public TO app_tenant;
+
+-- Current tenant/user of the transaction, NULL when not set
+CREATE OR REPLACE FUNCTION app_current_tenant_id()
+RETURNS UUID AS $$ SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid $$
+LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION app_current_user_id()
+RETURNS UUID AS $$ SELECT NULLIF(current_setting('app.user_id', true), '')::uuid $$
+LANGUAGE sql STABLE;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop functions and revoke privileges (reverse order of creation)
+DROP FUNCTION IF EXISTS app_current_user_id();
+DROP FUNCTION IF EXISTS app_current_tenant_id();
+REVOKE USAGE ON SCHEMA public FROM app_tenant;
+-- The role is cluster-wide and may still be used by other databases, so it is kept.
+
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create event outbox table and indexes
+-- Domain events for asynchronous subscribers of the in-process event bus. One row is
+-- stored per event and subscriber, so a failing subscriber is retried on its own.
+-- Rows are delivered at least once and pruned some time after delivery.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.event_outbox (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    event_name TEXT NOT NULL,
+    subscriber TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT DEFAULT NULL,
+    available_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP, -- next delivery attempt (or end of the claim lease)
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMPTZ DEFAULT NULL,
+    failed_at TIMESTAMPTZ DEFAULT NULL -- set when delivery was given up
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_event_outbox_pending ON public.event_outbox (available_at) WHERE delivered_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_event_outbox_delivered_at ON public.event_outbox (delivered_at) WHERE delivered_at IS NOT NULL;
+
+-- Events are never read inside tenant-scoped transactions
+REVOKE ALL ON public.event_outbox FROM app_tenant;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop indexes and table (reverse order of creation)
+DROP INDEX IF EXISTS idx_event_outbox_delivered_at;
+DROP INDEX IF EXISTS idx_event_outbox_pending;
+DROP TABLE IF EXISTS public.event_outbox;
+
+-- +goose StatementEnd
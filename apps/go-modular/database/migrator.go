@@ -5,13 +5,15 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
+	"go-modular/internal/module"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -23,8 +25,9 @@ import (
 var MigrationFS embed.FS
 
 type Migrator struct {
-	db   *sql.DB
-	pool *pgxpool.Pool
+	db         *sql.DB
+	pool       *pgxpool.Pool
+	migrations *mergedMigrations
 }
 
 // createConnection creates a new database connection pool and a SQL database instance with retry mechanism
@@ -98,7 +101,15 @@ func min(a, b time.Duration) time.Duration {
 	return b
 }
 
+// NewMigrator creates a Migrator running the core migrations of MigrationFS together with
+// the migrations of the modules registered with module.Register.
 func NewMigrator(dbURL string) *Migrator {
+	migrations, err := loadMigrations()
+	if err != nil {
+		slog.Error("Failed to load database migrations", "err", err.Error())
+		os.Exit(1)
+	}
+
 	pool, db, err := createConnection(dbURL)
 	if err != nil {
 		slog.Error("Failed to create database connection", "err", err.Error())
@@ -108,16 +119,27 @@ func NewMigrator(dbURL string) *Migrator {
 	// Configure goose migrator
 	_ = goose.SetDialect("postgres")
 	goose.SetTableName("app_migrations")
-	goose.SetBaseFS(MigrationFS)
+	goose.SetBaseFS(migrations)
 	goose.SetSequential(true)
 
 	slog.Info("Database migrator initialized")
-	return &Migrator{db: db, pool: pool}
+	return &Migrator{db: db, pool: pool, migrations: migrations}
+}
+
+// loadMigrations merges the core migrations with the migrations of the registered modules.
+func loadMigrations() (*mergedMigrations, error) {
+	core, err := fs.Sub(MigrationFS, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	sources := module.Default().Migrations()
+	sources[coreMigrations] = core
+	return mergeMigrations(sources)
 }
 
 func (m *Migrator) MigrateUp(ctx context.Context) error {
 	slog.Info("Applying Goose migrations", "direction", "up")
-	if err := goose.UpContext(ctx, m.db, "migrations"); err != nil {
+	if err := goose.UpContext(ctx, m.db, migrationsDir); err != nil {
 		slog.Error("Goose migration up failed", "err", err)
 		return fmt.Errorf("goose up: %w", err)
 	}
@@ -139,7 +161,7 @@ func (m *Migrator) MigrateDown(ctx context.Context, steps string) error {
 	}
 
 	for i := 0; i < downSteps; i++ {
-		if err := goose.Down(m.db, "migrations"); err != nil {
+		if err := goose.Down(m.db, migrationsDir); err != nil {
 			slog.Warn("No more Goose migrations to rollback", "iteration", i+1)
 			break
 		}
@@ -159,7 +181,7 @@ func (m *Migrator) MigrateReset(ctx context.Context) error {
 	}
 
 	slog.Info("Resetting all Goose migrations")
-	if err := goose.Reset(m.db, "migrations"); err != nil {
+	if err := goose.Reset(m.db, migrationsDir); err != nil {
 		slog.Error("Failed to reset Goose migrations", "err", err)
 		panic(fmt.Errorf("failed to reset migrations: %w", err))
 	}
@@ -169,7 +191,7 @@ func (m *Migrator) MigrateReset(ctx context.Context) error {
 
 func (m *Migrator) MigrateStatus(ctx context.Context) error {
 	slog.Info("Checking current Goose migration status")
-	if err := goose.Status(m.db, "migrations"); err != nil {
+	if err := goose.Status(m.db, migrationsDir); err != nil {
 		slog.Error("Failed to show Goose migration status", "err", err)
 		panic(fmt.Errorf("failed to show migration status: %w", err))
 	}
@@ -178,14 +200,29 @@ func (m *Migrator) MigrateStatus(ctx context.Context) error {
 
 func (m *Migrator) MigrateVersion(ctx context.Context) error {
 	slog.Info("Checking current Goose migration version")
-	if err := goose.Version(m.db, "migrations"); err != nil {
+	if err := goose.Version(m.db, migrationsDir); err != nil {
 		slog.Error("Failed to show Goose migration version", "err", err)
 		panic(fmt.Errorf("failed to show migration version: %w", err))
 	}
 	return nil
 }
 
-func (m *Migrator) MigrateCreate(ctx context.Context, migrationName string) error {
+// sqlMigrationTemplate is the content of new migration files, as created by goose.
+const sqlMigrationTemplate = `-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+`
+
+// MigrateCreate creates a migration file in the core migrations directory, or in the
+// migrations directory of moduleName when set. Its version follows the last version of all
+// migrations, so versions of the core and module migrations never collide.
+func (m *Migrator) MigrateCreate(ctx context.Context, migrationName, moduleName string) error {
 	if err := m.MigrateStatus(ctx); err != nil {
 		slog.Error("Failed to show migration status before create", "err", err)
 	}
@@ -195,41 +232,46 @@ func (m *Migrator) MigrateCreate(ctx context.Context, migrationName string) erro
 		slog.Error("Failed to get working directory", "err", err)
 		return err
 	}
-	migrationsDir := filepath.Join(execDir, "migrations")
-	if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-		// Try relative to this source file (for go run or test)
-		migrationsDir = filepath.Join(execDir, "database", "migrations")
-		if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-			slog.Error("Migrations directory does not exist", "checked", []string{
-				filepath.Join(execDir, "migrations"),
-				filepath.Join(execDir, "database", "migrations"),
-			})
-			return fmt.Errorf("migrations directory does not exist")
-		}
-	}
 
-	files, err := os.ReadDir(migrationsDir)
-	if err != nil {
-		slog.Error("Failed to read migrations directory", "err", err)
-		return err
-	}
-	for _, f := range files {
-		if f.IsDir() {
-			continue
+	var dir string
+	if moduleName != "" {
+		// Module migrations live next to the module, see module.Module.Migrations
+		moduleDir := filepath.Join(execDir, "modules", moduleName)
+		if _, err := os.Stat(moduleDir); os.IsNotExist(err) {
+			slog.Error("Module directory does not exist", "checked", moduleDir)
+			return fmt.Errorf("module directory does not exist: %s", moduleDir)
 		}
-		name := f.Name()
-		parts := strings.SplitN(name, "_", 2)
-		if len(parts) != 2 {
-			continue
+		dir = filepath.Join(moduleDir, migrationsDir)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
 		}
-		if strings.TrimSuffix(parts[1], ".sql") == migrationName {
-			slog.Warn("Migration already exists", "migration", name)
-			return fmt.Errorf("migration already exists: %s", name)
+		if _, ok := module.Default().Migrations()[moduleName]; !ok {
+			slog.Warn("Module has no migrations yet, embed the directory and return it from Migrations", "module", moduleName, "dir", dir)
 		}
+	} else {
+		dir = filepath.Join(execDir, migrationsDir)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			// Try relative to this source file (for go run or test)
+			dir = filepath.Join(execDir, "database", migrationsDir)
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				slog.Error("Migrations directory does not exist", "checked", []string{
+					filepath.Join(execDir, migrationsDir),
+					filepath.Join(execDir, "database", migrationsDir),
+				})
+				return fmt.Errorf("migrations directory does not exist")
+			}
+		}
+	}
+
+	if existing, ok := m.migrations.source(migrationName); ok {
+		slog.Warn("Migration already exists", "migration", existing)
+		return fmt.Errorf("migration already exists: %s", existing)
 	}
 
-	slog.Info("Creating new Goose migration file", "name", migrationName)
-	if err := goose.Create(m.db, migrationsDir, migrationName, "sql"); err != nil {
+	name := fmt.Sprintf("%05d_%s.sql", m.migrations.lastVersion()+1, migrationName)
+	path := filepath.Join(dir, name)
+	slog.Info("Creating new Goose migration file", "name", migrationName, "file", path)
+	if err := os.WriteFile(path, []byte(sqlMigrationTemplate), 0o644); err != nil {
 		slog.Error("Failed to create migration file", "err", err)
 		return err
 	}
@@ -18,6 +18,7 @@ type UserSeed struct {
 	Password        string
 	Metadata        map[string]string
 	EmailVerifiedAt *time.Time // pakai time.Time pointer
+	IsAdmin         bool
 }
 
 func UserFactory(ctx context.Context, pool *pgxpool.Pool) error {
@@ -33,6 +34,7 @@ func UserFactory(ctx context.Context, pool *pgxpool.Pool) error {
 			Password:        "secure.password",
 			Metadata:        map[string]string{"timezone": "Asia/Jakarta"},
 			EmailVerifiedAt: &now, // verified
+			IsAdmin:         true,
 		},
 		{
 			DisplayName:     "John Doe",
@@ -45,14 +47,15 @@ func UserFactory(ctx context.Context, pool *pgxpool.Pool) error {
 	}
 
 	insertUserQuery := `
-        INSERT INTO public.users (username, display_name, email, metadata, email_verified_at)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO public.users (username, display_name, email, metadata, email_verified_at, is_admin)
+        VALUES ($1, $2, $3, $4, $5, $6)
         ON CONFLICT (username) DO UPDATE
         SET
             display_name = EXCLUDED.display_name,
             email = EXCLUDED.email,
             metadata = EXCLUDED.metadata,
-            email_verified_at = EXCLUDED.email_verified_at
+            email_verified_at = EXCLUDED.email_verified_at,
+            is_admin = EXCLUDED.is_admin
         RETURNING id
     `
 
@@ -82,7 +85,7 @@ func UserFactory(ctx context.Context, pool *pgxpool.Pool) error {
 		}
 
 		var userID string
-		err = tx.QueryRow(ctx, insertUserQuery, u.Username, u.DisplayName, u.Email, metadataJSON, u.EmailVerifiedAt).Scan(&userID)
+		err = tx.QueryRow(ctx, insertUserQuery, u.Username, u.DisplayName, u.Email, metadataJSON, u.EmailVerifiedAt, u.IsAdmin).Scan(&userID)
 		if err != nil {
 			slog.Error("Failed to seed user", "username", u.Username, "err", err)
 			return err
@@ -3,12 +3,15 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,6 +19,15 @@ import (
 type PostgresDB struct {
 	Pool *pgxpool.Pool // Use connection pool for most PostgreSQL operations
 	Conn *pgx.Conn     // Optional: single connection, can be nil if not used
+
+	tenantRole string       // Role assumed by tenant-scoped transactions (see BeginTenantTx)
+	logger     *slog.Logger // Logs failed rollbacks of tenant-scoped transactions
+
+	replicas      []*replica // Read replicas, see Reader
+	nextReplica   atomic.Uint64
+	maxReplicaLag time.Duration
+	stopReplicas  context.CancelFunc // Stops the replica health checks
+	replicasDone  chan struct{}
 }
 
 // Connection configuration
@@ -28,9 +40,16 @@ type PostgresConfig struct {
 	SearchPath      string
 	Timezone        string
 	EnableOTel      bool
+	TenantRole      string       // Role for tenant-scoped transactions (default: DefaultTenantRole, "-" disables)
+	Logger          *slog.Logger // Logs failed rollbacks of tenant-scoped transactions (default: slog.Default())
+
+	// Read replicas (optional). Read-only queries routed with Reader run on them, see Reader.
+	ReplicaURLs          []string
+	MaxReplicaLag        time.Duration // Replicas lagging further behind are skipped (default: 5s)
+	ReplicaCheckInterval time.Duration // Interval of the replica health checks (default: 5s)
 }
 
-// NewPostgres creates a new database connection pool.
+// NewPostgres creates a new database connection pool, and one per replica of ReplicaURLs.
 // Only URL is mandatory, other fields are optional and will use defaults if zero.
 func NewPostgres(cfg PostgresConfig) (*PostgresDB, error) {
 	// apply sensible defaults
@@ -46,8 +65,47 @@ func NewPostgres(cfg PostgresConfig) (*PostgresDB, error) {
 	if cfg.Timezone == "" {
 		cfg.Timezone = "UTC"
 	}
+	switch cfg.TenantRole {
+	case "":
+		cfg.TenantRole = DefaultTenantRole
+	case "-":
+		cfg.TenantRole = ""
+	}
 
-	poolConfig, err := pgxpool.ParseConfig(cfg.URL)
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	if cfg.MaxReplicaLag == 0 {
+		cfg.MaxReplicaLag = 5 * time.Second
+	}
+	if cfg.ReplicaCheckInterval == 0 {
+		cfg.ReplicaCheckInterval = 5 * time.Second
+	}
+
+	pool, err := newPool(cfg, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	db := &PostgresDB{Pool: pool, tenantRole: cfg.TenantRole, logger: cfg.Logger, maxReplicaLag: cfg.MaxReplicaLag}
+
+	for _, replicaURL := range cfg.ReplicaURLs {
+		replicaPool, err := newPool(cfg, replicaURL)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		db.replicas = append(db.replicas, &replica{pool: replicaPool})
+	}
+	if len(db.replicas) > 0 {
+		db.startReplicaChecks(cfg.ReplicaCheckInterval)
+	}
+	return db, nil
+}
+
+// newPool creates a connection pool to url with the settings of cfg.
+func newPool(cfg PostgresConfig, url string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pg pool config: %w", err)
 	}
@@ -72,6 +130,8 @@ func NewPostgres(cfg PostgresConfig) (*PostgresDB, error) {
 	poolConfig.ConnConfig.RuntimeParams["TimeZone"] = cfg.Timezone
 	poolConfig.ConnConfig.RuntimeParams["timezone"] = cfg.Timezone
 
+	poolConfig.ConnConfig.BuildContextWatcherHandler = cancelQueryHandler
+
 	// Initialize OpenTelemetry tracing for pgx if enabled
 	// Uses otelpgx package to create a tracer that trims SQL in span names
 	// and uses a custom function to generate span names from statements
@@ -87,8 +147,7 @@ func NewPostgres(cfg PostgresConfig) (*PostgresDB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pgxpool: %w", err)
 	}
-
-	return &PostgresDB{Pool: pool}, nil
+	return pool, nil
 }
 
 // NewPostgresWithSingleConn creates a new PostgresDB with both pool and single connection exported.
@@ -106,10 +165,8 @@ func NewPostgresWithSingleConn(cfg PostgresConfig) (*PostgresDB, error) {
 		return nil, fmt.Errorf("failed to create single connection: %w", err)
 	}
 
-	return &PostgresDB{
-		Pool: pg.Pool,
-		Conn: conn,
-	}, nil
+	pg.Conn = conn
+	return pg, nil
 }
 
 // Ping checks if the database connection is alive
@@ -117,8 +174,15 @@ func (db *PostgresDB) Ping(ctx context.Context) error {
 	return db.Pool.Ping(ctx)
 }
 
-// Close closes all connections in the pool
+// Close closes all connections in the pool and in the replica pools
 func (db *PostgresDB) Close() {
+	if db.stopReplicas != nil {
+		db.stopReplicas()
+		<-db.replicasDone
+	}
+	for _, r := range db.replicas {
+		r.pool.Close()
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
 	}
@@ -184,6 +248,12 @@ func (db *PostgresDB) GetPoolConnection(ctx context.Context) (*pgxpool.Conn, err
 	return db.Pool.Acquire(ctx)
 }
 
+// cancelQueryHandler makes a cancelled context (e.g. of a timed out request) cancel the running
+// query on the server, instead of only closing the connection while the query keeps running.
+func cancelQueryHandler(pgConn *pgconn.PgConn) ctxwatch.Handler {
+	return &pgconn.CancelRequestContextWatcherHandler{Conn: pgConn, DeadlineDelay: time.Second}
+}
+
 // CheckPostgresConnection tests database connectivity with timeout
 func CheckPostgresConnection(cfg PostgresConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -235,6 +305,8 @@ func NewSingleConnection(cfg PostgresConfig) (*pgx.Conn, error) {
 		connCfg.ConnectTimeout = 10 * time.Second
 	}
 
+	connCfg.BuildContextWatcherHandler = cancelQueryHandler
+
 	// Initialize OpenTelemetry tracing for pgx if enabled
 	// Uses otelpgx package to create a tracer that trims SQL in span names
 	// and uses a custom function to generate span names from statements
@@ -0,0 +1,183 @@
+package adapter
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLagQuery returns how far a replica is behind its primary, in seconds. A replica
+// that replayed everything it received is not lagging even when the primary was idle since
+// its last transaction, and a server that is not in recovery never lags.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8`
+
+// replica is a read replica and the outcome of its last health check.
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// ReadRouter picks the connection read-only queries run on. *PostgresDB implements it.
+type ReadRouter interface {
+	Reader(ctx context.Context) DBTX
+}
+
+// Ensure PostgresDB implements ReadRouter
+var _ ReadRouter = (*PostgresDB)(nil)
+
+// Reader returns the connection for a read-only query: the transaction stored in ctx, the
+// primary when ctx reads its own writes (see ReadYourWrites and UsePrimary), or a healthy
+// replica picked round-robin. It falls back to the primary when no replica is healthy.
+func (db *PostgresDB) Reader(ctx context.Context) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if len(db.replicas) == 0 || readsPrimary(ctx) {
+		return db.Pool
+	}
+	next := db.nextReplica.Add(1)
+	for i := range db.replicas {
+		r := db.replicas[(next+uint64(i))%uint64(len(db.replicas))]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return db.Pool
+}
+
+// ReadConn returns the connection of a read-only query: the pick of router, or Conn when
+// there is no router.
+func ReadConn(ctx context.Context, pool *pgxpool.Pool, router ReadRouter) DBTX {
+	if router == nil {
+		return Conn(ctx, pool)
+	}
+	return router.Reader(ctx)
+}
+
+// startReplicaChecks checks the replicas once, then every interval until Close. Replicas
+// that cannot be queried or lag more than the maximum replica lag are skipped by Reader
+// until a later check succeeds.
+func (db *PostgresDB) startReplicaChecks(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	db.stopReplicas = cancel
+	db.replicasDone = make(chan struct{})
+
+	db.checkReplicas(ctx)
+	go func() {
+		defer close(db.replicasDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				db.checkReplicas(ctx)
+			}
+		}
+	}()
+}
+
+// checkReplicas updates the health of every replica.
+func (db *PostgresDB) checkReplicas(ctx context.Context) {
+	for _, r := range db.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		var lag float64
+		err := r.pool.QueryRow(checkCtx, replicaLagQuery).Scan(&lag)
+		cancel()
+		r.healthy.Store(err == nil && time.Duration(lag*float64(time.Second)) <= db.maxReplicaLag)
+	}
+}
+
+// readState records whether the reads of a context must go to the primary.
+type readState struct {
+	primary atomic.Bool
+}
+
+type readStateKey struct{}
+
+// ReadYourWrites returns a copy of ctx that tracks its writes: once a write ran through Conn,
+// or a TxManager or tenant transaction committed, Reader routes the reads of ctx to the
+// primary, so they see the write even when replicas lag behind. Requests get one from
+// middleware.ReadYourWritesMiddleware.
+func ReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(readStateKey{}).(*readState); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, readStateKey{}, &readState{})
+}
+
+// UsePrimary returns a copy of ctx whose reads always go to the primary, e.g. for a
+// security check that must not see a stale row.
+func UsePrimary(ctx context.Context) context.Context {
+	state := &readState{}
+	state.primary.Store(true)
+	return context.WithValue(ctx, readStateKey{}, state)
+}
+
+// readsPrimary reports whether the reads of ctx must go to the primary.
+func readsPrimary(ctx context.Context) bool {
+	state, ok := ctx.Value(readStateKey{}).(*readState)
+	return ok && state.primary.Load()
+}
+
+// markWritten routes the later reads of ctx to the primary when ctx tracks its writes.
+func markWritten(ctx context.Context) {
+	if state, ok := ctx.Value(readStateKey{}).(*readState); ok {
+		state.primary.Store(true)
+	}
+}
+
+// writeTracker is the primary pool of a context tracking its writes. Statements other than
+// plain SELECT queries are taken as writes.
+type writeTracker struct {
+	pool  *pgxpool.Pool
+	state *readState
+}
+
+func (w writeTracker) track(sql string) {
+	if !isSelect(sql) {
+		w.state.primary.Store(true)
+	}
+}
+
+func (w writeTracker) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	w.track(sql)
+	return w.pool.Exec(ctx, sql, args...)
+}
+
+func (w writeTracker) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	w.track(sql)
+	return w.pool.Query(ctx, sql, args...)
+}
+
+func (w writeTracker) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	w.track(sql)
+	return w.pool.QueryRow(ctx, sql, args...)
+}
+
+func (w writeTracker) Begin(ctx context.Context) (pgx.Tx, error) {
+	w.state.primary.Store(true)
+	return w.pool.Begin(ctx)
+}
+
+func (w writeTracker) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	w.state.primary.Store(true)
+	return w.pool.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// isSelect reports whether sql is a SELECT statement. Statements starting with a WITH clause
+// may write and are not.
+func isSelect(sql string) bool {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	return len(sql) >= 6 && strings.EqualFold(sql[:6], "select")
+}
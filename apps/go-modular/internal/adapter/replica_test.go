@@ -0,0 +1,97 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-modular/pkg/testutils"
+)
+
+func TestPostgresDB_Replicas(t *testing.T) {
+	ctx := context.Background()
+
+	// Two independent servers stand in for a primary and its replica; each one knows its role
+	primaryPool, primaryURL, err := testutils.NewTestEnv(t).SetupPostgres()
+	require.NoError(t, err)
+	replicaPool, replicaURL, err := testutils.NewTestEnv(t).SetupPostgres()
+	require.NoError(t, err)
+	_, err = primaryPool.Exec(ctx, `CREATE TABLE server_role AS SELECT 'primary'::text AS name`)
+	require.NoError(t, err)
+	_, err = replicaPool.Exec(ctx, `CREATE TABLE server_role AS SELECT 'replica'::text AS name`)
+	require.NoError(t, err)
+
+	db, err := NewPostgres(PostgresConfig{URL: primaryURL, ReplicaURLs: []string{replicaURL}, TenantRole: "-"})
+	require.NoError(t, err)
+	defer db.Close()
+
+	role := func(t *testing.T, ctx context.Context) string {
+		var name string
+		require.NoError(t, db.Reader(ctx).QueryRow(ctx, `SELECT name FROM server_role`).Scan(&name))
+		return name
+	}
+
+	t.Run("reads_go_to_healthy_replicas", func(t *testing.T) {
+		assert.Equal(t, "replica", role(t, ctx))
+		assert.Equal(t, "replica", role(t, ReadYourWrites(ctx)))
+	})
+
+	t.Run("reads_follow_writes_to_the_primary", func(t *testing.T) {
+		ctx := ReadYourWrites(ctx)
+		// Plain reads through Conn do not pin
+		var n int
+		require.NoError(t, Conn(ctx, db.Pool).QueryRow(ctx, `SELECT count(*) FROM server_role`).Scan(&n))
+		assert.Equal(t, "replica", role(t, ctx))
+
+		_, err := Conn(ctx, db.Pool).Exec(ctx, `UPDATE server_role SET name = name`)
+		require.NoError(t, err)
+		assert.Equal(t, "primary", role(t, ctx))
+	})
+
+	t.Run("committed_units_of_work_pin_to_the_primary", func(t *testing.T) {
+		ctx := ReadYourWrites(ctx)
+		err := NewTxManager(db.Pool, TxManagerOpts{}).WithinTx(ctx, func(ctx context.Context) error {
+			// Reads inside the unit of work use its transaction
+			assert.Equal(t, "primary", role(t, ctx))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "primary", role(t, ctx))
+	})
+
+	t.Run("use_primary_overrides", func(t *testing.T) {
+		assert.Equal(t, "primary", role(t, UsePrimary(ctx)))
+	})
+
+	t.Run("lagging_replicas_are_skipped", func(t *testing.T) {
+		db.maxReplicaLag = -1
+		db.checkReplicas(ctx)
+		assert.Equal(t, "primary", role(t, ctx))
+
+		db.maxReplicaLag = 5 * time.Second
+		db.checkReplicas(ctx)
+		assert.Equal(t, "replica", role(t, ctx))
+	})
+
+	t.Run("unreachable_replicas_are_skipped", func(t *testing.T) {
+		// Refuse new connections and drop the open ones
+		_, err := replicaPool.Exec(ctx, `ALTER DATABASE testdb ALLOW_CONNECTIONS false`)
+		require.NoError(t, err)
+		_, err = replicaPool.Exec(ctx, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = 'testdb' AND pid <> pg_backend_pid()`)
+		require.NoError(t, err)
+		replicaPool.Close()
+
+		db.checkReplicas(ctx)
+		assert.Equal(t, "primary", role(t, ctx))
+	})
+}
+
+func TestIsSelect(t *testing.T) {
+	assert.True(t, isSelect("\n\t\tSELECT id FROM users"))
+	assert.True(t, isSelect("(select 1) UNION (select 2)"))
+	assert.False(t, isSelect("UPDATE users SET name = $1"))
+	assert.False(t, isSelect("WITH d AS (DELETE FROM users RETURNING id) SELECT * FROM d"))
+	assert.False(t, isSelect("SEL"))
+}
@@ -0,0 +1,106 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultTenantRole is the restricted role tenant-scoped transactions switch to.
+// It is created by the row-level security migration.
+const DefaultTenantRole = "app_tenant"
+
+// DBTX is the subset of query methods shared by *pgxpool.Pool and pgx.Tx, so repositories
+// can run the same queries on the pool or inside a request transaction.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// Tenant identifies who a tenant-scoped transaction runs for. Both values are exposed to
+// row-level security policies as app.tenant_id and app.user_id.
+type Tenant struct {
+	TenantID string // Active organization ID (empty when none)
+	UserID   string // Authenticated user ID (empty when anonymous)
+}
+
+type txContextKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx. Repositories resolving their connection
+// with Conn will run their queries inside tx.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored in ctx, if any.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(pgx.Tx)
+	return tx, ok && tx != nil
+}
+
+// Conn returns the transaction stored in ctx, or pool when there is none. Writes through
+// the pool route the later reads of ctx to the primary, see ReadYourWrites.
+func Conn(ctx context.Context, pool *pgxpool.Pool) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if state, ok := ctx.Value(readStateKey{}).(*readState); ok && !state.primary.Load() {
+		return writeTracker{pool: pool, state: state}
+	}
+	return pool
+}
+
+// BeginTenantTx starts a transaction scoped to tenant. Inside it the connection switches to
+// the tenant role (SET LOCAL ROLE) and app.tenant_id / app.user_id are set for the lifetime
+// of the transaction only, so row-level security policies filter every query even when it
+// has no WHERE clause. The caller must commit or roll back the returned transaction.
+func (db *PostgresDB) BeginTenantTx(ctx context.Context, tenant Tenant) (pgx.Tx, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin tenant transaction: %w", err)
+	}
+
+	// set_config(..., true) is the parameterized equivalent of SET LOCAL
+	_, err = tx.Exec(ctx, `SELECT set_config('app.tenant_id', $1, true), set_config('app.user_id', $2, true)`, tenant.TenantID, tenant.UserID)
+	if err == nil && db.tenantRole != "" {
+		_, err = tx.Exec(ctx, `SET LOCAL ROLE `+pgx.Identifier{db.tenantRole}.Sanitize())
+	}
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("failed to scope transaction to tenant: %w", err)
+	}
+	return tx, nil
+}
+
+// WithTenantTx executes fn within a tenant-scoped transaction (see BeginTenantTx).
+// The transaction is also stored in the context passed to fn, so repositories using
+// Conn pick it up. It commits when fn returns nil and rolls back otherwise.
+func (db *PostgresDB) WithTenantTx(ctx context.Context, tenant Tenant, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	tx, err := db.BeginTenantTx(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			db.logger.Warn("failed to rollback tenant transaction", slog.String("error", err.Error()))
+		}
+	}()
+
+	if err := fn(ContextWithTx(ctx, tx), tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit tenant transaction: %w", err)
+	}
+	markWritten(ctx)
+	return nil
+}
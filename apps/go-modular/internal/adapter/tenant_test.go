@@ -0,0 +1,108 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-modular/pkg/testutils"
+)
+
+func TestTenantTx_RowLevelSecurity(t *testing.T) {
+	ctx := context.Background()
+
+	te := testutils.NewTestEnv(t)
+	_, pgURL, err := te.SetupPostgres()
+	require.NoError(t, err)
+
+	// ensure env/config is set for migrations
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	db, err := NewPostgres(PostgresConfig{URL: pgURL, MaxConnections: 5, MinConnections: 1})
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Seed two organizations with one member each as the owner role (bypasses RLS)
+	var userA, userB uuid.UUID
+	rows, err := db.Pool.Query(ctx, `SELECT id FROM public.users ORDER BY id LIMIT 2`)
+	require.NoError(t, err)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	require.NoError(t, err)
+	require.Len(t, ids, 2, "two seeded users are required by tests")
+	userA, userB = ids[0], ids[1]
+
+	var orgA, orgB uuid.UUID
+	require.NoError(t, db.Pool.QueryRow(ctx, `INSERT INTO public.organizations (name, slug) VALUES ('Tenant A', 'tenant-a') RETURNING id`).Scan(&orgA))
+	require.NoError(t, db.Pool.QueryRow(ctx, `INSERT INTO public.organizations (name, slug) VALUES ('Tenant B', 'tenant-b') RETURNING id`).Scan(&orgB))
+	_, err = db.Pool.Exec(ctx, `INSERT INTO public.organization_members (organization_id, user_id, role) VALUES ($1, $2, 'owner'), ($3, $4, 'owner')`, orgA, userA, orgB, userB)
+	require.NoError(t, err)
+
+	tenantA := Tenant{TenantID: orgA.String(), UserID: userA.String()}
+
+	t.Run("queries_without_where_only_see_own_tenant", func(t *testing.T) {
+		err := db.WithTenantTx(ctx, tenantA, func(ctx context.Context, tx pgx.Tx) error {
+			rows, err := Conn(ctx, db.Pool).Query(ctx, `SELECT id FROM public.organizations`)
+			require.NoError(t, err)
+			orgs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+			require.NoError(t, err)
+			assert.Equal(t, []uuid.UUID{orgA}, orgs)
+
+			var members int
+			require.NoError(t, tx.QueryRow(ctx, `SELECT count(*) FROM public.organization_members`).Scan(&members))
+			assert.Equal(t, 1, members)
+
+			// users of other tenants are hidden
+			var visible bool
+			require.NoError(t, tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM public.users WHERE id = $1)`, userB).Scan(&visible))
+			assert.False(t, visible)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("cross_tenant_writes_are_rejected", func(t *testing.T) {
+		err := db.WithTenantTx(ctx, tenantA, func(ctx context.Context, tx pgx.Tx) error {
+			cmd, err := tx.Exec(ctx, `UPDATE public.organizations SET name = 'hijacked' WHERE id = $1`, orgB)
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), cmd.RowsAffected())
+
+			cmd, err = tx.Exec(ctx, `DELETE FROM public.organization_members`)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), cmd.RowsAffected(), "only the own tenant's membership may be deleted")
+			return pgx.ErrTxClosed // roll back
+		})
+		require.ErrorIs(t, err, pgx.ErrTxClosed)
+
+		err = db.WithTenantTx(ctx, tenantA, func(ctx context.Context, tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, `INSERT INTO public.organization_members (organization_id, user_id) VALUES ($1, $2)`, orgB, userA)
+			return err
+		})
+		require.Error(t, err, "inserting into another tenant must violate the row-level security policy")
+
+		var name string
+		require.NoError(t, db.Pool.QueryRow(ctx, `SELECT name FROM public.organizations WHERE id = $1`, orgB).Scan(&name))
+		assert.Equal(t, "Tenant B", name)
+	})
+
+	t.Run("no_tenant_sees_nothing", func(t *testing.T) {
+		err := db.WithTenantTx(ctx, Tenant{}, func(ctx context.Context, tx pgx.Tx) error {
+			var n int
+			require.NoError(t, tx.QueryRow(ctx, `SELECT count(*) FROM public.organizations`).Scan(&n))
+			assert.Equal(t, 0, n)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("settings_do_not_leak_to_pool", func(t *testing.T) {
+		require.NoError(t, db.WithTenantTx(ctx, tenantA, func(context.Context, pgx.Tx) error { return nil }))
+
+		var n int
+		require.NoError(t, db.Pool.QueryRow(ctx, `SELECT count(*) FROM public.organizations`).Scan(&n))
+		assert.GreaterOrEqual(t, n, 2)
+	})
+}
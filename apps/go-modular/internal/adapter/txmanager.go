@@ -0,0 +1,172 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Transactor runs a unit of work: several repository calls that must apply together or not
+// at all.
+type Transactor interface {
+	// WithinTx runs fn in a transaction stored in the context passed to fn, so repositories
+	// resolving their connection with Conn join it. It commits when fn returns nil and
+	// rolls back otherwise. fn may run more than once and must not have side effects
+	// outside the database, see AfterCommit.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NopTransactor runs fn without a transaction. It is used when no database is configured,
+// e.g. by services built with in-memory repositories.
+type NopTransactor struct{}
+
+func (NopTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// TxManagerOpts configures a TxManager.
+type TxManagerOpts struct {
+	IsoLevel   pgx.TxIsoLevel // Isolation level of the transactions (default: database default)
+	MaxRetries int            // Retries after serialization failures and deadlocks (default: 3, -1 disables)
+	Logger     *slog.Logger   // Logs retried transactions (optional)
+}
+
+// TxManager is the Transactor backed by a PostgreSQL pool. A unit of work started inside
+// another one joins the outer transaction, so only the outermost one commits and retries.
+type TxManager struct {
+	pool       *pgxpool.Pool
+	isoLevel   pgx.TxIsoLevel
+	maxRetries int
+	logger     *slog.Logger
+}
+
+// Ensure TxManager implements Transactor
+var _ Transactor = (*TxManager)(nil)
+
+// NewTxManager creates a TxManager starting its transactions on pool.
+func NewTxManager(pool *pgxpool.Pool, opts TxManagerOpts) *TxManager {
+	if pool == nil {
+		panic("TxManager requires a pool")
+	}
+	switch {
+	case opts.MaxRetries == 0:
+		opts.MaxRetries = 3
+	case opts.MaxRetries < 0:
+		opts.MaxRetries = 0
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &TxManager{
+		pool:       pool,
+		isoLevel:   opts.IsoLevel,
+		maxRetries: opts.MaxRetries,
+		logger:     opts.Logger,
+	}
+}
+
+// WithinTx runs fn in a transaction, or in the transaction already stored in ctx. The whole
+// unit of work is retried with a short backoff when PostgreSQL aborts it with a
+// serialization failure or a deadlock. Functions registered with AfterCommit run once the
+// transaction is committed.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		state := &unitOfWork{}
+		err := m.runTx(ctx, state, fn)
+		if err == nil {
+			state.committed(ctx)
+			return nil
+		}
+		if attempt >= m.maxRetries || !IsRetryableTxError(err) {
+			return err
+		}
+
+		m.logger.Warn("retrying transaction", slog.Int("attempt", attempt+1), slog.String("error", err.Error()))
+		backoff := time.Duration(attempt+1)*10*time.Millisecond + rand.N(10*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// runTx runs one attempt of a unit of work.
+func (m *TxManager) runTx(ctx context.Context, state *unitOfWork, fn func(ctx context.Context) error) error {
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: m.isoLevel})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			m.logger.Warn("failed to rollback transaction", slog.String("error", err.Error()))
+		}
+	}()
+
+	txCtx := context.WithValue(ContextWithTx(ctx, tx), unitOfWorkKey{}, state)
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	markWritten(ctx)
+	return nil
+}
+
+// IsRetryableTxError reports whether err aborted a transaction that succeeds when run
+// again: a serialization failure (40001) or a deadlock (40P01).
+func IsRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+type unitOfWorkKey struct{}
+
+// unitOfWork collects the functions to run after one attempt of a unit of work commits.
+type unitOfWork struct {
+	mu          sync.Mutex
+	afterCommit []func(ctx context.Context)
+}
+
+// committed runs the functions registered with AfterCommit, with the context the unit of
+// work was started with.
+func (u *unitOfWork) committed(ctx context.Context) {
+	u.mu.Lock()
+	fns := u.afterCommit
+	u.afterCommit = nil
+	u.mu.Unlock()
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+// AfterCommit registers fn to run once the unit of work of ctx (see TxManager) commits. fn
+// never runs when the unit of work rolls back, and runs once when it is retried. It reports
+// false, without registering fn, when ctx holds no unit of work; callers then run fn
+// themselves.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) bool {
+	state, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok {
+		return false
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.afterCommit = append(state.afterCommit, fn)
+	return true
+}
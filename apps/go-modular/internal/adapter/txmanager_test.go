@@ -0,0 +1,110 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-modular/pkg/testutils"
+)
+
+func TestTxManager_WithinTx(t *testing.T) {
+	ctx := context.Background()
+
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	defer pool.Close()
+
+	_, err = pool.Exec(ctx, `CREATE TABLE tx_items (name text PRIMARY KEY)`)
+	require.NoError(t, err)
+	count := func(t *testing.T) int {
+		var n int
+		require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM tx_items`).Scan(&n))
+		return n
+	}
+	insert := func(ctx context.Context, name string) error {
+		_, err := Conn(ctx, pool).Exec(ctx, `INSERT INTO tx_items (name) VALUES ($1)`, name)
+		return err
+	}
+
+	tm := NewTxManager(pool, TxManagerOpts{})
+
+	t.Run("commits_and_runs_after_commit", func(t *testing.T) {
+		var published []string
+		err := tm.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, "a"))
+			assert.True(t, AfterCommit(ctx, func(ctx context.Context) {
+				_, inTx := TxFromContext(ctx)
+				assert.False(t, inTx)
+				published = append(published, "a")
+			}))
+			assert.Empty(t, published)
+			return insert(ctx, "b")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count(t))
+		assert.Equal(t, []string{"a"}, published)
+	})
+
+	t.Run("rolls_back_joined_units_of_work", func(t *testing.T) {
+		ran := false
+		err := tm.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, "c"))
+			// The inner unit of work joins the outer transaction instead of committing
+			require.NoError(t, tm.WithinTx(ctx, func(ctx context.Context) error {
+				AfterCommit(ctx, func(context.Context) { ran = true })
+				return insert(ctx, "d")
+			}))
+			return insert(ctx, "a") // duplicate
+		})
+		require.Error(t, err)
+		assert.Equal(t, 2, count(t))
+		assert.False(t, ran)
+	})
+
+	t.Run("retries_serialization_failures", func(t *testing.T) {
+		attempts, runs := 0, 0
+		err := tm.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			AfterCommit(ctx, func(context.Context) { runs++ })
+			if err := insert(ctx, "e"); err != nil {
+				return err
+			}
+			if attempts < 3 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 1, runs)
+		assert.Equal(t, 3, count(t))
+	})
+
+	t.Run("gives_up_after_max_retries", func(t *testing.T) {
+		attempts := 0
+		err := NewTxManager(pool, TxManagerOpts{MaxRetries: 1}).WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			return &pgconn.PgError{Code: "40P01"}
+		})
+		assert.True(t, IsRetryableTxError(err))
+		assert.Equal(t, 2, attempts)
+
+		// Other errors are not retried
+		attempts = 0
+		err = tm.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			return errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("after_commit_without_unit_of_work", func(t *testing.T) {
+		assert.False(t, AfterCommit(ctx, func(context.Context) { t.Fatal("must not be registered") }))
+	})
+}
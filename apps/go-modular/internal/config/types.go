@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // JWTAlgorithm is a typesafe enum for JWT algorithm
 // Supported values: "HS256", "RS256"
 type JWTAlgorithm string
@@ -11,6 +13,8 @@ const (
 
 type Config struct {
 	App       AppConfig       `env:",squash"`
+	Auth      AuthConfig      `env:",squash"`
+	Webhook   WebhookConfig   `env:",squash"`
 	Database  DatabaseConfig  `env:",squash"`
 	Mailer    MailerConfig    `env:",squash"`
 	FileStore FileStoreConfig `env:",squash"`
@@ -32,12 +36,64 @@ type AppConfig struct {
 	RateLimitRequests  int          `env:"RATE_LIMIT_REQUESTS"`
 	RateLimitBurstSize int          `env:"RATE_LIMIT_BURST_SIZE"`
 	EnableAPIDocs      bool         `env:"ENABLE_API_DOCS"`
+
+	DataExportLinkExpiry   time.Duration `env:"DATA_EXPORT_LINK_EXPIRY"`   // Lifetime of emailed personal data export download links
+	UserMetadataSchemaFile string        `env:"USER_METADATA_SCHEMA_FILE"` // JSON Schema declaring custom user metadata fields (none when empty)
+
+	EventOutboxEnabled      bool          `env:"EVENT_OUTBOX_ENABLED"`       // Store events for asynchronous handlers and deliver them at least once
+	EventOutboxPollInterval time.Duration `env:"EVENT_OUTBOX_POLL_INTERVAL"` // Maximum delay before stored events are delivered
+}
+
+type AuthConfig struct {
+	WebAuthnRPID      string   `env:"WEBAUTHN_RP_ID"`      // Relying party ID, usually the registrable domain (e.g. example.com)
+	WebAuthnRPName    string   `env:"WEBAUTHN_RP_NAME"`    // Human-palatable relying party name shown by authenticators
+	WebAuthnRPOrigins []string `env:"WEBAUTHN_RP_ORIGINS"` // Fully qualified origins allowed to perform WebAuthn ceremonies
+
+	CookieSessionEnabled bool   `env:"AUTH_COOKIE_SESSION_ENABLED"` // Issue access/refresh tokens as HttpOnly cookies (browser session mode)
+	CookieDomain         string `env:"AUTH_COOKIE_DOMAIN"`          // Cookie Domain attribute, empty means host-only cookies
+	CookieSecure         bool   `env:"AUTH_COOKIE_SECURE"`          // Cookie Secure attribute (required in production and with SameSite=None)
+	CookieSameSite       string `env:"AUTH_COOKIE_SAMESITE"`        // lax|strict|none
+
+	PasswordMinLength        int  `env:"AUTH_PASSWORD_MIN_LENGTH"`        // Minimum password length (>= 8)
+	PasswordRequireUpper     bool `env:"AUTH_PASSWORD_REQUIRE_UPPER"`     // Require at least one uppercase letter
+	PasswordRequireLower     bool `env:"AUTH_PASSWORD_REQUIRE_LOWER"`     // Require at least one lowercase letter
+	PasswordRequireDigit     bool `env:"AUTH_PASSWORD_REQUIRE_DIGIT"`     // Require at least one digit
+	PasswordRequireSymbol    bool `env:"AUTH_PASSWORD_REQUIRE_SYMBOL"`    // Require at least one non-alphanumeric character
+	PasswordHistorySize      int  `env:"AUTH_PASSWORD_HISTORY_SIZE"`      // Reject reuse of the last N passwords (0 disables)
+	PasswordBlocklistEnabled bool `env:"AUTH_PASSWORD_BLOCKLIST_ENABLED"` // Reject common and breached passwords (bundled list, no network)
+
+	TokenSweepInterval  time.Duration `env:"AUTH_TOKEN_SWEEP_INTERVAL"`   // Interval of the expired token sweeper (0 disables)
+	TokenSweepBatchSize int           `env:"AUTH_TOKEN_SWEEP_BATCH_SIZE"` // Maximum rows deleted per sweeper statement
+
+	ServiceTokenExpiry time.Duration `env:"AUTH_SERVICE_TOKEN_EXPIRY"` // Lifetime of client-credentials (service) access tokens
+
+	SessionIdleTimeout           time.Duration `env:"AUTH_SESSION_IDLE_TIMEOUT"`             // Sessions not refreshed within this duration are rejected
+	SessionAbsoluteLifetime      time.Duration `env:"AUTH_SESSION_ABSOLUTE_LIFETIME"`        // Maximum session lifetime regardless of activity
+	SessionRememberMeIdleTimeout time.Duration `env:"AUTH_SESSION_REMEMBER_ME_IDLE_TIMEOUT"` // Idle timeout of remember-me sessions
+	SessionRememberMeLifetime    time.Duration `env:"AUTH_SESSION_REMEMBER_ME_LIFETIME"`     // Absolute lifetime of remember-me sessions
+
+	SignInRiskEnabled     bool `env:"AUTH_SIGNIN_RISK_ENABLED"`      // Flag sign-ins from unseen devices, browsers or networks and email an alert
+	SignInRiskHistorySize int  `env:"AUTH_SIGNIN_RISK_HISTORY_SIZE"` // Number of recent sessions a sign-in is compared to
+	SignInRiskStepUp      bool `env:"AUTH_SIGNIN_RISK_STEP_UP"`      // Require an emailed code to complete flagged password sign-ins
+
+	ImpersonationTokenExpiry time.Duration `env:"AUTH_IMPERSONATION_TOKEN_EXPIRY"` // Lifetime of admin impersonation access tokens
+}
+
+type WebhookConfig struct {
+	DeliveryTimeout      time.Duration `env:"WEBHOOK_DELIVERY_TIMEOUT"`       // Timeout of a webhook delivery request
+	MaxAttempts          int           `env:"WEBHOOK_MAX_ATTEMPTS"`           // Attempts before a delivery is marked failed (retried with exponential backoff)
+	DisableAfterFailures int           `env:"WEBHOOK_DISABLE_AFTER_FAILURES"` // Consecutive failed attempts before an endpoint is disabled
+	PollInterval         time.Duration `env:"WEBHOOK_POLL_INTERVAL"`          // Time between polls for due deliveries
 }
 
 type DatabaseConfig struct {
 	PostgresURL   string `env:"DATABASE_URL"`
 	PgMaxPoolSize int    `env:"PG_MAX_POOL_SIZE"`
 	PgMaxRetries  int    `env:"PG_MAX_RETRIES"`
+	PgTenantRole  string `env:"PG_TENANT_ROLE"` // Role assumed by tenant-scoped (RLS) transactions, "-" disables
+
+	PgReplicaURLs   []string      `env:"DATABASE_REPLICA_URLS"` // Read replicas serving read-only queries (optional)
+	PgMaxReplicaLag time.Duration `env:"PG_MAX_REPLICA_LAG"`    // Replicas lagging further behind are skipped
 }
 
 type MailerConfig struct {
@@ -4,10 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 )
 
+// pgRoleRegex matches a plain (unquoted) Postgres role name
+var pgRoleRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
 // Validates critical configuration values
 func validateConfig(config *Config) error {
 	if config == nil {
@@ -42,11 +48,117 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	// WebAuthn relying party
+	rpID := strings.TrimSpace(config.Auth.WebAuthnRPID)
+	if rpID != "" {
+		if strings.Contains(rpID, "://") || strings.Contains(rpID, "/") {
+			errs = append(errs, fmt.Sprintf("invalid WebAuthn RP ID: %q (must be a bare domain)", rpID))
+		}
+		origins := config.GetWebAuthnRPOrigins()
+		if len(origins) == 0 {
+			errs = append(errs, "WebAuthn RP origins are required when WebAuthn RP ID is set")
+		}
+		for _, origin := range origins {
+			u, err := url.Parse(origin)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Sprintf("invalid WebAuthn RP origin: %q", origin))
+			}
+		}
+	}
+
+	// Cookie session mode
+	sameSite := strings.ToLower(strings.TrimSpace(config.Auth.CookieSameSite))
+	if sameSite != "" {
+		validSameSite := []string{"lax", "strict", "none"}
+		if !slices.Contains(validSameSite, sameSite) {
+			errs = append(errs, fmt.Sprintf("invalid auth cookie SameSite: %q (valid: %v)", config.Auth.CookieSameSite, validSameSite))
+		}
+	}
+	if config.Auth.CookieSessionEnabled {
+		if sameSite == "none" && !config.Auth.CookieSecure {
+			errs = append(errs, "auth cookie Secure must be enabled when SameSite is none")
+		}
+		if strings.EqualFold(mode, "production") && !config.Auth.CookieSecure {
+			errs = append(errs, "auth cookie Secure must be enabled in production")
+		}
+	}
+
+	// Password policy
+	if config.Auth.PasswordMinLength < 8 {
+		errs = append(errs, fmt.Sprintf("auth password min length must be >= 8 (got %d)", config.Auth.PasswordMinLength))
+	}
+	if config.Auth.PasswordHistorySize < 0 {
+		errs = append(errs, fmt.Sprintf("auth password history size must be >= 0 (got %d)", config.Auth.PasswordHistorySize))
+	}
+
+	// Expired token sweeper
+	if config.Auth.TokenSweepInterval < 0 {
+		errs = append(errs, fmt.Sprintf("auth token sweep interval must be >= 0 (got %s)", config.Auth.TokenSweepInterval))
+	}
+	if config.Auth.TokenSweepBatchSize <= 0 {
+		errs = append(errs, fmt.Sprintf("auth token sweep batch size must be > 0 (got %d)", config.Auth.TokenSweepBatchSize))
+	}
+	if config.Auth.ServiceTokenExpiry <= 0 || config.Auth.ServiceTokenExpiry > 24*time.Hour {
+		errs = append(errs, fmt.Sprintf("auth service token expiry must be > 0 and <= 24h (got %s)", config.Auth.ServiceTokenExpiry))
+	}
+
+	// Session policy
+	errs = append(errs, validateSessionPolicy(config.Auth)...)
+
+	// Suspicious sign-in detection
+	if config.Auth.SignInRiskEnabled && (config.Auth.SignInRiskHistorySize <= 0 || config.Auth.SignInRiskHistorySize > 1000) {
+		errs = append(errs, fmt.Sprintf("auth sign-in risk history size must be > 0 and <= 1000 (got %d)", config.Auth.SignInRiskHistorySize))
+	}
+	if config.Auth.SignInRiskStepUp && !config.Auth.SignInRiskEnabled {
+		errs = append(errs, "auth sign-in risk step-up requires AUTH_SIGNIN_RISK_ENABLED")
+	}
+
+	// Admin impersonation tokens are kept short-lived
+	if config.Auth.ImpersonationTokenExpiry <= 0 || config.Auth.ImpersonationTokenExpiry > time.Hour {
+		errs = append(errs, fmt.Sprintf("auth impersonation token expiry must be > 0 and <= 1h (got %s)", config.Auth.ImpersonationTokenExpiry))
+	}
+
+	// Webhooks
+	if config.Webhook.DeliveryTimeout <= 0 || config.Webhook.DeliveryTimeout > time.Minute {
+		errs = append(errs, fmt.Sprintf("webhook delivery timeout must be > 0 and <= 1m (got %s)", config.Webhook.DeliveryTimeout))
+	}
+	if config.Webhook.MaxAttempts <= 0 || config.Webhook.MaxAttempts > 20 {
+		errs = append(errs, fmt.Sprintf("webhook max attempts must be > 0 and <= 20 (got %d)", config.Webhook.MaxAttempts))
+	}
+	if config.Webhook.DisableAfterFailures <= 0 {
+		errs = append(errs, fmt.Sprintf("webhook disable after failures must be > 0 (got %d)", config.Webhook.DisableAfterFailures))
+	}
+	if config.Webhook.PollInterval <= 0 || config.Webhook.PollInterval > time.Minute {
+		errs = append(errs, fmt.Sprintf("webhook poll interval must be > 0 and <= 1m (got %s)", config.Webhook.PollInterval))
+	}
+
+	// CORS: credentialed requests (e.g. cookie sessions) are only allowed from explicit origins
+	if config.App.CORSCredentials && config.HasWildcardCORSOrigin() {
+		errs = append(errs, "CORS origins must be explicit (not \"*\") when CORS credentials are enabled")
+	}
+
 	// Server port
 	if config.App.ServerPort <= 0 || config.App.ServerPort > 65535 {
 		errs = append(errs, fmt.Sprintf("invalid server port: %d (must be 1-65535)", config.App.ServerPort))
 	}
 
+	// Personal data export download links
+	if config.App.DataExportLinkExpiry <= 0 || config.App.DataExportLinkExpiry > 7*24*time.Hour {
+		errs = append(errs, fmt.Sprintf("data export link expiry must be > 0 and <= 168h (got %s)", config.App.DataExportLinkExpiry))
+	}
+
+	// Event outbox
+	if config.App.EventOutboxEnabled && (config.App.EventOutboxPollInterval <= 0 || config.App.EventOutboxPollInterval > time.Minute) {
+		errs = append(errs, fmt.Sprintf("event outbox poll interval must be > 0 and <= 1m (got %s)", config.App.EventOutboxPollInterval))
+	}
+
+	// User metadata schema (compiled when the user module is loaded)
+	if path := strings.TrimSpace(config.App.UserMetadataSchemaFile); path != "" {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			errs = append(errs, fmt.Sprintf("user metadata schema file %q is not a readable file", path))
+		}
+	}
+
 	// Database URL
 	dbURL := strings.TrimSpace(config.Database.PostgresURL)
 	if dbURL == "" {
@@ -57,6 +169,17 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	// Read replica URLs
+	for i, replicaURL := range config.GetDatabaseReplicaURLs() {
+		// URLs hold passwords, so only their position is reported
+		if u, err := url.Parse(replicaURL); err != nil || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("invalid database replica URL #%d", i+1))
+		}
+	}
+	if config.Database.PgMaxReplicaLag < 0 {
+		errs = append(errs, fmt.Sprintf("pg max replica lag must be >= 0 (got %s)", config.Database.PgMaxReplicaLag))
+	}
+
 	// Postgres pool size / retries
 	if config.Database.PgMaxPoolSize <= 0 {
 		errs = append(errs, fmt.Sprintf("pg max pool size must be > 0 (got %d)", config.Database.PgMaxPoolSize))
@@ -65,6 +188,11 @@ func validateConfig(config *Config) error {
 		errs = append(errs, fmt.Sprintf("pg max retries must be >= 0 (got %d)", config.Database.PgMaxRetries))
 	}
 
+	// Postgres tenant role (empty uses the default, "-" disables role switching)
+	if role := config.Database.PgTenantRole; role != "" && role != "-" && !pgRoleRegex.MatchString(role) {
+		errs = append(errs, fmt.Sprintf("invalid pg tenant role: %q (must match %s or be \"-\")", role, pgRoleRegex.String()))
+	}
+
 	// Mailer
 	mh := strings.TrimSpace(config.Mailer.SMTPHost)
 	if mh != "" {
@@ -126,3 +254,41 @@ func validateConfig(config *Config) error {
 	}
 	return nil
 }
+
+// validateSessionPolicy checks the session idle timeouts and lifetimes: all must be positive,
+// an idle timeout cannot exceed its lifetime and remember-me sessions cannot be shorter than
+// regular ones.
+func validateSessionPolicy(auth AuthConfig) []string {
+	var errs []string
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"auth session idle timeout", auth.SessionIdleTimeout},
+		{"auth session absolute lifetime", auth.SessionAbsoluteLifetime},
+		{"auth session remember-me idle timeout", auth.SessionRememberMeIdleTimeout},
+		{"auth session remember-me lifetime", auth.SessionRememberMeLifetime},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Sprintf("%s must be > 0 (got %s)", d.name, d.value))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if auth.SessionIdleTimeout > auth.SessionAbsoluteLifetime {
+		errs = append(errs, fmt.Sprintf("auth session idle timeout must be <= absolute lifetime (got %s > %s)", auth.SessionIdleTimeout, auth.SessionAbsoluteLifetime))
+	}
+	if auth.SessionRememberMeIdleTimeout > auth.SessionRememberMeLifetime {
+		errs = append(errs, fmt.Sprintf("auth session remember-me idle timeout must be <= remember-me lifetime (got %s > %s)", auth.SessionRememberMeIdleTimeout, auth.SessionRememberMeLifetime))
+	}
+	if auth.SessionRememberMeIdleTimeout < auth.SessionIdleTimeout {
+		errs = append(errs, fmt.Sprintf("auth session remember-me idle timeout must be >= idle timeout (got %s < %s)", auth.SessionRememberMeIdleTimeout, auth.SessionIdleTimeout))
+	}
+	if auth.SessionRememberMeLifetime < auth.SessionAbsoluteLifetime {
+		errs = append(errs, fmt.Sprintf("auth session remember-me lifetime must be >= absolute lifetime (got %s < %s)", auth.SessionRememberMeLifetime, auth.SessionAbsoluteLifetime))
+	}
+	return errs
+}
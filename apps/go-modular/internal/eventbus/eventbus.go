@@ -0,0 +1,277 @@
+// Package eventbus is an in-process publish/subscribe bus for domain events between modules.
+// Modules publish typed events, defined in the models package of the publishing module, and
+// subscribe to the events of other modules in their NewModule instead of calling them.
+//
+// Synchronous handlers run inside Publish with the publisher's context; their errors are
+// returned to the publisher. Asynchronous handlers run after Publish returned: with an Outbox
+// the event is stored and delivered at least once by RunOutbox, which retries failed
+// deliveries, otherwise the handler runs in a goroutine and failures are only logged.
+// Outbox messages are stored per subscriber registered in the publishing process, so
+// processes without subscribers (e.g. CLI commands) publish to nobody.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"go-modular/internal/adapter"
+)
+
+// subscriberNameRegex matches valid subscriber names. They are stored with outbox messages,
+// so they must stay stable across releases.
+var subscriberNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
+// Event is a domain event. Events are value types that encode to JSON, so they can be
+// stored in the outbox; EventName must not depend on the field values.
+type Event interface {
+	// EventName identifies the event type, e.g. "user.deleted".
+	EventName() string
+}
+
+// Handler handles events of type E.
+type Handler[E Event] func(ctx context.Context, event E) error
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(*subscription)
+
+// Async runs the handler after Publish returned instead of inside it. Use it for work the
+// publisher must not wait for or fail on, e.g. sending emails or notifying other systems.
+func Async() SubscribeOption {
+	return func(s *subscription) { s.async = true }
+}
+
+type subscription struct {
+	event      string
+	subscriber string
+	async      bool
+	handle     func(ctx context.Context, event Event) error
+	decode     func(payload json.RawMessage) (Event, error)
+}
+
+// Bus dispatches published events to the subscribed handlers. It is safe for concurrent use.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]*subscription
+
+	logger *slog.Logger
+	outbox Outbox
+	wake   chan struct{}
+	async  sync.WaitGroup
+
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+	maxAttempts  int
+	retention    time.Duration
+}
+
+type BusOpts struct {
+	Logger *slog.Logger // Slog logger instance (optional)
+
+	// Outbox stores events for asynchronous handlers, which RunOutbox then delivers at least
+	// once (optional, asynchronous handlers run in-process and best effort when nil)
+	Outbox Outbox
+
+	PollInterval time.Duration // Time between outbox polls (default: 1s)
+	BatchSize    int           // Maximum messages claimed per poll (default: 100)
+	Lease        time.Duration // Time a claimed message is reserved for its handler (default: 5m)
+	MaxAttempts  int           // Deliveries before a message is given up (default: 10)
+	Retention    time.Duration // Time delivered messages are kept (default: 7 days)
+}
+
+// NewBus creates a Bus without subscribers.
+func NewBus(opts BusOpts) *Bus {
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 5 * time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = 7 * 24 * time.Hour
+	}
+	return &Bus{
+		subs:         map[string][]*subscription{},
+		logger:       opts.Logger,
+		outbox:       opts.Outbox,
+		wake:         make(chan struct{}, 1),
+		pollInterval: opts.PollInterval,
+		batchSize:    opts.BatchSize,
+		lease:        opts.Lease,
+		maxAttempts:  opts.MaxAttempts,
+		retention:    opts.Retention,
+	}
+}
+
+// Subscribe registers h for events of type E under the subscriber name, e.g.
+// "auth.clear_email_verification_tokens". Handlers of an event run in subscription order. It panics when
+// the name is invalid or already subscribed to the event, since both are programming
+// errors found at startup.
+func Subscribe[E Event](b *Bus, subscriber string, h Handler[E], opts ...SubscribeOption) {
+	var zero E
+	sub := &subscription{
+		event:      zero.EventName(),
+		subscriber: subscriber,
+		handle: func(ctx context.Context, event Event) error {
+			e, ok := event.(E)
+			if !ok {
+				return fmt.Errorf("event %s has type %T, want %T", event.EventName(), event, zero)
+			}
+			return h(ctx, e)
+		},
+		decode: func(payload json.RawMessage) (Event, error) {
+			var e E
+			if err := json.Unmarshal(payload, &e); err != nil {
+				return nil, err
+			}
+			return e, nil
+		},
+	}
+	for _, opt := range opts {
+		opt(sub)
+	}
+
+	if !subscriberNameRegex.MatchString(subscriber) {
+		panic(fmt.Sprintf("eventbus: invalid subscriber name %q", subscriber))
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, existing := range b.subs[sub.event] {
+		if existing.subscriber == subscriber {
+			panic(fmt.Sprintf("eventbus: %q subscribed to %s twice", subscriber, sub.event))
+		}
+	}
+	b.subs[sub.event] = append(b.subs[sub.event], sub)
+}
+
+// subscriptions returns the subscriptions of an event.
+func (b *Bus) subscriptions(event string) []*subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.subs[event]
+}
+
+// subscription returns the subscription of subscriber to event, or nil.
+func (b *Bus) subscription(event, subscriber string) *subscription {
+	for _, sub := range b.subscriptions(event) {
+		if sub.subscriber == subscriber {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Publish runs the synchronous handlers of every event and hands them to the asynchronous
+// ones. The errors of synchronous handlers, and failures to store events in the outbox,
+// are joined and returned; every handler runs regardless of the others' errors.
+//
+// Events published inside a unit of work (see adapter.TxManager) describe changes that are
+// not stored yet. They are stored in the outbox within the unit of work, so they commit or
+// roll back with the change, while the in-process handlers run once it commits and never
+// when it rolls back. Their errors are then logged instead of returned.
+func (b *Bus) Publish(ctx context.Context, events ...Event) error {
+	var errs []error
+	if b.outbox != nil {
+		for _, event := range events {
+			if err := b.enqueue(ctx, event, b.asyncSubscriptions(event.EventName())); err != nil {
+				errs = append(errs, fmt.Errorf("store %s in outbox: %w", event.EventName(), err))
+			}
+		}
+	}
+	deferred := adapter.AfterCommit(ctx, func(ctx context.Context) {
+		if err := b.dispatch(ctx, events...); err != nil {
+			b.logger.Error("failed to publish events after commit",
+				slog.String("op", "eventbus.Publish"),
+				slog.String("error", err.Error()))
+		}
+	})
+	if !deferred {
+		errs = append(errs, b.dispatch(ctx, events...))
+	}
+	return errors.Join(errs...)
+}
+
+// asyncSubscriptions returns the asynchronous subscriptions to event.
+func (b *Bus) asyncSubscriptions(event string) []*subscription {
+	var async []*subscription
+	for _, sub := range b.subscriptions(event) {
+		if sub.async {
+			async = append(async, sub)
+		}
+	}
+	return async
+}
+
+// dispatch runs the in-process part of Publish: the synchronous handlers, and either the
+// asynchronous handlers in goroutines or, with an outbox, a wake-up of the dispatcher.
+func (b *Bus) dispatch(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, event := range events {
+		for _, sub := range b.subscriptions(event.EventName()) {
+			switch {
+			case !sub.async:
+				if err := call(ctx, sub, event); err != nil {
+					errs = append(errs, fmt.Errorf("%s subscriber %s: %w", event.EventName(), sub.subscriber, err))
+				}
+			case b.outbox == nil:
+				b.goAsync(ctx, sub, event)
+			}
+		}
+	}
+	if b.outbox != nil {
+		select {
+		case b.wake <- struct{}{}:
+		default:
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// goAsync runs an asynchronous handler in-process. The handler keeps the values of ctx but
+// not its cancellation, as the request that published the event is usually done by then.
+func (b *Bus) goAsync(ctx context.Context, sub *subscription, event Event) {
+	ctx = context.WithoutCancel(ctx)
+	b.async.Add(1)
+	go func() {
+		defer b.async.Done()
+		if err := call(ctx, sub, event); err != nil {
+			b.logger.Error("event handler failed",
+				slog.String("op", "eventbus.Publish"),
+				slog.String("event", event.EventName()),
+				slog.String("subscriber", sub.subscriber),
+				slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// Wait blocks until the in-process asynchronous handlers started so far have returned.
+func (b *Bus) Wait() {
+	b.async.Wait()
+}
+
+// call runs a handler, turning panics into errors so one subscriber cannot take down the
+// publisher or the outbox dispatcher.
+func call(ctx context.Context, sub *subscription, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return sub.handle(ctx, event)
+}
@@ -0,0 +1,279 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (testEvent) EventName() string { return "test.happened" }
+
+type otherEvent struct{}
+
+func (otherEvent) EventName() string { return "test.other" }
+
+type unsubscribedEvent struct{}
+
+func (unsubscribedEvent) EventName() string { return "test.unsubscribed" }
+
+func newTestBus(outbox Outbox) *Bus {
+	return NewBus(BusOpts{
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Outbox:      outbox,
+		MaxAttempts: 3,
+	})
+}
+
+// memoryOutbox is an in-memory Outbox; claimed messages become available again after Retry.
+type memoryOutbox struct {
+	mu        sync.Mutex
+	msgs      []*memoryMessage
+	enqueueFn func([]OutboxMessage) error
+}
+
+type memoryMessage struct {
+	OutboxMessage
+	availableAt time.Time
+	delivered   bool
+	failed      bool
+	lastError   string
+}
+
+func (o *memoryOutbox) Enqueue(_ context.Context, msgs []OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.enqueueFn != nil {
+		if err := o.enqueueFn(msgs); err != nil {
+			return err
+		}
+	}
+	for _, m := range msgs {
+		o.msgs = append(o.msgs, &memoryMessage{OutboxMessage: m})
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Claim(_ context.Context, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	var claimed []OutboxMessage
+	for _, m := range o.msgs {
+		if len(claimed) == limit {
+			break
+		}
+		if m.delivered || m.failed || m.availableAt.After(now) {
+			continue
+		}
+		m.Attempts++
+		m.availableAt = now.Add(lease)
+		claimed = append(claimed, m.OutboxMessage)
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) find(id uuid.UUID) *memoryMessage {
+	for _, m := range o.msgs {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (o *memoryOutbox) MarkDelivered(_ context.Context, id uuid.UUID) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.find(id).delivered = true
+	return nil
+}
+
+func (o *memoryOutbox) Retry(_ context.Context, id uuid.UUID, reason string, _ time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.find(id)
+	m.lastError = reason
+	m.availableAt = time.Time{} // retry immediately in tests
+	return nil
+}
+
+func (o *memoryOutbox) GiveUp(_ context.Context, id uuid.UUID, reason string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	m := o.find(id)
+	m.lastError = reason
+	m.failed = true
+	return nil
+}
+
+func (o *memoryOutbox) DeleteDelivered(context.Context, time.Time, int) (int64, error) {
+	return 0, nil
+}
+
+func TestBus_Publish_Sync(t *testing.T) {
+	ctx := context.Background()
+	bus := newTestBus(nil)
+
+	var calls []string
+	Subscribe(bus, "first", func(_ context.Context, e testEvent) error {
+		calls = append(calls, "first:"+e.Name)
+		return errors.New("boom")
+	})
+	Subscribe(bus, "second", func(_ context.Context, e testEvent) error {
+		calls = append(calls, "second:"+e.Name)
+		return nil
+	})
+	Subscribe(bus, "other", func(_ context.Context, _ otherEvent) error {
+		calls = append(calls, "other")
+		return nil
+	})
+
+	err := bus.Publish(ctx, testEvent{ID: 1, Name: "a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test.happened subscriber first: boom")
+	// Every handler runs, in subscription order, despite the first one failing
+	assert.Equal(t, []string{"first:a", "second:a"}, calls)
+
+	// Events without subscribers are dropped
+	calls = nil
+	require.NoError(t, bus.Publish(ctx, unsubscribedEvent{}))
+	assert.Empty(t, calls)
+}
+
+func TestBus_Publish_RecoversPanics(t *testing.T) {
+	bus := newTestBus(nil)
+	Subscribe(bus, "panics", func(context.Context, testEvent) error { panic("oops") })
+
+	err := bus.Publish(context.Background(), testEvent{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handler panicked: oops")
+}
+
+func TestBus_Publish_AsyncInProcess(t *testing.T) {
+	bus := newTestBus(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var got []testEvent
+	Subscribe(bus, "async", func(ctx context.Context, e testEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		got = append(got, e)
+		return errors.New("only logged")
+	}, Async())
+
+	require.NoError(t, bus.Publish(ctx, testEvent{ID: 1}, testEvent{ID: 2}))
+	// The publisher's cancellation does not reach asynchronous handlers
+	cancel()
+	bus.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	slices.SortFunc(got, func(a, b testEvent) int { return a.ID - b.ID })
+	assert.Equal(t, []testEvent{{ID: 1}, {ID: 2}}, got)
+}
+
+func TestBus_Subscribe_Validation(t *testing.T) {
+	bus := newTestBus(nil)
+	h := func(context.Context, testEvent) error { return nil }
+
+	assert.PanicsWithValue(t, `eventbus: invalid subscriber name "Bad Name"`, func() { Subscribe(bus, "Bad Name", h) })
+	Subscribe(bus, "audit.record", h)
+	assert.PanicsWithValue(t, `eventbus: "audit.record" subscribed to test.happened twice`, func() { Subscribe(bus, "audit.record", h, Async()) })
+}
+
+func TestBus_Outbox_AtLeastOnceDelivery(t *testing.T) {
+	ctx := context.Background()
+	outbox := &memoryOutbox{}
+	bus := newTestBus(outbox)
+
+	var syncCalls int
+	Subscribe(bus, "sync", func(context.Context, testEvent) error { syncCalls++; return nil })
+
+	var flakyCalls int
+	var delivered []testEvent
+	Subscribe(bus, "flaky", func(_ context.Context, e testEvent) error {
+		flakyCalls++
+		if flakyCalls == 1 {
+			return errors.New("temporary failure")
+		}
+		delivered = append(delivered, e)
+		return nil
+	}, Async())
+
+	var brokenCalls int
+	Subscribe(bus, "broken", func(context.Context, testEvent) error { brokenCalls++; return errors.New("always fails") }, Async())
+
+	require.NoError(t, bus.Publish(ctx, testEvent{ID: 7, Name: "stored"}))
+	assert.Equal(t, 1, syncCalls)
+	// One message per asynchronous subscriber, nothing delivered before the dispatcher runs
+	require.Len(t, outbox.msgs, 2)
+	assert.Equal(t, "flaky", outbox.msgs[0].Subscriber)
+	assert.Equal(t, "broken", outbox.msgs[1].Subscriber)
+	assert.JSONEq(t, `{"id":7,"name":"stored"}`, string(outbox.msgs[0].Payload))
+	assert.Zero(t, flakyCalls)
+
+	// Every Drain is one poll of the dispatcher
+	for range 3 {
+		require.NoError(t, bus.Drain(ctx))
+	}
+
+	// The flaky subscriber is retried until it succeeds, the event is decoded from the payload
+	assert.Equal(t, 2, flakyCalls)
+	assert.Equal(t, []testEvent{{ID: 7, Name: "stored"}}, delivered)
+	assert.True(t, outbox.msgs[0].delivered)
+
+	// The broken subscriber is given up after MaxAttempts, independently of the other one
+	assert.Equal(t, 3, brokenCalls)
+	assert.True(t, outbox.msgs[1].failed)
+	assert.Equal(t, "always fails", outbox.msgs[1].lastError)
+}
+
+func TestBus_Outbox_EnqueueFailure(t *testing.T) {
+	outbox := &memoryOutbox{enqueueFn: func([]OutboxMessage) error { return errors.New("db down") }}
+	bus := newTestBus(outbox)
+	Subscribe(bus, "async", func(context.Context, testEvent) error { return nil }, Async())
+
+	err := bus.Publish(context.Background(), testEvent{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "store test.happened in outbox: db down")
+}
+
+func TestBus_Outbox_UnknownSubscriber(t *testing.T) {
+	ctx := context.Background()
+	outbox := &memoryOutbox{}
+	require.NoError(t, outbox.Enqueue(ctx, []OutboxMessage{{
+		ID: uuid.Must(uuid.NewV7()), EventName: "test.happened", Subscriber: "removed", Payload: []byte(`{}`),
+	}}))
+
+	bus := newTestBus(outbox)
+	for range 3 {
+		require.NoError(t, bus.Drain(ctx))
+	}
+	assert.True(t, outbox.msgs[0].failed)
+	assert.Equal(t, "no such subscriber", outbox.msgs[0].lastError)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 5*time.Second, retryDelay(1))
+	assert.Equal(t, 10*time.Second, retryDelay(2))
+	assert.Equal(t, 40*time.Second, retryDelay(4))
+	assert.Equal(t, time.Hour, retryDelay(20))
+}
@@ -0,0 +1,194 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// maxRetryDelay caps the exponential backoff between delivery attempts.
+const maxRetryDelay = time.Hour
+
+// retryBaseDelay is the delay after the first failed delivery; it doubles per attempt.
+const retryBaseDelay = 5 * time.Second
+
+// pruneInterval is the time between deletions of delivered outbox messages.
+const pruneInterval = time.Hour
+
+// OutboxMessage is an event stored for one asynchronous subscriber.
+type OutboxMessage struct {
+	ID         uuid.UUID       `json:"id"`
+	EventName  string          `json:"event_name"`
+	Subscriber string          `json:"subscriber"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"` // delivery attempts, including the current one once claimed
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// Outbox stores events for asynchronous subscribers until they are delivered.
+type Outbox interface {
+	// Enqueue stores messages, which become available for delivery immediately.
+	Enqueue(ctx context.Context, msgs []OutboxMessage) error
+	// Claim reserves up to limit available messages for lease and counts the attempt.
+	// Messages that are not delivered or failed before the lease ends are claimed again.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error)
+	// MarkDelivered completes a message.
+	MarkDelivered(ctx context.Context, id uuid.UUID) error
+	// Retry records a failed attempt and makes the message available again at retryAt.
+	Retry(ctx context.Context, id uuid.UUID, reason string, retryAt time.Time) error
+	// GiveUp records a failed attempt after which the message is not delivered again.
+	GiveUp(ctx context.Context, id uuid.UUID, reason string) error
+	// DeleteDelivered deletes up to limit messages delivered before the given time.
+	DeleteDelivered(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// enqueue stores event for every subscription. The dispatcher is woken by dispatch, once
+// the messages are committed.
+func (b *Bus) enqueue(ctx context.Context, event Event, subs []*subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	msgs := make([]OutboxMessage, len(subs))
+	for i, sub := range subs {
+		msgs[i] = OutboxMessage{
+			ID:         uuid.Must(uuid.NewV7()),
+			EventName:  event.EventName(),
+			Subscriber: sub.subscriber,
+			Payload:    payload,
+			CreatedAt:  now,
+		}
+	}
+	return b.outbox.Enqueue(ctx, msgs)
+}
+
+// RunOutbox delivers stored events to the asynchronous subscribers until ctx is cancelled:
+// whenever an event is published and at least every poll interval. Delivered messages are
+// pruned after the retention period. It is a no-op when the bus has no outbox.
+//
+// Several instances may run it against the same outbox; every message is claimed by one of
+// them at a time. Handlers must be idempotent, since a message is delivered again when the
+// instance fails before marking it delivered.
+func (b *Bus) RunOutbox(ctx context.Context) {
+	if b.outbox == nil {
+		return
+	}
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		if err := b.Drain(ctx); err != nil && ctx.Err() == nil {
+			b.logger.Error("event outbox delivery failed", slog.String("op", "eventbus.RunOutbox"), slog.String("error", err.Error()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+		case <-ticker.C:
+		case <-pruneTicker.C:
+			b.prune(ctx)
+		}
+	}
+}
+
+// Drain delivers the available outbox messages, batch by batch, until none are left.
+func (b *Bus) Drain(ctx context.Context) error {
+	if b.outbox == nil {
+		return nil
+	}
+	for {
+		msgs, err := b.outbox.Claim(ctx, b.batchSize, b.lease)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := b.deliver(ctx, msg); err != nil {
+				return err
+			}
+		}
+		if len(msgs) < b.batchSize {
+			return nil
+		}
+	}
+}
+
+// deliver runs the handler of a claimed message and records the outcome. Only failures to
+// record the outcome are returned; handler failures are retried with exponential backoff.
+func (b *Bus) deliver(ctx context.Context, msg OutboxMessage) error {
+	herr := b.handle(ctx, msg)
+	if herr == nil {
+		return b.outbox.MarkDelivered(ctx, msg.ID)
+	}
+
+	logAttrs := []any{
+		slog.String("op", "eventbus.deliver"),
+		slog.String("event", msg.EventName),
+		slog.String("subscriber", msg.Subscriber),
+		slog.String("message_id", msg.ID.String()),
+		slog.Int("attempts", msg.Attempts),
+		slog.String("error", herr.Error()),
+	}
+	if msg.Attempts >= b.maxAttempts {
+		b.logger.Error("event delivery given up", logAttrs...)
+		return b.outbox.GiveUp(ctx, msg.ID, herr.Error())
+	}
+	b.logger.Warn("event delivery failed, will retry", logAttrs...)
+	return b.outbox.Retry(ctx, msg.ID, herr.Error(), time.Now().Add(retryDelay(msg.Attempts)))
+}
+
+// handle decodes a message and runs its subscriber, bounded by the claim lease so the
+// message is not claimed again while its handler still runs.
+func (b *Bus) handle(ctx context.Context, msg OutboxMessage) error {
+	sub := b.subscription(msg.EventName, msg.Subscriber)
+	if sub == nil {
+		// E.g. a subscriber removed in a newer release, or not yet added on this instance
+		return errors.New("no such subscriber")
+	}
+	event, err := sub.decode(msg.Payload)
+	if err != nil {
+		return fmt.Errorf("decode event: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.lease)
+	defer cancel()
+	return call(ctx, sub, event)
+}
+
+// prune deletes delivered messages older than the retention period.
+func (b *Bus) prune(ctx context.Context) {
+	before := time.Now().Add(-b.retention)
+	for {
+		n, err := b.outbox.DeleteDelivered(ctx, before, 1000)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.logger.Error("event outbox pruning failed", slog.String("op", "eventbus.prune"), slog.String("error", err.Error()))
+			}
+			return
+		}
+		if n < 1000 {
+			return
+		}
+	}
+}
+
+// retryDelay returns the backoff after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,112 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-modular/internal/adapter"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxTable is the table of the PostgreSQL outbox.
+const OutboxTable = "public.event_outbox"
+
+// PostgresOutbox is an Outbox stored in the event_outbox table. Claims use
+// FOR UPDATE SKIP LOCKED, so several instances can deliver from it concurrently.
+type PostgresOutbox struct {
+	pgPool *pgxpool.Pool
+}
+
+// Ensure PostgresOutbox implements Outbox
+var _ Outbox = (*PostgresOutbox)(nil)
+
+// NewPostgresOutbox creates a PostgresOutbox.
+func NewPostgresOutbox(pgPool *pgxpool.Pool) *PostgresOutbox {
+	if pgPool == nil {
+		panic("PgPool is required")
+	}
+	return &PostgresOutbox{pgPool: pgPool}
+}
+
+// Enqueue stores msgs in the transaction of ctx, if any (see adapter.Conn), so they are
+// committed or rolled back with the change they describe.
+func (o *PostgresOutbox) Enqueue(ctx context.Context, msgs []OutboxMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	// One statement, so the messages of an event are stored together even without a transaction
+	values := make([]string, len(msgs))
+	args := make([]any, 0, len(msgs)*5)
+	for i, m := range msgs {
+		n := i * 5
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+5)
+		args = append(args, m.ID, m.EventName, m.Subscriber, m.Payload, m.CreatedAt)
+	}
+	query := `INSERT INTO ` + OutboxTable + ` (id, event_name, subscriber, payload, created_at, available_at)
+        VALUES ` + strings.Join(values, ", ")
+	_, err := adapter.Conn(ctx, o.pgPool).Exec(ctx, query, args...)
+	return err
+}
+
+func (o *PostgresOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]OutboxMessage, error) {
+	query := `UPDATE ` + OutboxTable + ` SET attempts = attempts + 1, available_at = $2
+        WHERE id IN (
+            SELECT id FROM ` + OutboxTable + `
+            WHERE delivered_at IS NULL AND failed_at IS NULL AND available_at <= CURRENT_TIMESTAMP
+            ORDER BY available_at, id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING id, event_name, subscriber, payload, attempts, created_at`
+	rows, err := o.pgPool.Query(ctx, query, limit, time.Now().Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (OutboxMessage, error) {
+		var m OutboxMessage
+		err := row.Scan(&m.ID, &m.EventName, &m.Subscriber, &m.Payload, &m.Attempts, &m.CreatedAt)
+		return m, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// UPDATE ... RETURNING does not keep the order of the subquery; UUIDv7 IDs sort by creation
+	slices.SortFunc(msgs, func(a, b OutboxMessage) int { return bytes.Compare(a.ID.Bytes(), b.ID.Bytes()) })
+	return msgs, nil
+}
+
+func (o *PostgresOutbox) MarkDelivered(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE ` + OutboxTable + ` SET delivered_at = CURRENT_TIMESTAMP, last_error = NULL WHERE id = $1`
+	_, err := o.pgPool.Exec(ctx, query, id)
+	return err
+}
+
+func (o *PostgresOutbox) Retry(ctx context.Context, id uuid.UUID, reason string, retryAt time.Time) error {
+	query := `UPDATE ` + OutboxTable + ` SET last_error = $2, available_at = $3 WHERE id = $1`
+	_, err := o.pgPool.Exec(ctx, query, id, reason, retryAt)
+	return err
+}
+
+func (o *PostgresOutbox) GiveUp(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `UPDATE ` + OutboxTable + ` SET last_error = $2, failed_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := o.pgPool.Exec(ctx, query, id, reason)
+	return err
+}
+
+func (o *PostgresOutbox) DeleteDelivered(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `DELETE FROM ` + OutboxTable + ` WHERE id IN (
+            SELECT id FROM ` + OutboxTable + ` WHERE delivered_at < $1 LIMIT $2
+        )`
+	cmd, err := o.pgPool.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
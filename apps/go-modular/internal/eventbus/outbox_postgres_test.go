@@ -0,0 +1,149 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-modular/internal/adapter"
+	"go-modular/pkg/testutils"
+)
+
+func TestPostgresOutbox_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	outbox := NewPostgresOutbox(pool)
+	now := time.Now()
+	msgs := []OutboxMessage{
+		{ID: uuid.Must(uuid.NewV7()), EventName: "user.created", Subscriber: "a", Payload: []byte(`{"n":1}`), CreatedAt: now},
+		{ID: uuid.Must(uuid.NewV7()), EventName: "user.created", Subscriber: "b", Payload: []byte(`{"n":1}`), CreatedAt: now},
+		{ID: uuid.Must(uuid.NewV7()), EventName: "user.deleted", Subscriber: "a", Payload: []byte(`{"n":2}`), CreatedAt: now},
+	}
+	require.NoError(t, outbox.Enqueue(ctx, msgs))
+
+	// Claims are limited, counted and ordered by creation
+	claimed, err := outbox.Claim(ctx, 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, msgs[0].ID, claimed[0].ID)
+	assert.Equal(t, msgs[1].ID, claimed[1].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.JSONEq(t, `{"n":1}`, string(claimed[0].Payload))
+
+	// Leased messages are not claimed again
+	claimed, err = outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, msgs[2].ID, claimed[0].ID)
+
+	require.NoError(t, outbox.MarkDelivered(ctx, msgs[0].ID))
+	require.NoError(t, outbox.GiveUp(ctx, msgs[2].ID, "always fails"))
+	require.NoError(t, outbox.Retry(ctx, msgs[1].ID, "temporary failure", time.Now().Add(-time.Second)))
+
+	// Only the retried message is available again
+	claimed, err = outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, msgs[1].ID, claimed[0].ID)
+	assert.Equal(t, 2, claimed[0].Attempts)
+
+	var lastError string
+	require.NoError(t, pool.QueryRow(ctx, `SELECT last_error FROM `+OutboxTable+` WHERE id = $1`, msgs[2].ID).Scan(&lastError))
+	assert.Equal(t, "always fails", lastError)
+
+	// Only delivered messages are pruned
+	n, err := outbox.DeleteDelivered(ctx, time.Now().Add(time.Minute), 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	var remaining int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM `+OutboxTable).Scan(&remaining))
+	assert.Equal(t, 2, remaining)
+}
+
+func TestPostgresOutbox_EnqueueJoinsTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	outbox := NewPostgresOutbox(pool)
+	count := func() int {
+		var n int
+		require.NoError(t, pool.QueryRow(ctx, `SELECT count(*) FROM `+OutboxTable).Scan(&n))
+		return n
+	}
+	enqueueInTx := func(commit bool) {
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+		msg := OutboxMessage{ID: uuid.Must(uuid.NewV7()), EventName: "user.created", Subscriber: "a", Payload: []byte(`{}`), CreatedAt: time.Now()}
+		require.NoError(t, outbox.Enqueue(adapter.ContextWithTx(ctx, tx), []OutboxMessage{msg}))
+		assert.Equal(t, 0, count(), "not visible before commit")
+		if commit {
+			require.NoError(t, tx.Commit(ctx))
+		}
+	}
+
+	enqueueInTx(false)
+	assert.Equal(t, 0, count(), "rolled back with the transaction")
+	enqueueInTx(true)
+	assert.Equal(t, 1, count())
+}
+
+func TestBus_Publish_WithinUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	bus := newTestBus(NewPostgresOutbox(pool))
+	var syncCalls int
+	Subscribe(bus, "sync", func(context.Context, testEvent) error { syncCalls++; return nil })
+	Subscribe(bus, "async", func(context.Context, testEvent) error { return nil }, Async())
+	txm := adapter.NewTxManager(pool, adapter.TxManagerOpts{})
+	count := func(ctx context.Context) int {
+		var n int
+		require.NoError(t, adapter.Conn(ctx, pool).QueryRow(ctx, `SELECT count(*) FROM `+OutboxTable).Scan(&n))
+		return n
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, bus.Publish(ctx, testEvent{ID: 1}))
+			// Stored in the transaction, in-process handlers wait for the commit
+			assert.Equal(t, 1, count(ctx))
+			assert.Equal(t, 0, count(context.Background()))
+			assert.Zero(t, syncCalls)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count(ctx))
+		assert.Equal(t, 1, syncCalls)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, bus.Publish(ctx, testEvent{ID: 2}))
+			return errors.New("abort")
+		})
+		require.Error(t, err)
+		assert.Equal(t, 1, count(ctx), "the message of the rolled back unit of work is discarded")
+		assert.Equal(t, 1, syncCalls)
+	})
+}
@@ -1,12 +1,20 @@
 package middleware
 
 import (
+	"slices"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func CompressionMiddleware() echo.MiddlewareFunc {
+// CompressionMiddleware compresses responses with gzip. Streams are sent uncompressed, since
+// compression buffers their events: the given streaming routes ("METHOD /route/:param", e.g.
+// the realtime endpoints) and requests that look like streams, see IsStreamingRequest.
+func CompressionMiddleware(streamingRoutes ...string) echo.MiddlewareFunc {
 	return middleware.GzipWithConfig(middleware.GzipConfig{
 		Level: 5,
+		Skipper: func(c echo.Context) bool {
+			return slices.Contains(streamingRoutes, c.Request().Method+" "+c.Path()) || IsStreamingRequest(c.Request())
+		},
 	})
 }
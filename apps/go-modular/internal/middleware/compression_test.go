@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(CompressionMiddleware("GET /events/stream"))
+	body := strings.Repeat("data: hello\n\n", 100)
+	handler := func(c echo.Context) error { return c.String(http.StatusOK, body) }
+	e.GET("/events/stream", handler)
+	e.GET("/users", handler)
+
+	serve := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/users", nil)
+	assert.Equal(t, "gzip", rec.Header().Get(echo.HeaderContentEncoding))
+
+	// streaming routes are skipped even when the request does not look like a stream
+	rec = serve("/events/stream", nil)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, body, rec.Body.String())
+
+	rec = serve("/users", map[string]string{echo.HeaderAccept: "text/event-stream"})
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+}
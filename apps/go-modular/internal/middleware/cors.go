@@ -7,9 +7,12 @@ import (
 	emw "github.com/labstack/echo/v4/middleware"
 )
 
+// CORSMiddleware configures CORS from the app config. Config validation only allows
+// credentials together with explicit origins, so no site can make credentialed requests
+// with the session cookie of a user.
 func CORSMiddleware(cfg *config.Config) echo.MiddlewareFunc {
 	return emw.CORSWithConfig(emw.CORSConfig{
-		AllowOrigins: cfg.App.CORSOrigins,
+		AllowOrigins: cfg.GetCORSOrigins(),
 		AllowMethods: []string{
 			echo.GET,
 			echo.POST,
@@ -22,6 +25,11 @@ func CORSMiddleware(cfg *config.Config) echo.MiddlewareFunc {
 			echo.HeaderOrigin,
 			echo.HeaderContentType,
 			echo.HeaderAccept,
+			echo.HeaderAuthorization,
+			echo.HeaderXCSRFToken,
+			"If-Match",
+			"X-App-Audience",
+			"X-Device-Fingerprint",
 		},
 		ExposeHeaders: []string{
 			echo.HeaderAccept,
@@ -31,6 +39,7 @@ func CORSMiddleware(cfg *config.Config) echo.MiddlewareFunc {
 			echo.HeaderConnection,
 			echo.HeaderContentLength,
 			echo.HeaderContentType,
+			"ETag",
 			echo.HeaderOrigin,
 			echo.HeaderXCSRFToken,
 			echo.HeaderXRequestID,
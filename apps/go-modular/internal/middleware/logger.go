@@ -47,6 +47,17 @@ func LoggerMiddleware(logger *slog.Logger) echo.MiddlewareFunc {
 				logAttrs = append(logAttrs, slog.String("query", req.URL.RawQuery))
 			}
 
+			// Flag requests made with an admin impersonation token (set by the auth JWT middleware)
+			if impersonatorID, ok := c.Get("impersonator_id").(string); ok && impersonatorID != "" {
+				logAttrs = append(logAttrs,
+					slog.Bool("impersonated", true),
+					slog.String("impersonator_id", impersonatorID),
+				)
+				if userID, ok := c.Get("user_id").(string); ok {
+					logAttrs = append(logAttrs, slog.String("user_id", userID))
+				}
+			}
+
 			// Log with appropriate level based on status code
 			switch {
 			case status >= 500:
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"go-modular/internal/adapter"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadYourWritesMiddleware tracks the writes of each request (see adapter.ReadYourWrites),
+// so reads following a write in the same request go to the primary instead of a read
+// replica that may not have the write yet.
+func ReadYourWritesMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.SetRequest(c.Request().WithContext(adapter.ReadYourWrites(c.Request().Context())))
+			return next(c)
+		}
+	}
+}
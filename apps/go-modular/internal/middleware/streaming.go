@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// IsStreamingRequest reports whether r asks for a long-lived stream (a WebSocket upgrade or a
+// Server-Sent Events request). It is only a hint for skipping compression: the headers are
+// controlled by the client, so it must never exempt a request from authorization or resource
+// limits such as timeouts. Streaming routes are exempted by route instead.
+func IsStreamingRequest(r *http.Request) bool {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go-modular/internal/adapter"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// TenantTxMiddleware runs each request in a tenant-scoped transaction (row-level security).
+// The tenant is the organization the request was scoped to (apputils.OrgIDContextKey, set by
+// the organization scope middleware, which must run first), or else the org_id claim. The
+// user is taken from the sub claim stored in the request context by the JWT middleware. The
+// transaction is stored in the request context so repositories resolving their connection
+// with adapter.Conn use it.
+//
+// The transaction commits when the handler returns no error and the response status is
+// below 400, otherwise it is rolled back. Because the response is written by the handler,
+// a failed commit is logged but cannot change the status already sent.
+func TenantTxMiddleware(pg *adapter.PostgresDB, logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			var tenant adapter.Tenant
+			if orgID, ok := ctx.Value(apputils.OrgIDContextKey).(uuid.UUID); ok {
+				tenant.TenantID = orgID.String()
+			}
+			if claims, ok := ctx.Value(apputils.JWTClaimsContextKey).(map[string]any); ok {
+				if v, ok := claims["org_id"]; ok && v != nil && tenant.TenantID == "" {
+					tenant.TenantID = fmt.Sprint(v)
+				}
+				// service tokens have no user, their subject is the client ID
+				if v, ok := claims["sub"]; ok && v != nil && claims["typ"] != apputils.TokenTypeService {
+					tenant.UserID = fmt.Sprint(v)
+				}
+			}
+
+			tx, err := pg.BeginTenantTx(ctx, tenant)
+			if err != nil {
+				logger.Error("failed to begin tenant transaction", "error", err.Error())
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "database unavailable")
+			}
+			c.SetRequest(c.Request().WithContext(adapter.ContextWithTx(ctx, tx)))
+
+			err = next(c)
+			if err != nil || c.Response().Status >= http.StatusBadRequest {
+				_ = tx.Rollback(ctx)
+				return err
+			}
+			if cerr := tx.Commit(ctx); cerr != nil {
+				logger.Error("failed to commit tenant transaction", "error", cerr.Error())
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(RequestIDHeader, "req-1")
+			return next(c)
+		}
+	})
+	e.Use(TimeoutMiddlewareWithConfig(TimeoutConfig{
+		Timeout: 50 * time.Millisecond,
+		Routes: map[string]time.Duration{
+			"POST /upload": time.Second,
+			"/download":    0,
+		},
+	}))
+
+	slow := func(c echo.Context) error {
+		select {
+		case <-c.Request().Context().Done():
+			// Writes after the deadline are discarded
+			_ = c.String(http.StatusOK, "too late")
+			return c.Request().Context().Err()
+		case <-time.After(200 * time.Millisecond):
+			return c.String(http.StatusOK, "done")
+		}
+	}
+	e.GET("/slow", slow)
+	e.POST("/upload", slow)
+	e.GET("/download", slow)
+	e.GET("/fast", func(c echo.Context) error {
+		c.Response().Header().Set("X-Handler", "fast")
+		return c.JSON(http.StatusCreated, map[string]string{"ok": "yes"})
+	})
+	e.GET("/error", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot, "no coffee")
+	})
+	e.GET("/panic", func(c echo.Context) error {
+		panic("boom")
+	})
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("buffered_response_is_sent", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/fast")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"ok":"yes"}`, rec.Body.String())
+		assert.Equal(t, "fast", rec.Header().Get("X-Handler"))
+		assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("errors_reach_the_error_handler", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/error")
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Contains(t, rec.Body.String(), "no coffee")
+	})
+
+	t.Run("timeout_discards_the_response", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/slow")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+		assert.NotContains(t, rec.Body.String(), "too late")
+
+		var problem timeoutProblem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusServiceUnavailable, problem.Status)
+		assert.Equal(t, "/slow", problem.Instance)
+	})
+
+	t.Run("streaming_headers_do_not_disable_the_timeout", func(t *testing.T) {
+		for name, header := range map[string][2]string{
+			"sse":       {"Accept", "text/event-stream"},
+			"websocket": {"Upgrade", "websocket"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+				req.Header.Set(header[0], header[1])
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+				assert.NotContains(t, rec.Body.String(), "too late")
+			})
+		}
+	})
+
+	t.Run("route_overrides", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/upload")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "done", rec.Body.String())
+
+		rec = serve(http.MethodGet, "/download")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("panics_are_raised_in_the_request_goroutine", func(t *testing.T) {
+		assert.PanicsWithValue(t, "boom", func() { serve(http.MethodGet, "/panic") })
+	})
+}
+
+func TestTimeoutMiddleware_GatewayTimeout(t *testing.T) {
+	e := echo.New()
+	e.Use(TimeoutMiddlewareWithConfig(TimeoutConfig{Timeout: 10 * time.Millisecond, StatusCode: http.StatusGatewayTimeout}))
+	e.GET("/", func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return errors.New("query cancelled")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+
+	assert.Panics(t, func() {
+		TimeoutMiddlewareWithConfig(TimeoutConfig{Timeout: time.Second, StatusCode: http.StatusRequestTimeout})
+	})
+}
+
+func TestTimeoutMiddleware_RespondsWhileHandlerIsBlocked(t *testing.T) {
+	release := make(chan struct{})
+	e := echo.New()
+	e.Use(TimeoutMiddlewareWithConfig(TimeoutConfig{Timeout: 20 * time.Millisecond}))
+	e.GET("/stuck", func(c echo.Context) error {
+		// Ignores the deadline until the test received the response
+		<-release
+		return c.String(http.StatusOK, "too late")
+	})
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	res, err := client.Get(srv.URL + "/stuck")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int64(len(body)), res.ContentLength)
+	var problem timeoutProblem
+	require.NoError(t, json.Unmarshal(body, &problem))
+	assert.Equal(t, "/stuck", problem.Instance)
+}
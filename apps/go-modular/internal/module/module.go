@@ -0,0 +1,94 @@
+// Package module defines the lifecycle of the application modules and the registry wiring
+// them together. Modules register themselves from their init function; the server then
+// initializes them in dependency order, registers their routes and runs their background
+// work, and the migrator runs their migrations.
+package module
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"go-modular/internal/adapter"
+	"go-modular/internal/config"
+	"go-modular/internal/eventbus"
+	"go-modular/internal/notification"
+	"go-modular/internal/personaldata"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/labstack/echo/v4"
+)
+
+// Module is a feature of the application with its own routes, background work and schema.
+type Module interface {
+	// Name uniquely identifies the module; other modules depend on it by this name.
+	Name() string
+	// Dependencies returns the names of the modules initialized before this one.
+	Dependencies() []string
+	// Init builds the module from the shared dependencies and provides the services other
+	// modules look up. Services of the dependencies are available at this point.
+	Init(ctx context.Context, app *App) error
+	// RegisterRoutes registers the module endpoints to the API group, once all modules are
+	// initialized.
+	RegisterRoutes(g *echo.Group)
+	// Start starts the background work of the module, which stops when ctx is cancelled.
+	Start(ctx context.Context) error
+	// Stop releases the resources of the module after the HTTP server stopped.
+	Stop(ctx context.Context) error
+	// Migrations returns the goose SQL migrations of the module at the root of the FS, or
+	// nil. Versions share one sequence with the core migrations of the database package.
+	Migrations() fs.FS
+	// Health returns the checks reported by the health endpoint, or nil.
+	Health() []health.Check
+}
+
+// App holds the dependencies shared by all modules, and the services modules provide to
+// each other.
+type App struct {
+	Config      *config.Config
+	Logger      *slog.Logger
+	DB          *adapter.PostgresDB
+	Mailer      *notification.Mailer   // nil when no mailer is configured
+	Events      *eventbus.Bus          // domain event bus shared by all modules
+	DataExports *personaldata.Registry // personal data exporters of all modules
+	Echo        *echo.Echo             // for global middleware and server hooks
+
+	mu       sync.RWMutex
+	services map[reflect.Type]any
+}
+
+// Provide makes svc available to the other modules as a T, usually an interface of the
+// module's services package. It panics when a T was already provided.
+func Provide[T any](app *App, svc T) {
+	t := reflect.TypeFor[T]()
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.services == nil {
+		app.services = make(map[reflect.Type]any)
+	}
+	if _, ok := app.services[t]; ok {
+		panic(fmt.Sprintf("module: %s already provided", t))
+	}
+	app.services[t] = svc
+}
+
+// Lookup returns the T provided by a module, if any.
+func Lookup[T any](app *App) (T, bool) {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+	svc, ok := app.services[reflect.TypeFor[T]()].(T)
+	return svc, ok
+}
+
+// MustLookup returns the T provided by a module. It panics when none was provided, which
+// means a module is missing from the dependencies of the caller.
+func MustLookup[T any](app *App) T {
+	svc, ok := Lookup[T](app)
+	if !ok {
+		panic(fmt.Sprintf("module: no %s provided", reflect.TypeFor[T]()))
+	}
+	return svc
+}
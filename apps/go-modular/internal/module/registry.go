@@ -0,0 +1,198 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/labstack/echo/v4"
+)
+
+// Registry holds the modules of the application.
+type Registry struct {
+	mu      sync.Mutex
+	modules map[string]Module
+	started []Module // modules started, in dependency order
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{modules: make(map[string]Module)}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the registry modules add themselves to with Register.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds m to the default registry. Modules call it from their init function.
+func Register(m Module) {
+	defaultRegistry.Register(m)
+}
+
+// Register adds m to the registry. It panics when the name is empty or already registered.
+func (r *Registry) Register(m Module) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := m.Name()
+	if name == "" {
+		panic("module: empty module name")
+	}
+	if _, ok := r.modules[name]; ok {
+		panic("module: duplicate module " + name)
+	}
+	r.modules[name] = m
+}
+
+// Modules returns the registered modules in dependency order: every module comes after
+// its dependencies, modules are otherwise sorted by name. It fails when a dependency is not
+// registered or dependencies form a cycle.
+func (r *Registry) Modules() ([]Module, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.modules))
+	for name := range r.modules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(names))
+	ordered := make([]Module, 0, len(names))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+		m := r.modules[name]
+		state[name] = visiting
+		deps := slices.Clone(m.Dependencies())
+		slices.Sort(deps)
+		for _, dep := range deps {
+			if _, ok := r.modules[dep]; !ok {
+				return fmt.Errorf("module %s depends on unregistered module %s", name, dep)
+			}
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, m)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Init initializes all modules in dependency order.
+func (r *Registry) Init(ctx context.Context, app *App) error {
+	modules, err := r.Modules()
+	if err != nil {
+		return err
+	}
+	for _, m := range modules {
+		if app.Logger != nil {
+			app.Logger.Debug("Initializing module", "module", m.Name())
+		}
+		if err := m.Init(ctx, app); err != nil {
+			return fmt.Errorf("init module %s: %w", m.Name(), err)
+		}
+	}
+	return nil
+}
+
+// RegisterRoutes registers the routes of all modules to g.
+func (r *Registry) RegisterRoutes(g *echo.Group) error {
+	modules, err := r.Modules()
+	if err != nil {
+		return err
+	}
+	for _, m := range modules {
+		m.RegisterRoutes(g)
+	}
+	return nil
+}
+
+// Start starts all modules in dependency order. When a module fails to start, the modules
+// already started are stopped.
+func (r *Registry) Start(ctx context.Context) error {
+	modules, err := r.Modules()
+	if err != nil {
+		return err
+	}
+	for _, m := range modules {
+		if err := m.Start(ctx); err != nil {
+			err = fmt.Errorf("start module %s: %w", m.Name(), err)
+			return errors.Join(err, r.Stop(ctx))
+		}
+		r.mu.Lock()
+		r.started = append(r.started, m)
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop stops the started modules in reverse dependency order. All modules are stopped
+// even when some fail.
+func (r *Registry) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	started := r.started
+	r.started = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := started[i].Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop module %s: %w", started[i].Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Migrations returns the migrations of the modules that have some, by module name.
+func (r *Registry) Migrations() map[string]fs.FS {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	migrations := make(map[string]fs.FS)
+	for name, m := range r.modules {
+		if fsys := m.Migrations(); fsys != nil {
+			migrations[name] = fsys
+		}
+	}
+	return migrations
+}
+
+// HealthChecks returns the health checks of all modules. Check names are prefixed with the
+// module name.
+func (r *Registry) HealthChecks() []health.Check {
+	modules, err := r.Modules()
+	if err != nil {
+		return nil
+	}
+	var checks []health.Check
+	for _, m := range modules {
+		for _, check := range m.Health() {
+			check.Name = m.Name() + "." + check.Name
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
@@ -0,0 +1,134 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModule records its lifecycle calls in a shared log.
+type fakeModule struct {
+	name       string
+	deps       []string
+	log        *[]string
+	startErr   error
+	migrations fs.FS
+}
+
+func (m *fakeModule) Name() string           { return m.name }
+func (m *fakeModule) Dependencies() []string { return m.deps }
+
+func (m *fakeModule) Init(_ context.Context, app *App) error {
+	*m.log = append(*m.log, "init "+m.name)
+	return nil
+}
+
+func (m *fakeModule) RegisterRoutes(g *echo.Group) {
+	g.GET("/"+m.name, func(c echo.Context) error { return nil })
+}
+
+func (m *fakeModule) Start(context.Context) error {
+	*m.log = append(*m.log, "start "+m.name)
+	return m.startErr
+}
+
+func (m *fakeModule) Stop(context.Context) error {
+	*m.log = append(*m.log, "stop "+m.name)
+	return nil
+}
+
+func (m *fakeModule) Migrations() fs.FS { return m.migrations }
+
+func (m *fakeModule) Health() []health.Check {
+	return []health.Check{{Name: "ready", Check: func(context.Context) error { return nil }}}
+}
+
+func TestRegistry_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	var log []string
+	r := NewRegistry()
+	r.Register(&fakeModule{name: "auth", deps: []string{"user", "audit"}, log: &log})
+	r.Register(&fakeModule{name: "user", deps: []string{"audit"}, log: &log, migrations: fstest.MapFS{}})
+	r.Register(&fakeModule{name: "audit", log: &log})
+	r.Register(&fakeModule{name: "billing", log: &log})
+
+	require.NoError(t, r.Init(ctx, &App{}))
+	// Dependencies first, otherwise by name
+	assert.Equal(t, []string{"init audit", "init user", "init auth", "init billing"}, log)
+
+	e := echo.New()
+	require.NoError(t, r.RegisterRoutes(e.Group("/api")))
+	assert.Len(t, e.Routes(), 4)
+
+	log = nil
+	require.NoError(t, r.Start(ctx))
+	require.NoError(t, r.Stop(ctx))
+	assert.Equal(t, []string{
+		"start audit", "start user", "start auth", "start billing",
+		"stop billing", "stop auth", "stop user", "stop audit",
+	}, log)
+
+	assert.Len(t, r.Migrations(), 1)
+	assert.Contains(t, r.Migrations(), "user")
+	checks := r.HealthChecks()
+	require.Len(t, checks, 4)
+	assert.Equal(t, "audit.ready", checks[0].Name)
+}
+
+func TestRegistry_StartFailureStopsStartedModules(t *testing.T) {
+	var log []string
+	r := NewRegistry()
+	r.Register(&fakeModule{name: "audit", log: &log})
+	r.Register(&fakeModule{name: "user", deps: []string{"audit"}, log: &log, startErr: errors.New("boom")})
+
+	err := r.Start(context.Background())
+	assert.EqualError(t, err, "start module user: boom")
+	assert.Equal(t, []string{"start audit", "start user", "stop audit"}, log)
+}
+
+func TestRegistry_InvalidDependencies(t *testing.T) {
+	var log []string
+	r := NewRegistry()
+	r.Register(&fakeModule{name: "auth", deps: []string{"user"}, log: &log})
+	_, err := r.Modules()
+	assert.EqualError(t, err, "module auth depends on unregistered module user")
+
+	r.Register(&fakeModule{name: "user", deps: []string{"org"}, log: &log})
+	r.Register(&fakeModule{name: "org", deps: []string{"auth"}, log: &log})
+	_, err = r.Modules()
+	assert.EqualError(t, err, "module dependency cycle: auth -> user -> org -> auth")
+	assert.Error(t, r.Init(context.Background(), &App{}))
+	assert.Empty(t, log)
+
+	assert.PanicsWithValue(t, "module: duplicate module auth", func() {
+		r.Register(&fakeModule{name: "auth", log: &log})
+	})
+}
+
+type greeter interface{ Greet() string }
+
+type english struct{}
+
+func (english) Greet() string { return "hello" }
+
+func TestApp_ProvideLookup(t *testing.T) {
+	app := &App{}
+	_, ok := Lookup[greeter](app)
+	assert.False(t, ok)
+	assert.PanicsWithValue(t, "module: no module.greeter provided", func() { MustLookup[greeter](app) })
+
+	Provide[greeter](app, english{})
+	assert.Equal(t, "hello", MustLookup[greeter](app).Greet())
+
+	// Services are looked up by the provided type
+	_, ok = Lookup[english](app)
+	assert.False(t, ok)
+	assert.Panics(t, func() { Provide[greeter](app, english{}) })
+}
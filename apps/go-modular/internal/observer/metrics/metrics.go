@@ -1 +1,37 @@
+// Package metrics provides the application's metric instruments. Instruments are
+// created from the global OpenTelemetry meter provider and are no-ops until a
+// provider is registered (e.g. when telemetry is enabled).
 package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "go-modular"
+
+var (
+	instrumentsOnce sync.Once
+	prunedRows      metric.Int64Counter
+)
+
+func initInstruments() {
+	meter := otel.Meter(meterName)
+	prunedRows, _ = meter.Int64Counter("db.pruned_rows",
+		metric.WithDescription("Number of expired rows deleted by the token sweeper"),
+		metric.WithUnit("{row}"),
+	)
+}
+
+// RecordPrunedRows adds n to the number of expired rows deleted from table.
+func RecordPrunedRows(ctx context.Context, table string, n int64) {
+	instrumentsOnce.Do(initInstruments)
+	if prunedRows == nil || n <= 0 {
+		return
+	}
+	prunedRows.Add(ctx, n, metric.WithAttributes(attribute.String("table", table)))
+}
@@ -0,0 +1,159 @@
+// Package personaldata builds personal data exports (GDPR data portability). Every module
+// contributes the data it holds about a user through an Exporter registered in a Registry;
+// Build collects them into a single ZIP archive.
+package personaldata
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// ManifestFile is the name of the archive entry describing the export.
+const ManifestFile = "manifest.json"
+
+// exporterNameRegex matches valid exporter names, which are used as directory names.
+var exporterNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Exporter contributes the personal data a module holds about a user to an export.
+type Exporter interface {
+	// Name identifies the module. Its files are stored in a directory of that name.
+	Name() string
+	// Export writes the data held about userID to w.
+	Export(ctx context.Context, userID uuid.UUID, w *Writer) error
+}
+
+type exporterFunc struct {
+	name string
+	fn   func(ctx context.Context, userID uuid.UUID, w *Writer) error
+}
+
+func (e exporterFunc) Name() string { return e.name }
+
+func (e exporterFunc) Export(ctx context.Context, userID uuid.UUID, w *Writer) error {
+	return e.fn(ctx, userID, w)
+}
+
+// NewExporter returns an Exporter named name that calls fn.
+func NewExporter(name string, fn func(ctx context.Context, userID uuid.UUID, w *Writer) error) Exporter {
+	return exporterFunc{name: name, fn: fn}
+}
+
+// Registry holds the exporters of all modules. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	exporters []Exporter
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds an exporter. It panics when the name is invalid or already registered,
+// since both are programming errors found at startup.
+func (r *Registry) Register(e Exporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name := e.Name()
+	if !exporterNameRegex.MatchString(name) {
+		panic(fmt.Sprintf("personaldata: invalid exporter name %q", name))
+	}
+	for _, existing := range r.exporters {
+		if existing.Name() == name {
+			panic(fmt.Sprintf("personaldata: exporter %q registered twice", name))
+		}
+	}
+	r.exporters = append(r.exporters, e)
+}
+
+// Exporters returns the registered exporters in registration order.
+func (r *Registry) Exporters() []Exporter {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Exporter(nil), r.exporters...)
+}
+
+// Manifest describes the contents of an export archive.
+type Manifest struct {
+	UserID      uuid.UUID        `json:"user_id"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Modules     []ManifestModule `json:"modules"`
+}
+
+// ManifestModule lists the files contributed by one exporter.
+type ManifestModule struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// Build writes a ZIP archive with the data of every registered exporter, followed by a
+// manifest.json listing the files, to out. Any exporter error fails the whole export, so
+// users never receive an incomplete archive.
+func (r *Registry) Build(ctx context.Context, userID uuid.UUID, out io.Writer) (*Manifest, error) {
+	zw := zip.NewWriter(out)
+	manifest := &Manifest{UserID: userID, GeneratedAt: time.Now().UTC(), Modules: []ManifestModule{}}
+
+	for _, e := range r.Exporters() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		w := &Writer{zw: zw, dir: e.Name(), modified: manifest.GeneratedAt}
+		if err := e.Export(ctx, userID, w); err != nil {
+			return nil, fmt.Errorf("export %s data: %w", e.Name(), err)
+		}
+		manifest.Modules = append(manifest.Modules, ManifestModule{Name: e.Name(), Files: w.files})
+	}
+
+	mw := &Writer{zw: zw, modified: manifest.GeneratedAt}
+	if err := mw.WriteJSON(ManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Writer adds the files of one exporter to an export archive.
+type Writer struct {
+	zw       *zip.Writer
+	dir      string
+	modified time.Time
+	files    []string
+}
+
+// Create adds a file to the exporter's directory and returns a writer for its contents,
+// valid until the next call to Create or WriteJSON.
+func (w *Writer) Create(name string) (io.Writer, error) {
+	if name == "" || path.Clean(name) != name || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return nil, fmt.Errorf("personaldata: invalid file name %q", name)
+	}
+	full := path.Join(w.dir, name)
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: full, Method: zip.Deflate, Modified: w.modified})
+	if err != nil {
+		return nil, err
+	}
+	w.files = append(w.files, full)
+	return f, nil
+}
+
+// WriteJSON adds a file containing v as indented JSON.
+func (w *Writer) WriteJSON(name string, v any) error {
+	f, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,103 @@
+package personaldata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZip(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = data
+	}
+	return files
+}
+
+func TestRegistry_Build(t *testing.T) {
+	userID := uuid.Must(uuid.NewV7())
+	r := NewRegistry()
+	r.Register(NewExporter("user", func(ctx context.Context, id uuid.UUID, w *Writer) error {
+		return w.WriteJSON("profile.json", map[string]string{"id": id.String()})
+	}))
+	r.Register(NewExporter("audit", func(ctx context.Context, id uuid.UUID, w *Writer) error {
+		f, err := w.Create("events.ndjson")
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, "{}\n{}\n")
+		return err
+	}))
+	r.Register(NewExporter("empty", func(ctx context.Context, id uuid.UUID, w *Writer) error {
+		return nil
+	}))
+
+	var buf bytes.Buffer
+	manifest, err := r.Build(context.Background(), userID, &buf)
+	require.NoError(t, err)
+	require.Len(t, manifest.Modules, 3)
+	assert.Equal(t, []string{"user/profile.json"}, manifest.Modules[0].Files)
+	assert.Equal(t, []string{"audit/events.ndjson"}, manifest.Modules[1].Files)
+	assert.Empty(t, manifest.Modules[2].Files)
+
+	files := readZip(t, buf.Bytes())
+	assert.Len(t, files, 3)
+	assert.JSONEq(t, `{"id":"`+userID.String()+`"}`, string(files["user/profile.json"]))
+	assert.Equal(t, "{}\n{}\n", string(files["audit/events.ndjson"]))
+
+	var got Manifest
+	require.NoError(t, json.Unmarshal(files[ManifestFile], &got))
+	assert.Equal(t, userID, got.UserID)
+	assert.Len(t, got.Modules, 3)
+}
+
+func TestRegistry_BuildFailsOnExporterError(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewExporter("broken", func(ctx context.Context, id uuid.UUID, w *Writer) error {
+		return errors.New("boom")
+	}))
+
+	_, err := r.Build(context.Background(), uuid.Must(uuid.NewV7()), io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "export broken data: boom")
+}
+
+func TestRegistry_Register(t *testing.T) {
+	noop := func(ctx context.Context, id uuid.UUID, w *Writer) error { return nil }
+	r := NewRegistry()
+	r.Register(NewExporter("auth", noop))
+
+	assert.Panics(t, func() { r.Register(NewExporter("auth", noop)) }, "duplicate name")
+	assert.Panics(t, func() { r.Register(NewExporter("Auth/../x", noop)) }, "invalid name")
+	assert.Len(t, r.Exporters(), 1)
+}
+
+func TestWriter_CreateRejectsEscapingNames(t *testing.T) {
+	for _, name := range []string{"", "..", "../x.json", "/abs.json", "a/../../b.json", "./a.json"} {
+		t.Run(name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register(NewExporter("mod", func(ctx context.Context, id uuid.UUID, w *Writer) error {
+				_, err := w.Create(name)
+				return err
+			}))
+			_, err := r.Build(context.Background(), uuid.Must(uuid.NewV7()), io.Discard)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -26,6 +26,7 @@ type ServerHandler struct {
 	PGPool *pgxpool.Pool
 	Logger *slog.Logger
 	WebFS  embed.FS
+	Checks []health.Check // additional health checks, e.g. of the modules
 }
 
 // NewServerHandler creates a new ServerHandler.
@@ -86,9 +87,9 @@ func (h *ServerHandler) RootHandler(staticFS http.FileSystem) echo.HandlerFunc {
 // @Tags	        General Information
 // @Router		    /healthz [get]
 func (h *ServerHandler) HealthCheckHandler(c echo.Context) error {
-	hc := health.NewChecker(
-		health.WithCacheDuration(10*time.Second),
-		health.WithTimeout(5*time.Second),
+	opts := []health.CheckerOption{
+		health.WithCacheDuration(10 * time.Second),
+		health.WithTimeout(5 * time.Second),
 		health.WithCheck(health.Check{
 			Name:    "database",
 			Timeout: 2 * time.Second,
@@ -96,7 +97,11 @@ func (h *ServerHandler) HealthCheckHandler(c echo.Context) error {
 				return h.PGPool.Ping(ctx)
 			},
 		}),
-	)
+	}
+	for _, check := range h.Checks {
+		opts = append(opts, health.WithCheck(check))
+	}
+	hc := health.NewChecker(opts...)
 
 	// Transform health.NewHandler to Echo handler
 	handler := health.NewHandler(hc)
@@ -1,20 +1,29 @@
 package server
 
 import (
+	"context"
+
 	"go-modular/internal/adapter"
 	"go-modular/internal/config"
+	"go-modular/internal/eventbus"
 	"go-modular/internal/middleware"
+	"go-modular/internal/module"
 	"go-modular/internal/notification"
+	"go-modular/internal/personaldata"
 
 	"github.com/labstack/echo/v4"
 
-	modAuth "go-modular/modules/auth"
-	modUser "go-modular/modules/user"
+	// Register the application modules
+	_ "go-modular/modules"
 )
 
-// registerModules registers application modules, injects middleware and attaches routes.
-// Keeps Start() concise and centralizes module wiring for easier testing/refactor.
-func (s *HTTPServer) registerModules(cfg *config.Config, pg *adapter.PostgresDB, mailer *notification.Mailer, e *echo.Echo) error {
+// registerModules initializes the registered application modules (see internal/module) in
+// dependency order, injects global middleware and attaches routes. Background workers of
+// the modules are started with ctx and stop when it is cancelled; the returned registry
+// stops the modules on shutdown.
+func (s *HTTPServer) registerModules(ctx context.Context, cfg *config.Config, pg *adapter.PostgresDB, mailer *notification.Mailer, e *echo.Echo) (*module.Registry, error) {
+	registry := module.Default()
+
 	// Register primary HTTP server routes
 	serverHandler := NewServerHandler(pg.Pool, s.logger)
 	serverHandler.RegisterRoutes(e)
@@ -24,30 +33,50 @@ func (s *HTTPServer) registerModules(cfg *config.Config, pg *adapter.PostgresDB,
 	e.Use(middleware.RateLimitMiddleware(
 		cfg.App.RateLimitRequests, cfg.App.RateLimitBurstSize,
 	))
-	e.Use(middleware.CompressionMiddleware())
+	// Realtime streams are never compressed, whatever the headers of the request
+	e.Use(middleware.CompressionMiddleware("GET /api/v1/events/stream", "GET /api/v1/events/ws"))
+	e.Use(middleware.ReadYourWritesMiddleware())
+	if cfg.Auth.CookieSessionEnabled {
+		e.Use(middleware.CSRFMiddleware(cfg))
+	}
 
 	// Create API v1 route group
 	apiV1Route := e.Group("/api/v1")
 
-	// Load user module (no auth middleware yet)
-	userModule := modUser.NewModule(&modUser.Options{PgPool: pg.Pool, Logger: s.logger})
+	// Modules publish domain events and subscribe to each other's events on a shared bus
+	busOpts := eventbus.BusOpts{Logger: s.logger, PollInterval: cfg.App.EventOutboxPollInterval}
+	if cfg.App.EventOutboxEnabled {
+		busOpts.Outbox = eventbus.NewPostgresOutbox(pg.Pool)
+	}
+	events := eventbus.NewBus(busOpts)
+
+	app := &module.App{
+		Config: cfg,
+		Logger: s.logger,
+		DB:     pg,
+		Mailer: mailer,
+		Events: events,
+		Echo:   e,
 
-	// Load auth module (requires user service)
-	authModule := modAuth.NewModule(&modAuth.Options{
-		PgPool:       pg.Pool,
-		Logger:       s.logger,
-		UserService:  userModule.GetUserService(),
-		JWTSecretKey: []byte(cfg.App.JWTSecretKey),
-		BaseURL:      cfg.GetAppBaseURL(),
-		Mailer:       mailer,
-	})
+		// Every module contributes the personal data it holds to user data exports
+		DataExports: personaldata.NewRegistry(),
+	}
+	if err := registry.Init(ctx, app); err != nil {
+		return nil, err
+	}
+	serverHandler.Checks = registry.HealthChecks()
 
-	// Inject auth middleware into user module so protected user routes use same JWT config
-	userModule.Use(authModule.JWTMiddleware())
+	// Deliver stored events once all modules subscribed
+	go events.RunOutbox(ctx)
 
-	// Register the module routes after injecting middleware
-	userModule.RegisterRoutes(apiV1Route)
-	authModule.RegisterRoutes(apiV1Route)
+	// Register the module routes once all modules are initialized, so they can use each
+	// other's middleware
+	if err := registry.RegisterRoutes(apiV1Route); err != nil {
+		return nil, err
+	}
+	if err := registry.Start(ctx); err != nil {
+		return nil, err
+	}
 
-	return nil
+	return registry, nil
 }
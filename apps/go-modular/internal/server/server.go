@@ -84,12 +84,31 @@ func (s *HTTPServer) Start() error {
 	}))
 
 	e.Use(appMiddleware.RequestIDMiddleware())
-	e.Use(appMiddleware.SecurityHeadersMiddleware())         // Globally enable security headers
-	e.Use(appMiddleware.TimeoutMiddleware(time.Second * 30)) // Maximum request timeout: 30s
+	e.Use(appMiddleware.SecurityHeadersMiddleware()) // Globally enable security headers
+	// Logs the response actually sent, including timeout responses
 	e.Use(appMiddleware.LoggerMiddleware(s.logger))
+	e.Use(appMiddleware.TimeoutMiddlewareWithConfig(appMiddleware.TimeoutConfig{
+		Timeout: 30 * time.Second, // Maximum request timeout: 30s
+		Routes: map[string]time.Duration{
+			"POST /api/v1/auth/signin/email":    10 * time.Second,
+			"POST /api/v1/auth/signin/username": 10 * time.Second,
+			"POST /api/v1/users/imports":        5 * time.Minute, // CSV/JSON uploads
+			// Downloads and realtime connections are streamed, so they are neither limited
+			// nor buffered
+			"GET /api/v1/users/exports/:jobId/download": 0,
+			"GET /api/v1/users/data-exports/download":   0,
+			"GET /api/v1/events/stream":                 0,
+			"GET /api/v1/events/ws":                     0,
+		},
+	}))
+
+	// Background workers started by modules are stopped before the DB pool is closed
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
 
 	// Register modules and their route, put inside helper to make Start tidy
-	if err := s.registerModules(cfg, pg, mailer, e); err != nil {
+	modules, err := s.registerModules(bgCtx, cfg, pg, mailer, e)
+	if err != nil {
 		s.logger.Error("failed to register modules", "err", err)
 		// decide whether to exit or continue; here we exit as server without routes is useless
 		return err
@@ -134,6 +153,12 @@ func (s *HTTPServer) Start() error {
 		s.logger.Error("failed to shutdown HTTP server gracefully", "err", err)
 	}
 
+	// Stop modules and their background workers
+	if err := modules.Stop(shutdownCtx); err != nil {
+		s.logger.Error("failed to stop modules", "err", err)
+	}
+	stopBackground()
+
 	// Close DB pool
 	s.logger.Info("Closing database connections")
 	pg.Close()
@@ -175,7 +200,13 @@ func (s *HTTPServer) initializeDatabase(cfg *config.Config) (*adapter.PostgresDB
 	attempt := 1
 
 	for {
-		pgCfg := adapter.PostgresConfig{URL: cfg.GetDatabaseURL()}
+		pgCfg := adapter.PostgresConfig{
+			URL:           cfg.GetDatabaseURL(),
+			TenantRole:    cfg.Database.PgTenantRole,
+			ReplicaURLs:   cfg.GetDatabaseReplicaURLs(),
+			MaxReplicaLag: cfg.Database.PgMaxReplicaLag,
+			Logger:        s.logger,
+		}
 		pg, err := adapter.NewPostgres(pgCfg)
 		if err == nil {
 			// verify connection with Ping and timeout
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-modular/modules/audit/models"
+	"go-modular/modules/audit/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// HandlerInterface defines the contract for audit handlers.
+type HandlerInterface interface {
+	ListAuditEvents(c echo.Context) error
+}
+
+// Ensure Handler implements HandlerInterface
+var _ HandlerInterface = (*Handler)(nil)
+
+// Handler holds dependencies for audit handlers.
+type Handler struct {
+	logger       *slog.Logger
+	auditService services.AuditServiceInterface
+	validator    *validator.Validate
+}
+
+type HandlerOpts struct {
+	Logger       *slog.Logger
+	AuditService services.AuditServiceInterface
+}
+
+// NewHandler creates a new Handler instance.
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:       opts.Logger,
+		auditService: opts.AuditService,
+		validator:    validator.New(),
+	}
+}
+
+// @Summary      List audit events
+// @Description  Retrieves security audit events, newest first. Action accepts a trailing "*" for prefix match (e.g. auth.*) (admin only)
+// @Tags         Audit
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true   "Bearer {token}"
+// @Param        actor_id       query     string  false  "Actor user ID"
+// @Param        action         query     string  false  "Action, or prefix ending with *"
+// @Param        target_type    query     string  false  "Target type (user, session, passkey, ...)"
+// @Param        target_id      query     string  false  "Target ID"
+// @Param        since          query     string  false  "RFC3339 lower bound (inclusive)"
+// @Param        until          query     string  false  "RFC3339 upper bound (exclusive)"
+// @Param        limit          query     int     false  "Page size (default 50, max 500)"
+// @Param        offset         query     int     false  "Offset"
+// @Produce      json
+// @Success      200  {array}   models.AuditEvent
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Router       /api/v1/audit-events [get]
+func (h *Handler) ListAuditEvents(c echo.Context) error {
+	var req models.ListAuditEventsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid filter parameters"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	filter, err := FilterFromRequest(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 50
+	}
+
+	events, err := h.auditService.ListEvents(c.Request().Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list audit events", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve audit events"})
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
+
+// FilterFromRequest converts validated query parameters into a repository filter.
+func FilterFromRequest(req models.ListAuditEventsRequest) (*models.FilterAuditEvent, error) {
+	filter := &models.FilterAuditEvent{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	if req.ActorID != "" {
+		id, err := uuid.FromString(req.ActorID)
+		if err != nil {
+			return nil, err
+		}
+		filter.ActorID = &id
+	}
+	if req.Action != "" {
+		filter.Action = &req.Action
+	}
+	if req.TargetType != "" {
+		filter.TargetType = &req.TargetType
+	}
+	if req.TargetID != "" {
+		filter.TargetID = &req.TargetID
+	}
+	if req.Since != "" {
+		t, err := time.Parse(time.RFC3339, req.Since)
+		if err != nil {
+			return nil, err
+		}
+		filter.Since = &t
+	}
+	if req.Until != "" {
+		t, err := time.Parse(time.RFC3339, req.Until)
+		if err != nil {
+			return nil, err
+		}
+		filter.Until = &t
+	}
+	return filter, nil
+}
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create audit events table and indexes
+-- Append-only security audit log of authentication and admin events.
+-- actor_id is not a foreign key so events survive user deletion.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.audit_events (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    actor_id UUID DEFAULT NULL, -- user who performed the action (NULL for anonymous/system)
+    action TEXT NOT NULL CHECK (char_length(action) > 0), -- e.g. auth.signin.succeeded, user.deleted
+    target_type TEXT DEFAULT NULL, -- e.g. user, session, passkey
+    target_id TEXT DEFAULT NULL,
+    ip_address INET DEFAULT NULL,
+    user_agent TEXT DEFAULT NULL,
+    request_id TEXT DEFAULT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Audit events table indexes
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON public.audit_events (actor_id) WHERE actor_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON public.audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target ON public.audit_events (target_type, target_id) WHERE target_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON public.audit_events (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop indexes, and table(s) (reverse order of creation)
+DROP INDEX IF EXISTS idx_audit_events_actor_id;
+DROP INDEX IF EXISTS idx_audit_events_action;
+DROP INDEX IF EXISTS idx_audit_events_target;
+DROP INDEX IF EXISTS idx_audit_events_created_at;
+DROP TABLE IF EXISTS public.audit_events;
+
+-- +goose StatementEnd
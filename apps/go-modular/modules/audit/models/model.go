@@ -0,0 +1,96 @@
+// Package models contains struct definitions related to the database layer for the audit module.
+// This file defines models that map to database tables and are used for database operations.
+
+package models
+
+import (
+	"net"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Define table name for AuditEvent model
+const AuditEventTable = "public.audit_events"
+
+// AuditAction identifies what happened. Actions are dot-separated: <module>.<resource>.<verb>.
+type AuditAction string
+
+const (
+	ActionSignInSucceeded      AuditAction = "auth.signin.succeeded"
+	ActionSignInFailed         AuditAction = "auth.signin.failed"
+	ActionSignInFlagged        AuditAction = "auth.signin.flagged"
+	ActionPasswordSet          AuditAction = "auth.password.set"
+	ActionPasswordChanged      AuditAction = "auth.password.changed"
+	ActionPasswordChangeFailed AuditAction = "auth.password.change_failed"
+	ActionSessionRevoked       AuditAction = "auth.session.revoked"
+	ActionRefreshTokenRevoked  AuditAction = "auth.refresh_token.revoked"
+	ActionEmailVerified        AuditAction = "auth.email.verified"
+	ActionPasskeyRegistered    AuditAction = "auth.passkey.registered"
+	ActionPasskeyDeleted       AuditAction = "auth.passkey.deleted"
+	ActionUserCreated          AuditAction = "user.created"
+	ActionUserUpdated          AuditAction = "user.updated"
+	ActionUserDeleted          AuditAction = "user.deleted"
+	ActionOrgCreated           AuditAction = "org.created"
+	ActionOrgUpdated           AuditAction = "org.updated"
+	ActionOrgDeleted           AuditAction = "org.deleted"
+	ActionOrgMemberAdded       AuditAction = "org.member.added"
+	ActionOrgMemberRoleChanged AuditAction = "org.member.role_changed"
+	ActionOrgMemberRemoved     AuditAction = "org.member.removed"
+	ActionOrgInvitationSent    AuditAction = "org.invitation.sent"
+	ActionOrgInvitationRevoked AuditAction = "org.invitation.revoked"
+	ActionServiceTokenIssued   AuditAction = "auth.service_token.issued"
+	ActionServiceTokenFailed   AuditAction = "auth.service_token.failed"
+	ActionServiceClientCreated AuditAction = "auth.service_client.created"
+	ActionServiceClientRevoked AuditAction = "auth.service_client.revoked"
+	ActionImpersonationStarted AuditAction = "auth.impersonation.started"
+	ActionUserAdminGranted     AuditAction = "user.admin.granted"
+	ActionUserAdminRevoked     AuditAction = "user.admin.revoked"
+	ActionUsersImported        AuditAction = "user.bulk.imported"
+	ActionUsersExported        AuditAction = "user.bulk.exported"
+	ActionDataExportRequested  AuditAction = "user.data_export.requested"
+	ActionDataExportDownloaded AuditAction = "user.data_export.downloaded"
+	ActionWebhookCreated       AuditAction = "webhook.endpoint.created"
+	ActionWebhookUpdated       AuditAction = "webhook.endpoint.updated"
+	ActionWebhookDeleted       AuditAction = "webhook.endpoint.deleted"
+	ActionWebhookDisabled      AuditAction = "webhook.endpoint.disabled"
+	ActionWebhookReplayed      AuditAction = "webhook.delivery.replayed"
+)
+
+// Common target types
+const (
+	TargetUser          = "user"
+	TargetSession       = "session"
+	TargetRefreshToken  = "refresh_token"
+	TargetPasskey       = "passkey"
+	TargetOrganization  = "organization"
+	TargetServiceClient = "service_client"
+	TargetWebhook       = "webhook_endpoint"
+	TargetDelivery      = "webhook_delivery"
+)
+
+// AuditEvent represents a single entry in the security audit log.
+type AuditEvent struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	ActorID    *uuid.UUID     `json:"actor_id" db:"actor_id"`       // User who performed the action (nil for anonymous)
+	Action     AuditAction    `json:"action" db:"action"`           // What happened
+	TargetType *string        `json:"target_type" db:"target_type"` // Kind of resource affected
+	TargetID   *string        `json:"target_id" db:"target_id"`     // ID of the resource affected
+	IPAddress  *net.IP        `json:"ip_address" db:"ip_address"`
+	UserAgent  *string        `json:"user_agent" db:"user_agent"`
+	RequestID  *string        `json:"request_id" db:"request_id"`
+	Metadata   map[string]any `json:"metadata,omitempty" db:"metadata"` // Arbitrary JSON metadata (stored as JSONB)
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+}
+
+// FilterAuditEvent narrows down audit event queries. Nil/zero fields are ignored.
+type FilterAuditEvent struct {
+	ActorID    *uuid.UUID
+	Action     *string // exact action, or prefix when ending with "*" (e.g. "auth.*")
+	TargetType *string
+	TargetID   *string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
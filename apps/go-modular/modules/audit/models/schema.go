@@ -0,0 +1,16 @@
+// Package models contains HTTP request/response schema definitions for the audit module.
+// This file defines struct types used for HTTP payloads, validation, and OpenAPI documentation.
+
+package models
+
+// ListAuditEventsRequest represents the query parameters accepted by GET /audit-events.
+type ListAuditEventsRequest struct {
+	ActorID    string `query:"actor_id" validate:"omitempty,uuid" example:"0198c5a2-7d4e-7b3a-9f1e-2a6c8d9e0f11"`
+	Action     string `query:"action" validate:"omitempty,max=128" example:"auth.signin.*"`
+	TargetType string `query:"target_type" validate:"omitempty,max=64" example:"user"`
+	TargetID   string `query:"target_id" validate:"omitempty,max=128"`
+	Since      string `query:"since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" example:"2025-01-01T00:00:00Z"`
+	Until      string `query:"until" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" example:"2025-12-31T23:59:59Z"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=500" example:"50"`
+	Offset     int    `query:"offset" validate:"omitempty,min=0" example:"0"`
+}
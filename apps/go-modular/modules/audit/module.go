@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"go-modular/internal/middleware"
+	"go-modular/internal/module"
+	"go-modular/internal/personaldata"
+	"go-modular/modules/audit/handler"
+	"go-modular/modules/audit/repository"
+	"go-modular/modules/audit/services"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+
+	modAuth "go-modular/modules/auth"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func init() {
+	module.Register(&AuditModule{})
+}
+
+type Options struct {
+	PgPool *pgxpool.Pool // PostgreSQL connection pool (required)
+	Logger *slog.Logger  // Slog logger instance (optional)
+
+	// DataExports receives the module's personal data exporter (optional)
+	DataExports *personaldata.Registry
+}
+
+// AuditModule holds dependencies for audit-related handlers.
+type AuditModule struct {
+	app          *module.App // set when initialized by the module registry
+	logger       *slog.Logger
+	middlewares  []echo.MiddlewareFunc
+	handler      *handler.Handler
+	auditService services.AuditServiceInterface
+}
+
+// NewModule creates a new AuditModule.
+func NewModule(opts *Options) *AuditModule {
+	if opts.PgPool == nil {
+		panic("invalid audit module options: PgPool is required")
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+
+	auditService := services.NewAuditService(services.AuditServiceOpts{
+		AuditRepo: repository.NewAuditRepository(opts.PgPool, logger),
+		Logger:    logger,
+	})
+
+	if opts.DataExports != nil {
+		opts.DataExports.Register(personaldata.NewExporter("audit", auditService.ExportPersonalData))
+	}
+
+	h := handler.NewHandler(&handler.HandlerOpts{
+		Logger:       logger,
+		AuditService: auditService,
+	})
+
+	return &AuditModule{
+		logger:       logger,
+		handler:      h,
+		auditService: auditService,
+	}
+}
+
+// Ensure AuditModule implements module.Module
+var _ module.Module = (*AuditModule)(nil)
+
+func (m *AuditModule) Name() string { return "audit" }
+
+func (m *AuditModule) Dependencies() []string { return nil }
+
+// Init builds the module and provides its service as the auditSvc.Recorder of the other
+// modules. The request context middleware is registered globally.
+func (m *AuditModule) Init(_ context.Context, app *module.App) error {
+	*m = *NewModule(&Options{PgPool: app.DB.Pool, Logger: app.Logger, DataExports: app.DataExports})
+	m.app = app
+	module.Provide[services.Recorder](app, m.auditService)
+	app.Echo.Use(m.RequestContextMiddleware())
+	return nil
+}
+
+func (m *AuditModule) Start(context.Context) error { return nil }
+
+func (m *AuditModule) Stop(context.Context) error { return nil }
+
+func (m *AuditModule) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	return sub
+}
+
+func (m *AuditModule) Health() []health.Check { return nil }
+
+// Expose AuditService, so other modules can record events
+func (m *AuditModule) GetAuditService() services.AuditServiceInterface {
+	return m.auditService
+}
+
+// RequestContextMiddleware stores the client IP, user agent and request ID in the request
+// context so audit events recorded by services are enriched automatically.
+// Register it globally, after RequestIDMiddleware.
+func (m *AuditModule) RequestContextMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := services.WithRequestInfo(c.Request().Context(), services.RequestInfo{
+				IPAddress: c.RealIP(),
+				UserAgent: c.Request().UserAgent(),
+				RequestID: middleware.GetRequestIDFromEcho(c),
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// Use adds middleware(s) to the AuditModule (grouped).
+func (m *AuditModule) Use(mw ...echo.MiddlewareFunc) {
+	m.middlewares = append(m.middlewares, mw...)
+}
+
+// RegisterRoutes registers audit endpoints to the given Echo group.
+func (m *AuditModule) RegisterRoutes(e *echo.Group) {
+	// Modules initialized by the registry authenticate with the auth module
+	if m.app != nil {
+		authModule := module.MustLookup[*modAuth.AuthModule](m.app)
+		m.Use(authModule.JWTMiddleware(), modAuth.DenyImpersonation(), authModule.RequireAdmin(), modAuth.RequireResourceScopes("audit"))
+	}
+	g := e.Group("/audit-events", m.middlewares...)
+	g.GET("", m.handler.ListAuditEvents)
+}
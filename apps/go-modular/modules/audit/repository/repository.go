@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"go-modular/modules/audit/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepositoryInterface defines the contract for audit event data access.
+type AuditRepositoryInterface interface {
+	CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter *models.FilterAuditEvent) ([]*models.AuditEvent, error)
+	IterateAuditEvents(ctx context.Context, filter *models.FilterAuditEvent, fn func(*models.AuditEvent) error) error
+}
+
+// Ensure AuditRepository implements AuditRepositoryInterface
+var _ AuditRepositoryInterface = (*AuditRepository)(nil)
+
+// AuditRepository implements AuditRepositoryInterface using pgxpool.
+type AuditRepository struct {
+	pgPool *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewAuditRepository creates a new AuditRepository with pgxpool and slog logger.
+func NewAuditRepository(pgPool *pgxpool.Pool, logger *slog.Logger) *AuditRepository {
+	return &AuditRepository{
+		pgPool: pgPool,
+		logger: logger,
+	}
+}
+
+// CreateAuditEvent inserts a new audit event. Audit events are append-only.
+func (r *AuditRepository) CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.Must(uuid.NewV7())
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	metaArg := []byte("{}")
+	if event.Metadata != nil {
+		b, err := json.Marshal(event.Metadata)
+		if err != nil {
+			r.logger.Error("failed to marshal metadata for audit event", "op", "CreateAuditEvent", "action", string(event.Action), "error", err.Error())
+			return err
+		}
+		metaArg = b
+	}
+
+	query := `INSERT INTO ` + models.AuditEventTable + `
+        (id, actor_id, action, target_type, target_id, ip_address, user_agent, request_id, metadata, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.pgPool.Exec(ctx, query,
+		event.ID,
+		event.ActorID,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestID,
+		metaArg,
+		event.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("failed to insert audit event", "op", "CreateAuditEvent", "action", string(event.Action), "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// ListAuditEvents returns audit events matching the filter, newest first.
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, filter *models.FilterAuditEvent) ([]*models.AuditEvent, error) {
+	events := []*models.AuditEvent{}
+	err := r.IterateAuditEvents(ctx, filter, func(e *models.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// IterateAuditEvents streams audit events matching the filter (newest first) to fn
+// without loading them all into memory. Iteration stops at the first error from fn.
+func (r *AuditRepository) IterateAuditEvents(ctx context.Context, filter *models.FilterAuditEvent, fn func(*models.AuditEvent) error) error {
+	query, args := buildListQuery(filter)
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to query audit events", "op", "IterateAuditEvents", "error", err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			r.logger.Error("failed to scan audit event", "op", "IterateAuditEvents", "error", err.Error())
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func buildListQuery(filter *models.FilterAuditEvent) (string, []any) {
+	query := `SELECT id, actor_id, action, target_type, target_id, ip_address, user_agent, request_id, metadata, created_at
+        FROM ` + models.AuditEventTable
+	args := []any{}
+	where := []string{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter != nil {
+		if filter.ActorID != nil {
+			where = append(where, "actor_id = "+arg(*filter.ActorID))
+		}
+		if filter.Action != nil && *filter.Action != "" {
+			if prefix, ok := strings.CutSuffix(*filter.Action, "*"); ok {
+				where = append(where, "action LIKE "+arg(escapeLike(prefix)+"%"))
+			} else {
+				where = append(where, "action = "+arg(*filter.Action))
+			}
+		}
+		if filter.TargetType != nil && *filter.TargetType != "" {
+			where = append(where, "target_type = "+arg(*filter.TargetType))
+		}
+		if filter.TargetID != nil && *filter.TargetID != "" {
+			where = append(where, "target_id = "+arg(*filter.TargetID))
+		}
+		if filter.Since != nil {
+			where = append(where, "created_at >= "+arg(*filter.Since))
+		}
+		if filter.Until != nil {
+			where = append(where, "created_at < "+arg(*filter.Until))
+		}
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if filter != nil && filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit)
+	}
+	if filter != nil && filter.Offset > 0 {
+		query += " OFFSET " + arg(filter.Offset)
+	}
+	return query, args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func scanAuditEvent(row pgx.Row) (*models.AuditEvent, error) {
+	var e models.AuditEvent
+	var ip net.IP
+	var metaBytes []byte
+	err := row.Scan(
+		&e.ID,
+		&e.ActorID,
+		&e.Action,
+		&e.TargetType,
+		&e.TargetID,
+		&ip,
+		&e.UserAgent,
+		&e.RequestID,
+		&metaBytes,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if ip != nil {
+		e.IPAddress = &ip
+	}
+	if len(metaBytes) > 0 {
+		var m map[string]any
+		if err := json.Unmarshal(metaBytes, &m); err == nil && len(m) > 0 {
+			e.Metadata = m
+		}
+	}
+	return &e, nil
+}
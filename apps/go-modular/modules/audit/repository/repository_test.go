@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"go-modular/modules/audit/models"
+	"go-modular/pkg/testutils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+}
+
+func setupRepo(t *testing.T) (*AuditRepository, func()) {
+	t.Helper()
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+
+	// ensure env/config is set for migrations and app code
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	repo := NewAuditRepository(pool, newLogger())
+
+	teardown := func() {
+		_, _ = pool.Exec(context.Background(), `TRUNCATE TABLE `+models.AuditEventTable)
+		pool.Close()
+	}
+
+	return repo, teardown
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestAuditRepository_Create_List_Filter(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	actor := uuid.Must(uuid.NewV7())
+	ip := net.ParseIP("203.0.113.7")
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	events := []*models.AuditEvent{
+		{ActorID: &actor, Action: models.ActionSignInSucceeded, TargetType: strPtr(models.TargetUser), TargetID: strPtr(actor.String()), IPAddress: &ip, UserAgent: strPtr("test-agent"), RequestID: strPtr("req_1"), Metadata: map[string]any{"method": "password"}, CreatedAt: base},
+		{Action: models.ActionSignInFailed, Metadata: map[string]any{"reason": "user_not_found"}, CreatedAt: base.Add(time.Minute)},
+		{ActorID: &actor, Action: models.ActionUserDeleted, TargetType: strPtr(models.TargetUser), TargetID: strPtr("other"), CreatedAt: base.Add(2 * time.Minute)},
+	}
+	for _, e := range events {
+		require.NoError(t, repo.CreateAuditEvent(ctx, e))
+		require.NotEqual(t, uuid.Nil, e.ID)
+	}
+
+	// list all, newest first
+	all, err := repo.ListAuditEvents(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, models.ActionUserDeleted, all[0].Action)
+	assert.Equal(t, models.ActionSignInSucceeded, all[2].Action)
+	require.NotNil(t, all[2].IPAddress)
+	assert.Equal(t, "203.0.113.7", all[2].IPAddress.String())
+	assert.Equal(t, "password", all[2].Metadata["method"])
+	assert.Nil(t, all[1].ActorID)
+
+	// filter by actor
+	byActor, err := repo.ListAuditEvents(ctx, &models.FilterAuditEvent{ActorID: &actor})
+	require.NoError(t, err)
+	assert.Len(t, byActor, 2)
+
+	// filter by action prefix
+	signins, err := repo.ListAuditEvents(ctx, &models.FilterAuditEvent{Action: strPtr("auth.signin.*")})
+	require.NoError(t, err)
+	assert.Len(t, signins, 2)
+
+	// filter by exact action and target
+	deleted, err := repo.ListAuditEvents(ctx, &models.FilterAuditEvent{Action: strPtr(string(models.ActionUserDeleted)), TargetID: strPtr("other")})
+	require.NoError(t, err)
+	assert.Len(t, deleted, 1)
+
+	// filter by time window and paging
+	since := base.Add(30 * time.Second)
+	windowed, err := repo.ListAuditEvents(ctx, &models.FilterAuditEvent{Since: &since, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, windowed, 1)
+	assert.Equal(t, models.ActionSignInFailed, windowed[0].Action)
+
+	// iterate stops on callback error
+	var buf bytes.Buffer
+	stop := io.ErrShortWrite
+	n := 0
+	err = repo.IterateAuditEvents(ctx, nil, func(e *models.AuditEvent) error {
+		n++
+		buf.WriteString(string(e.Action))
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type ctxKey string
+
+const requestInfoCtxKey ctxKey = "go-modular.audit.request_info"
+
+// RequestInfo holds request attributes recorded alongside audit events.
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+// WithRequestInfo stores request attributes in ctx so that audit events recorded
+// deeper in the call stack (services) can be enriched without threading them manually.
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoCtxKey, info)
+}
+
+// RequestInfoFromContext returns the request attributes stored by WithRequestInfo.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoCtxKey).(RequestInfo)
+	return info, ok
+}
+
+// ActorFromContext returns the authenticated user ID placed in ctx by the JWT middleware.
+// For impersonation tokens the acting admin ("act" claim, RFC 8693) is returned instead of
+// the impersonated "sub", so actions taken while impersonating are attributed to the admin.
+func ActorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	claims, ok := ctx.Value(apputils.JWTClaimsContextKey).(map[string]any)
+	if !ok {
+		return uuid.Nil, false
+	}
+	sub, ok := claims["sub"]
+	if act, isMap := claims["act"].(map[string]any); isMap {
+		sub, ok = act["sub"]
+	}
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromString(fmt.Sprint(sub))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseIP returns nil when s is not a valid IP address.
+func parseIP(s string) *net.IP {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil
+	}
+	return &ip
+}
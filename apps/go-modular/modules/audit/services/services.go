@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+
+	"go-modular/modules/audit/models"
+	"go-modular/modules/audit/repository"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Recorder records audit events. Other modules depend on this narrow interface only.
+// Recording is best-effort: failures are logged and never fail the audited operation.
+type Recorder interface {
+	Record(ctx context.Context, event *models.AuditEvent)
+}
+
+// AuditServiceInterface defines the contract for audit business logic.
+type AuditServiceInterface interface {
+	Recorder
+	ListEvents(ctx context.Context, filter *models.FilterAuditEvent) ([]*models.AuditEvent, error)
+	ExportEvents(ctx context.Context, filter *models.FilterAuditEvent, w io.Writer) (int, error)
+}
+
+// Ensure AuditService implements AuditServiceInterface
+var _ AuditServiceInterface = (*AuditService)(nil)
+
+// AuditService implements audit business logic using an AuditRepositoryInterface.
+type AuditService struct {
+	auditRepo repository.AuditRepositoryInterface
+	logger    *slog.Logger
+}
+
+type AuditServiceOpts struct {
+	AuditRepo repository.AuditRepositoryInterface
+	Logger    *slog.Logger
+}
+
+// NewAuditService creates a new AuditService.
+func NewAuditService(opts AuditServiceOpts) *AuditService {
+	if opts.AuditRepo == nil {
+		panic("AuditRepo is required")
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &AuditService{
+		auditRepo: opts.AuditRepo,
+		logger:    opts.Logger,
+	}
+}
+
+// Record stores an audit event. Actor, IP address, user agent and request ID are
+// filled from ctx when not set on the event.
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) {
+	if event == nil {
+		return
+	}
+	if event.ActorID == nil {
+		if actor, ok := ActorFromContext(ctx); ok {
+			event.ActorID = &actor
+		}
+	}
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		if event.IPAddress == nil {
+			event.IPAddress = parseIP(info.IPAddress)
+		}
+		if event.UserAgent == nil && info.UserAgent != "" {
+			ua := info.UserAgent
+			event.UserAgent = &ua
+		}
+		if event.RequestID == nil && info.RequestID != "" {
+			rid := info.RequestID
+			event.RequestID = &rid
+		}
+	}
+
+	// Never let a cancelled request drop the audit trail
+	if err := s.auditRepo.CreateAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		s.logger.Error("failed to record audit event", "action", string(event.Action), "error", err.Error())
+	}
+}
+
+// ListEvents returns audit events matching the filter, newest first.
+func (s *AuditService) ListEvents(ctx context.Context, filter *models.FilterAuditEvent) ([]*models.AuditEvent, error) {
+	return s.auditRepo.ListAuditEvents(ctx, filter)
+}
+
+// ExportEvents writes audit events matching the filter to w as JSON lines
+// (one JSON object per line) and returns the number of events written.
+func (s *AuditService) ExportEvents(ctx context.Context, filter *models.FilterAuditEvent, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	count := 0
+	err := s.auditRepo.IterateAuditEvents(ctx, filter, func(e *models.AuditEvent) error {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// NewEvent is a convenience constructor for events targeting a single resource.
+func NewEvent(action models.AuditAction, targetType string, targetID uuid.UUID, metadata map[string]any) *models.AuditEvent {
+	event := &models.AuditEvent{Action: action, Metadata: metadata}
+	if targetType != "" {
+		event.TargetType = &targetType
+	}
+	if targetID != uuid.Nil {
+		id := targetID.String()
+		event.TargetID = &id
+	}
+	return event
+}
+
+// NopRecorder discards all events. Used when a module is created without an auditor.
+type NopRecorder struct{}
+
+func (NopRecorder) Record(context.Context, *models.AuditEvent) {}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"go-modular/internal/personaldata"
+	"go-modular/modules/audit/models"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// ExportPersonalData writes the audit events performed by the user or targeting the user to
+// a personal data export, as JSON lines (newest first within each group).
+func (s *AuditService) ExportPersonalData(ctx context.Context, userID uuid.UUID, w *personaldata.Writer) error {
+	f, err := w.Create("events.ndjson")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	seen := map[uuid.UUID]struct{}{}
+	write := func(e *models.AuditEvent) error {
+		if _, ok := seen[e.ID]; ok {
+			return nil
+		}
+		seen[e.ID] = struct{}{}
+		return enc.Encode(e)
+	}
+
+	if err := s.auditRepo.IterateAuditEvents(ctx, &models.FilterAuditEvent{ActorID: &userID}, write); err != nil {
+		return err
+	}
+	targetType, targetID := models.TargetUser, userID.String()
+	return s.auditRepo.IterateAuditEvents(ctx, &models.FilterAuditEvent{TargetType: &targetType, TargetID: &targetID}, write)
+}
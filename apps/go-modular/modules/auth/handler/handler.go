@@ -2,6 +2,7 @@ package handler
 
 import (
 	"log/slog"
+	"net/http"
 
 	"go-modular/modules/auth/services"
 
@@ -21,6 +22,10 @@ type HandlerInterface interface {
 	GetSession(c echo.Context) error
 	DeleteSession(c echo.Context) error
 
+	// Self-service session handlers (/users/me/sessions)
+	ListMySessions(c echo.Context) error
+	RevokeMySession(c echo.Context) error
+
 	// Refresh token handlers
 	CreateRefreshToken(c echo.Context) error
 	UpdateRefreshToken(c echo.Context) error
@@ -33,6 +38,38 @@ type HandlerInterface interface {
 	ValidateEmailVerificationByLink(c echo.Context) error
 	RevokeEmailVerification(c echo.Context) error
 	ResendEmailVerification(c echo.Context) error
+
+	// Passkey (WebAuthn) handlers
+	BeginPasskeyRegistration(c echo.Context) error
+	FinishPasskeyRegistration(c echo.Context) error
+	ListPasskeys(c echo.Context) error
+	DeletePasskey(c echo.Context) error
+	BeginPasskeySignIn(c echo.Context) error
+	SignInWithPasskey(c echo.Context) error
+
+	// Suspicious sign-in step-up verification and "this wasn't me" session revocation
+	VerifySignInStepUp(c echo.Context) error
+	ConfirmRevokeSessionFromAlert(c echo.Context) error
+	RevokeSessionFromAlert(c echo.Context) error
+
+	// Sign out (also clears cookie-mode session cookies)
+	SignOut(c echo.Context) error
+
+	// Session refresh (slides the idle timeout and issues a new access token)
+	RefreshSession(c echo.Context) error
+
+	// Active organization switch (re-issues the access token with a new org_id claim)
+	SwitchOrganization(c echo.Context) error
+
+	// OAuth2 token endpoint (client_credentials grant for service clients)
+	IssueOAuthToken(c echo.Context) error
+
+	// OAuth2 token introspection and revocation (RFC 7662 / RFC 7009)
+	IntrospectOAuthToken(c echo.Context) error
+	RevokeOAuthToken(c echo.Context) error
+
+	// Admin impersonation (short-lived access token with an "act" claim)
+	ImpersonateUser(c echo.Context) error
 }
 
 // Ensure Handler implements HandlerInterface
@@ -43,18 +80,28 @@ type Handler struct {
 	logger      *slog.Logger
 	authService services.AuthServiceInterface
 	validator   *validator.Validate
+	cookies     CookieConfig
 }
 
 type HandlerOpts struct {
 	Logger      *slog.Logger
 	AuthService services.AuthServiceInterface
+	Cookies     CookieConfig // Cookie-based session mode (optional)
 }
 
 // NewHandler creates a new Handler instance.
 func NewHandler(opts *HandlerOpts) *Handler {
+	cookies := opts.Cookies
+	if cookies.RefreshTokenPath == "" {
+		cookies.RefreshTokenPath = "/api/v1/auth"
+	}
+	if cookies.SameSite == 0 {
+		cookies.SameSite = http.SameSiteLaxMode
+	}
 	return &Handler{
 		logger:      opts.Logger,
 		authService: opts.AuthService,
 		validator:   validator.New(),
+		cookies:     cookies,
 	}
 }
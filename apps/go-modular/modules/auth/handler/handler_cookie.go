@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// CookieConfig configures the cookie-based browser session mode. When Enabled, sign-in
+// responses set HttpOnly access/refresh token cookies instead of returning the tokens
+// in the response body, so SPAs never need to keep JWTs in localStorage.
+type CookieConfig struct {
+	Enabled          bool
+	Domain           string
+	Secure           bool
+	SameSite         http.SameSite
+	RefreshTokenPath string // Path the refresh cookie is scoped to (default: /api/v1/auth)
+}
+
+// respondAuthenticated writes a successful sign-in response, setting session cookies
+// when cookie mode is enabled.
+func (h *Handler) respondAuthenticated(c echo.Context, authedUser *models.AuthenticatedUser) error {
+	if !h.cookies.Enabled {
+		return c.JSON(http.StatusOK, authedUser)
+	}
+
+	c.SetCookie(h.newSessionCookie(apputils.AccessTokenCookieName, authedUser.AccessToken, "/", authedUser.TokenExpiry))
+	c.SetCookie(h.newSessionCookie(apputils.RefreshTokenCookieName, authedUser.RefreshToken, h.cookies.RefreshTokenPath, authedUser.SessionExpiry))
+
+	// Tokens live in HttpOnly cookies only; never expose them to JavaScript
+	resp := *authedUser
+	resp.AccessToken = ""
+	resp.RefreshToken = ""
+	return c.JSON(http.StatusOK, resp)
+}
+
+// clearSessionCookies expires the access and refresh token cookies.
+func (h *Handler) clearSessionCookies(c echo.Context) {
+	for _, ck := range []*http.Cookie{
+		h.newSessionCookie(apputils.AccessTokenCookieName, "", "/", time.Unix(0, 0)),
+		h.newSessionCookie(apputils.RefreshTokenCookieName, "", h.cookies.RefreshTokenPath, time.Unix(0, 0)),
+	} {
+		ck.MaxAge = -1
+		c.SetCookie(ck)
+	}
+}
+
+func (h *Handler) newSessionCookie(name, value, path string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.cookies.Domain,
+		Expires:  expires,
+		Secure:   h.cookies.Secure,
+		HttpOnly: true,
+		SameSite: h.cookies.SameSite,
+	}
+}
+
+// @Summary      Sign out
+// @Description  Deletes the current session and clears the session cookies
+// @Tags         Auth - Authentication
+// @Security     BearerAuth
+// @Param        Authorization  header    string  false  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/auth/signout [post]
+func (h *Handler) SignOut(c echo.Context) error {
+	if sid, ok := c.Get("session_id").(string); ok {
+		if sessionID, err := uuid.FromString(sid); err == nil {
+			if err := h.authService.DeleteSession(c.Request().Context(), sessionID); err != nil {
+				h.logger.Warn("Failed to delete session on sign out", "session_id", sid, "error", err.Error())
+			}
+		}
+	}
+
+	h.clearSessionCookies(c)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Signed out successfully"})
+}
+
+// @Summary      Refresh session
+// @Description  Exchanges a refresh token for a new access token and slides the session idle timeout. The refresh token is read from the body or, in cookie mode, from the refresh token cookie. Fails once the session idle timeout or absolute lifetime is exceeded.
+// @Tags         Auth - Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.RefreshSessionRequest  false  "Refresh payload"
+// @Success      200   {object}  models.RefreshSessionResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Router       /api/v1/auth/refresh [post]
+func (h *Handler) RefreshSession(c echo.Context) error {
+	var req models.RefreshSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if req.RefreshToken == "" {
+		if ck, err := c.Cookie(apputils.RefreshTokenCookieName); err == nil {
+			req.RefreshToken = ck.Value
+		}
+	}
+	if req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+	}
+
+	resp, err := h.authService.RefreshSession(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSession), errors.Is(err, services.ErrSessionRevoked):
+			h.clearSessionCookies(c)
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or revoked refresh token"})
+		case errors.Is(err, services.ErrSessionExpired), errors.Is(err, services.ErrSessionIdle):
+			h.clearSessionCookies(c)
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Session expired, please sign in again"})
+		}
+		h.logger.Error("Failed to refresh session", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to refresh session, please try again later"})
+	}
+
+	if h.cookies.Enabled {
+		// Replace the access token cookie; the token is never exposed to JavaScript
+		c.SetCookie(h.newSessionCookie(apputils.AccessTokenCookieName, resp.AccessToken, "/", resp.TokenExpiry))
+		resp.AccessToken = ""
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary      Impersonate user
+// @Description  Issues a short-lived access token for the user on behalf of the calling admin. The token carries an "act" claim (RFC 8693) naming the admin, cannot be refreshed and is rejected by sensitive endpoints (password, passkeys, sessions). Every impersonation is recorded in the audit log.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Param        Authorization  header    string                         true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        userId   path      string                         true  "User ID"
+// @Param        request  body      models.ImpersonateUserRequest  true  "Impersonation payload"
+// @Success      200      {object}  models.ImpersonationResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Router       /api/v1/admin/users/{userId}/impersonate [post]
+func (h *Handler) ImpersonateUser(c echo.Context) error {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	sid, _ := c.Get("session_id").(string)
+	sessionID, err := uuid.FromString(sid)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Access token is not bound to a session"})
+	}
+	targetID, err := uuid.FromString(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	var req models.ImpersonateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	resp, err := h.authService.ImpersonateUser(c.Request().Context(), adminID, sessionID, targetID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotAdmin),
+			errors.Is(err, services.ErrCannotImpersonateSelf),
+			errors.Is(err, services.ErrCannotImpersonateAdmin):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrImpersonationTargetNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidSession):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		h.logger.Error("Failed to impersonate user", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to impersonate user, please try again later"})
+	}
+
+	// The token is always returned in the body, never as a cookie, so it cannot replace
+	// the admin's own browser session.
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// oauthError writes an OAuth2 error response (RFC 6749 section 5.2).
+func oauthError(c echo.Context, status int, code, description string) error {
+	if status == http.StatusUnauthorized {
+		c.Response().Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	return c.JSON(status, models.OAuthErrorResponse{Error: code, ErrorDescription: description})
+}
+
+// clientCredentials returns the client credentials from HTTP Basic authentication or, when
+// absent, from the request body. ok is false when both methods are used (RFC 6749 2.3).
+func clientCredentials(c echo.Context, bodyID, bodySecret string) (clientID, clientSecret string, ok bool) {
+	if id, secret, basic := c.Request().BasicAuth(); basic {
+		if bodyID != "" || bodySecret != "" {
+			return "", "", false
+		}
+		return id, secret, true
+	}
+	return bodyID, bodySecret, true
+}
+
+// @Summary      Issue OAuth2 access token
+// @Description  Issues a short-lived service access token (typ=service) for the client_credentials grant. Client credentials are accepted with HTTP Basic authentication or in the form body.
+// @Tags         Auth - OAuth2
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true   "Must be client_credentials"
+// @Param        client_id      formData  string  false  "Client ID (when not using HTTP Basic)"
+// @Param        client_secret  formData  string  false  "Client secret (when not using HTTP Basic)"
+// @Param        scope          formData  string  false  "Space-delimited scopes (default: all allowed scopes)"
+// @Success      200  {object}  models.OAuthTokenResponse
+// @Failure      400  {object}  models.OAuthErrorResponse
+// @Failure      401  {object}  models.OAuthErrorResponse
+// @Router       /api/v1/oauth/token [post]
+func (h *Handler) IssueOAuthToken(c echo.Context) error {
+	// Token responses must never be cached (RFC 6749 section 5.1)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	var req models.OAuthTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Invalid request payload")
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "grant_type is required")
+	}
+	if req.GrantType != models.GrantTypeClientCredentials {
+		return oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "Only the client_credentials grant is supported")
+	}
+
+	clientID, clientSecret, ok := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Client credentials must be sent with one authentication method only")
+	}
+
+	resp, err := h.authService.IssueClientCredentialsToken(c.Request().Context(), clientID, clientSecret, req.Scope)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidClient):
+			return oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		case errors.Is(err, services.ErrInvalidScope):
+			return oauthError(c, http.StatusBadRequest, "invalid_scope", err.Error())
+		}
+		h.logger.Error("Failed to issue service access token", "error", err.Error())
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Failed to issue access token, please try again later")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Introspect token
+// @Description  Reports whether an access, refresh or service token is active (RFC 7662). Signature, expiry and revocation of the session, refresh token or service client are checked. Requires service client authentication.
+// @Tags         Auth - OAuth2
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token            formData  string  true   "Token to introspect"
+// @Param        token_type_hint  formData  string  false  "access_token or refresh_token"
+// @Param        client_id        formData  string  false  "Client ID (when not using HTTP Basic)"
+// @Param        client_secret    formData  string  false  "Client secret (when not using HTTP Basic)"
+// @Success      200  {object}  models.OAuthIntrospectionResponse
+// @Failure      400  {object}  models.OAuthErrorResponse
+// @Failure      401  {object}  models.OAuthErrorResponse
+// @Router       /api/v1/oauth/introspect [post]
+func (h *Handler) IntrospectOAuthToken(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	var req models.OAuthIntrospectRequest
+	if err := c.Bind(&req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Invalid request payload")
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+	}
+	clientID, clientSecret, ok := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Client credentials must be sent with one authentication method only")
+	}
+
+	resp, err := h.authService.IntrospectToken(c.Request().Context(), clientID, clientSecret, req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidClient) {
+			return oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		}
+		h.logger.Error("Failed to introspect token", "error", err.Error())
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Failed to introspect token, please try again later")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Revoke token
+// @Description  Revokes a refresh token and its session, which also deactivates the access tokens bound to it (RFC 7009). Unknown or invalid tokens are ignored. Requires service client authentication.
+// @Tags         Auth - OAuth2
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token            formData  string  true   "Refresh token to revoke"
+// @Param        token_type_hint  formData  string  false  "refresh_token"
+// @Param        client_id        formData  string  false  "Client ID (when not using HTTP Basic)"
+// @Param        client_secret    formData  string  false  "Client secret (when not using HTTP Basic)"
+// @Success      200
+// @Failure      400  {object}  models.OAuthErrorResponse
+// @Failure      401  {object}  models.OAuthErrorResponse
+// @Router       /api/v1/oauth/revoke [post]
+func (h *Handler) RevokeOAuthToken(c echo.Context) error {
+	var req models.OAuthRevokeRequest
+	if err := c.Bind(&req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Invalid request payload")
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+	}
+	clientID, clientSecret, ok := clientCredentials(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Client credentials must be sent with one authentication method only")
+	}
+
+	if err := h.authService.RevokeToken(c.Request().Context(), clientID, clientSecret, req.Token); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidClient):
+			return oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		case errors.Is(err, services.ErrUnsupportedTokenType):
+			return oauthError(c, http.StatusBadRequest, "unsupported_token_type", err.Error())
+		}
+		h.logger.Error("Failed to revoke token", "error", err.Error())
+		return oauthError(c, http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke token, please try again later")
+	}
+	return c.NoContent(http.StatusOK)
+}
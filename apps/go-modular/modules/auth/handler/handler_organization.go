@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary      Switch active organization
+// @Description  Re-issues the access token of the current session with the org_id claim set to another organization the user belongs to
+// @Tags         Auth - Authentication
+// @Security     BearerAuth
+// @Param        Authorization  header    string                            true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.SwitchOrganizationRequest  true  "Organization payload"
+// @Success      200      {object}  models.SwitchOrganizationResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Router       /api/v1/auth/organization/switch [post]
+func (h *Handler) SwitchOrganization(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	sid, _ := c.Get("session_id").(string)
+	sessionID, err := uuid.FromString(sid)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Access token is not bound to a session"})
+	}
+
+	var req models.SwitchOrganizationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+	orgID := uuid.FromStringOrNil(req.OrganizationID)
+
+	resp, err := h.authService.SwitchOrganization(c.Request().Context(), userID, sessionID, orgID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotOrganizationMember):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidSession):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrOrganizationsDisabled):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		h.logger.Error("Failed to switch organization", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to switch organization, please try again later"})
+	}
+
+	if h.cookies.Enabled {
+		// Replace the access token cookie; the token is never exposed to JavaScript
+		c.SetCookie(h.newSessionCookie(apputils.AccessTokenCookieName, resp.AccessToken, "/", resp.TokenExpiry))
+		resp.AccessToken = ""
+	}
+	return c.JSON(http.StatusOK, resp)
+}
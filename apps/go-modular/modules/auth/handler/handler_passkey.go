@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// currentUserID returns the authenticated user ID set by the JWT middleware.
+func currentUserID(c echo.Context) (uuid.UUID, bool) {
+	v := c.Get("user_id")
+	if v == nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromString(fmt.Sprint(v))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// @Summary      Begin passkey registration
+// @Description  Starts a WebAuthn registration ceremony for the authenticated user
+// @Tags         Auth - Passkeys
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  models.PasskeyChallengeResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/auth/passkeys/register/begin [post]
+func (h *Handler) BeginPasskeyRegistration(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	resp, err := h.authService.BeginPasskeyRegistration(c.Request().Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to begin passkey registration", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to begin passkey registration"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Finish passkey registration
+// @Description  Verifies the authenticator attestation and stores the new passkey
+// @Tags         Auth - Passkeys
+// @Security     BearerAuth
+// @Param        Authorization  header    string                                   true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.FinishPasskeyRegistrationRequest  true  "Attestation payload"
+// @Success      201   {object}  models.WebAuthnCredential
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]string
+// @Router       /api/v1/auth/passkeys/register/finish [post]
+func (h *Handler) FinishPasskeyRegistration(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req models.FinishPasskeyRegistrationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	passkey, err := h.authService.FinishPasskeyRegistration(c.Request().Context(), userID, req.ChallengeID, req.FriendlyName, req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPasskeyChallenge):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid or expired challenge"})
+		case errors.Is(err, services.ErrInvalidPasskey):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid passkey"})
+		default:
+			h.logger.Error("Failed to finish passkey registration", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to register passkey"})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, passkey)
+}
+
+// @Summary      List passkeys
+// @Description  Lists the passkeys registered by the authenticated user
+// @Tags         Auth - Passkeys
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {array}   models.WebAuthnCredential
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/auth/passkeys [get]
+func (h *Handler) ListPasskeys(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	passkeys, err := h.authService.ListPasskeys(c.Request().Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list passkeys", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list passkeys"})
+	}
+
+	return c.JSON(http.StatusOK, passkeys)
+}
+
+// @Summary      Delete passkey
+// @Description  Deletes one of the authenticated user's passkeys
+// @Tags         Auth - Passkeys
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Param        passkeyId      path      string  true  "Passkey ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/v1/auth/passkeys/{passkeyId} [delete]
+func (h *Handler) DeletePasskey(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	passkeyID, err := uuid.FromString(c.Param("passkeyId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid passkey ID"})
+	}
+
+	if err := h.authService.DeletePasskey(c.Request().Context(), userID, passkeyID); err != nil {
+		if errors.Is(err, services.ErrPasskeyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Passkey not found"})
+		}
+		h.logger.Error("Failed to delete passkey", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete passkey"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary      Begin passkey sign in
+// @Description  Starts a discoverable WebAuthn assertion ceremony
+// @Tags         Auth - Authentication
+// @Produce      json
+// @Success      200  {object}  models.PasskeyChallengeResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /api/v1/auth/signin/passkey/begin [post]
+func (h *Handler) BeginPasskeySignIn(c echo.Context) error {
+	resp, err := h.authService.BeginPasskeySignIn(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to begin passkey sign in", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to begin passkey sign in"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Sign in with passkey
+// @Description  Verifies a WebAuthn assertion and issues access and refresh tokens
+// @Tags         Auth - Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.SignInWithPasskeyRequest  true  "Assertion payload"
+// @Success      200   {object}  models.SignInResponse
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Router       /api/v1/auth/signin/passkey/finish [post]
+func (h *Handler) SignInWithPasskey(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Propagate a minimal headers map into ctx so services can read audience etc.
+	headers := map[string]string{
+		"X-App-Audience":                 c.Request().Header.Get("X-App-Audience"),
+		services.DeviceFingerprintHeader: c.Request().Header.Get(services.DeviceFingerprintHeader),
+	}
+	ctx = context.WithValue(ctx, apputils.HeadersContextKey, headers)
+
+	var req models.SignInWithPasskeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	authedUser, err := h.authService.SignInWithPasskey(ctx, req.ChallengeID, req.Credential, req.RememberMe)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPasskeyChallenge):
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":   "Invalid or expired challenge",
+				"details": "Start the passkey sign in again.",
+			})
+		case errors.Is(err, services.ErrInvalidPasskey), errors.Is(err, services.ErrInvalidCredentials):
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"error":   "Invalid passkey",
+				"details": "The passkey could not be verified.",
+			})
+		case errors.Is(err, services.ErrEmailNotVerified):
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"error":   "Email is not verified",
+				"details": "Please verify your email address before signing in.",
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":   "Internal server error",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	return h.respondAuthenticated(c, authedUser)
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
 	"go-modular/pkg/apputils"
 
 	"github.com/gofrs/uuid/v5"
@@ -20,7 +22,7 @@ import (
 // @Produce      json
 // @Param        body  body      models.SetPasswordRequest  true  "Password payload"
 // @Success      201   {object}  map[string]string
-// @Failure      400   {object}  map[string]string
+// @Failure      400   {object}  map[string]interface{}
 // @Router       /api/v1/auth/password [post]
 func (h *Handler) SetUserPassword(c echo.Context) error {
 	var req models.SetPasswordRequest
@@ -45,6 +47,12 @@ func (h *Handler) SetUserPassword(c echo.Context) error {
 	}
 
 	if err := h.authService.SetUserPassword(c.Request().Context(), userPassword); err != nil {
+		if pe := (*apputils.PolicyError)(nil); errors.As(err, &pe) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": apputils.ValidationErrorsToMap(err, req),
+			})
+		}
 		h.logger.Error("Failed to set user password", slog.String("error", err.Error()))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to set password"})
 	}
@@ -62,7 +70,7 @@ func (h *Handler) SetUserPassword(c echo.Context) error {
 // @Param        userId  path      string                      true  "User ID"
 // @Param        body    body      models.UpdatePasswordRequest true  "Password payload"
 // @Success      200     {object}  map[string]string
-// @Failure      400     {object}  map[string]string
+// @Failure      400     {object}  map[string]interface{}
 // @Router       /api/v1/auth/password/:userId [put]
 func (h *Handler) UpdateUserPassword(c echo.Context) error {
 	userIDStr := c.Param("userId")
@@ -89,9 +97,53 @@ func (h *Handler) UpdateUserPassword(c echo.Context) error {
 		req.CurrentPassword,
 		req.NewPassword,
 	); err != nil {
+		if pe := (*apputils.PolicyError)(nil); errors.As(err, &pe) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": apputils.ValidationErrorsToMap(err, req),
+			})
+		}
 		h.logger.Error("Failed to update user password", slog.String("error", err.Error()))
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Password updated successfully"})
 }
+
+// @Summary      Accept invitation
+// @Description  Sets the first password of a user invited by an import, using the token of the emailed invitation link. The email address is marked verified; the user can sign in afterwards.
+// @Tags         Auth - User Password
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.AcceptInvitationRequest  true  "Invitation token and password"
+// @Success      200   {object}  map[string]string
+// @Failure      400   {object}  map[string]interface{}
+// @Router       /api/v1/auth/invitation/accept [post]
+func (h *Handler) AcceptInvitation(c echo.Context) error {
+	var req models.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	if _, err := h.authService.AcceptInvitation(c.Request().Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidInvitationToken) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if pe := (*apputils.PolicyError)(nil); errors.As(err, &pe) {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":   "Validation failed",
+				"details": apputils.ValidationErrorsToMap(err, req),
+			})
+		}
+		h.logger.Error("Failed to accept invitation", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to set password"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password set successfully, you can now sign in"})
+}
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
 	"net"
 	"net/http"
 	"time"
 
 	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/services"
 	"go-modular/pkg/apputils"
 
 	"github.com/gofrs/uuid/v5"
@@ -207,3 +209,79 @@ func (h *Handler) DeleteSession(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Session deleted successfully"})
 }
+
+// @Summary      List my sessions
+// @Description  Lists the active sessions of the authenticated user, newest first. The session of the current access token is marked with current=true.
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {array}   models.UserSessionResponse
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/users/me/sessions [get]
+func (h *Handler) ListMySessions(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	currentSID, _ := c.Get("session_id").(string)
+
+	sessions, err := h.authService.ListUserSessions(c.Request().Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list sessions"})
+	}
+
+	resp := make([]models.UserSessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		item := models.UserSessionResponse{
+			ID:             s.ID,
+			DeviceName:     s.DeviceName,
+			UserAgent:      s.UserAgent,
+			RememberMe:     s.RememberMe,
+			CreatedAt:      s.CreatedAt,
+			LastActivityAt: s.LastActivityAt(),
+			ExpiresAt:      s.ExpiresAt,
+			Current:        s.ID.String() == currentSID,
+		}
+		if s.IPAddress != nil {
+			ip := s.IPAddress.String()
+			item.IPAddress = &ip
+		}
+		resp = append(resp, item)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary      Revoke my session
+// @Description  Revokes one of the authenticated user's sessions, e.g. to sign out a lost device
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Param        sessionId  path      string  true  "Session ID"
+// @Success      200        {object}  map[string]string
+// @Failure      400        {object}  map[string]string
+// @Failure      401        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Router       /api/v1/users/me/sessions/{sessionId} [delete]
+func (h *Handler) RevokeMySession(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	sessionID, err := uuid.FromString(c.Param("sessionId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid session_id"})
+	}
+
+	if err := h.authService.RevokeUserSession(c.Request().Context(), userID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
+		}
+		h.logger.Error("Failed to revoke session", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke session"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Session revoked successfully"})
+}
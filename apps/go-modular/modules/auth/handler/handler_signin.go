@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"html/template"
 	"net/http"
+	"strings"
 
 	"go-modular/modules/auth/models"
 	"go-modular/modules/auth/services"
@@ -27,7 +30,8 @@ func (h *Handler) SignInWithEmail(c echo.Context) error {
 
 	// Propagate a minimal headers map into ctx so services can read audience etc.
 	headers := map[string]string{
-		"X-App-Audience": c.Request().Header.Get("X-App-Audience"),
+		"X-App-Audience":                 c.Request().Header.Get("X-App-Audience"),
+		services.DeviceFingerprintHeader: c.Request().Header.Get(services.DeviceFingerprintHeader),
 	}
 	ctx = context.WithValue(ctx, apputils.HeadersContextKey, headers)
 
@@ -45,8 +49,11 @@ func (h *Handler) SignInWithEmail(c echo.Context) error {
 		})
 	}
 
-	authedUser, err := h.authService.SignInWithEmail(ctx, req.Email, req.Password)
+	authedUser, err := h.authService.SignInWithEmail(ctx, req.Email, req.Password, req.RememberMe)
 	if err != nil {
+		if ok, resp := respondStepUpRequired(c, err); ok {
+			return resp
+		}
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
@@ -66,7 +73,7 @@ func (h *Handler) SignInWithEmail(c echo.Context) error {
 		}
 	}
 
-	return c.JSON(http.StatusOK, authedUser)
+	return h.respondAuthenticated(c, authedUser)
 }
 
 // @Summary      Sign in with username
@@ -84,7 +91,8 @@ func (h *Handler) SignInWithUsername(c echo.Context) error {
 
 	// Propagate headers into ctx for services
 	headers := map[string]string{
-		"X-App-Audience": c.Request().Header.Get("X-App-Audience"),
+		"X-App-Audience":                 c.Request().Header.Get("X-App-Audience"),
+		services.DeviceFingerprintHeader: c.Request().Header.Get(services.DeviceFingerprintHeader),
 	}
 	ctx = context.WithValue(ctx, apputils.HeadersContextKey, headers)
 
@@ -102,8 +110,11 @@ func (h *Handler) SignInWithUsername(c echo.Context) error {
 		})
 	}
 
-	authedUser, err := h.authService.SignInWithUsername(ctx, req.Username, req.Password)
+	authedUser, err := h.authService.SignInWithUsername(ctx, req.Username, req.Password, req.RememberMe)
 	if err != nil {
+		if ok, resp := respondStepUpRequired(c, err); ok {
+			return resp
+		}
 		switch {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
@@ -123,5 +134,155 @@ func (h *Handler) SignInWithUsername(c echo.Context) error {
 		}
 	}
 
-	return c.JSON(http.StatusOK, authedUser)
+	return h.respondAuthenticated(c, authedUser)
+}
+
+// respondStepUpRequired writes the step-up challenge of a flagged sign-in. ok is false when
+// err is not a *services.StepUpRequiredError.
+func respondStepUpRequired(c echo.Context, err error) (ok bool, resp error) {
+	var stepUp *services.StepUpRequiredError
+	if !errors.As(err, &stepUp) {
+		return false, nil
+	}
+	return true, c.JSON(http.StatusUnauthorized, models.StepUpRequiredResponse{
+		Error:       "Verification required",
+		ChallengeID: stepUp.ChallengeID,
+		ExpiresAt:   stepUp.ExpiresAt,
+		Reasons:     stepUp.Reasons,
+	})
+}
+
+// @Summary      Verify sign-in
+// @Description  Completes a sign-in flagged as unusual (new device, browser or network) with the verification code emailed to the user
+// @Tags         Auth - Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      models.VerifySignInStepUpRequest  true  "Verification payload"
+// @Success      200   {object}  models.SignInResponse
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Router       /api/v1/auth/signin/verify [post]
+func (h *Handler) VerifySignInStepUp(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Propagate headers into ctx for services
+	headers := map[string]string{
+		"X-App-Audience":                 c.Request().Header.Get("X-App-Audience"),
+		services.DeviceFingerprintHeader: c.Request().Header.Get(services.DeviceFingerprintHeader),
+	}
+	ctx = context.WithValue(ctx, apputils.HeadersContextKey, headers)
+
+	var req models.VerifySignInStepUpRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Invalid request payload",
+			"details": err.Error(),
+		})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	authedUser, err := h.authService.VerifySignInStepUp(ctx, req.ChallengeID, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStepUpCode) {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"error":   "Invalid or expired verification code",
+				"details": "Check the code from the email or sign in again.",
+			})
+		}
+		h.logger.Error("Failed to verify sign-in", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Internal server error",
+			"details": "Failed to verify sign-in, please try again later",
+		})
+	}
+
+	return h.respondAuthenticated(c, authedUser)
+}
+
+// notMePage is the page of the "this wasn't me" link of sign-in alerts: a form confirming
+// the revocation, or the outcome once it was submitted.
+var notMePage = template.Must(template.New("not-me").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Sign out unknown session</title></head>
+<body>
+{{if .Message}}<p>{{.Message}}</p>{{else}}<h1>Was this sign-in not you?</h1>
+<p>Signing out the new session ends it on the device it was started on.</p>
+<form method="post" action="/api/v1/auth/signin/not-me">
+<input type="hidden" name="token" value="{{.Token}}">
+{{if .CSRFToken}}<input type="hidden" name="` + apputils.CSRFCookieName + `" value="{{.CSRFToken}}">{{end}}
+<button type="submit">Sign out this session</button>
+</form>{{end}}
+</body>
+</html>`))
+
+type notMePageData struct {
+	Token     string
+	CSRFToken string
+	Message   string
+}
+
+// renderNotMePage writes notMePage. It is never cached and leaks no token through the Referer.
+func renderNotMePage(c echo.Context, status int, data notMePageData) error {
+	var buf bytes.Buffer
+	if err := notMePage.Execute(&buf, data); err != nil {
+		return err
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set("Referrer-Policy", "no-referrer")
+	return c.HTMLBlob(status, buf.Bytes())
+}
+
+// @Summary      Confirm session revocation from sign-in alert
+// @Description  "This wasn't me" link sent in new sign-in alert emails. Shows a form that confirms the revocation with a POST, so opening the link (e.g. by a mail scanner) revokes nothing.
+// @Tags         Auth - Authentication
+// @Produce      html
+// @Param        token  query     string  true  "Alert token"
+// @Success      200    {string}  string
+// @Failure      400    {string}  string
+// @Router       /api/v1/auth/signin/not-me [get]
+func (h *Handler) ConfirmRevokeSessionFromAlert(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return renderNotMePage(c, http.StatusBadRequest, notMePageData{Message: "The link is incomplete. Open it again from the email."})
+	}
+	csrfToken, _ := c.Get("csrf").(string)
+	return renderNotMePage(c, http.StatusOK, notMePageData{Token: token, CSRFToken: csrfToken})
+}
+
+// @Summary      Revoke session from sign-in alert
+// @Description  Revokes the session reported by a new sign-in alert. Submitted by the form of the "this wasn't me" link, which gets an HTML page back; other clients get JSON. The token is single use.
+// @Tags         Auth - Authentication
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token  formData  string  true  "Alert token"
+// @Success      200    {object}  map[string]string
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Router       /api/v1/auth/signin/not-me [post]
+func (h *Handler) RevokeSessionFromAlert(c echo.Context) error {
+	respond := func(status int, key, message string) error {
+		if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+			return renderNotMePage(c, status, notMePageData{Message: message})
+		}
+		return c.JSON(status, map[string]string{key: message})
+	}
+
+	token := c.FormValue("token")
+	if token == "" {
+		return respond(http.StatusBadRequest, "error", "token is required")
+	}
+
+	if err := h.authService.RevokeSessionFromAlert(c.Request().Context(), token); err != nil {
+		if errors.Is(err, services.ErrInvalidAlertToken) {
+			return respond(http.StatusUnauthorized, "error", "Invalid or expired link")
+		}
+		h.logger.Error("Failed to revoke session from alert", "error", err.Error())
+		return respond(http.StatusInternalServerError, "error", "Failed to revoke session, please try again later")
+	}
+	return respond(http.StatusOK, "message", "The session has been signed out. We recommend changing your password.")
 }
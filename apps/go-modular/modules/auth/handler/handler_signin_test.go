@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go-modular/modules/auth/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// alertAuthService records revocations from sign-in alerts. Other AuthServiceInterface
+// methods are not implemented and panic when called.
+type alertAuthService struct {
+	services.AuthServiceInterface
+	revoked []string
+}
+
+func (s *alertAuthService) RevokeSessionFromAlert(_ context.Context, rawToken string) error {
+	if rawToken != "valid-token" {
+		return services.ErrInvalidAlertToken
+	}
+	s.revoked = append(s.revoked, rawToken)
+	return nil
+}
+
+func TestHandler_NotMe(t *testing.T) {
+	svc := &alertAuthService{}
+	h := NewHandler(&HandlerOpts{Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), AuthService: svc})
+	e := echo.New()
+	e.GET("/not-me", h.ConfirmRevokeSessionFromAlert)
+	e.POST("/not-me", h.RevokeSessionFromAlert)
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	postForm := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/not-me", strings.NewReader(url.Values{"token": {token}}.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		return serve(req)
+	}
+
+	t.Run("get_only_confirms", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/not-me?token=valid-token", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+		assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+		assert.Contains(t, rec.Body.String(), `<form method="post"`)
+		assert.Contains(t, rec.Body.String(), `value="valid-token"`)
+		assert.Empty(t, svc.revoked, "opening the link must not revoke the session")
+	})
+
+	t.Run("get_escapes_the_token", func(t *testing.T) {
+		rec := serve(httptest.NewRequest(http.MethodGet, "/not-me?token="+url.QueryEscape(`"><script>`), nil))
+		assert.NotContains(t, rec.Body.String(), "<script>")
+	})
+
+	t.Run("post_revokes", func(t *testing.T) {
+		rec := postForm("valid-token")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "The session has been signed out")
+		assert.Equal(t, []string{"valid-token"}, svc.revoked)
+	})
+
+	t.Run("post_rejects_invalid_tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, postForm("unknown-token").Code)
+		assert.Equal(t, http.StatusBadRequest, postForm("").Code)
+	})
+
+	t.Run("post_answers_api_clients_with_json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/not-me?token=unknown-token", nil)
+		rec := serve(req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"error":"Invalid or expired link"}`, rec.Body.String())
+	})
+}
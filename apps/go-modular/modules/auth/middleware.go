@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -12,24 +13,32 @@ import (
 )
 
 // JWTMiddleware verifies a Bearer JWT and stores the parsed token claims in echo.Context.
+// When no Authorization header is present, the access token cookie issued by the
+// cookie-based session mode is used as a fallback.
+//
+// Both user access tokens (typ=access) and service tokens (typ=service, client_credentials
+// grant) are accepted. Service tokens carry no user or session, so "user_id" and
+// "session_id" are only set for user tokens; "client_id" and "scopes" are set instead.
 // Usage:
 //
 //	e.Use(auth.JWTMiddleware(opts.JWTSecretKey, opts.SigningAlg))
 func JWTMiddleware(secret []byte, alg jwa.SignatureAlgorithm) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			var tokenStr string
 			auth := c.Request().Header.Get("Authorization")
-			if auth == "" {
+			if auth != "" {
+				parts := strings.SplitN(auth, " ", 2)
+				if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
+				}
+				tokenStr = strings.TrimSpace(parts[1])
+			} else if ck, err := c.Cookie(apputils.AccessTokenCookieName); err == nil && ck.Value != "" {
+				tokenStr = ck.Value
+			} else {
 				return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization header")
 			}
 
-			parts := strings.SplitN(auth, " ", 2)
-			if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header format")
-			}
-
-			tokenStr := strings.TrimSpace(parts[1])
-
 			// Use the shared JWT helper to parse & validate (validates exp/nbf etc).
 			jwtGen := apputils.NewJWTGenerator(apputils.JWTConfig{
 				SecretKey:  secret,
@@ -41,25 +50,38 @@ func JWTMiddleware(secret []byte, alg jwa.SignatureAlgorithm) echo.MiddlewareFun
 				return echo.NewHTTPError(http.StatusUnauthorized, fmt.Sprintf("invalid token: %v", err))
 			}
 
-			// Enforce token type to be "access" (defensive)
-			if t, ok := claims["typ"]; ok {
-				if ts, ok := t.(string); ok && ts != "access" {
-					return echo.NewHTTPError(http.StatusUnauthorized, "token is not an access token")
-				}
+			// Enforce token type to be "access" or "service" (defensive)
+			tokenType := tokenTypeOf(claims)
+			if tokenType != apputils.TokenTypeAccess && tokenType != apputils.TokenTypeService {
+				return echo.NewHTTPError(http.StatusUnauthorized, "token is not an access token")
 			}
 
 			// store claims for handlers to use
 			c.Set("jwt_claims", claims)
+			c.Set("token_type", tokenType)
 
 			// Extract common fields into shortcuts
-			if sub, ok := claims["sub"]; ok {
-				c.Set("user_id", fmt.Sprint(sub))
-			}
-			// session id may be stored as "sid" or "SID" (signing code used "SID")
-			if sid, ok := claims["sid"]; ok {
-				c.Set("session_id", fmt.Sprint(sid))
-			} else if sid2, ok := claims["SID"]; ok {
-				c.Set("session_id", fmt.Sprint(sid2))
+			if tokenType == apputils.TokenTypeService {
+				if cid, ok := claims["client_id"]; ok {
+					c.Set("client_id", fmt.Sprint(cid))
+				}
+				c.Set("scopes", strings.Fields(fmt.Sprint(claims["scope"])))
+			} else {
+				if sub, ok := claims["sub"]; ok {
+					c.Set("user_id", fmt.Sprint(sub))
+				}
+				// session id may be stored as "sid" or "SID" (signing code used "SID")
+				if sid, ok := claims["sid"]; ok {
+					c.Set("session_id", fmt.Sprint(sid))
+				} else if sid2, ok := claims["SID"]; ok {
+					c.Set("session_id", fmt.Sprint(sid2))
+				}
+				// impersonation tokens name the acting admin in the "act" claim (RFC 8693)
+				if act, ok := claims["act"].(map[string]any); ok {
+					if sub, ok := act["sub"]; ok {
+						c.Set("impersonator_id", fmt.Sprint(sub))
+					}
+				}
 			}
 			if aud, ok := claims["aud"]; ok {
 				c.Set("audience", fmt.Sprint(aud))
@@ -88,6 +110,7 @@ func GetJWTClaims(c echo.Context) (map[string]any, bool) {
 }
 
 // GetUserID attempts to return a user id from context (user_id key or token 'sub' claim).
+// Service tokens have no user, so false is returned for them.
 func GetUserID(c echo.Context) (string, bool) {
 	if v := c.Get("user_id"); v != nil {
 		if s, ok := v.(string); ok {
@@ -95,10 +118,99 @@ func GetUserID(c echo.Context) (string, bool) {
 		}
 		return fmt.Sprint(v), true
 	}
-	if claims, ok := GetJWTClaims(c); ok {
+	if claims, ok := GetJWTClaims(c); ok && tokenTypeOf(claims) != apputils.TokenTypeService {
 		if sub, ok := claims["sub"]; ok {
 			return fmt.Sprint(sub), true
 		}
 	}
 	return "", false
 }
+
+// tokenTypeOf returns the "typ" claim, defaulting to "access" for tokens without one.
+func tokenTypeOf(claims map[string]any) string {
+	if t, ok := claims["typ"].(string); ok {
+		return t
+	}
+	return apputils.TokenTypeAccess
+}
+
+// IsServiceToken reports whether the request was authenticated with a service token.
+func IsServiceToken(c echo.Context) bool {
+	t, _ := c.Get("token_type").(string)
+	return t == apputils.TokenTypeService
+}
+
+// GetImpersonatorID returns the ID of the admin impersonating the token subject, if the
+// request was authenticated with an impersonation token.
+func GetImpersonatorID(c echo.Context) (string, bool) {
+	id, ok := c.Get("impersonator_id").(string)
+	return id, ok && id != ""
+}
+
+// IsImpersonated reports whether the request was authenticated with an impersonation token.
+func IsImpersonated(c echo.Context) bool {
+	_, ok := GetImpersonatorID(c)
+	return ok
+}
+
+// DenyImpersonation returns middleware that rejects impersonation tokens with 403. It must
+// run after JWTMiddleware and guards sensitive endpoints (credentials, sessions) that an
+// admin acting as another user must not reach.
+// Usage:
+//
+//	g.POST("/password", h.SetUserPassword, auth.DenyImpersonation())
+func DenyImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if IsImpersonated(c) {
+				return echo.NewHTTPError(http.StatusForbidden, "not allowed while impersonating a user")
+			}
+			return next(c)
+		}
+	}
+}
+
+// GetScopes returns the scopes granted to the service token of the request.
+func GetScopes(c echo.Context) []string {
+	scopes, _ := c.Get("scopes").([]string)
+	return scopes
+}
+
+// RequireScopes returns middleware that rejects service tokens missing any of the given
+// scopes with 403. It must run after JWTMiddleware. User access tokens carry no scopes and
+// pass through; they are authorized by the user-level checks of the handlers.
+// Usage:
+//
+//	g.GET("/reports", h.ListReports, auth.RequireScopes("reports:read"))
+func RequireScopes(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !IsServiceToken(c) {
+				return next(c)
+			}
+			granted := GetScopes(c)
+			for _, s := range scopes {
+				if !slices.Contains(granted, s) {
+					return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("insufficient scope: %s required", s))
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireResourceScopes returns middleware requiring "<resource>:read" for safe methods
+// (GET, HEAD, OPTIONS) and "<resource>:write" for all other methods (see RequireScopes).
+func RequireResourceScopes(resource string) echo.MiddlewareFunc {
+	read, write := RequireScopes(resource+":read"), RequireScopes(resource+":write")
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		readNext, writeNext := read(next), write(next)
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return readNext(c)
+			}
+			return writeNext(c)
+		}
+	}
+}
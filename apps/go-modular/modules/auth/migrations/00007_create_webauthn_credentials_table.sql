@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create WebAuthn credentials table and indexes
+-- Stores passkeys registered by users (one row per authenticator credential).
+-- Registration/assertion challenges are kept in public.one_time_tokens.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.webauthn_credentials (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL UNIQUE, -- raw credential ID returned by the authenticator
+    public_key BYTEA NOT NULL, -- COSE encoded credential public key
+    attestation_type TEXT NOT NULL DEFAULT 'none',
+    aaguid BYTEA DEFAULT NULL, -- authenticator model identifier
+    sign_count BIGINT NOT NULL DEFAULT 0 CHECK (sign_count >= 0),
+    transports TEXT[] NOT NULL DEFAULT '{}', -- usb, nfc, ble, internal, hybrid
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    friendly_name TEXT NOT NULL CHECK (char_length(friendly_name) > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT NULL,
+    last_used_at TIMESTAMPTZ DEFAULT NULL
+);
+
+-- WebAuthn credentials table indexes and updated_at trigger
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON public.webauthn_credentials (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webauthn_credentials_credential_id ON public.webauthn_credentials (credential_id);
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_last_used_at ON public.webauthn_credentials (last_used_at) WHERE last_used_at IS NOT NULL;
+CREATE TRIGGER trg_webauthn_credentials_updated_at BEFORE UPDATE ON public.webauthn_credentials FOR EACH ROW EXECUTE FUNCTION fn_updated_at_value();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop triggers, indexes, and table(s) (reverse order of creation)
+DROP TRIGGER IF EXISTS trg_webauthn_credentials_updated_at ON public.webauthn_credentials;
+DROP INDEX IF EXISTS idx_webauthn_credentials_last_used_at;
+DROP INDEX IF EXISTS idx_webauthn_credentials_credential_id;
+DROP INDEX IF EXISTS idx_webauthn_credentials_user_id;
+DROP TABLE IF EXISTS public.webauthn_credentials;
+
+-- +goose StatementEnd
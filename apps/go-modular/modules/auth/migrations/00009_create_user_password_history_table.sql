@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create user_password_history table and indexes
+-- Keeps the hashes of a user's most recent passwords to prevent reuse.
+-- The number of retained entries is controlled by AUTH_PASSWORD_HISTORY_SIZE.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.user_password_history (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    password_hash BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- User password history table indexes
+CREATE INDEX IF NOT EXISTS idx_user_password_history_user_id_created_at ON public.user_password_history (user_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop indexes and table(s) (reverse order of creation)
+DROP INDEX IF EXISTS idx_user_password_history_user_id_created_at;
+DROP TABLE IF EXISTS public.user_password_history;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create service clients table and indexes
+-- OAuth2 clients used by internal workers for the client_credentials grant.
+-- Secrets are high-entropy random values, only their SHA-256 hash is stored.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.service_clients (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    client_id TEXT NOT NULL UNIQUE CHECK (char_length(client_id) > 0),
+    name TEXT NOT NULL CHECK (char_length(name) > 0),
+    secret_hash TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}', -- scopes the client may request, e.g. users:read
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT NULL,
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    revoked_at TIMESTAMPTZ DEFAULT NULL
+);
+
+-- Service clients table indexes and updated_at trigger
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_clients_client_id ON public.service_clients (client_id);
+CREATE TRIGGER trg_service_clients_updated_at BEFORE UPDATE ON public.service_clients FOR EACH ROW EXECUTE FUNCTION fn_updated_at_value();
+
+-- Client secrets are never needed inside tenant-scoped transactions
+REVOKE ALL ON public.service_clients FROM app_tenant;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop trigger, indexes, and table(s) (reverse order of creation)
+DROP TRIGGER IF EXISTS trg_service_clients_updated_at ON public.service_clients;
+DROP INDEX IF EXISTS idx_service_clients_client_id;
+DROP TABLE IF EXISTS public.service_clients;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Session timeout policies
+-- expires_at now holds the absolute session deadline; idle timeouts are measured
+-- from COALESCE(refreshed_at, created_at). remember_me selects the longer
+-- remember-me idle timeout and lifetime chosen at sign-in.
+-- ============================================================================
+ALTER TABLE public.sessions ADD COLUMN IF NOT EXISTS remember_me BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE public.sessions DROP COLUMN IF EXISTS remember_me;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Domain events published by the auth module on the event bus (see internal/eventbus).
+const (
+	EventSessionRevoked  = "auth.session_revoked"
+	EventPasswordChanged = "auth.password_changed"
+)
+
+// SessionRevoked is published after a session was revoked or deleted (signed out).
+type SessionRevoked struct {
+	SessionID  uuid.UUID  `json:"session_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	RevokedBy  *uuid.UUID `json:"revoked_by,omitempty"`
+	Reason     string     `json:"reason"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+func (SessionRevoked) EventName() string { return EventSessionRevoked }
+
+// PasswordChanged is published after a user's password was set or changed.
+type PasswordChanged struct {
+	UserID     uuid.UUID `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (PasswordChanged) EventName() string { return EventPasswordChanged }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"net"
+	"slices"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -21,6 +22,19 @@ type UserPassword struct {
 	UpdatedAt    *time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// -- MARK: PasswordHistory section
+
+// Define table name for PasswordHistory model
+const PasswordHistoryTable = "public.user_password_history"
+
+// PasswordHistory represents a previously used password hash of a user
+type PasswordHistory struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	PasswordHash string    `json:"password_hash" db:"password_hash"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
 // -- MARK: Session section
 
 // Define table name for Session model
@@ -35,11 +49,20 @@ type Session struct {
 	DeviceName        *string    `json:"device_name" db:"device_name"`
 	DeviceFingerprint *string    `json:"device_fingerprint" db:"device_fingerprint"`
 	IPAddress         *net.IP    `json:"ip_address" db:"ip_address"`
-	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"` // Absolute deadline of the session
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
-	RefreshedAt       *time.Time `json:"refreshed_at" db:"refreshed_at"`
+	RefreshedAt       *time.Time `json:"refreshed_at" db:"refreshed_at"` // Last refresh, idle timeouts are measured from it
 	RevokedAt         *time.Time `json:"revoked_at" db:"revoked_at"`
 	RevokedBy         *uuid.UUID `json:"revoked_by" db:"revoked_by"`
+	RememberMe        bool       `json:"remember_me" db:"remember_me"` // Uses the remember-me idle timeout and lifetime
+}
+
+// LastActivityAt returns when the session was last refreshed, or created when never refreshed.
+func (s *Session) LastActivityAt() time.Time {
+	if s.RefreshedAt != nil {
+		return *s.RefreshedAt
+	}
+	return s.CreatedAt
 }
 
 // -- MARK: RefreshToken section
@@ -72,6 +95,11 @@ type OneTimeTokenSubject string
 const (
 	OneTimeTokenSubjectEmailOTP          OneTimeTokenSubject = "email_otp"
 	OneTimeTokenSubjectEmailVerification OneTimeTokenSubject = "email_verification"
+	OneTimeTokenSubjectPasskeyRegister   OneTimeTokenSubject = "passkey_registration"
+	OneTimeTokenSubjectPasskeySignIn     OneTimeTokenSubject = "passkey_signin"
+	OneTimeTokenSubjectSignInStepUp      OneTimeTokenSubject = "signin_step_up"
+	OneTimeTokenSubjectSessionAlert      OneTimeTokenSubject = "session_alert"
+	OneTimeTokenSubjectAccountSetup      OneTimeTokenSubject = "account_setup"
 )
 
 // OneTimeToken represents a one-time-use token for sensitive authentication flows.
@@ -95,6 +123,59 @@ type OneTimeToken struct {
 	LastSentAt *time.Time          `json:"last_sent_at" db:"last_sent_at"`   // Last time the token was sent (for throttling)
 }
 
+// -- MARK: WebAuthnCredential section
+
+// Define table name for WebAuthnCredential model
+const WebAuthnCredentialTable = "public.webauthn_credentials"
+
+// WebAuthnCredential represents a passkey registered by a user. The raw credential ID
+// and COSE public key are stored as returned by the authenticator during registration.
+type WebAuthnCredential struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	CredentialID    []byte     `json:"credential_id" db:"credential_id"`
+	PublicKey       []byte     `json:"-" db:"public_key"`
+	AttestationType string     `json:"attestation_type" db:"attestation_type"`
+	AAGUID          []byte     `json:"aaguid" db:"aaguid"`
+	SignCount       int64      `json:"sign_count" db:"sign_count"`
+	Transports      []string   `json:"transports" db:"transports"`
+	BackupEligible  bool       `json:"backup_eligible" db:"backup_eligible"`
+	BackupState     bool       `json:"backup_state" db:"backup_state"`
+	FriendlyName    string     `json:"friendly_name" db:"friendly_name"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at" db:"updated_at"`
+	LastUsedAt      *time.Time `json:"last_used_at" db:"last_used_at"`
+}
+
+// -- MARK: ServiceClient section
+
+// Define table name for ServiceClient model
+const ServiceClientTable = "public.service_clients"
+
+// ServiceClient is an OAuth2 client used for service-to-service calls (client_credentials
+// grant). Only the SHA-256 hash of the client secret is stored.
+type ServiceClient struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ClientID   string     `json:"client_id" db:"client_id"`
+	Name       string     `json:"name" db:"name"`
+	SecretHash string     `json:"-" db:"secret_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at" db:"updated_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// HasScopes reports whether every scope in scopes is allowed for the client.
+func (c *ServiceClient) HasScopes(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
 // -- MARK: User credentials section
 
 type UserWithCredentials struct {
@@ -105,6 +186,21 @@ type UserWithCredentials struct {
 
 type AuthenticatedUser struct {
 	UserWithCredentials
-	SessionID   *uuid.UUID `json:"session_id"`
-	TokenExpiry time.Time  `json:"token_expiry"`
+	SessionID     *uuid.UUID `json:"session_id"`
+	TokenExpiry   time.Time  `json:"token_expiry"`
+	SessionExpiry time.Time  `json:"session_expiry"` // Absolute deadline of the session and its refresh token
+}
+
+// -- MARK: Pruning section
+
+// PruneResult reports how many expired rows were deleted per table by a sweep
+type PruneResult struct {
+	OneTimeTokens int64 `json:"one_time_tokens"`
+	RefreshTokens int64 `json:"refresh_tokens"`
+	Sessions      int64 `json:"sessions"`
+}
+
+// Total returns the number of rows deleted across all tables
+func (r *PruneResult) Total() int64 {
+	return r.OneTimeTokens + r.RefreshTokens + r.Sessions
 }
@@ -3,6 +3,14 @@
 
 package models
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	user_models "go-modular/modules/user/models"
+)
+
 type SetPasswordRequest struct {
 	UserID               string `json:"user_id" validate:"required,uuid"`
 	Password             string `json:"password" validate:"required,min=8" example:"secure.password"`
@@ -15,6 +23,13 @@ type UpdatePasswordRequest struct {
 	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqfield=NewPassword" example:"secret.password"`
 }
 
+// AcceptInvitationRequest sets the first password of an invited user.
+type AcceptInvitationRequest struct {
+	Token                string `json:"token" validate:"required"`
+	Password             string `json:"password" validate:"required,min=8" example:"secure.password"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqfield=Password" example:"secure.password"`
+}
+
 type CreateSessionRequest struct {
 	UserID            string  `json:"user_id" validate:"required,uuid"`
 	TokenHash         string  `json:"token_hash" validate:"required"`
@@ -54,23 +69,135 @@ type UpdateRefreshTokenRequest struct {
 }
 
 type SignInWithEmailRequest struct {
-	Email    string `json:"email" validate:"required,email" example:"user@example.com"`
-	Password string `json:"password" validate:"required" example:"secure.password"`
+	Email      string `json:"email" validate:"required,email" example:"user@example.com"`
+	Password   string `json:"password" validate:"required" example:"secure.password"`
+	RememberMe bool   `json:"remember_me" example:"false"`
 }
 
 type SignInWithUsernameRequest struct {
-	Username string `json:"username" validate:"required" example:"user123"`
-	Password string `json:"password" validate:"required" example:"secure.password"`
+	Username   string `json:"username" validate:"required" example:"user123"`
+	Password   string `json:"password" validate:"required" example:"secure.password"`
+	RememberMe bool   `json:"remember_me" example:"false"`
 }
 
 type SignInResponse struct {
 	AuthenticatedUser
 }
 
+// StepUpRequiredResponse is returned when a flagged sign-in must be completed with the
+// verification code emailed to the user (POST /auth/signin/verify).
+type StepUpRequiredResponse struct {
+	Error       string    `json:"error" example:"Verification required"`
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	ExpiresAt   time.Time `json:"expires_at" example:"2025-12-31T23:59:59Z"`
+	Reasons     []string  `json:"reasons" example:"new_device,new_network"`
+}
+
+// VerifySignInStepUpRequest represents the request payload for completing a step-up sign-in.
+type VerifySignInStepUpRequest struct {
+	ChallengeID string `json:"challenge_id" validate:"required,uuid"`
+	Code        string `json:"code" validate:"required,numeric,len=6" example:"123456"`
+}
+
+// PasskeyChallengeResponse is returned when a WebAuthn ceremony is started. The client passes
+// Options to navigator.credentials.create()/get() and echoes ChallengeID back when finishing.
+type PasskeyChallengeResponse struct {
+	ChallengeID string    `json:"challenge_id" example:"Xk2...1735689599"`
+	Options     any       `json:"options"`
+	ExpiresAt   time.Time `json:"expires_at" example:"2025-12-31T23:59:59Z"`
+}
+
+// FinishPasskeyRegistrationRequest represents the request payload for completing passkey registration.
+type FinishPasskeyRegistrationRequest struct {
+	ChallengeID  string          `json:"challenge_id" validate:"required"`
+	FriendlyName string          `json:"friendly_name" validate:"required,max=64" example:"MacBook Touch ID"`
+	Credential   json.RawMessage `json:"credential" validate:"required" swaggertype:"object"`
+}
+
+// SignInWithPasskeyRequest represents the request payload for completing passkey sign-in.
+type SignInWithPasskeyRequest struct {
+	ChallengeID string          `json:"challenge_id" validate:"required"`
+	Credential  json.RawMessage `json:"credential" validate:"required" swaggertype:"object"`
+	RememberMe  bool            `json:"remember_me" example:"false"`
+}
+
+// RefreshSessionRequest represents the request payload for refreshing a session. In cookie
+// mode the refresh token is read from the refresh cookie when omitted.
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// RefreshSessionResponse carries the access token issued for a refreshed session.
+type RefreshSessionResponse struct {
+	AccessToken   string    `json:"access_token,omitempty"`
+	SessionID     uuid.UUID `json:"session_id"`
+	TokenExpiry   time.Time `json:"token_expiry"`
+	SessionExpiry time.Time `json:"session_expiry"`
+}
+
 type AccessTokenPayload struct {
-	UserID string `json:"user_id"` // User ID
-	Email  string `json:"email"`   // User Email
-	SID    string `json:"sid"`     // Session ID
+	UserID string      `json:"user_id"`          // User ID
+	Email  string      `json:"email"`            // User Email
+	SID    string      `json:"sid"`              // Session ID
+	OrgID  string      `json:"org_id,omitempty"` // Active organization ID (omitted when the user has none)
+	Act    *ActorClaim `json:"act,omitempty"`    // Acting party of an impersonation token (RFC 8693)
+}
+
+// ActorClaim is the "act" (actor) claim of RFC 8693 section 4.1. It identifies the admin
+// acting on behalf of the token subject when the token was issued for impersonation.
+type ActorClaim struct {
+	Subject string `json:"sub"` // User ID of the impersonating admin
+}
+
+// ServiceTokenPayload is the payload of a service (client_credentials) access token. It has
+// no user or session; the subject is the client ID and typ overrides the default "access".
+type ServiceTokenPayload struct {
+	Type     string `json:"typ"`       // Always apputils.TokenTypeService
+	ClientID string `json:"client_id"` // Service client ID
+	Scope    string `json:"scope"`     // Space-delimited granted scopes
+}
+
+// UserSessionResponse describes one of the authenticated user's active sessions. Current is
+// set for the session of the access token used to make the request.
+type UserSessionResponse struct {
+	ID             uuid.UUID `json:"id"`
+	DeviceName     *string   `json:"device_name"`
+	UserAgent      *string   `json:"user_agent"`
+	IPAddress      *string   `json:"ip_address"`
+	RememberMe     bool      `json:"remember_me"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Current        bool      `json:"current"`
+}
+
+// ImpersonateUserRequest represents the request payload for impersonating a user. The reason
+// is recorded in the audit log.
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500" example:"Debugging ticket #4821"`
+}
+
+// ImpersonationResponse carries the short-lived access token issued to an admin for
+// impersonating a user. No refresh token is issued; the admin must start a new
+// impersonation once it expires.
+type ImpersonationResponse struct {
+	AccessToken    string           `json:"access_token"`
+	TokenType      string           `json:"token_type" example:"Bearer"`
+	TokenExpiry    time.Time        `json:"token_expiry"`
+	ImpersonatorID uuid.UUID        `json:"impersonator_id"`
+	User           user_models.User `json:"user"`
+}
+
+// SwitchOrganizationRequest represents the request payload for switching the active organization.
+type SwitchOrganizationRequest struct {
+	OrganizationID string `json:"organization_id" validate:"required,uuid" example:"0198c5a2-7d4e-7b3a-9f1e-2a6c8d9e0f11"`
+}
+
+// SwitchOrganizationResponse carries the access token re-issued for the new active organization.
+type SwitchOrganizationResponse struct {
+	AccessToken    string    `json:"access_token,omitempty"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	TokenExpiry    time.Time `json:"token_expiry"`
 }
 
 // InitiateEmailVerificationRequest represents the request payload for initiating email verification.
@@ -93,3 +220,62 @@ type RevokeEmailVerificationRequest struct {
 type ResendEmailVerificationRequest struct {
 	Email string `json:"email" validate:"required,email" example:"user@example.com"`
 }
+
+// GrantTypeClientCredentials is the only OAuth2 grant type supported by the token endpoint.
+const GrantTypeClientCredentials = "client_credentials"
+
+// OAuthTokenRequest represents the OAuth2 token request (RFC 6749, form-encoded). Client
+// credentials may be sent in the body or with HTTP Basic authentication.
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" validate:"required" example:"client_credentials"`
+	ClientID     string `form:"client_id" json:"client_id,omitempty" example:"svc_reporting"`
+	ClientSecret string `form:"client_secret" json:"client_secret,omitempty" example:"s3cr3t"`
+	Scope        string `form:"scope" json:"scope,omitempty" example:"users:read"` // Space-delimited, defaults to all allowed scopes
+}
+
+// OAuthTokenResponse represents the OAuth2 access token response (RFC 6749 section 5.1).
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"900"` // Lifetime in seconds
+	Scope       string `json:"scope,omitempty" example:"users:read"`
+}
+
+// OAuthIntrospectRequest represents the token introspection request (RFC 7662, form-encoded).
+// The calling client authenticates like on the token endpoint.
+type OAuthIntrospectRequest struct {
+	Token         string `form:"token" json:"token" validate:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint,omitempty" example:"access_token"` // access_token|refresh_token
+	ClientID      string `form:"client_id" json:"client_id,omitempty" example:"svc_gateway"`
+	ClientSecret  string `form:"client_secret" json:"client_secret,omitempty" example:"s3cr3t"`
+}
+
+// OAuthIntrospectionResponse represents the token introspection response (RFC 7662 section 2.2).
+// Only "active" is set for inactive (invalid, expired or revoked) tokens.
+type OAuthIntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Type      string `json:"typ,omitempty" example:"access"` // Token type claim: access|refresh|service
+	Scope     string `json:"scope,omitempty" example:"users:read"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	OrgID     string `json:"org_id,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+}
+
+// OAuthRevokeRequest represents the token revocation request (RFC 7009, form-encoded).
+// Only refresh tokens can be revoked; revoking one also revokes its session.
+type OAuthRevokeRequest struct {
+	Token         string `form:"token" json:"token" validate:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint,omitempty" example:"refresh_token"`
+	ClientID      string `form:"client_id" json:"client_id,omitempty" example:"svc_gateway"`
+	ClientSecret  string `form:"client_secret" json:"client_secret,omitempty" example:"s3cr3t"`
+}
+
+// OAuthErrorResponse represents an OAuth2 error response (RFC 6749 section 5.2).
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_client"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
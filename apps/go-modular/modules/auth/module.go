@@ -1,23 +1,43 @@
 package auth
 
 import (
+	"context"
+	"embed"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
+	"go-modular/internal/adapter"
+	"go-modular/internal/eventbus"
+	"go-modular/internal/module"
 	"go-modular/internal/notification"
+	"go-modular/internal/personaldata"
 	"go-modular/modules/auth/handler"
 	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
 
+	"github.com/alexliesenfeld/health"
+	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 	"github.com/lestrrat-go/jwx/jwa"
 
+	auditSvc "go-modular/modules/audit/services"
 	svcUser "go-modular/modules/auth/services"
+	orgSvc "go-modular/modules/organization/services"
 	svcAuth "go-modular/modules/user/services"
 )
 
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func init() {
+	module.Register(&AuthModule{})
+}
+
 type Options struct {
 	PgPool             *pgxpool.Pool // PostgreSQL connection pool (required)
 	Logger             *slog.Logger  // Slog logger instance (optional)
@@ -34,6 +54,63 @@ type Options struct {
 	// Caller MUST provide a fully qualified base URL (e.g. https://example.com)
 	// via Options.BaseURL before creating the module. We no longer read APP_BASE_URL here.
 	BaseURL string
+
+	// InvitationURL overrides the set-password link target of invitations sent to imported
+	// users, e.g. a frontend page (optional)
+	InvitationURL string
+
+	// WebAuthn relying party settings (optional). When empty they are derived from BaseURL.
+	WebAuthnRPID      string
+	WebAuthnRPName    string
+	WebAuthnRPOrigins []string
+
+	// Cookie-based browser session mode (optional). When enabled, sign-in sets HttpOnly
+	// access/refresh token cookies and JWTMiddleware accepts the access token cookie.
+	CookieSessionEnabled bool
+	CookieDomain         string
+	CookieSecure         bool
+	CookieSameSite       http.SameSite
+
+	// Auditor records security audit events (optional)
+	Auditor auditSvc.Recorder
+
+	// Password policy applied when setting or changing passwords (optional).
+	// PasswordHistorySize prevents reuse of the last N passwords (0 disables).
+	PasswordPolicy      apputils.PasswordPolicy
+	PasswordHistorySize int
+
+	// Expired token sweeper (optional). A zero TokenSweepInterval disables the sweeper.
+	TokenSweepInterval  time.Duration
+	TokenSweepBatchSize int
+
+	// Organizations resolves memberships for the org_id access token claim (optional).
+	// When nil, access tokens carry no org_id and organization switching is disabled.
+	Organizations svcUser.OrganizationResolver
+
+	// ServiceTokenExpiry is the lifetime of client_credentials service tokens (default: 15m).
+	ServiceTokenExpiry time.Duration
+
+	// SessionPolicy sets the idle timeout and absolute lifetime of sessions, with longer
+	// remember-me durations selectable per sign-in (default: svcUser.DefaultSessionPolicy()).
+	SessionPolicy svcUser.SessionPolicy
+
+	// SignInRisk flags sign-ins from unseen devices, browsers or networks, emails a new sign-in
+	// alert and optionally requires an emailed verification code (disabled when zero).
+	SignInRisk svcUser.SignInRiskPolicy
+
+	// ImpersonationTokenExpiry is the lifetime of the access tokens issued to admins by
+	// POST /admin/users/:userId/impersonate (default: 15m).
+	ImpersonationTokenExpiry time.Duration
+
+	// DataExports receives the module's personal data exporter (optional)
+	DataExports *personaldata.Registry
+
+	// Replicas routes session lookups and listings to read replicas (optional)
+	Replicas adapter.ReadRouter
+
+	// Events is the domain event bus shared by all modules. The module publishes session and
+	// password events and subscribes to user events (optional, a private bus when nil)
+	Events *eventbus.Bus
 }
 
 // AuthModule holds dependencies for auth-related handlers.
@@ -41,10 +118,14 @@ type AuthModule struct {
 	logger      *slog.Logger
 	middlewares []echo.MiddlewareFunc
 	handler     *handler.Handler
+	authService *svcUser.AuthService
 
 	// mailer available to the module/handlers
 	mailer *notification.Mailer
 
+	// sweeper deletes expired tokens in the background (nil when disabled)
+	sweeper *svcUser.TokenSweeper
+
 	// keep JWT config so we can attach middleware to protected routes
 	jwtSecretKey []byte
 	signingAlg   jwa.SignatureAlgorithm
@@ -99,31 +180,167 @@ func NewModule(opts *Options) *AuthModule {
 	}
 
 	authRepo := repository.NewAuthRepository(opts.PgPool, logger)
+	if opts.Replicas != nil {
+		authRepo.UseReplicas(opts.Replicas)
+	}
 	authService := svcUser.NewAuthService(svcUser.AuthServiceOpts{
-		AuthRepo:           authRepo,
-		UserService:        opts.UserService,
-		JWTSecretKey:       opts.JWTSecretKey,
-		AccessTokenExpiry:  opts.AccessTokenExpiry,
-		RefreshTokenExpiry: opts.RefreshTokenExpiry,
-		SigningAlg:         opts.SigningAlg,
-		Mailer:             opts.Mailer,
-		BaseURL:            opts.BaseURL,
+		AuthRepo:            authRepo,
+		UserService:         opts.UserService,
+		JWTSecretKey:        opts.JWTSecretKey,
+		AccessTokenExpiry:   opts.AccessTokenExpiry,
+		RefreshTokenExpiry:  opts.RefreshTokenExpiry,
+		SigningAlg:          opts.SigningAlg,
+		Mailer:              opts.Mailer,
+		BaseURL:             opts.BaseURL,
+		InvitationURL:       opts.InvitationURL,
+		WebAuthnRPID:        opts.WebAuthnRPID,
+		WebAuthnRPName:      opts.WebAuthnRPName,
+		WebAuthnRPOrigins:   opts.WebAuthnRPOrigins,
+		Auditor:             opts.Auditor,
+		PasswordPolicy:      opts.PasswordPolicy,
+		PasswordHistorySize: opts.PasswordHistorySize,
+		Organizations:       opts.Organizations,
+		ServiceTokenExpiry:  opts.ServiceTokenExpiry,
+		SessionPolicy:       opts.SessionPolicy,
+		SignInRisk:          opts.SignInRisk,
+		ImpersonationExpiry: opts.ImpersonationTokenExpiry,
+		Events:              opts.Events,
+		Tx:                  adapter.NewTxManager(opts.PgPool, adapter.TxManagerOpts{Logger: logger}),
+		Logger:              logger,
 	})
 
+	if opts.Events != nil {
+		eventbus.Subscribe(opts.Events, "auth.clear_email_verification_tokens", authService.ClearEmailVerificationTokens, eventbus.Async())
+		eventbus.Subscribe(opts.Events, "auth.send_invitation", authService.SendInvitation, eventbus.Async())
+	}
+
+	if opts.DataExports != nil {
+		opts.DataExports.Register(personaldata.NewExporter("auth", authService.ExportPersonalData))
+	}
+
 	h := handler.NewHandler(&handler.HandlerOpts{
 		Logger:      logger,
 		AuthService: authService,
+		Cookies: handler.CookieConfig{
+			Enabled:  opts.CookieSessionEnabled,
+			Domain:   opts.CookieDomain,
+			Secure:   opts.CookieSecure,
+			SameSite: opts.CookieSameSite,
+		},
 	})
 
+	var sweeper *svcUser.TokenSweeper
+	if opts.TokenSweepInterval > 0 {
+		sweeper = svcUser.NewTokenSweeper(svcUser.TokenSweeperOpts{
+			AuthRepo:  authRepo,
+			Logger:    logger,
+			Interval:  opts.TokenSweepInterval,
+			BatchSize: opts.TokenSweepBatchSize,
+		})
+	}
+
 	return &AuthModule{
 		logger:       logger,
 		handler:      h,
+		authService:  authService,
 		mailer:       opts.Mailer,
+		sweeper:      sweeper,
 		jwtSecretKey: opts.JWTSecretKey,
 		signingAlg:   opts.SigningAlg,
 	}
 }
 
+// Ensure AuthModule implements module.Module
+var _ module.Module = (*AuthModule)(nil)
+
+func (m *AuthModule) Name() string { return "auth" }
+
+func (m *AuthModule) Dependencies() []string { return []string{"audit", "organization", "user"} }
+
+// Init builds the module from the application config. The module provides itself, so the
+// other modules protect their routes with its JWTMiddleware and RequireAdmin.
+func (m *AuthModule) Init(_ context.Context, app *module.App) error {
+	cfg := app.Config
+	*m = *NewModule(&Options{
+		PgPool:       app.DB.Pool,
+		Logger:       app.Logger,
+		UserService:  module.MustLookup[svcAuth.UserServiceInterface](app),
+		JWTSecretKey: []byte(cfg.App.JWTSecretKey),
+		BaseURL:      cfg.GetAppBaseURL(),
+		Mailer:       app.Mailer,
+
+		WebAuthnRPID:      cfg.Auth.WebAuthnRPID,
+		WebAuthnRPName:    cfg.Auth.WebAuthnRPName,
+		WebAuthnRPOrigins: cfg.GetWebAuthnRPOrigins(),
+
+		CookieSessionEnabled: cfg.Auth.CookieSessionEnabled,
+		CookieDomain:         cfg.Auth.CookieDomain,
+		CookieSecure:         cfg.Auth.CookieSecure,
+		CookieSameSite:       cfg.GetCookieSameSite(),
+
+		Auditor: module.MustLookup[auditSvc.Recorder](app),
+
+		PasswordPolicy: apputils.PasswordPolicy{
+			MinLength:      cfg.Auth.PasswordMinLength,
+			RequireUpper:   cfg.Auth.PasswordRequireUpper,
+			RequireLower:   cfg.Auth.PasswordRequireLower,
+			RequireDigit:   cfg.Auth.PasswordRequireDigit,
+			RequireSymbol:  cfg.Auth.PasswordRequireSymbol,
+			CheckBlocklist: cfg.Auth.PasswordBlocklistEnabled,
+		},
+		PasswordHistorySize: cfg.Auth.PasswordHistorySize,
+
+		TokenSweepInterval:  cfg.Auth.TokenSweepInterval,
+		TokenSweepBatchSize: cfg.Auth.TokenSweepBatchSize,
+		ServiceTokenExpiry:  cfg.Auth.ServiceTokenExpiry,
+		SessionPolicy: svcUser.SessionPolicy{
+			IdleTimeout:           cfg.Auth.SessionIdleTimeout,
+			AbsoluteLifetime:      cfg.Auth.SessionAbsoluteLifetime,
+			RememberMeIdleTimeout: cfg.Auth.SessionRememberMeIdleTimeout,
+			RememberMeLifetime:    cfg.Auth.SessionRememberMeLifetime,
+		},
+		SignInRisk: svcUser.SignInRiskPolicy{
+			Enabled:     cfg.Auth.SignInRiskEnabled,
+			HistorySize: cfg.Auth.SignInRiskHistorySize,
+			StepUp:      cfg.Auth.SignInRiskStepUp,
+		},
+		ImpersonationTokenExpiry: cfg.Auth.ImpersonationTokenExpiry,
+
+		// Resolves org_id claims through the organization module
+		Organizations: module.MustLookup[orgSvc.OrganizationServiceInterface](app),
+		DataExports:   app.DataExports,
+		Replicas:      app.DB,
+		Events:        app.Events,
+	})
+	module.Provide(app, m)
+	return nil
+}
+
+// Start runs the expired token sweeper until ctx is cancelled.
+func (m *AuthModule) Start(ctx context.Context) error {
+	m.StartTokenSweeper(ctx)
+	return nil
+}
+
+func (m *AuthModule) Stop(context.Context) error { return nil }
+
+func (m *AuthModule) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	return sub
+}
+
+func (m *AuthModule) Health() []health.Check { return nil }
+
+// StartTokenSweeper runs the expired token sweeper in the background until ctx is cancelled.
+// It is a no-op when the sweeper is disabled.
+func (m *AuthModule) StartTokenSweeper(ctx context.Context) {
+	if m.sweeper == nil {
+		return
+	}
+	m.logger.Info("Starting expired token sweeper")
+	go m.sweeper.Run(ctx)
+}
+
 // Use adds middleware(s) to the AuthModule (grouped).
 func (m *AuthModule) Use(mw ...echo.MiddlewareFunc) {
 	m.middlewares = append(m.middlewares, mw...)
@@ -134,28 +351,89 @@ func (m *AuthModule) JWTMiddleware() echo.MiddlewareFunc {
 	return JWTMiddleware(m.jwtSecretKey, m.signingAlg)
 }
 
+// RequireAdmin returns middleware that rejects users without administrator privileges with
+// 403. It must run after JWTMiddleware; service tokens are always rejected.
+func (m *AuthModule) RequireAdmin() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			uid, ok := GetUserID(c)
+			if !ok {
+				return echo.NewHTTPError(http.StatusForbidden, "administrator privileges required")
+			}
+			userID, err := uuid.FromString(uid)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusForbidden, "administrator privileges required")
+			}
+			isAdmin, err := m.authService.IsAdmin(c.Request().Context(), userID)
+			if err != nil {
+				m.logger.Error("Failed to check admin privileges", "user_id", uid, "error", err.Error())
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to check privileges")
+			}
+			if !isAdmin {
+				return echo.NewHTTPError(http.StatusForbidden, "administrator privileges required")
+			}
+			return next(c)
+		}
+	}
+}
+
 // RegisterRoutes registers auth endpoints to the given Echo group.
 func (m *AuthModule) RegisterRoutes(e *echo.Group) {
 	// Public routes (no access token required)
 	publicGroup := e.Group("/auth", m.middlewares...)
 	publicGroup.POST("/signin/email", m.handler.SignInWithEmail)
 	publicGroup.POST("/signin/username", m.handler.SignInWithUsername)
+	publicGroup.POST("/signin/passkey/begin", m.handler.BeginPasskeySignIn)
+	publicGroup.POST("/signin/passkey/finish", m.handler.SignInWithPasskey)
+	publicGroup.POST("/signin/verify", m.handler.VerifySignInStepUp)
+	// The emailed link only shows a confirmation, so link scanners cannot revoke sessions
+	publicGroup.GET("/signin/not-me", m.handler.ConfirmRevokeSessionFromAlert)
+	publicGroup.POST("/signin/not-me", m.handler.RevokeSessionFromAlert)
 	publicGroup.GET("/verify-email", m.handler.ValidateEmailVerificationByLink)
+	publicGroup.POST("/refresh", m.handler.RefreshSession)
 	publicGroup.POST("/refresh-token", m.handler.CreateRefreshToken)
 	publicGroup.PUT("/refresh-token", m.handler.UpdateRefreshToken)
 	publicGroup.GET("/refresh-token/:tokenId", m.handler.GetRefreshToken)
 	publicGroup.DELETE("/refresh-token/:tokenId", m.handler.DeleteRefreshToken)
 	publicGroup.POST("/verification/email/initiate", m.handler.InitiateEmailVerification)
 	publicGroup.POST("/verification/email/validate", m.handler.ValidateEmailVerification)
+	publicGroup.POST("/invitation/accept", m.handler.AcceptInvitation)
 
 	// Protected routes (require access token)
 	protected := publicGroup.Group("", m.JWTMiddleware())
-	protected.POST("/password", m.handler.SetUserPassword)
-	protected.PUT("/password/:userId", m.handler.UpdateUserPassword)
-	protected.POST("/session", m.handler.CreateSession)
-	protected.PUT("/session", m.handler.UpdateSession)
 	protected.GET("/session/:sessionId", m.handler.GetSession)
-	protected.DELETE("/session/:sessionId", m.handler.DeleteSession)
 	protected.POST("/verification/email/revoke", m.handler.RevokeEmailVerification)
 	protected.POST("/verification/email/resend", m.handler.ResendEmailVerification)
+	protected.GET("/passkeys", m.handler.ListPasskeys)
+
+	// Sensitive routes, not reachable with impersonation tokens
+	sensitive := protected.Group("", DenyImpersonation())
+	sensitive.POST("/signout", m.handler.SignOut)
+	sensitive.POST("/password", m.handler.SetUserPassword)
+	sensitive.PUT("/password/:userId", m.handler.UpdateUserPassword)
+	sensitive.POST("/session", m.handler.CreateSession)
+	sensitive.PUT("/session", m.handler.UpdateSession)
+	sensitive.DELETE("/session/:sessionId", m.handler.DeleteSession)
+	sensitive.POST("/passkeys/register/begin", m.handler.BeginPasskeyRegistration)
+	sensitive.POST("/passkeys/register/finish", m.handler.FinishPasskeyRegistration)
+	sensitive.DELETE("/passkeys/:passkeyId", m.handler.DeletePasskey)
+	sensitive.POST("/organization/switch", m.handler.SwitchOrganization)
+
+	// Self-service session routes of the authenticated user. They live in the auth module as
+	// sessions are owned by it; the user module serves the rest of /users/me.
+	meSessions := e.Group("/users/me/sessions", m.middlewares...)
+	meSessions.Use(m.JWTMiddleware())
+	meSessions.GET("", m.handler.ListMySessions)
+	meSessions.DELETE("/:sessionId", m.handler.RevokeMySession, DenyImpersonation())
+
+	// Admin routes (require an admin access token, impersonation tokens are rejected)
+	adminGroup := e.Group("/admin", m.middlewares...)
+	adminGroup.Use(m.JWTMiddleware(), DenyImpersonation(), m.RequireAdmin())
+	adminGroup.POST("/users/:userId/impersonate", m.handler.ImpersonateUser)
+
+	// OAuth2 endpoints (service clients authenticate with their client credentials)
+	oauthGroup := e.Group("/oauth", m.middlewares...)
+	oauthGroup.POST("/token", m.handler.IssueOAuthToken)
+	oauthGroup.POST("/introspect", m.handler.IntrospectOAuthToken)
+	oauthGroup.POST("/revoke", m.handler.RevokeOAuthToken)
 }
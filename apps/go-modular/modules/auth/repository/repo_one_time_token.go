@@ -13,14 +13,44 @@ import (
 // FindAllOneTimeTokens returns all one time tokens in the database.
 func (r *AuthRepository) FindAllOneTimeTokens(ctx context.Context) ([]*models.OneTimeToken, error) {
 	query := `SELECT id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at FROM ` + models.OneTimeTokenTable
-	rows, err := r.pgPool.Query(ctx, query)
+	rows, err := r.conn(ctx).Query(ctx, query)
 	if err != nil {
 		r.logger.Error("failed to query all one time tokens", "op", "FindAllOneTimeTokens", "error", err.Error())
 		return nil, err
 	}
+	return r.collectOneTimeTokens(rows, "FindAllOneTimeTokens")
+}
+
+// FindOneTimeTokensByUserSubject returns the one time tokens of the given subject bound to a user.
+func (r *AuthRepository) FindOneTimeTokensByUserSubject(ctx context.Context, userID uuid.UUID, subject models.OneTimeTokenSubject) ([]*models.OneTimeToken, error) {
+	query := `SELECT id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at FROM ` + models.OneTimeTokenTable + `
+        WHERE user_id = $1 AND subject = $2 ORDER BY created_at DESC`
+	rows, err := r.conn(ctx).Query(ctx, query, userID, subject)
+	if err != nil {
+		r.logger.Error("failed to query one time tokens", "op", "FindOneTimeTokensByUserSubject", "user_id", userID.String(), "subject", string(subject), "error", err.Error())
+		return nil, err
+	}
+	return r.collectOneTimeTokens(rows, "FindOneTimeTokensByUserSubject")
+}
+
+// FindOneTimeTokensByRelatesTo returns the one time tokens of the given subject whose
+// relates_to matches case-insensitively (e.g. an email address).
+func (r *AuthRepository) FindOneTimeTokensByRelatesTo(ctx context.Context, subject models.OneTimeTokenSubject, relatesTo string) ([]*models.OneTimeToken, error) {
+	query := `SELECT id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at FROM ` + models.OneTimeTokenTable + `
+        WHERE lower(relates_to) = lower($1) AND subject = $2 ORDER BY created_at DESC`
+	rows, err := r.conn(ctx).Query(ctx, query, relatesTo, subject)
+	if err != nil {
+		r.logger.Error("failed to query one time tokens", "op", "FindOneTimeTokensByRelatesTo", "subject", string(subject), "error", err.Error())
+		return nil, err
+	}
+	return r.collectOneTimeTokens(rows, "FindOneTimeTokensByRelatesTo")
+}
+
+// collectOneTimeTokens scans all rows into one time tokens and closes rows.
+func (r *AuthRepository) collectOneTimeTokens(rows pgx.Rows, op string) ([]*models.OneTimeToken, error) {
 	defer rows.Close()
 
-	var tokens []*models.OneTimeToken
+	tokens := []*models.OneTimeToken{}
 	for rows.Next() {
 		var t models.OneTimeToken
 		var metaBytes []byte
@@ -36,7 +66,7 @@ func (r *AuthRepository) FindAllOneTimeTokens(ctx context.Context) ([]*models.On
 			&t.LastSentAt,
 		)
 		if err != nil {
-			r.logger.Error("failed to scan one time token", "op", "FindAllOneTimeTokens", "error", err.Error())
+			r.logger.Error("failed to scan one time token", "op", op, "error", err.Error())
 			return nil, err
 		}
 		if len(metaBytes) > 0 {
@@ -49,6 +79,10 @@ func (r *AuthRepository) FindAllOneTimeTokens(ctx context.Context) ([]*models.On
 		}
 		tokens = append(tokens, &t)
 	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to iterate one time tokens", "op", op, "error", err.Error())
+		return nil, err
+	}
 	return tokens, nil
 }
 
@@ -76,7 +110,7 @@ func (r *AuthRepository) CreateOneTimeToken(ctx context.Context, token *models.O
 	query := `INSERT INTO ` + models.OneTimeTokenTable + `
         (id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at)
         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := r.pgPool.Exec(ctx, query,
+	_, err := r.conn(ctx).Exec(ctx, query,
 		token.ID,
 		token.UserID,
 		token.Subject,
@@ -100,7 +134,7 @@ func (r *AuthRepository) GetOneTimeTokenByID(ctx context.Context, tokenID uuid.U
 	query := `SELECT id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at FROM ` + models.OneTimeTokenTable + ` WHERE id = $1`
 	var t models.OneTimeToken
 	var metaBytes []byte
-	err := r.pgPool.QueryRow(ctx, query, tokenID).Scan(
+	err := r.conn(ctx).QueryRow(ctx, query, tokenID).Scan(
 		&t.ID,
 		&t.UserID,
 		&t.Subject,
@@ -135,7 +169,7 @@ func (r *AuthRepository) GetOneTimeTokenByTokenHash(ctx context.Context, tokenHa
 	query := `SELECT id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at FROM ` + models.OneTimeTokenTable + ` WHERE token_hash = $1`
 	var t models.OneTimeToken
 	var metaBytes []byte
-	err := r.pgPool.QueryRow(ctx, query, tokenHash).Scan(
+	err := r.conn(ctx).QueryRow(ctx, query, tokenHash).Scan(
 		&t.ID,
 		&t.UserID,
 		&t.Subject,
@@ -168,7 +202,7 @@ func (r *AuthRepository) GetOneTimeTokenByTokenHash(ctx context.Context, tokenHa
 // DeleteOneTimeToken deletes a one time token by its ID.
 func (r *AuthRepository) DeleteOneTimeToken(ctx context.Context, tokenID uuid.UUID) error {
 	query := `DELETE FROM ` + models.OneTimeTokenTable + ` WHERE id = $1`
-	cmd, err := r.pgPool.Exec(ctx, query, tokenID)
+	cmd, err := r.conn(ctx).Exec(ctx, query, tokenID)
 	if err != nil {
 		r.logger.Error("failed to delete one time token", "op", "DeleteOneTimeToken", "token_id", tokenID.String(), "error", err.Error())
 		return err
@@ -184,7 +218,7 @@ func (r *AuthRepository) DeleteOneTimeToken(ctx context.Context, tokenID uuid.UU
 // UpdateOneTimeTokenLastSentAt updates the last_sent_at field of a one time token by its ID.
 func (r *AuthRepository) UpdateOneTimeTokenLastSentAt(ctx context.Context, tokenID uuid.UUID, lastSentAt time.Time) error {
 	query := `UPDATE ` + models.OneTimeTokenTable + ` SET last_sent_at = $1 WHERE id = $2`
-	cmd, err := r.pgPool.Exec(ctx, query, lastSentAt, tokenID)
+	cmd, err := r.conn(ctx).Exec(ctx, query, lastSentAt, tokenID)
 	if err != nil {
 		r.logger.Error("failed to update last_sent_at", "op", "UpdateOneTimeTokenLastSentAt", "token_id", tokenID.String(), "error", err.Error())
 		return err
@@ -196,3 +230,59 @@ func (r *AuthRepository) UpdateOneTimeTokenLastSentAt(ctx context.Context, token
 	r.logger.Info("one time token last_sent_at updated", "op", "UpdateOneTimeTokenLastSentAt", "token_id", tokenID.String())
 	return nil
 }
+
+// UpdateOneTimeTokenMetadata replaces the metadata of a one time token by its ID.
+func (r *AuthRepository) UpdateOneTimeTokenMetadata(ctx context.Context, tokenID uuid.UUID, metadata map[string]any) error {
+	var metaArg interface{}
+	if metadata != nil {
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			r.logger.Error("failed to marshal metadata for one time token", "op", "UpdateOneTimeTokenMetadata", "token_id", tokenID.String(), "error", err.Error())
+			return err
+		}
+		metaArg = b
+	}
+	query := `UPDATE ` + models.OneTimeTokenTable + ` SET metadata = $1 WHERE id = $2`
+	cmd, err := r.conn(ctx).Exec(ctx, query, metaArg, tokenID)
+	if err != nil {
+		r.logger.Error("failed to update metadata", "op", "UpdateOneTimeTokenMetadata", "token_id", tokenID.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("one time token not found for update", "op", "UpdateOneTimeTokenMetadata", "token_id", tokenID.String())
+		return ErrNotFound
+	}
+	return nil
+}
+
+// IncrementOneTimeTokenAttempts atomically increments the "attempts" metadata value of a one
+// time token by its ID and returns the new count, so concurrent attempts are all counted.
+func (r *AuthRepository) IncrementOneTimeTokenAttempts(ctx context.Context, tokenID uuid.UUID) (int, error) {
+	query := `UPDATE ` + models.OneTimeTokenTable + `
+        SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{attempts}', to_jsonb(COALESCE((metadata ->> 'attempts')::int, 0) + 1))
+        WHERE id = $1
+        RETURNING (metadata ->> 'attempts')::int`
+	var attempts int
+	if err := r.conn(ctx).QueryRow(ctx, query, tokenID).Scan(&attempts); err != nil {
+		if err == pgx.ErrNoRows {
+			r.logger.Warn("one time token not found for update", "op", "IncrementOneTimeTokenAttempts", "token_id", tokenID.String())
+			return 0, ErrNotFound
+		}
+		r.logger.Error("failed to increment attempts", "op", "IncrementOneTimeTokenAttempts", "token_id", tokenID.String(), "error", err.Error())
+		return 0, err
+	}
+	return attempts, nil
+}
+
+// DeleteOneTimeTokensByUserSubject deletes every one time token of the given subject bound to a user.
+// It is not an error if no token matches.
+func (r *AuthRepository) DeleteOneTimeTokensByUserSubject(ctx context.Context, userID uuid.UUID, subject models.OneTimeTokenSubject) error {
+	query := `DELETE FROM ` + models.OneTimeTokenTable + ` WHERE user_id = $1 AND subject = $2`
+	cmd, err := r.conn(ctx).Exec(ctx, query, userID, subject)
+	if err != nil {
+		r.logger.Error("failed to delete one time tokens", "op", "DeleteOneTimeTokensByUserSubject", "user_id", userID.String(), "subject", string(subject), "error", err.Error())
+		return err
+	}
+	r.logger.Info("one time tokens deleted", "op", "DeleteOneTimeTokensByUserSubject", "user_id", userID.String(), "subject", string(subject), "count", cmd.RowsAffected())
+	return nil
+}
@@ -123,6 +123,20 @@ func TestOneTimeToken_CRUD_and_NotFound(t *testing.T) {
 	// compare dereferenced pointer within a second
 	assert.WithinDuration(t, newSent, *updated.LastSentAt, time.Second)
 
+	// Update metadata
+	require.NoError(t, repo.UpdateOneTimeTokenMetadata(ctx, token.ID, map[string]any{"reason": "signup", "attempts": 2}))
+	updated, err = repo.GetOneTimeTokenByID(ctx, token.ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, updated.Metadata["attempts"])
+
+	// Increment attempts keeps the other metadata
+	attempts, err := repo.IncrementOneTimeTokenAttempts(ctx, token.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	updated, err = repo.GetOneTimeTokenByID(ctx, token.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "signup", updated.Metadata["reason"])
+
 	// Delete
 	require.NoError(t, repo.DeleteOneTimeToken(ctx, token.ID))
 
@@ -137,6 +151,12 @@ func TestOneTimeToken_CRUD_and_NotFound(t *testing.T) {
 	// Update last_sent_at on missing ID returns ErrNotFound
 	err = repo.UpdateOneTimeTokenLastSentAt(ctx, token.ID, time.Now())
 	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Update metadata on missing ID returns ErrNotFound
+	err = repo.UpdateOneTimeTokenMetadata(ctx, token.ID, map[string]any{})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.IncrementOneTimeTokenAttempts(ctx, token.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
 }
 
 func TestOneTimeToken_Get_NotFound(t *testing.T) {
@@ -152,3 +172,49 @@ func TestOneTimeToken_Get_NotFound(t *testing.T) {
 	_, err = repo.GetOneTimeTokenByTokenHash(ctx, "non-existent-hash-xyz")
 	assert.ErrorIs(t, err, ErrNotFound)
 }
+
+func TestOneTimeToken_TargetedQueries_and_PruneExpired(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupAuthRepo(t)
+	defer teardown()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	token := &models.OneTimeToken{
+		UserID:    &uid,
+		Subject:   models.OneTimeTokenSubjectEmailVerification,
+		TokenHash: "targeted-query-hash",
+		RelatesTo: "Jane.Doe@Example.com",
+		CreatedAt: now,
+		ExpiresAt: now.Add(1 * time.Minute),
+	}
+	require.NoError(t, repo.CreateOneTimeToken(ctx, token))
+
+	// by (user_id, subject)
+	byUser, err := repo.FindOneTimeTokensByUserSubject(ctx, uid, models.OneTimeTokenSubjectEmailVerification)
+	require.NoError(t, err)
+	require.Len(t, byUser, 1)
+	assert.Equal(t, token.ID, byUser[0].ID)
+
+	other, err := repo.FindOneTimeTokensByUserSubject(ctx, uid, models.OneTimeTokenSubjectPasskeySignIn)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+
+	// by lower(relates_to), case-insensitive
+	byRelates, err := repo.FindOneTimeTokensByRelatesTo(ctx, models.OneTimeTokenSubjectEmailVerification, "jane.doe@example.com")
+	require.NoError(t, err)
+	require.Len(t, byRelates, 1)
+	assert.Equal(t, token.ID, byRelates[0].ID)
+
+	// not yet expired relative to now
+	n, err := repo.DeleteExpiredOneTimeTokens(ctx, now, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// expired relative to a later cutoff; batches are bounded by limit
+	n, err = repo.DeleteExpiredOneTimeTokens(ctx, now.Add(time.Hour), 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = repo.GetOneTimeTokenByID(ctx, token.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -18,7 +18,7 @@ func (r *AuthRepository) SetUserPassword(ctx context.Context, userPassword *mode
 	userPassword.CreatedAt = time.Now()
 
 	query := `INSERT INTO ` + models.UserPasswordTable + ` (user_id, password_hash, created_at) VALUES ($1, $2, $3)`
-	_, err := r.pgPool.Exec(ctx, query,
+	_, err := r.conn(ctx).Exec(ctx, query,
 		userPassword.UserID,
 		[]byte(userPassword.PasswordHash),
 		userPassword.CreatedAt,
@@ -36,7 +36,7 @@ func (r *AuthRepository) SetUserPassword(ctx context.Context, userPassword *mode
 func (r *AuthRepository) UpdateUserPassword(ctx context.Context, userID uuid.UUID, newPassword string) error {
 	now := time.Now()
 	query := `UPDATE ` + models.UserPasswordTable + ` SET password_hash = $1, updated_at = $2 WHERE user_id = $3`
-	cmd, err := r.pgPool.Exec(ctx, query, []byte(newPassword), now, userID)
+	cmd, err := r.conn(ctx).Exec(ctx, query, []byte(newPassword), now, userID)
 	if err != nil {
 		r.logger.Error("failed to update user password", "op", "UpdateUserPassword", "user_id", userID.String(), "error", err.Error())
 		return err
@@ -54,7 +54,7 @@ func (r *AuthRepository) UpdateUserPassword(ctx context.Context, userID uuid.UUI
 func (r *AuthRepository) ValidateUserPassword(ctx context.Context, userID uuid.UUID, password string) (bool, error) {
 	var passwordHash []byte
 	query := `SELECT password_hash FROM ` + models.UserPasswordTable + ` WHERE user_id = $1`
-	err := r.pgPool.QueryRow(ctx, query, userID).Scan(&passwordHash)
+	err := r.conn(ctx).QueryRow(ctx, query, userID).Scan(&passwordHash)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			r.logger.Warn("user password not found", "op", "ValidateUserPassword", "user_id", userID.String())
@@ -70,5 +70,21 @@ func (r *AuthRepository) ValidateUserPassword(ctx context.Context, userID uuid.U
 		r.logger.Error("failed to validate password hash", "op", "ValidateUserPassword", "user_id", userID.String(), "error", err.Error())
 		return false, err
 	}
+
+	// Transparently upgrade legacy (bcrypt) or outdated argon2id hashes on
+	// successful validation. Failures are logged and never fail the sign in.
+	if ok && hasher.NeedsRehash(string(passwordHash)) {
+		newHash, err := hasher.Hash(password)
+		if err != nil {
+			r.logger.Error("failed to rehash user password", "op", "ValidateUserPassword", "user_id", userID.String(), "error", err.Error())
+			return ok, nil
+		}
+		if err := r.UpdateUserPassword(ctx, userID, newHash); err != nil {
+			r.logger.Error("failed to store rehashed user password", "op", "ValidateUserPassword", "user_id", userID.String(), "error", err.Error())
+			return ok, nil
+		}
+		r.logger.Info("user password rehashed", "op", "ValidateUserPassword", "user_id", userID.String())
+	}
+
 	return ok, nil
 }
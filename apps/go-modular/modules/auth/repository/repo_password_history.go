@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"go-modular/modules/auth/models"
+)
+
+func (r *AuthRepository) CreatePasswordHistory(ctx context.Context, entry *models.PasswordHistory) error {
+	if entry.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.Must(uuid.NewV7())
+	}
+	entry.CreatedAt = time.Now()
+
+	query := `INSERT INTO ` + models.PasswordHistoryTable + ` (id, user_id, password_hash, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.conn(ctx).Exec(ctx, query, entry.ID, entry.UserID, []byte(entry.PasswordHash), entry.CreatedAt)
+	if err != nil {
+		r.logger.Error("failed to insert password history", "op", "CreatePasswordHistory", "user_id", entry.UserID.String(), "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// ListPasswordHistory returns the user's most recent password hashes, newest first.
+func (r *AuthRepository) ListPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]*models.PasswordHistory, error) {
+	query := `SELECT id, user_id, password_hash, created_at FROM ` + models.PasswordHistoryTable + `
+        WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+	rows, err := r.conn(ctx).Query(ctx, query, userID, limit)
+	if err != nil {
+		r.logger.Error("failed to query password history", "op", "ListPasswordHistory", "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.PasswordHistory{}
+	for rows.Next() {
+		var e models.PasswordHistory
+		var hash []byte
+		if err := rows.Scan(&e.ID, &e.UserID, &hash, &e.CreatedAt); err != nil {
+			r.logger.Error("failed to scan password history", "op", "ListPasswordHistory", "user_id", userID.String(), "error", err.Error())
+			return nil, err
+		}
+		e.PasswordHash = string(hash)
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// PrunePasswordHistory deletes all but the user's most recent keep entries.
+func (r *AuthRepository) PrunePasswordHistory(ctx context.Context, userID uuid.UUID, keep int) error {
+	query := `DELETE FROM ` + models.PasswordHistoryTable + ` WHERE user_id = $1 AND id NOT IN (
+        SELECT id FROM ` + models.PasswordHistoryTable + ` WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2)`
+	_, err := r.conn(ctx).Exec(ctx, query, userID, keep)
+	if err != nil {
+		r.logger.Error("failed to prune password history", "op", "PrunePasswordHistory", "user_id", userID.String(), "error", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"go-modular/modules/auth/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordHistory_Create_List_Prune(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupAuthRepo(t)
+	defer teardown()
+
+	for _, hash := range []string{"hash-1", "hash-2", "hash-3"} {
+		require.NoError(t, repo.CreatePasswordHistory(ctx, &models.PasswordHistory{UserID: uid, PasswordHash: hash}))
+	}
+
+	// newest first, limited
+	entries, err := repo.ListPasswordHistory(ctx, uid, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "hash-3", entries[0].PasswordHash)
+	assert.Equal(t, "hash-2", entries[1].PasswordHash)
+
+	// prune keeps only the most recent entry
+	require.NoError(t, repo.PrunePasswordHistory(ctx, uid, 1))
+	entries, err = repo.ListPasswordHistory(ctx, uid, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "hash-3", entries[0].PasswordHash)
+}
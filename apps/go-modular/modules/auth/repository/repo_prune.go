@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-modular/modules/auth/models"
+)
+
+// DeleteExpiredOneTimeTokens deletes up to limit one time tokens that expired before the given time.
+func (r *AuthRepository) DeleteExpiredOneTimeTokens(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return r.deleteExpiredBatch(ctx, models.OneTimeTokenTable, "DeleteExpiredOneTimeTokens", before, limit)
+}
+
+// DeleteExpiredSessions deletes up to limit sessions that expired before the given time.
+// Refresh tokens of deleted sessions are removed by the ON DELETE CASCADE constraint.
+func (r *AuthRepository) DeleteExpiredSessions(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return r.deleteExpiredBatch(ctx, models.SessionTable, "DeleteExpiredSessions", before, limit)
+}
+
+// DeleteExpiredRefreshTokens deletes up to limit refresh tokens that expired before the given time.
+func (r *AuthRepository) DeleteExpiredRefreshTokens(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return r.deleteExpiredBatch(ctx, models.RefreshTokenTable, "DeleteExpiredRefreshTokens", before, limit)
+}
+
+// deleteExpiredBatch deletes one batch of rows with expires_at < before, oldest first,
+// so large backlogs are removed in short transactions instead of one long-running delete.
+func (r *AuthRepository) deleteExpiredBatch(ctx context.Context, table, op string, before time.Time, limit int) (int64, error) {
+	query := `DELETE FROM ` + table + ` WHERE id IN (
+        SELECT id FROM ` + table + ` WHERE expires_at < $1 ORDER BY expires_at LIMIT $2 FOR UPDATE SKIP LOCKED)`
+	cmd, err := r.conn(ctx).Exec(ctx, query, before, limit)
+	if err != nil {
+		r.logger.Error("failed to delete expired rows", "op", op, "error", err.Error())
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
@@ -21,7 +21,7 @@ func (r *AuthRepository) CreateRefreshToken(ctx context.Context, refreshToken *m
 	query := `INSERT INTO ` + models.RefreshTokenTable + `
         (id, user_id, session_id, token_hash, ip_address, user_agent, expires_at, created_at, revoked_at, revoked_by)
         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
-	_, err := r.pgPool.Exec(ctx, query,
+	_, err := r.conn(ctx).Exec(ctx, query,
 		refreshToken.ID,
 		refreshToken.UserID,
 		refreshToken.SessionID,
@@ -47,7 +47,7 @@ func (r *AuthRepository) GetRefreshToken(ctx context.Context, tokenID uuid.UUID)
 	query := `SELECT id, user_id, session_id, token_hash, ip_address, user_agent, expires_at, created_at, revoked_at, revoked_by
         FROM ` + models.RefreshTokenTable + ` WHERE id = $1`
 	var ip net.IP
-	err := r.pgPool.QueryRow(ctx, query, tokenID).Scan(
+	err := r.conn(ctx).QueryRow(ctx, query, tokenID).Scan(
 		&t.ID,
 		&t.UserID,
 		&t.SessionID,
@@ -78,7 +78,7 @@ func (r *AuthRepository) UpdateRefreshToken(ctx context.Context, refreshToken *m
 	query := `UPDATE ` + models.RefreshTokenTable + `
         SET user_id=$2, session_id=$3, token_hash=$4, ip_address=$5, user_agent=$6, expires_at=$7, created_at=$8, revoked_at=$9, revoked_by=$10
         WHERE id=$1`
-	cmd, err := r.pgPool.Exec(ctx, query,
+	cmd, err := r.conn(ctx).Exec(ctx, query,
 		refreshToken.ID,
 		refreshToken.UserID,
 		refreshToken.SessionID,
@@ -105,7 +105,7 @@ func (r *AuthRepository) UpdateRefreshToken(ctx context.Context, refreshToken *m
 // DeleteRefreshToken deletes a refresh token by its ID.
 func (r *AuthRepository) DeleteRefreshToken(ctx context.Context, tokenID uuid.UUID) error {
 	query := `DELETE FROM ` + models.RefreshTokenTable + ` WHERE id = $1`
-	cmd, err := r.pgPool.Exec(ctx, query, tokenID)
+	cmd, err := r.conn(ctx).Exec(ctx, query, tokenID)
 	if err != nil {
 		r.logger.Error("failed to delete refresh token", "op", "DeleteRefreshToken", "refresh_token_id", tokenID.String(), "error", err.Error())
 		return err
@@ -123,7 +123,7 @@ func (r *AuthRepository) ValidateRefreshToken(ctx context.Context, tokenID uuid.
 	var revokedAt *time.Time
 	var expiresAt time.Time
 	query := `SELECT revoked_at, expires_at FROM ` + models.RefreshTokenTable + ` WHERE id = $1`
-	err := r.pgPool.QueryRow(ctx, query, tokenID).Scan(&revokedAt, &expiresAt)
+	err := r.conn(ctx).QueryRow(ctx, query, tokenID).Scan(&revokedAt, &expiresAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			r.logger.Warn("refresh token not found", "op", "ValidateRefreshToken", "refresh_token_id", tokenID.String())
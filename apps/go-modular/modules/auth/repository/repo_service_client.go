@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go-modular/modules/auth/models"
+)
+
+const serviceClientColumns = `id, client_id, name, secret_hash, scopes, created_at, updated_at, last_used_at, revoked_at`
+
+// scanServiceClient scans a single service_clients row selected with serviceClientColumns.
+func scanServiceClient(row pgx.Row) (*models.ServiceClient, error) {
+	var c models.ServiceClient
+	err := row.Scan(
+		&c.ID,
+		&c.ClientID,
+		&c.Name,
+		&c.SecretHash,
+		&c.Scopes,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.LastUsedAt,
+		&c.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateServiceClient inserts a new service client into the database.
+func (r *AuthRepository) CreateServiceClient(ctx context.Context, client *models.ServiceClient) error {
+	if client.ID == uuid.Nil {
+		client.ID = uuid.Must(uuid.NewV7())
+	}
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = time.Now()
+	}
+	if client.Scopes == nil {
+		client.Scopes = []string{}
+	}
+
+	query := `INSERT INTO ` + models.ServiceClientTable + `
+        (id, client_id, name, secret_hash, scopes, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.conn(ctx).Exec(ctx, query,
+		client.ID,
+		client.ClientID,
+		client.Name,
+		client.SecretHash,
+		client.Scopes,
+		client.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("failed to insert service client", "op", "CreateServiceClient", "client_id", client.ClientID, "error", err.Error())
+		return err
+	}
+	r.logger.Info("service client created", "op", "CreateServiceClient", "client_id", client.ClientID)
+	return nil
+}
+
+// GetServiceClientByClientID retrieves a service client by its public client ID.
+func (r *AuthRepository) GetServiceClientByClientID(ctx context.Context, clientID string) (*models.ServiceClient, error) {
+	query := `SELECT ` + serviceClientColumns + ` FROM ` + models.ServiceClientTable + ` WHERE client_id = $1`
+	c, err := scanServiceClient(r.conn(ctx).QueryRow(ctx, query, clientID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.logger.Warn("service client not found", "op", "GetServiceClientByClientID", "client_id", clientID)
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get service client", "op", "GetServiceClientByClientID", "client_id", clientID, "error", err.Error())
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListServiceClients returns all service clients, including revoked ones.
+func (r *AuthRepository) ListServiceClients(ctx context.Context) ([]*models.ServiceClient, error) {
+	query := `SELECT ` + serviceClientColumns + ` FROM ` + models.ServiceClientTable + ` ORDER BY created_at ASC`
+	rows, err := r.conn(ctx).Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to query service clients", "op", "ListServiceClients", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []*models.ServiceClient{}
+	for rows.Next() {
+		c, err := scanServiceClient(rows)
+		if err != nil {
+			r.logger.Error("failed to scan service client", "op", "ListServiceClients", "error", err.Error())
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, rows.Err()
+}
+
+// UpdateServiceClientLastUsedAt records when the client last obtained an access token.
+func (r *AuthRepository) UpdateServiceClientLastUsedAt(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := `UPDATE ` + models.ServiceClientTable + ` SET last_used_at = $1 WHERE id = $2`
+	cmd, err := r.conn(ctx).Exec(ctx, query, usedAt, id)
+	if err != nil {
+		r.logger.Error("failed to update service client usage", "op", "UpdateServiceClientLastUsedAt", "id", id.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("service client not found for update", "op", "UpdateServiceClientLastUsedAt", "id", id.String())
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeServiceClient marks a service client as revoked so it can no longer obtain tokens.
+func (r *AuthRepository) RevokeServiceClient(ctx context.Context, clientID string, revokedAt time.Time) error {
+	query := `UPDATE ` + models.ServiceClientTable + ` SET revoked_at = $1 WHERE client_id = $2 AND revoked_at IS NULL`
+	cmd, err := r.conn(ctx).Exec(ctx, query, revokedAt, clientID)
+	if err != nil {
+		r.logger.Error("failed to revoke service client", "op", "RevokeServiceClient", "client_id", clientID, "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("active service client not found for revoke", "op", "RevokeServiceClient", "client_id", clientID)
+		return ErrNotFound
+	}
+	r.logger.Info("service client revoked", "op", "RevokeServiceClient", "client_id", clientID)
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceClient_CRUD_and_Revoke(t *testing.T) {
+	ctx := context.Background()
+	repo, _, teardown := setupAuthRepo(t)
+	defer teardown()
+
+	client := &models.ServiceClient{
+		ClientID:   "svc_" + uuid.Must(uuid.NewV4()).String(),
+		Name:       "Reporting worker",
+		SecretHash: "hash",
+		Scopes:     []string{"users:read", "audit:read"},
+	}
+
+	// create
+	err := repo.CreateServiceClient(ctx, client)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, client.ID)
+
+	// duplicate client id is rejected
+	dup := *client
+	dup.ID = uuid.Nil
+	err = repo.CreateServiceClient(ctx, &dup)
+	assert.Error(t, err)
+
+	// get by client id
+	got, err := repo.GetServiceClientByClientID(ctx, client.ClientID)
+	require.NoError(t, err)
+	assert.Equal(t, client.ID, got.ID)
+	assert.Equal(t, "hash", got.SecretHash)
+	assert.ElementsMatch(t, client.Scopes, got.Scopes)
+	assert.Nil(t, got.LastUsedAt)
+	assert.Nil(t, got.RevokedAt)
+
+	// list
+	list, err := repo.ListServiceClients(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+
+	// update usage
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.UpdateServiceClientLastUsedAt(ctx, client.ID, usedAt))
+	got, err = repo.GetServiceClientByClientID(ctx, client.ClientID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastUsedAt)
+	assert.WithinDuration(t, usedAt, *got.LastUsedAt, time.Second)
+
+	// revoke, then revoking again is not found
+	require.NoError(t, repo.RevokeServiceClient(ctx, client.ClientID, time.Now()))
+	got, err = repo.GetServiceClientByClientID(ctx, client.ClientID)
+	require.NoError(t, err)
+	assert.NotNil(t, got.RevokedAt)
+	assert.ErrorIs(t, repo.RevokeServiceClient(ctx, client.ClientID, time.Now()), ErrNotFound)
+
+	// not found
+	_, err = repo.GetServiceClientByClientID(ctx, "svc_missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateServiceClientLastUsedAt(ctx, uuid.Must(uuid.NewV7()), usedAt), ErrNotFound)
+}
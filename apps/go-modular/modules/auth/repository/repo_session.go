@@ -19,9 +19,9 @@ func (r *AuthRepository) CreateSession(ctx context.Context, session *models.Sess
 		session.CreatedAt = time.Now()
 	}
 	query := `INSERT INTO ` + models.SessionTable + `
-        (id, user_id, token_hash, user_agent, device_name, device_fingerprint, ip_address, expires_at, created_at, refreshed_at, revoked_at, revoked_by)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`
-	_, err := r.pgPool.Exec(ctx, query,
+        (id, user_id, token_hash, user_agent, device_name, device_fingerprint, ip_address, expires_at, created_at, refreshed_at, revoked_at, revoked_by, remember_me)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`
+	_, err := r.conn(ctx).Exec(ctx, query,
 		session.ID,
 		session.UserID,
 		session.TokenHash,
@@ -34,6 +34,7 @@ func (r *AuthRepository) CreateSession(ctx context.Context, session *models.Sess
 		session.RefreshedAt,
 		session.RevokedAt,
 		session.RevokedBy,
+		session.RememberMe,
 	)
 	if err != nil {
 		r.logger.Error("failed to insert session", "op", "CreateSession", "user_id", session.UserID.String(), "error", err.Error())
@@ -43,13 +44,14 @@ func (r *AuthRepository) CreateSession(ctx context.Context, session *models.Sess
 	return nil
 }
 
-// GetSession retrieves a session by its ID.
-func (r *AuthRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+// sessionColumns lists the columns read by scanSession, in order.
+const sessionColumns = `id, user_id, token_hash, user_agent, device_name, device_fingerprint, ip_address, expires_at, created_at, refreshed_at, revoked_at, revoked_by, remember_me`
+
+// scanSession scans a row selected with sessionColumns.
+func scanSession(row pgx.Row) (*models.Session, error) {
 	var s models.Session
-	query := `SELECT id, user_id, token_hash, user_agent, device_name, device_fingerprint, ip_address, expires_at, created_at, refreshed_at, revoked_at, revoked_by
-        FROM ` + models.SessionTable + ` WHERE id = $1`
 	var ip net.IP
-	err := r.pgPool.QueryRow(ctx, query, sessionID).Scan(
+	if err := row.Scan(
 		&s.ID,
 		&s.UserID,
 		&s.TokenHash,
@@ -62,7 +64,20 @@ func (r *AuthRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*
 		&s.RefreshedAt,
 		&s.RevokedAt,
 		&s.RevokedBy,
-	)
+		&s.RememberMe,
+	); err != nil {
+		return nil, err
+	}
+	if ip != nil {
+		s.IPAddress = &ip
+	}
+	return &s, nil
+}
+
+// GetSession retrieves a session by its ID.
+func (r *AuthRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM ` + models.SessionTable + ` WHERE id = $1`
+	s, err := scanSession(r.readConn(ctx).QueryRow(ctx, query, sessionID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			r.logger.Warn("session not found", "op", "GetSession", "session_id", sessionID.String())
@@ -71,18 +86,71 @@ func (r *AuthRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*
 		r.logger.Error("failed to get session", "op", "GetSession", "session_id", sessionID.String(), "error", err.Error())
 		return nil, err
 	}
-	if ip != nil {
-		s.IPAddress = &ip
+	return s, nil
+}
+
+// ListRecentSessionsByUser returns up to limit of the user's most recent sessions, newest
+// first, including revoked and expired ones that have not been pruned yet.
+func (r *AuthRepository) ListRecentSessionsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM ` + models.SessionTable + `
+        WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+	rows, err := r.readConn(ctx).Query(ctx, query, userID, limit)
+	if err != nil {
+		r.logger.Error("failed to list sessions", "op", "ListRecentSessionsByUser", "user_id", userID.String(), "error", err.Error())
+		return nil, err
 	}
-	return &s, nil
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			r.logger.Error("failed to scan session", "op", "ListRecentSessionsByUser", "user_id", userID.String(), "error", err.Error())
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to iterate sessions", "op", "ListRecentSessionsByUser", "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// ListActiveSessionsByUser returns the user's sessions that are neither revoked nor past
+// their absolute deadline, newest first. Idle timeouts are applied by the session policy.
+func (r *AuthRepository) ListActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM ` + models.SessionTable + `
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now() ORDER BY created_at DESC`
+	rows, err := r.readConn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error("failed to list active sessions", "op", "ListActiveSessionsByUser", "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			r.logger.Error("failed to scan session", "op", "ListActiveSessionsByUser", "user_id", userID.String(), "error", err.Error())
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to iterate sessions", "op", "ListActiveSessionsByUser", "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	return sessions, nil
 }
 
 // UpdateSession updates an existing session in the database.
 func (r *AuthRepository) UpdateSession(ctx context.Context, session *models.Session) error {
 	query := `UPDATE ` + models.SessionTable + `
-        SET user_id=$2, token_hash=$3, user_agent=$4, device_name=$5, device_fingerprint=$6, ip_address=$7, expires_at=$8, created_at=$9, refreshed_at=$10, revoked_at=$11, revoked_by=$12
+        SET user_id=$2, token_hash=$3, user_agent=$4, device_name=$5, device_fingerprint=$6, ip_address=$7, expires_at=$8, created_at=$9, refreshed_at=$10, revoked_at=$11, revoked_by=$12, remember_me=$13
         WHERE id=$1`
-	cmd, err := r.pgPool.Exec(ctx, query,
+	cmd, err := r.conn(ctx).Exec(ctx, query,
 		session.ID,
 		session.UserID,
 		session.TokenHash,
@@ -95,6 +163,7 @@ func (r *AuthRepository) UpdateSession(ctx context.Context, session *models.Sess
 		session.RefreshedAt,
 		session.RevokedAt,
 		session.RevokedBy,
+		session.RememberMe,
 	)
 	if err != nil {
 		r.logger.Error("failed to update session", "op", "UpdateSession", "session_id", session.ID.String(), "error", err.Error())
@@ -108,10 +177,25 @@ func (r *AuthRepository) UpdateSession(ctx context.Context, session *models.Sess
 	return nil
 }
 
+// TouchSession slides the idle timeout of an active session by setting refreshed_at.
+func (r *AuthRepository) TouchSession(ctx context.Context, sessionID uuid.UUID, refreshedAt time.Time) error {
+	query := `UPDATE ` + models.SessionTable + ` SET refreshed_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+	cmd, err := r.conn(ctx).Exec(ctx, query, sessionID, refreshedAt)
+	if err != nil {
+		r.logger.Error("failed to touch session", "op", "TouchSession", "session_id", sessionID.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("active session not found for touch", "op", "TouchSession", "session_id", sessionID.String())
+		return ErrNotFound
+	}
+	return nil
+}
+
 // DeleteSession deletes a session by its ID.
 func (r *AuthRepository) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
 	query := `DELETE FROM ` + models.SessionTable + ` WHERE id = $1`
-	cmd, err := r.pgPool.Exec(ctx, query, sessionID)
+	cmd, err := r.conn(ctx).Exec(ctx, query, sessionID)
 	if err != nil {
 		r.logger.Error("failed to delete session", "op", "DeleteSession", "session_id", sessionID.String(), "error", err.Error())
 		return err
@@ -129,7 +213,7 @@ func (r *AuthRepository) ValidateSession(ctx context.Context, sessionID uuid.UUI
 	var revokedAt *time.Time
 	var expiresAt time.Time
 	query := `SELECT revoked_at, expires_at FROM ` + models.SessionTable + ` WHERE id = $1`
-	err := r.pgPool.QueryRow(ctx, query, sessionID).Scan(&revokedAt, &expiresAt)
+	err := r.conn(ctx).QueryRow(ctx, query, sessionID).Scan(&revokedAt, &expiresAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			r.logger.Warn("session not found", "op", "ValidateSession", "session_id", sessionID.String())
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepo_RememberMe_Touch(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupRefreshRepo(t)
+	defer teardown()
+
+	session := &models.Session{
+		UserID:     uid,
+		TokenHash:  "sesshash-" + uuid.Must(uuid.NewV7()).String(),
+		ExpiresAt:  time.Now().Add(30 * 24 * time.Hour),
+		RememberMe: true,
+	}
+	require.NoError(t, repo.CreateSession(ctx, session))
+	defer func() { _ = repo.DeleteSession(ctx, session.ID) }()
+
+	got, err := repo.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.True(t, got.RememberMe)
+	assert.Nil(t, got.RefreshedAt)
+	assert.WithinDuration(t, got.CreatedAt, got.LastActivityAt(), 0)
+
+	// touch slides refreshed_at
+	refreshedAt := time.Now().UTC().Truncate(time.Second).Add(time.Minute)
+	require.NoError(t, repo.TouchSession(ctx, session.ID, refreshedAt))
+	got, err = repo.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.RefreshedAt)
+	assert.WithinDuration(t, refreshedAt, *got.RefreshedAt, time.Second)
+	assert.WithinDuration(t, refreshedAt, got.LastActivityAt(), time.Second)
+
+	// revoked sessions cannot be touched
+	revokedAt := time.Now()
+	got.RevokedAt = &revokedAt
+	require.NoError(t, repo.UpdateSession(ctx, got))
+	err = repo.TouchSession(ctx, session.ID, time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// unknown session
+	err = repo.TouchSession(ctx, uuid.Must(uuid.NewV7()), time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSessionRepo_ListRecentSessionsByUser(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupRefreshRepo(t)
+	defer teardown()
+
+	base := time.Now().Add(-time.Hour)
+	ids := make([]uuid.UUID, 0, 3)
+	for i := range 3 {
+		ua := "Mozilla/5.0 test " + string(rune('a'+i))
+		session := &models.Session{
+			UserID:    uid,
+			TokenHash: "sesshash-" + uuid.Must(uuid.NewV7()).String(),
+			UserAgent: &ua,
+			ExpiresAt: time.Now().Add(24 * time.Hour),
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}
+		require.NoError(t, repo.CreateSession(ctx, session))
+		ids = append(ids, session.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			_ = repo.DeleteSession(ctx, id)
+		}
+	}()
+
+	// newest first, limited
+	sessions, err := repo.ListRecentSessionsByUser(ctx, uid, 2)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, ids[2], sessions[0].ID)
+	assert.Equal(t, ids[1], sessions[1].ID)
+	require.NotNil(t, sessions[0].UserAgent)
+
+	// unknown user has no sessions
+	sessions, err = repo.ListRecentSessionsByUser(ctx, uuid.Must(uuid.NewV7()), 10)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestSessionRepo_ListActiveSessionsByUser(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupRefreshRepo(t)
+	defer teardown()
+
+	newSession := func(expiresAt time.Time, createdAt time.Time) *models.Session {
+		session := &models.Session{
+			UserID:    uid,
+			TokenHash: "sesshash-" + uuid.Must(uuid.NewV7()).String(),
+			ExpiresAt: expiresAt,
+			CreatedAt: createdAt,
+		}
+		require.NoError(t, repo.CreateSession(ctx, session))
+		return session
+	}
+	now := time.Now()
+	older := newSession(now.Add(24*time.Hour), now.Add(-2*time.Hour))
+	newer := newSession(now.Add(24*time.Hour), now.Add(-time.Hour))
+	expired := newSession(now.Add(-time.Minute), now.Add(-3*time.Hour))
+	revoked := newSession(now.Add(24*time.Hour), now.Add(-30*time.Minute))
+	revokedAt := now
+	revoked.RevokedAt = &revokedAt
+	require.NoError(t, repo.UpdateSession(ctx, revoked))
+	defer func() {
+		for _, s := range []*models.Session{older, newer, expired, revoked} {
+			_ = repo.DeleteSession(ctx, s.ID)
+		}
+	}()
+
+	// revoked and expired sessions are excluded, newest first
+	sessions, err := repo.ListActiveSessionsByUser(ctx, uid)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, newer.ID, sessions[0].ID)
+	assert.Equal(t, older.ID, sessions[1].ID)
+
+	sessions, err = repo.ListActiveSessionsByUser(ctx, uuid.Must(uuid.NewV7()))
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
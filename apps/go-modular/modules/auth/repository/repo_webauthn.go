@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go-modular/modules/auth/models"
+)
+
+const webAuthnCredentialColumns = `id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports,
+        backup_eligible, backup_state, friendly_name, created_at, updated_at, last_used_at`
+
+// scanWebAuthnCredential scans a single webauthn_credentials row selected with webAuthnCredentialColumns.
+func scanWebAuthnCredential(row pgx.Row) (*models.WebAuthnCredential, error) {
+	var c models.WebAuthnCredential
+	err := row.Scan(
+		&c.ID,
+		&c.UserID,
+		&c.CredentialID,
+		&c.PublicKey,
+		&c.AttestationType,
+		&c.AAGUID,
+		&c.SignCount,
+		&c.Transports,
+		&c.BackupEligible,
+		&c.BackupState,
+		&c.FriendlyName,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateWebAuthnCredential inserts a new WebAuthn credential (passkey) into the database.
+func (r *AuthRepository) CreateWebAuthnCredential(ctx context.Context, credential *models.WebAuthnCredential) error {
+	if credential.ID == uuid.Nil {
+		credential.ID = uuid.Must(uuid.NewV7())
+	}
+	if credential.CreatedAt.IsZero() {
+		credential.CreatedAt = time.Now()
+	}
+	if credential.Transports == nil {
+		credential.Transports = []string{}
+	}
+
+	query := `INSERT INTO ` + models.WebAuthnCredentialTable + `
+        (id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, friendly_name, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := r.conn(ctx).Exec(ctx, query,
+		credential.ID,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.AttestationType,
+		credential.AAGUID,
+		credential.SignCount,
+		credential.Transports,
+		credential.BackupEligible,
+		credential.BackupState,
+		credential.FriendlyName,
+		credential.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("failed to insert webauthn credential", "op", "CreateWebAuthnCredential", "user_id", credential.UserID.String(), "error", err.Error())
+		return err
+	}
+	r.logger.Info("webauthn credential created", "op", "CreateWebAuthnCredential", "credential_id", credential.ID.String())
+	return nil
+}
+
+// ListWebAuthnCredentialsByUserID returns all WebAuthn credentials owned by a user.
+func (r *AuthRepository) ListWebAuthnCredentialsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.WebAuthnCredential, error) {
+	query := `SELECT ` + webAuthnCredentialColumns + ` FROM ` + models.WebAuthnCredentialTable + `
+        WHERE user_id = $1 ORDER BY created_at ASC`
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error("failed to query webauthn credentials", "op", "ListWebAuthnCredentialsByUserID", "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []*models.WebAuthnCredential{}
+	for rows.Next() {
+		c, err := scanWebAuthnCredential(rows)
+		if err != nil {
+			r.logger.Error("failed to scan webauthn credential", "op", "ListWebAuthnCredentialsByUserID", "error", err.Error())
+			return nil, err
+		}
+		credentials = append(credentials, c)
+	}
+	return credentials, rows.Err()
+}
+
+// GetWebAuthnCredentialByCredentialID retrieves a WebAuthn credential by the authenticator credential ID.
+func (r *AuthRepository) GetWebAuthnCredentialByCredentialID(ctx context.Context, credentialID []byte) (*models.WebAuthnCredential, error) {
+	query := `SELECT ` + webAuthnCredentialColumns + ` FROM ` + models.WebAuthnCredentialTable + ` WHERE credential_id = $1`
+	c, err := scanWebAuthnCredential(r.conn(ctx).QueryRow(ctx, query, credentialID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.logger.Warn("webauthn credential not found", "op", "GetWebAuthnCredentialByCredentialID")
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get webauthn credential", "op", "GetWebAuthnCredentialByCredentialID", "error", err.Error())
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateWebAuthnCredentialUsage stores the new signature counter and backup state after a successful assertion.
+func (r *AuthRepository) UpdateWebAuthnCredentialUsage(ctx context.Context, credentialID []byte, signCount int64, backupState bool, usedAt time.Time) error {
+	query := `UPDATE ` + models.WebAuthnCredentialTable + ` SET sign_count = $1, backup_state = $2, last_used_at = $3 WHERE credential_id = $4`
+	cmd, err := r.conn(ctx).Exec(ctx, query, signCount, backupState, usedAt, credentialID)
+	if err != nil {
+		r.logger.Error("failed to update webauthn credential usage", "op", "UpdateWebAuthnCredentialUsage", "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("webauthn credential not found for update", "op", "UpdateWebAuthnCredentialUsage")
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteWebAuthnCredential deletes a WebAuthn credential by its ID, scoped to the owning user.
+func (r *AuthRepository) DeleteWebAuthnCredential(ctx context.Context, userID, id uuid.UUID) error {
+	query := `DELETE FROM ` + models.WebAuthnCredentialTable + ` WHERE id = $1 AND user_id = $2`
+	cmd, err := r.conn(ctx).Exec(ctx, query, id, userID)
+	if err != nil {
+		r.logger.Error("failed to delete webauthn credential", "op", "DeleteWebAuthnCredential", "id", id.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("webauthn credential not found for delete", "op", "DeleteWebAuthnCredential", "id", id.String())
+		return ErrNotFound
+	}
+	r.logger.Info("webauthn credential deleted", "op", "DeleteWebAuthnCredential", "id", id.String())
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnCredential_CRUD_and_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupAuthRepo(t)
+	defer teardown()
+
+	credentialID := []byte("test-credential-" + uuid.Must(uuid.NewV4()).String())
+	cred := &models.WebAuthnCredential{
+		UserID:          uid,
+		CredentialID:    credentialID,
+		PublicKey:       []byte{0xa5, 0x01, 0x02, 0x03, 0x26},
+		AttestationType: "none",
+		SignCount:       0,
+		Transports:      []string{"internal", "hybrid"},
+		BackupEligible:  true,
+		FriendlyName:    "Test passkey",
+	}
+
+	// create
+	err := repo.CreateWebAuthnCredential(ctx, cred)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, cred.ID)
+
+	// duplicate credential id is rejected
+	dup := *cred
+	dup.ID = uuid.Nil
+	err = repo.CreateWebAuthnCredential(ctx, &dup)
+	assert.Error(t, err)
+
+	// get by credential id
+	got, err := repo.GetWebAuthnCredentialByCredentialID(ctx, credentialID)
+	require.NoError(t, err)
+	assert.Equal(t, cred.ID, got.ID)
+	assert.Equal(t, uid, got.UserID)
+	assert.Equal(t, cred.PublicKey, got.PublicKey)
+	assert.ElementsMatch(t, cred.Transports, got.Transports)
+	assert.True(t, got.BackupEligible)
+	assert.Nil(t, got.LastUsedAt)
+
+	// list by user
+	list, err := repo.ListWebAuthnCredentialsByUserID(ctx, uid)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, cred.ID, list[0].ID)
+
+	// update usage
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	err = repo.UpdateWebAuthnCredentialUsage(ctx, credentialID, 7, true, usedAt)
+	require.NoError(t, err)
+	got, err = repo.GetWebAuthnCredentialByCredentialID(ctx, credentialID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got.SignCount)
+	assert.True(t, got.BackupState)
+	require.NotNil(t, got.LastUsedAt)
+	assert.WithinDuration(t, usedAt, *got.LastUsedAt, time.Second)
+
+	// delete scoped to another user is not found
+	err = repo.DeleteWebAuthnCredential(ctx, uuid.Must(uuid.NewV7()), cred.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// delete
+	err = repo.DeleteWebAuthnCredential(ctx, uid, cred.ID)
+	require.NoError(t, err)
+
+	// not found after delete
+	_, err = repo.GetWebAuthnCredentialByCredentialID(ctx, credentialID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	err = repo.UpdateWebAuthnCredentialUsage(ctx, credentialID, 8, false, usedAt)
+	assert.ErrorIs(t, err, ErrNotFound)
+	err = repo.DeleteWebAuthnCredential(ctx, uid, cred.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go-modular/internal/adapter"
 )
 
 // AuthRepositoryInterface defines the contract for user data access.
@@ -18,11 +19,17 @@ type AuthRepositoryInterface interface {
 	SetUserPassword(ctx context.Context, userPassword *models.UserPassword) error
 	UpdateUserPassword(ctx context.Context, userID uuid.UUID, newPassword string) error
 	ValidateUserPassword(ctx context.Context, userID uuid.UUID, password string) (bool, error)
+	CreatePasswordHistory(ctx context.Context, entry *models.PasswordHistory) error
+	ListPasswordHistory(ctx context.Context, userID uuid.UUID, limit int) ([]*models.PasswordHistory, error)
+	PrunePasswordHistory(ctx context.Context, userID uuid.UUID, keep int) error
 
 	// Session operations
 	CreateSession(ctx context.Context, session *models.Session) error
 	GetSession(ctx context.Context, sessionID uuid.UUID) (*models.Session, error)
 	UpdateSession(ctx context.Context, session *models.Session) error
+	TouchSession(ctx context.Context, sessionID uuid.UUID, refreshedAt time.Time) error
+	ListRecentSessionsByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*models.Session, error)
+	ListActiveSessionsByUser(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
 	DeleteSession(ctx context.Context, sessionID uuid.UUID) error
 	ValidateSession(ctx context.Context, sessionID uuid.UUID) (bool, error)
 
@@ -35,11 +42,35 @@ type AuthRepositoryInterface interface {
 
 	// OneTimeToken operations
 	FindAllOneTimeTokens(ctx context.Context) ([]*models.OneTimeToken, error)
+	FindOneTimeTokensByUserSubject(ctx context.Context, userID uuid.UUID, subject models.OneTimeTokenSubject) ([]*models.OneTimeToken, error)
+	FindOneTimeTokensByRelatesTo(ctx context.Context, subject models.OneTimeTokenSubject, relatesTo string) ([]*models.OneTimeToken, error)
 	CreateOneTimeToken(ctx context.Context, token *models.OneTimeToken) error
 	GetOneTimeTokenByID(ctx context.Context, tokenID uuid.UUID) (*models.OneTimeToken, error)
 	GetOneTimeTokenByTokenHash(ctx context.Context, tokenHash string) (*models.OneTimeToken, error)
 	DeleteOneTimeToken(ctx context.Context, tokenID uuid.UUID) error
 	UpdateOneTimeTokenLastSentAt(ctx context.Context, tokenID uuid.UUID, lastSentAt time.Time) error
+	UpdateOneTimeTokenMetadata(ctx context.Context, tokenID uuid.UUID, metadata map[string]any) error
+	IncrementOneTimeTokenAttempts(ctx context.Context, tokenID uuid.UUID) (int, error)
+	DeleteOneTimeTokensByUserSubject(ctx context.Context, userID uuid.UUID, subject models.OneTimeTokenSubject) error
+
+	// WebAuthn credential (passkey) operations
+	CreateWebAuthnCredential(ctx context.Context, credential *models.WebAuthnCredential) error
+	ListWebAuthnCredentialsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.WebAuthnCredential, error)
+	GetWebAuthnCredentialByCredentialID(ctx context.Context, credentialID []byte) (*models.WebAuthnCredential, error)
+	UpdateWebAuthnCredentialUsage(ctx context.Context, credentialID []byte, signCount int64, backupState bool, usedAt time.Time) error
+	DeleteWebAuthnCredential(ctx context.Context, userID, id uuid.UUID) error
+
+	// Service client (OAuth2 client_credentials) operations
+	CreateServiceClient(ctx context.Context, client *models.ServiceClient) error
+	GetServiceClientByClientID(ctx context.Context, clientID string) (*models.ServiceClient, error)
+	ListServiceClients(ctx context.Context) ([]*models.ServiceClient, error)
+	UpdateServiceClientLastUsedAt(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	RevokeServiceClient(ctx context.Context, clientID string, revokedAt time.Time) error
+
+	// Expiry pruning (each call deletes at most limit rows)
+	DeleteExpiredOneTimeTokens(ctx context.Context, before time.Time, limit int) (int64, error)
+	DeleteExpiredSessions(ctx context.Context, before time.Time, limit int) (int64, error)
+	DeleteExpiredRefreshTokens(ctx context.Context, before time.Time, limit int) (int64, error)
 }
 
 // Ensure AuthRepository implements AuthRepositoryInterface
@@ -47,8 +78,9 @@ var _ AuthRepositoryInterface = (*AuthRepository)(nil)
 
 // AuthRepository is an implementation of AuthRepositoryInterface using pgxpool.
 type AuthRepository struct {
-	pgPool *pgxpool.Pool
-	logger *slog.Logger
+	pgPool   *pgxpool.Pool
+	replicas adapter.ReadRouter // Routes read-only queries, see UseReplicas (optional)
+	logger   *slog.Logger
 }
 
 // NewAuthRepository creates a new AuthRepository with pgxpool and slog logger.
@@ -59,5 +91,23 @@ func NewAuthRepository(pgPool *pgxpool.Pool, logger *slog.Logger) *AuthRepositor
 	}
 }
 
+// conn returns the transaction of the context when there is one, so queries join the
+// surrounding unit of work, or the pool otherwise.
+func (r *AuthRepository) conn(ctx context.Context) adapter.DBTX {
+	return adapter.Conn(ctx, r.pgPool)
+}
+
+// readConn returns the connection of a read-only query that tolerates replication lag: a
+// read replica when the repository uses replicas, or conn otherwise.
+func (r *AuthRepository) readConn(ctx context.Context) adapter.DBTX {
+	return adapter.ReadConn(ctx, r.pgPool, r.replicas)
+}
+
+// UseReplicas routes the read-only queries that tolerate replication lag with router, e.g.
+// an *adapter.PostgresDB with read replicas.
+func (r *AuthRepository) UseReplicas(router adapter.ReadRouter) {
+	r.replicas = router
+}
+
 // Sentinel error for not found
 var ErrNotFound = errors.New("not found")
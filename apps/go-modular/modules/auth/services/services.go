@@ -2,15 +2,24 @@ package services
 
 import (
 	"context"
+	"log/slog"
+	"net/url"
+	"strings"
 	"time"
 
+	"go-modular/internal/adapter"
+	"go-modular/internal/eventbus"
 	"go-modular/internal/notification"
 	"go-modular/modules/auth/models"
 	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/gofrs/uuid/v5"
 	"github.com/lestrrat-go/jwx/jwa"
 
+	auditSvc "go-modular/modules/audit/services"
+	userModels "go-modular/modules/user/models"
 	svcUser "go-modular/modules/user/services"
 )
 
@@ -27,6 +36,8 @@ type AuthServiceInterface interface {
 	UpdateSession(ctx context.Context, session *models.Session) error
 	DeleteSession(ctx context.Context, sessionID uuid.UUID) error
 	ValidateSession(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
 
 	// Refresh token management
 	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
@@ -36,8 +47,19 @@ type AuthServiceInterface interface {
 	ValidateRefreshToken(ctx context.Context, tokenID uuid.UUID) (bool, error)
 
 	// Authentication
-	SignInWithEmail(ctx context.Context, email, password string) (*models.AuthenticatedUser, error)
-	SignInWithUsername(ctx context.Context, username, password string) (*models.AuthenticatedUser, error)
+	SignInWithEmail(ctx context.Context, email, password string, rememberMe bool) (*models.AuthenticatedUser, error)
+	SignInWithUsername(ctx context.Context, username, password string, rememberMe bool) (*models.AuthenticatedUser, error)
+	RefreshSession(ctx context.Context, refreshToken string) (*models.RefreshSessionResponse, error)
+	VerifySignInStepUp(ctx context.Context, challengeID, code string) (*models.AuthenticatedUser, error)
+	RevokeSessionFromAlert(ctx context.Context, rawToken string) error
+
+	// Passkeys (WebAuthn)
+	BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*models.PasskeyChallengeResponse, error)
+	FinishPasskeyRegistration(ctx context.Context, userID uuid.UUID, challengeID, friendlyName string, credential []byte) (*models.WebAuthnCredential, error)
+	ListPasskeys(ctx context.Context, userID uuid.UUID) ([]*models.WebAuthnCredential, error)
+	DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error
+	BeginPasskeySignIn(ctx context.Context) (*models.PasskeyChallengeResponse, error)
+	SignInWithPasskey(ctx context.Context, challengeID string, credential []byte, rememberMe bool) (*models.AuthenticatedUser, error)
 
 	// Account verification (email-based, userID resolved internally)
 	// Initiate/Resend now accept an optional redirectTo which will be stored in token metadata.
@@ -45,6 +67,27 @@ type AuthServiceInterface interface {
 	ValidateEmailVerification(ctx context.Context, token string) (bool, error)
 	RevokeEmailVerification(ctx context.Context, token string) error
 	ResendEmailVerification(ctx context.Context, email, redirectTo string) error
+
+	// Invitations of imported users, who set their first password from the emailed link
+	SendInvitation(ctx context.Context, event userModels.UserInvited) error
+	AcceptInvitation(ctx context.Context, token, password string) (uuid.UUID, error)
+
+	// Organizations (active organization of the access token)
+	SwitchOrganization(ctx context.Context, userID, sessionID, orgID uuid.UUID) (*models.SwitchOrganizationResponse, error)
+
+	// Service clients (OAuth2 client_credentials grant)
+	CreateServiceClient(ctx context.Context, name string, scopes []string) (*models.ServiceClient, string, error)
+	ListServiceClients(ctx context.Context) ([]*models.ServiceClient, error)
+	RevokeServiceClient(ctx context.Context, clientID string) error
+	IssueClientCredentialsToken(ctx context.Context, clientID, clientSecret, scope string) (*models.OAuthTokenResponse, error)
+
+	// Token introspection and revocation (RFC 7662 / RFC 7009)
+	IntrospectToken(ctx context.Context, clientID, clientSecret, token string) (*models.OAuthIntrospectionResponse, error)
+	RevokeToken(ctx context.Context, clientID, clientSecret, token string) error
+
+	// Admin impersonation
+	IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error)
+	ImpersonateUser(ctx context.Context, adminID, sessionID, targetID uuid.UUID, reason string) (*models.ImpersonationResponse, error)
 }
 
 // Ensure AuthService implements AuthServiceInterface
@@ -52,25 +95,53 @@ var _ AuthServiceInterface = (*AuthService)(nil)
 
 // AuthService implements user business logic using a UserRepositoryInterface.
 type AuthService struct {
-	authRepo           repository.AuthRepositoryInterface
-	userService        svcUser.UserServiceInterface
-	secretKey          []byte                 // Secret key for signing JWTs
-	accessTokenExpiry  time.Duration          // Access token expiration duration
-	refreshTokenExpiry time.Duration          // Refresh token expiration duration
-	signingAlg         jwa.SignatureAlgorithm // Signing algorithm (default: HS256)
-	mailer             *notification.Mailer
-	baseURL            string // Base URL used when constructing verification links
+	authRepo            repository.AuthRepositoryInterface
+	userService         svcUser.UserServiceInterface
+	secretKey           []byte                 // Secret key for signing JWTs
+	accessTokenExpiry   time.Duration          // Access token expiration duration
+	refreshTokenExpiry  time.Duration          // Refresh token expiration duration
+	signingAlg          jwa.SignatureAlgorithm // Signing algorithm (default: HS256)
+	mailer              *notification.Mailer
+	baseURL             string // Base URL used when constructing verification links
+	invitationURL       string // Set-password link target of invitation emails
+	webAuthn            *webauthn.WebAuthn
+	auditor             auditSvc.Recorder
+	passwordPolicy      apputils.PasswordPolicy
+	passwordHistorySize int
+	organizations       OrganizationResolver
+	serviceTokenExpiry  time.Duration // Service (client_credentials) access token expiration duration
+	sessionPolicy       SessionPolicy
+	signInRisk          SignInRiskPolicy
+	impersonationExpiry time.Duration // Impersonation access token expiration duration
+	events              *eventbus.Bus
+	tx                  adapter.Transactor
+	logger              *slog.Logger
 }
 
 type AuthServiceOpts struct {
-	AuthRepo           repository.AuthRepositoryInterface
-	UserService        svcUser.UserServiceInterface
-	JWTSecretKey       []byte                 // Secret key for signing JWTs
-	AccessTokenExpiry  time.Duration          // Access token expiration duration
-	RefreshTokenExpiry time.Duration          // Refresh token expiration duration
-	SigningAlg         jwa.SignatureAlgorithm // Signing algorithm (default: HS256)
-	Mailer             *notification.Mailer   // Mailer service for sending emails
-	BaseURL            string                 // BaseURL used when constructing verification links (MANDATORY).
+	AuthRepo            repository.AuthRepositoryInterface
+	UserService         svcUser.UserServiceInterface
+	JWTSecretKey        []byte                  // Secret key for signing JWTs
+	AccessTokenExpiry   time.Duration           // Access token expiration duration
+	RefreshTokenExpiry  time.Duration           // Refresh token expiration duration
+	SigningAlg          jwa.SignatureAlgorithm  // Signing algorithm (default: HS256)
+	Mailer              *notification.Mailer    // Mailer service for sending emails
+	BaseURL             string                  // BaseURL used when constructing verification links (MANDATORY).
+	InvitationURL       string                  // Set-password link target of invitations, e.g. a frontend page (default: BaseURL + "/api/v1/auth/invitation/accept")
+	WebAuthnRPID        string                  // WebAuthn relying party ID (default: BaseURL hostname)
+	WebAuthnRPName      string                  // WebAuthn relying party display name (default: RP ID)
+	WebAuthnRPOrigins   []string                // Allowed WebAuthn origins (default: BaseURL origin)
+	Auditor             auditSvc.Recorder       // Security audit log recorder (optional)
+	PasswordPolicy      apputils.PasswordPolicy // Password policy (default: apputils.DefaultPasswordPolicy())
+	PasswordHistorySize int                     // Number of previous passwords that cannot be reused (0 disables)
+	Organizations       OrganizationResolver    // Resolves organization memberships for the org_id claim (optional)
+	ServiceTokenExpiry  time.Duration           // Service access token expiration duration (default: 15m)
+	SessionPolicy       SessionPolicy           // Session idle timeout and lifetime (default: DefaultSessionPolicy())
+	SignInRisk          SignInRiskPolicy        // New-device and suspicious sign-in detection (disabled when zero)
+	ImpersonationExpiry time.Duration           // Impersonation access token expiration duration (default: 15m)
+	Events              *eventbus.Bus           // Domain event bus (optional, events reach no other module when nil)
+	Tx                  adapter.Transactor      // Runs multi-step writes as one unit of work (optional, no transaction when nil)
+	Logger              *slog.Logger            // Logs failed event handlers (optional)
 }
 
 // NewAuthService creates a new AuthService.
@@ -93,6 +164,24 @@ func NewAuthService(opts AuthServiceOpts) *AuthService {
 	if opts.RefreshTokenExpiry == 0 {
 		opts.RefreshTokenExpiry = 7 * 24 * time.Hour
 	}
+	if opts.ServiceTokenExpiry == 0 {
+		opts.ServiceTokenExpiry = 15 * time.Minute
+	}
+	if opts.ImpersonationExpiry == 0 {
+		opts.ImpersonationExpiry = 15 * time.Minute
+	}
+	if opts.SessionPolicy == (SessionPolicy{}) {
+		opts.SessionPolicy = DefaultSessionPolicy()
+	}
+	if opts.SignInRisk.HistorySize <= 0 {
+		opts.SignInRisk.HistorySize = defaultRiskHistorySize
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.Events == nil {
+		opts.Events = eventbus.NewBus(eventbus.BusOpts{Logger: opts.Logger})
+	}
 
 	// BaseURL is mandatory
 	if opts.BaseURL == "" {
@@ -100,14 +189,68 @@ func NewAuthService(opts AuthServiceOpts) *AuthService {
 		panic("BaseURL is required")
 	}
 
+	if opts.InvitationURL == "" {
+		opts.InvitationURL = strings.TrimRight(opts.BaseURL, "/") + "/api/v1/auth/invitation/accept"
+	}
+	if _, err := url.Parse(opts.InvitationURL); err != nil {
+		panic("InvitationURL must be a valid URL")
+	}
+
+	// WebAuthn relying party defaults are derived from BaseURL
+	if opts.WebAuthnRPID == "" || len(opts.WebAuthnRPOrigins) == 0 {
+		u, err := url.Parse(opts.BaseURL)
+		if err != nil || u.Host == "" {
+			panic("BaseURL must be an absolute URL")
+		}
+		if opts.WebAuthnRPID == "" {
+			opts.WebAuthnRPID = u.Hostname()
+		}
+		if len(opts.WebAuthnRPOrigins) == 0 {
+			opts.WebAuthnRPOrigins = []string{u.Scheme + "://" + u.Host}
+		}
+	}
+	if opts.Auditor == nil {
+		opts.Auditor = auditSvc.NopRecorder{}
+	}
+	if opts.Tx == nil {
+		opts.Tx = adapter.NopTransactor{}
+	}
+	if opts.PasswordPolicy.MinLength == 0 {
+		opts.PasswordPolicy = apputils.DefaultPasswordPolicy()
+	}
+	if opts.WebAuthnRPName == "" {
+		opts.WebAuthnRPName = opts.WebAuthnRPID
+	}
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          opts.WebAuthnRPID,
+		RPDisplayName: opts.WebAuthnRPName,
+		RPOrigins:     opts.WebAuthnRPOrigins,
+	})
+	if err != nil {
+		panic("invalid WebAuthn configuration: " + err.Error())
+	}
+
 	return &AuthService{
-		authRepo:           opts.AuthRepo,
-		userService:        opts.UserService,
-		secretKey:          opts.JWTSecretKey,
-		accessTokenExpiry:  opts.AccessTokenExpiry,
-		refreshTokenExpiry: opts.RefreshTokenExpiry,
-		signingAlg:         opts.SigningAlg,
-		mailer:             opts.Mailer,
-		baseURL:            opts.BaseURL,
+		authRepo:            opts.AuthRepo,
+		userService:         opts.UserService,
+		secretKey:           opts.JWTSecretKey,
+		accessTokenExpiry:   opts.AccessTokenExpiry,
+		refreshTokenExpiry:  opts.RefreshTokenExpiry,
+		signingAlg:          opts.SigningAlg,
+		mailer:              opts.Mailer,
+		baseURL:             opts.BaseURL,
+		invitationURL:       opts.InvitationURL,
+		webAuthn:            wa,
+		auditor:             opts.Auditor,
+		passwordPolicy:      opts.PasswordPolicy,
+		passwordHistorySize: opts.PasswordHistorySize,
+		organizations:       opts.Organizations,
+		serviceTokenExpiry:  opts.ServiceTokenExpiry,
+		sessionPolicy:       opts.SessionPolicy,
+		signInRisk:          opts.SignInRisk,
+		impersonationExpiry: opts.ImpersonationExpiry,
+		events:              opts.Events,
+		tx:                  opts.Tx,
+		logger:              opts.Logger,
 	}
 }
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+
+	"github.com/gofrs/uuid/v5"
+
+	userModels "go-modular/modules/user/models"
+	svcUser "go-modular/modules/user/services"
+)
+
+// memoryAuthRepo keeps the rows used by the tested flows in memory. Other
+// AuthRepositoryInterface methods are not implemented and panic when called.
+type memoryAuthRepo struct {
+	repository.AuthRepositoryInterface
+
+	mu            sync.Mutex
+	tokens        map[uuid.UUID]*models.OneTimeToken
+	passwords     map[uuid.UUID]string
+	sessions      map[uuid.UUID]*models.Session
+	refreshTokens map[uuid.UUID]*models.RefreshToken
+	clients       map[string]*models.ServiceClient
+}
+
+func newMemoryAuthRepo() *memoryAuthRepo {
+	return &memoryAuthRepo{
+		tokens:        map[uuid.UUID]*models.OneTimeToken{},
+		passwords:     map[uuid.UUID]string{},
+		sessions:      map[uuid.UUID]*models.Session{},
+		refreshTokens: map[uuid.UUID]*models.RefreshToken{},
+		clients:       map[string]*models.ServiceClient{},
+	}
+}
+
+func (r *memoryAuthRepo) CreateOneTimeToken(_ context.Context, token *models.OneTimeToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token.ID == uuid.Nil {
+		token.ID = uuid.Must(uuid.NewV7())
+	}
+	c := *token
+	r.tokens[token.ID] = &c
+	return nil
+}
+
+func (r *memoryAuthRepo) GetOneTimeTokenByTokenHash(_ context.Context, tokenHash string) (*models.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			c := *t
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryAuthRepo) GetOneTimeTokenByID(_ context.Context, tokenID uuid.UUID) (*models.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[tokenID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *memoryAuthRepo) IncrementOneTimeTokenAttempts(_ context.Context, tokenID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[tokenID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	attempts, _ := t.Metadata["attempts"].(int)
+	metadata := maps.Clone(t.Metadata)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["attempts"] = attempts + 1
+	t.Metadata = metadata
+	return attempts + 1, nil
+}
+
+func (r *memoryAuthRepo) DeleteOneTimeToken(_ context.Context, tokenID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tokens[tokenID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.tokens, tokenID)
+	return nil
+}
+
+func (r *memoryAuthRepo) DeleteOneTimeTokensByUserSubject(_ context.Context, userID uuid.UUID, subject models.OneTimeTokenSubject) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, t := range r.tokens {
+		if t.UserID != nil && *t.UserID == userID && t.Subject == subject {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+// tokensOf returns the tokens of a user with the given subject.
+func (r *memoryAuthRepo) tokensOf(userID uuid.UUID, subject models.OneTimeTokenSubject) []*models.OneTimeToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []*models.OneTimeToken
+	for _, t := range r.tokens {
+		if t.UserID != nil && *t.UserID == userID && t.Subject == subject {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+func (r *memoryAuthRepo) SetUserPassword(_ context.Context, p *models.UserPassword) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.passwords[p.UserID] = p.PasswordHash
+	return nil
+}
+
+func (r *memoryAuthRepo) CreateSession(_ context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session.ID == uuid.Nil {
+		session.ID = uuid.Must(uuid.NewV7())
+	}
+	c := *session
+	r.sessions[session.ID] = &c
+	return nil
+}
+
+func (r *memoryAuthRepo) GetSession(_ context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	c := *session
+	return &c, nil
+}
+
+func (r *memoryAuthRepo) UpdateSession(_ context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[session.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	c := *session
+	r.sessions[session.ID] = &c
+	return nil
+}
+
+func (r *memoryAuthRepo) CreateRefreshToken(_ context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token.ID == uuid.Nil {
+		token.ID = uuid.Must(uuid.NewV7())
+	}
+	c := *token
+	r.refreshTokens[token.ID] = &c
+	return nil
+}
+
+func (r *memoryAuthRepo) GetRefreshToken(_ context.Context, tokenID uuid.UUID) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.refreshTokens[tokenID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	c := *token
+	return &c, nil
+}
+
+func (r *memoryAuthRepo) UpdateRefreshToken(_ context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.refreshTokens[token.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	c := *token
+	r.refreshTokens[token.ID] = &c
+	return nil
+}
+
+func (r *memoryAuthRepo) CreateServiceClient(_ context.Context, client *models.ServiceClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client.ID == uuid.Nil {
+		client.ID = uuid.Must(uuid.NewV7())
+	}
+	c := *client
+	r.clients[client.ClientID] = &c
+	return nil
+}
+
+func (r *memoryAuthRepo) GetServiceClientByClientID(_ context.Context, clientID string) (*models.ServiceClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	c := *client
+	return &c, nil
+}
+
+func (r *memoryAuthRepo) UpdateServiceClientLastUsedAt(_ context.Context, id uuid.UUID, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, client := range r.clients {
+		if client.ID == id {
+			client.LastUsedAt = &usedAt
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memoryAuthRepo) RevokeServiceClient(_ context.Context, clientID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[clientID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	client.RevokedAt = &revokedAt
+	return nil
+}
+
+// memoryUserService serves users from memory. Other UserServiceInterface methods are not
+// implemented and panic when called.
+type memoryUserService struct {
+	svcUser.UserServiceInterface
+
+	mu    sync.Mutex
+	users map[uuid.UUID]*userModels.User
+}
+
+func (s *memoryUserService) add(email string) *userModels.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.users == nil {
+		s.users = map[uuid.UUID]*userModels.User{}
+	}
+	user := &userModels.User{ID: uuid.Must(uuid.NewV7()), Email: email, DisplayName: "Test User"}
+	s.users[user.ID] = user
+	return user
+}
+
+func (s *memoryUserService) GetUserByID(_ context.Context, id uuid.UUID) (*userModels.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return nil, svcUser.ErrUserNotFound
+	}
+	c := *user
+	return &c, nil
+}
+
+func (s *memoryUserService) MarkEmailVerified(_ context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.users[userID].EmailVerifiedAt = &now
+	return nil
+}
+
+// newTestAuthService returns an AuthService backed by in-memory repositories.
+func newTestAuthService(t *testing.T) (*AuthService, *memoryAuthRepo, *memoryUserService) {
+	t.Helper()
+	repo := newMemoryAuthRepo()
+	users := &memoryUserService{}
+	svc := NewAuthService(AuthServiceOpts{
+		AuthRepo:     repo,
+		UserService:  users,
+		JWTSecretKey: []byte("test-secret-key-with-32-bytes!!!"),
+		BaseURL:      "http://localhost:8000",
+	})
+	return svc, repo, users
+}
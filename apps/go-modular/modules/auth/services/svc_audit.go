@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
+)
+
+// audit records a security audit event targeting a single resource. When actorID is
+// uuid.Nil the actor is resolved from the request context (JWT "sub" claim).
+func (s *AuthService) audit(ctx context.Context, action auditModels.AuditAction, actorID uuid.UUID, targetType string, targetID uuid.UUID, metadata map[string]any) {
+	event := auditSvc.NewEvent(action, targetType, targetID, metadata)
+	if actorID != uuid.Nil {
+		event.ActorID = &actorID
+	}
+	s.auditor.Record(ctx, event)
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"go-modular/internal/eventbus"
+	"go-modular/modules/auth/models"
+
+	userModels "go-modular/modules/user/models"
+)
+
+// publish publishes domain events. The changes they describe are already stored, so
+// failing handlers are logged instead of failing the caller.
+func (s *AuthService) publish(ctx context.Context, events ...eventbus.Event) {
+	if err := s.events.Publish(ctx, events...); err != nil {
+		s.logger.Error("failed to publish events", slog.String("op", "AuthService.publish"), slog.String("error", err.Error()))
+	}
+}
+
+// ClearEmailVerificationTokens deletes the pending email verification tokens of a user whose
+// email was verified, so links from earlier verification emails stop working.
+func (s *AuthService) ClearEmailVerificationTokens(ctx context.Context, event userModels.UserEmailVerified) error {
+	return s.authRepo.DeleteOneTimeTokensByUserSubject(ctx, event.UserID, models.OneTimeTokenSubjectEmailVerification)
+}
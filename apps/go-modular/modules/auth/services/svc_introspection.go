@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-modular/internal/adapter"
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+)
+
+// ErrUnsupportedTokenType is returned when revoking a token type other than refresh tokens.
+var ErrUnsupportedTokenType = errors.New("only refresh tokens can be revoked")
+
+// IntrospectToken authenticates the calling service client and reports whether token is
+// active (RFC 7662). A token is active when its signature and expiry are valid and it has not
+// been revoked: the session of access tokens, the refresh token row of refresh tokens and
+// the service client of service tokens must still be valid.
+func (s *AuthService) IntrospectToken(ctx context.Context, clientID, clientSecret, token string) (*models.OAuthIntrospectionResponse, error) {
+	if _, err := s.authenticateServiceClient(ctx, clientID, clientSecret); err != nil {
+		return nil, err
+	}
+
+	inactive := &models.OAuthIntrospectionResponse{Active: false}
+	claims, err := s.newJWTGenerator().ParseAndValidate(ctx, token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	resp := &models.OAuthIntrospectionResponse{
+		Active:    true,
+		Type:      claimString(claims, "typ"),
+		Subject:   claimString(claims, "sub"),
+		Issuer:    claimString(claims, "iss"),
+		ExpiresAt: claimUnix(claims, "exp"),
+		IssuedAt:  claimUnix(claims, "iat"),
+	}
+
+	var active bool
+	switch resp.Type {
+	case apputils.TokenTypeAccess, "":
+		resp.SessionID = claimString(claims, "sid")
+		resp.OrgID = claimString(claims, "org_id")
+		active, err = s.isSessionActive(ctx, resp.SessionID)
+	case apputils.TokenTypeRefresh:
+		var rt *models.RefreshToken
+		rt, active, err = s.activeRefreshToken(ctx, claimString(claims, "jti"), token)
+		if active && rt.SessionID != nil {
+			resp.SessionID = rt.SessionID.String()
+		}
+	case apputils.TokenTypeService:
+		resp.ClientID = claimString(claims, "client_id")
+		resp.Scope = claimString(claims, "scope")
+		active, err = s.isServiceClientActive(ctx, resp.ClientID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return inactive, nil
+	}
+	return resp, nil
+}
+
+// RevokeToken authenticates the calling service client and revokes a refresh token together
+// with its session, which also deactivates the access tokens bound to it (RFC 7009).
+// Invalid, unknown or already revoked tokens are ignored, as the RFC requires.
+func (s *AuthService) RevokeToken(ctx context.Context, clientID, clientSecret, token string) error {
+	if _, err := s.authenticateServiceClient(ctx, clientID, clientSecret); err != nil {
+		return err
+	}
+
+	claims, err := s.newJWTGenerator().ParseAndValidate(ctx, token)
+	if err != nil {
+		return nil
+	}
+	if typ := claimString(claims, "typ"); typ != apputils.TokenTypeRefresh {
+		return ErrUnsupportedTokenType
+	}
+
+	rt, active, err := s.activeRefreshToken(ctx, claimString(claims, "jti"), token)
+	if err != nil || !active {
+		return err
+	}
+
+	now := time.Now()
+	rt.RevokedAt = &now
+	if err := s.UpdateRefreshToken(ctx, rt); err != nil {
+		return err
+	}
+
+	if rt.SessionID != nil {
+		session, err := s.authRepo.GetSession(ctx, *rt.SessionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		if session.RevokedAt == nil {
+			session.RevokedAt = &now
+			if err := s.UpdateSession(ctx, session); err != nil {
+				return err
+			}
+			s.audit(ctx, auditModels.ActionSessionRevoked, session.UserID, auditModels.TargetSession, session.ID, map[string]any{
+				"reason": "refresh_token_revoked",
+			})
+			s.publish(ctx, models.SessionRevoked{SessionID: session.ID, UserID: session.UserID, Reason: "refresh_token_revoked", OccurredAt: now})
+		}
+	}
+	return nil
+}
+
+// isSessionActive reports whether the session an access token is bound to is still valid
+// under the session policy.
+func (s *AuthService) isSessionActive(ctx context.Context, sid string) (bool, error) {
+	sessionID, err := uuid.FromString(sid)
+	if err != nil || sessionID == uuid.Nil {
+		return false, nil
+	}
+	return s.ValidateSession(ctx, sessionID)
+}
+
+// activeRefreshToken loads the refresh token row identified by jti and reports whether it is
+// active: the stored hash must match token, it must not be revoked or expired, and its session
+// (if any) must not be revoked.
+func (s *AuthService) activeRefreshToken(ctx context.Context, jti, token string) (*models.RefreshToken, bool, error) {
+	tokenID, err := uuid.FromString(jti)
+	if err != nil {
+		return nil, false, nil
+	}
+	rt, err := s.authRepo.GetRefreshToken(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	hash := s.newJWTGenerator().GetHash(token)
+	if subtle.ConstantTimeCompare([]byte(hash), rt.TokenHash) != 1 {
+		return nil, false, nil
+	}
+	if rt.RevokedAt != nil || time.Now().After(rt.ExpiresAt) {
+		return rt, false, nil
+	}
+	// Idle and absolute session limits are enforced by the session policy, so only the
+	// revocation of the session is checked here, on the primary
+	if rt.SessionID != nil {
+		session, err := s.authRepo.GetSession(adapter.UsePrimary(ctx), *rt.SessionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return rt, false, nil
+			}
+			return rt, false, err
+		}
+		if session.RevokedAt != nil {
+			return rt, false, nil
+		}
+	}
+	return rt, true, nil
+}
+
+// isServiceClientActive reports whether the service client a service token was issued to
+// still exists and is not revoked.
+func (s *AuthService) isServiceClientActive(ctx context.Context, clientID string) (bool, error) {
+	if clientID == "" {
+		return false, nil
+	}
+	client, err := s.authRepo.GetServiceClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return client.RevokedAt == nil, nil
+}
+
+// claimString returns a string claim, or "" when it is missing.
+func claimString(claims map[string]any, key string) string {
+	v, ok := claims[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// claimUnix returns a time claim (exp, iat) as unix seconds, or 0 when it is missing.
+func claimUnix(claims map[string]any, key string) int64 {
+	switch v := claims[key].(type) {
+	case time.Time:
+		return v.Unix()
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return 0
+}
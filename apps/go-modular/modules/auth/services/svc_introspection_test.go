@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSession is a session with the access and refresh token issued for it.
+type testSession struct {
+	session        *models.Session
+	refreshTokenID uuid.UUID
+	accessToken    string
+	refreshToken   string
+}
+
+// newTestSession stores a session and its refresh token like a sign-in does.
+func newTestSession(t *testing.T, svc *AuthService, repo *memoryAuthRepo) *testSession {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	userID := uuid.Must(uuid.NewV7())
+	jwtGen := svc.newJWTGenerator()
+
+	ts := &testSession{refreshTokenID: uuid.Must(uuid.NewV7())}
+	var err error
+	ts.refreshToken, err = jwtGen.GenerateRefreshTokenJWT(ctx, userID.String(), "client-app", ts.refreshTokenID.String())
+	require.NoError(t, err)
+	ts.session = &models.Session{UserID: userID, TokenHash: jwtGen.GetHash(ts.refreshToken), CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, repo.CreateSession(ctx, ts.session))
+	require.NoError(t, repo.CreateRefreshToken(ctx, &models.RefreshToken{
+		ID:        ts.refreshTokenID,
+		UserID:    userID,
+		SessionID: &ts.session.ID,
+		TokenHash: []byte(jwtGen.GetHash(ts.refreshToken)),
+		ExpiresAt: ts.session.ExpiresAt,
+	}))
+	ts.accessToken, err = svc.signAccessToken(ctx, jwtGen, userID, "user@example.com", ts.session.ID, uuid.Nil)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestAuthService_IntrospectToken(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestAuthService(t)
+	caller, secret, err := svc.CreateServiceClient(ctx, "resource-server", []string{"introspect"})
+	require.NoError(t, err)
+	introspect := func(t *testing.T, token string) *models.OAuthIntrospectionResponse {
+		t.Helper()
+		resp, err := svc.IntrospectToken(ctx, caller.ClientID, secret, token)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("client_authentication", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		_, err := svc.IntrospectToken(ctx, caller.ClientID, "wrong-secret", ts.accessToken)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+		_, err = svc.IntrospectToken(ctx, "svc_unknown", secret, ts.accessToken)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+		_, err = svc.IntrospectToken(ctx, "", "", ts.accessToken)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+
+		revoked, revokedSecret, err := svc.CreateServiceClient(ctx, "revoked", nil)
+		require.NoError(t, err)
+		require.NoError(t, svc.RevokeServiceClient(ctx, revoked.ClientID))
+		_, err = svc.IntrospectToken(ctx, revoked.ClientID, revokedSecret, ts.accessToken)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+	})
+
+	t.Run("malformed_token_is_inactive", func(t *testing.T) {
+		assert.Equal(t, &models.OAuthIntrospectionResponse{Active: false}, introspect(t, "not-a-jwt"))
+	})
+
+	t.Run("access_token", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		resp := introspect(t, ts.accessToken)
+		assert.True(t, resp.Active)
+		assert.Equal(t, ts.session.UserID.String(), resp.Subject)
+		assert.Equal(t, ts.session.ID.String(), resp.SessionID)
+
+		// revoking the session deactivates its access tokens at once
+		now := time.Now()
+		ts.session.RevokedAt = &now
+		require.NoError(t, repo.UpdateSession(ctx, ts.session))
+		assert.False(t, introspect(t, ts.accessToken).Active)
+	})
+
+	t.Run("refresh_token", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		resp := introspect(t, ts.refreshToken)
+		assert.True(t, resp.Active)
+		assert.Equal(t, apputils.TokenTypeRefresh, resp.Type)
+		assert.Equal(t, ts.session.ID.String(), resp.SessionID)
+	})
+
+	t.Run("revoked_refresh_token_is_inactive", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		rt, err := repo.GetRefreshToken(ctx, ts.refreshTokenID)
+		require.NoError(t, err)
+		now := time.Now()
+		rt.RevokedAt = &now
+		require.NoError(t, repo.UpdateRefreshToken(ctx, rt))
+		assert.False(t, introspect(t, ts.refreshToken).Active)
+	})
+
+	t.Run("refresh_token_of_revoked_session_is_inactive", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		now := time.Now()
+		ts.session.RevokedAt = &now
+		require.NoError(t, repo.UpdateSession(ctx, ts.session))
+		assert.False(t, introspect(t, ts.refreshToken).Active)
+	})
+
+	t.Run("refresh_token_hash_mismatch_is_inactive", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		// the row was rotated to another token with the same ID
+		rt, err := repo.GetRefreshToken(ctx, ts.refreshTokenID)
+		require.NoError(t, err)
+		rt.TokenHash = []byte(svc.newJWTGenerator().GetHash("another-token"))
+		require.NoError(t, repo.UpdateRefreshToken(ctx, rt))
+		assert.False(t, introspect(t, ts.refreshToken).Active)
+	})
+
+	t.Run("service_token", func(t *testing.T) {
+		client, clientSecret, err := svc.CreateServiceClient(ctx, "worker", []string{"users:read"})
+		require.NoError(t, err)
+		token, err := svc.IssueClientCredentialsToken(ctx, client.ClientID, clientSecret, "")
+		require.NoError(t, err)
+
+		resp := introspect(t, token.AccessToken)
+		assert.True(t, resp.Active)
+		assert.Equal(t, apputils.TokenTypeService, resp.Type)
+		assert.Equal(t, client.ClientID, resp.ClientID)
+		assert.Equal(t, "users:read", resp.Scope)
+
+		require.NoError(t, svc.RevokeServiceClient(ctx, client.ClientID))
+		assert.False(t, introspect(t, token.AccessToken).Active)
+	})
+}
+
+func TestAuthService_RevokeToken(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestAuthService(t)
+	caller, secret, err := svc.CreateServiceClient(ctx, "resource-server", nil)
+	require.NoError(t, err)
+
+	t.Run("client_authentication", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		assert.ErrorIs(t, svc.RevokeToken(ctx, caller.ClientID, "wrong-secret", ts.refreshToken), ErrInvalidClient)
+		rt, err := repo.GetRefreshToken(ctx, ts.refreshTokenID)
+		require.NoError(t, err)
+		assert.Nil(t, rt.RevokedAt, "nothing may be revoked")
+	})
+
+	t.Run("unsupported_token_type", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		assert.ErrorIs(t, svc.RevokeToken(ctx, caller.ClientID, secret, ts.accessToken), ErrUnsupportedTokenType)
+	})
+
+	t.Run("invalid_tokens_are_ignored", func(t *testing.T) {
+		assert.NoError(t, svc.RevokeToken(ctx, caller.ClientID, secret, "not-a-jwt"))
+	})
+
+	t.Run("refresh_token_and_session_are_revoked", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		require.NoError(t, svc.RevokeToken(ctx, caller.ClientID, secret, ts.refreshToken))
+
+		rt, err := repo.GetRefreshToken(ctx, ts.refreshTokenID)
+		require.NoError(t, err)
+		assert.NotNil(t, rt.RevokedAt)
+		session, err := repo.GetSession(ctx, ts.session.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, session.RevokedAt)
+
+		resp, err := svc.IntrospectToken(ctx, caller.ClientID, secret, ts.accessToken)
+		require.NoError(t, err)
+		assert.False(t, resp.Active, "access tokens of the session are revoked too")
+
+		// revoking again is a no-op
+		assert.NoError(t, svc.RevokeToken(ctx, caller.ClientID, secret, ts.refreshToken))
+	})
+
+	t.Run("hash_mismatch_is_ignored", func(t *testing.T) {
+		ts := newTestSession(t, svc, repo)
+		rt, err := repo.GetRefreshToken(ctx, ts.refreshTokenID)
+		require.NoError(t, err)
+		rt.TokenHash = []byte(svc.newJWTGenerator().GetHash("another-token"))
+		require.NoError(t, repo.UpdateRefreshToken(ctx, rt))
+
+		require.NoError(t, svc.RevokeToken(ctx, caller.ClientID, secret, ts.refreshToken))
+		session, err := repo.GetSession(ctx, ts.session.ID)
+		require.NoError(t, err)
+		assert.Nil(t, session.RevokedAt)
+	})
+}
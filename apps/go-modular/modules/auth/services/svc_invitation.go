@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	userModels "go-modular/modules/user/models"
+)
+
+// ErrInvalidInvitationToken is returned when an invitation link is unknown, expired or already used.
+var ErrInvalidInvitationToken = errors.New("invalid or expired invitation")
+
+// invitationExpiry is the lifetime of the set-password link of an invitation.
+const invitationExpiry = 7 * 24 * time.Hour
+
+// SendInvitation emails an invited user (see userModels.UserInvited) a single use link to
+// set their password. Links of earlier invitations of the user stop working.
+func (s *AuthService) SendInvitation(ctx context.Context, event userModels.UserInvited) error {
+	rawToken, err := apputils.GenerateURLSafeToken(48)
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	userID := event.UserID
+	now := time.Now()
+	token := &models.OneTimeToken{
+		UserID:     &userID,
+		Subject:    models.OneTimeTokenSubjectAccountSetup,
+		TokenHash:  hashOneTimeToken(rawToken),
+		RelatesTo:  event.Email,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(invitationExpiry),
+		LastSentAt: &now,
+	}
+	// A user holds one token per subject
+	if err := s.authRepo.DeleteOneTimeTokensByUserSubject(ctx, userID, models.OneTimeTokenSubjectAccountSetup); err != nil {
+		return err
+	}
+	if err := s.authRepo.CreateOneTimeToken(ctx, token); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(s.invitationURL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("token", rawToken)
+	u.RawQuery = q.Encode()
+
+	if err := s.sendInvitationEmail(ctx, event, u.String(), token.ExpiresAt); err != nil {
+		_ = s.authRepo.DeleteOneTimeToken(ctx, token.ID)
+		return fmt.Errorf("failed to send invitation: %w", err)
+	}
+	return nil
+}
+
+// AcceptInvitation sets the first password of an invited user from the token of their
+// invitation link. The link proves the user received the email, so their email address is
+// marked verified as well. The token, the password and the verification are stored
+// together.
+func (s *AuthService) AcceptInvitation(ctx context.Context, rawToken, password string) (uuid.UUID, error) {
+	if rawToken == "" {
+		return uuid.Nil, ErrInvalidInvitationToken
+	}
+	token, err := s.authRepo.GetOneTimeTokenByTokenHash(ctx, hashOneTimeToken(rawToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return uuid.Nil, ErrInvalidInvitationToken
+		}
+		return uuid.Nil, err
+	}
+	if token == nil || token.Subject != models.OneTimeTokenSubjectAccountSetup || token.UserID == nil || time.Now().After(token.ExpiresAt) {
+		return uuid.Nil, ErrInvalidInvitationToken
+	}
+	userID := *token.UserID
+
+	if err := s.checkPasswordPolicy(ctx, userID, password, "", "Password"); err != nil {
+		return uuid.Nil, err
+	}
+	hashed, err := apputils.NewPasswordHasher().Hash(password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// A concurrent request already used the invitation when the token is gone
+		if err := s.authRepo.DeleteOneTimeToken(ctx, token.ID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrInvalidInvitationToken
+			}
+			return err
+		}
+		if err := s.authRepo.SetUserPassword(ctx, &models.UserPassword{UserID: userID, PasswordHash: hashed}); err != nil {
+			return err
+		}
+		if err := s.recordPasswordHistory(ctx, userID, hashed); err != nil {
+			return err
+		}
+		return s.userService.MarkEmailVerified(ctx, userID)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	s.audit(ctx, auditModels.ActionPasswordSet, userID, auditModels.TargetUser, userID, map[string]any{"source": "invitation"})
+	s.audit(ctx, auditModels.ActionEmailVerified, userID, auditModels.TargetUser, userID, nil)
+	s.publish(ctx, models.PasswordChanged{UserID: userID, OccurredAt: time.Now()})
+	return userID, nil
+}
+
+func (s *AuthService) sendInvitationEmail(ctx context.Context, event userModels.UserInvited, setPasswordURL string, expiresAt time.Time) error {
+	data := map[string]any{
+		"DisplayName":    event.DisplayName,
+		"Email":          event.Email,
+		"Username":       event.Username,
+		"SetPasswordURL": setPasswordURL,
+		"ExpiresAt":      expiresAt.Format(time.RFC1123),
+	}
+
+	subject := "Your account has been created"
+	templateName := "user_invitation.html" // ensure this template exists in templates/emails/
+
+	if s.mailer != nil {
+		return s.mailer.SendEmail(ctx, []string{event.Email}, subject, templateName, data)
+	}
+
+	// Fallback for development: print the link
+	fmt.Println("No mailer configured, invitation for", event.Email, ":", setPasswordURL)
+	return nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/pkg/apputils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userModels "go-modular/modules/user/models"
+)
+
+func TestAuthService_Invitation(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, users := newTestAuthService(t)
+	user := users.add("invited@example.com")
+	event := userModels.UserInvited{UserID: user.ID, Email: user.Email, OccurredAt: time.Now()}
+
+	t.Run("send_replaces_earlier_invitations", func(t *testing.T) {
+		require.NoError(t, svc.SendInvitation(ctx, event))
+		require.NoError(t, svc.SendInvitation(ctx, event))
+		tokens := repo.tokensOf(user.ID, models.OneTimeTokenSubjectAccountSetup)
+		require.Len(t, tokens, 1)
+		assert.WithinDuration(t, time.Now().Add(invitationExpiry), tokens[0].ExpiresAt, time.Minute)
+	})
+
+	// The raw token is only emailed, so the tests store their own
+	invite := func(t *testing.T, raw string, expiresAt time.Time) {
+		t.Helper()
+		userID := user.ID
+		require.NoError(t, repo.CreateOneTimeToken(ctx, &models.OneTimeToken{
+			UserID: &userID, Subject: models.OneTimeTokenSubjectAccountSetup, TokenHash: hashOneTimeToken(raw), ExpiresAt: expiresAt,
+		}))
+	}
+
+	t.Run("unknown_and_expired_tokens_are_rejected", func(t *testing.T) {
+		_, err := svc.AcceptInvitation(ctx, "unknown", "Correct-Horse-42")
+		assert.ErrorIs(t, err, ErrInvalidInvitationToken)
+
+		invite(t, "expired", time.Now().Add(-time.Minute))
+		_, err = svc.AcceptInvitation(ctx, "expired", "Correct-Horse-42")
+		assert.ErrorIs(t, err, ErrInvalidInvitationToken)
+	})
+
+	t.Run("weak_passwords_keep_the_invitation", func(t *testing.T) {
+		invite(t, "weak", time.Now().Add(time.Hour))
+		_, err := svc.AcceptInvitation(ctx, "weak", "short")
+		var policyErr *apputils.PolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		_, err = repo.GetOneTimeTokenByTokenHash(ctx, hashOneTimeToken("weak"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("accept_sets_the_password_once", func(t *testing.T) {
+		invite(t, "valid", time.Now().Add(time.Hour))
+		userID, err := svc.AcceptInvitation(ctx, "valid", "Correct-Horse-42")
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+
+		ok, err := apputils.NewPasswordHasher().Validate("Correct-Horse-42", repo.passwords[user.ID])
+		require.NoError(t, err)
+		assert.True(t, ok)
+		verified, err := users.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, verified.EmailVerifiedAt)
+
+		_, err = svc.AcceptInvitation(ctx, "valid", "Correct-Horse-42")
+		assert.ErrorIs(t, err, ErrInvalidInvitationToken)
+	})
+}
@@ -3,10 +3,13 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"go-modular/modules/auth/models"
 	"go-modular/pkg/apputils"
+
+	auditModels "go-modular/modules/audit/models"
 )
 
 // SetUserPassword creates a new user password (with hashing).
@@ -14,13 +17,24 @@ func (s *AuthService) SetUserPassword(ctx context.Context, userPassword *models.
 	if userPassword == nil || userPassword.PasswordHash == "" {
 		return errors.New("password is required")
 	}
+	if err := s.checkPasswordPolicy(ctx, userPassword.UserID, userPassword.PasswordHash, "", "Password"); err != nil {
+		return err
+	}
 	hasher := apputils.NewPasswordHasher()
 	hashed, err := hasher.Hash(userPassword.PasswordHash)
 	if err != nil {
 		return err
 	}
 	userPassword.PasswordHash = hashed
-	return s.authRepo.SetUserPassword(ctx, userPassword)
+	if err := s.authRepo.SetUserPassword(ctx, userPassword); err != nil {
+		return err
+	}
+	if err := s.recordPasswordHistory(ctx, userPassword.UserID, hashed); err != nil {
+		return err
+	}
+	s.audit(ctx, auditModels.ActionPasswordSet, uuid.Nil, auditModels.TargetUser, userPassword.UserID, nil)
+	s.publish(ctx, models.PasswordChanged{UserID: userPassword.UserID, OccurredAt: time.Now()})
+	return nil
 }
 
 // UpdateUserPassword updates an existing user password (with current password validation and hashing).
@@ -38,15 +52,27 @@ func (s *AuthService) UpdateUserPassword(ctx context.Context, userID uuid.UUID,
 		return err
 	}
 	if !ok {
+		s.audit(ctx, auditModels.ActionPasswordChangeFailed, uuid.Nil, auditModels.TargetUser, userID, map[string]any{"reason": "current_password_incorrect"})
 		return errors.New("current password is incorrect")
 	}
+	if err := s.checkPasswordPolicy(ctx, userID, newPassword, currentPassword, "NewPassword"); err != nil {
+		return err
+	}
 
 	hasher := apputils.NewPasswordHasher()
 	hashed, err := hasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
-	return s.authRepo.UpdateUserPassword(ctx, userID, hashed)
+	if err := s.authRepo.UpdateUserPassword(ctx, userID, hashed); err != nil {
+		return err
+	}
+	if err := s.recordPasswordHistory(ctx, userID, hashed); err != nil {
+		return err
+	}
+	s.audit(ctx, auditModels.ActionPasswordChanged, uuid.Nil, auditModels.TargetUser, userID, nil)
+	s.publish(ctx, models.PasswordChanged{UserID: userID, OccurredAt: time.Now()})
+	return nil
 }
 
 // ValidateUserPassword checks if the provided password matches the user's current password.
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofrs/uuid/v5"
+	"go-modular/modules/auth/models"
+	"go-modular/pkg/apputils"
+)
+
+// checkPasswordPolicy validates a candidate password against the configured policy,
+// the user's email/username and the user's recent password history. Violations are
+// returned as an *apputils.PolicyError reported on the given request struct field.
+// current, when not empty, is the user's (already verified) current password.
+func (s *AuthService) checkPasswordPolicy(ctx context.Context, userID uuid.UUID, password, current, field string) error {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	identifiers := []string{user.Email}
+	if user.Username != nil {
+		identifiers = append(identifiers, *user.Username)
+	}
+
+	violations := s.passwordPolicy.Check(password, identifiers...)
+	if len(violations) == 0 {
+		reused := current != "" && password == current
+		if !reused {
+			reused, err = s.isRecentPassword(ctx, userID, password)
+			if err != nil {
+				return err
+			}
+		}
+		if reused {
+			violations = append(violations, fmt.Sprintf("Must not match any of your last %d passwords", max(s.passwordHistorySize, 1)))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &apputils.PolicyError{Field: field, Violations: violations}
+	}
+	return nil
+}
+
+// isRecentPassword reports whether password matches one of the user's recent password hashes.
+func (s *AuthService) isRecentPassword(ctx context.Context, userID uuid.UUID, password string) (bool, error) {
+	if s.passwordHistorySize <= 0 {
+		return false, nil
+	}
+	entries, err := s.authRepo.ListPasswordHistory(ctx, userID, s.passwordHistorySize)
+	if err != nil {
+		return false, err
+	}
+	hasher := apputils.NewPasswordHasher()
+	for _, e := range entries {
+		ok, err := hasher.Validate(password, e.PasswordHash)
+		if err != nil {
+			continue // skip unreadable legacy entries
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordPasswordHistory stores a new password hash and trims the history to the configured size.
+func (s *AuthService) recordPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	if s.passwordHistorySize <= 0 {
+		return nil
+	}
+	if err := s.authRepo.CreatePasswordHistory(ctx, &models.PasswordHistory{UserID: userID, PasswordHash: passwordHash}); err != nil {
+		return err
+	}
+	return s.authRepo.PrunePasswordHistory(ctx, userID, s.passwordHistorySize)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go-modular/internal/personaldata"
+	"go-modular/modules/auth/models"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// personalDataSessionLimit caps the number of sessions included in a personal data export.
+const personalDataSessionLimit = 10000
+
+// personalDataSession is a session as included in a personal data export. Token hashes and
+// device fingerprints are internal and left out.
+type personalDataSession struct {
+	ID             uuid.UUID  `json:"id"`
+	DeviceName     *string    `json:"device_name"`
+	UserAgent      *string    `json:"user_agent"`
+	IPAddress      *string    `json:"ip_address"`
+	RememberMe     bool       `json:"remember_me"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+}
+
+// ExportPersonalData writes the user's sessions (including revoked and expired ones that have
+// not been pruned yet) and passkeys to a personal data export.
+func (s *AuthService) ExportPersonalData(ctx context.Context, userID uuid.UUID, w *personaldata.Writer) error {
+	sessions, err := s.authRepo.ListRecentSessionsByUser(ctx, userID, personalDataSessionLimit)
+	if err != nil {
+		return err
+	}
+	items := make([]personalDataSession, 0, len(sessions))
+	for _, session := range sessions {
+		item := personalDataSession{
+			ID:             session.ID,
+			DeviceName:     session.DeviceName,
+			UserAgent:      session.UserAgent,
+			RememberMe:     session.RememberMe,
+			CreatedAt:      session.CreatedAt,
+			LastActivityAt: session.LastActivityAt(),
+			ExpiresAt:      session.ExpiresAt,
+			RevokedAt:      session.RevokedAt,
+		}
+		if session.IPAddress != nil {
+			ip := session.IPAddress.String()
+			item.IPAddress = &ip
+		}
+		items = append(items, item)
+	}
+	if err := w.WriteJSON("sessions.json", items); err != nil {
+		return err
+	}
+
+	passkeys, err := s.authRepo.ListWebAuthnCredentialsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if passkeys == nil {
+		passkeys = []*models.WebAuthnCredential{}
+	}
+	return w.WriteJSON("passkeys.json", passkeys)
+}
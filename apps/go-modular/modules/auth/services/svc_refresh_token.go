@@ -7,6 +7,8 @@ import (
 
 	"github.com/gofrs/uuid/v5"
 	"go-modular/modules/auth/models"
+
+	auditModels "go-modular/modules/audit/models"
 )
 
 // CreateRefreshToken creates a new refresh token.
@@ -39,7 +41,13 @@ func (s *AuthService) UpdateRefreshToken(ctx context.Context, token *models.Refr
 	if token == nil || token.ID == uuid.Nil {
 		return errors.New("refresh token and token.ID are required")
 	}
-	return s.authRepo.UpdateRefreshToken(ctx, token)
+	if err := s.authRepo.UpdateRefreshToken(ctx, token); err != nil {
+		return err
+	}
+	if token.RevokedAt != nil {
+		s.audit(ctx, auditModels.ActionRefreshTokenRevoked, uuid.Nil, auditModels.TargetRefreshToken, token.ID, nil)
+	}
+	return nil
 }
 
 // DeleteRefreshToken deletes a refresh token by its ID.
@@ -47,7 +55,11 @@ func (s *AuthService) DeleteRefreshToken(ctx context.Context, tokenID uuid.UUID)
 	if tokenID == uuid.Nil {
 		return errors.New("refresh_token_id is required")
 	}
-	return s.authRepo.DeleteRefreshToken(ctx, tokenID)
+	if err := s.authRepo.DeleteRefreshToken(ctx, tokenID); err != nil {
+		return err
+	}
+	s.audit(ctx, auditModels.ActionRefreshTokenRevoked, uuid.Nil, auditModels.TargetRefreshToken, tokenID, nil)
+	return nil
 }
 
 // ValidateRefreshToken checks if a refresh token is valid (not revoked and not expired).
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+)
+
+var (
+	// ErrInvalidClient is returned when client authentication fails (unknown, revoked or wrong secret).
+	ErrInvalidClient = errors.New("invalid client credentials")
+
+	// ErrInvalidScope is returned when a requested scope is not allowed for the client or malformed.
+	ErrInvalidScope = errors.New("requested scope is invalid or not allowed for this client")
+
+	// ErrServiceClientNotFound is returned when revoking an unknown or already revoked client.
+	ErrServiceClientNotFound = errors.New("service client not found")
+)
+
+// serviceClientIDPrefix makes service client IDs easy to recognize in logs and tokens.
+const serviceClientIDPrefix = "svc_"
+
+// scopeTokenRegex matches a single OAuth2 scope token (RFC 6749 section 3.3).
+var scopeTokenRegex = regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]+$`)
+
+// hashClientSecret returns the hex-encoded SHA-256 hash of a client secret. Secrets are
+// high-entropy random values, so a fast hash is sufficient.
+func hashClientSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateServiceClient registers a new service client allowed to request the given scopes.
+// The plain client secret is returned once and cannot be retrieved later.
+func (s *AuthService) CreateServiceClient(ctx context.Context, name string, scopes []string) (*models.ServiceClient, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("service client name is required")
+	}
+	for _, scope := range scopes {
+		if !scopeTokenRegex.MatchString(scope) {
+			return nil, "", fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+	}
+
+	idBytes := make([]byte, 12)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, "", fmt.Errorf("failed to generate client id: %w", err)
+	}
+	secret, err := apputils.GenerateURLSafeToken(64)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate client secret: %w", err)
+	}
+
+	client := &models.ServiceClient{
+		ClientID:   serviceClientIDPrefix + hex.EncodeToString(idBytes),
+		Name:       name,
+		SecretHash: hashClientSecret(secret),
+		Scopes:     slices.Compact(slices.Sorted(slices.Values(scopes))),
+	}
+	if err := s.authRepo.CreateServiceClient(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	s.audit(ctx, auditModels.ActionServiceClientCreated, uuid.Nil, auditModels.TargetServiceClient, client.ID, map[string]any{
+		"client_id": client.ClientID,
+		"scopes":    client.Scopes,
+	})
+	return client, secret, nil
+}
+
+// ListServiceClients returns all registered service clients, including revoked ones.
+func (s *AuthService) ListServiceClients(ctx context.Context) ([]*models.ServiceClient, error) {
+	return s.authRepo.ListServiceClients(ctx)
+}
+
+// RevokeServiceClient revokes a service client. Access tokens already issued remain valid
+// until they expire, which is why service tokens are short-lived.
+func (s *AuthService) RevokeServiceClient(ctx context.Context, clientID string) error {
+	client, err := s.authRepo.GetServiceClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrServiceClientNotFound
+		}
+		return err
+	}
+	if err := s.authRepo.RevokeServiceClient(ctx, clientID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrServiceClientNotFound
+		}
+		return err
+	}
+
+	s.audit(ctx, auditModels.ActionServiceClientRevoked, uuid.Nil, auditModels.TargetServiceClient, client.ID, map[string]any{
+		"client_id": client.ClientID,
+	})
+	return nil
+}
+
+// IssueClientCredentialsToken authenticates a service client and issues a short-lived access
+// token with typ=service (OAuth2 client_credentials grant). scope is a space-delimited list of
+// requested scopes; when empty all scopes allowed for the client are granted.
+func (s *AuthService) IssueClientCredentialsToken(ctx context.Context, clientID, clientSecret, scope string) (*models.OAuthTokenResponse, error) {
+	client, err := s.authenticateServiceClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := client.Scopes
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		if !client.HasScopes(requested) {
+			s.audit(ctx, auditModels.ActionServiceTokenFailed, uuid.Nil, auditModels.TargetServiceClient, client.ID, map[string]any{
+				"client_id": client.ClientID,
+				"reason":    "invalid_scope",
+				"scope":     scope,
+			})
+			return nil, ErrInvalidScope
+		}
+		granted = slices.Compact(slices.Sorted(slices.Values(requested)))
+	}
+	grantedScope := strings.Join(granted, " ")
+
+	jwtGen := apputils.NewJWTGenerator(apputils.JWTConfig{
+		SecretKey:         s.secretKey,
+		AccessTokenExpiry: s.serviceTokenExpiry,
+		SigningAlg:        s.signingAlg,
+		Issuer:            s.baseURL,
+	})
+	accessToken, err := jwtGen.Sign(ctx, models.ServiceTokenPayload{
+		Type:     apputils.TokenTypeService,
+		ClientID: client.ClientID,
+		Scope:    grantedScope,
+	}, client.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Usage tracking is best effort and must not fail the token request
+	_ = s.authRepo.UpdateServiceClientLastUsedAt(ctx, client.ID, time.Now())
+
+	s.audit(ctx, auditModels.ActionServiceTokenIssued, uuid.Nil, auditModels.TargetServiceClient, client.ID, map[string]any{
+		"client_id": client.ClientID,
+		"scope":     grantedScope,
+	})
+
+	return &models.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.serviceTokenExpiry / time.Second),
+		Scope:       grantedScope,
+	}, nil
+}
+
+// authenticateServiceClient verifies the client secret of an active service client.
+func (s *AuthService) authenticateServiceClient(ctx context.Context, clientID, clientSecret string) (*models.ServiceClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.authRepo.GetServiceClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+
+	reason := ""
+	switch {
+	case subtle.ConstantTimeCompare([]byte(hashClientSecret(clientSecret)), []byte(client.SecretHash)) != 1:
+		reason = "invalid_secret"
+	case client.RevokedAt != nil:
+		reason = "revoked"
+	}
+	if reason != "" {
+		s.audit(ctx, auditModels.ActionServiceTokenFailed, uuid.Nil, auditModels.TargetServiceClient, client.ID, map[string]any{
+			"client_id": client.ClientID,
+			"reason":    reason,
+		})
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
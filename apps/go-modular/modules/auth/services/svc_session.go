@@ -6,7 +6,12 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid/v5"
+	"go-modular/internal/adapter"
 	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
+
+	auditModels "go-modular/modules/audit/models"
 )
 
 // CreateSession creates a new session.
@@ -47,13 +52,139 @@ func (s *AuthService) DeleteSession(ctx context.Context, sessionID uuid.UUID) er
 	if sessionID == uuid.Nil {
 		return errors.New("session_id is required")
 	}
-	return s.authRepo.DeleteSession(ctx, sessionID)
+	session, err := s.authRepo.GetSession(adapter.UsePrimary(ctx), sessionID)
+	if err != nil {
+		return err
+	}
+	if err := s.authRepo.DeleteSession(ctx, sessionID); err != nil {
+		return err
+	}
+	s.audit(ctx, auditModels.ActionSessionRevoked, uuid.Nil, auditModels.TargetSession, sessionID, nil)
+	s.publish(ctx, models.SessionRevoked{SessionID: sessionID, UserID: session.UserID, Reason: "signed_out", OccurredAt: time.Now()})
+	return nil
 }
 
-// ValidateSession checks if a session is valid (not revoked and not expired).
+// ValidateSession checks if a session is valid: not revoked and within both the idle timeout
+// and the absolute lifetime of the session policy.
 func (s *AuthService) ValidateSession(ctx context.Context, sessionID uuid.UUID) (bool, error) {
 	if sessionID == uuid.Nil {
 		return false, errors.New("session_id is required")
 	}
-	return s.authRepo.ValidateSession(ctx, sessionID)
+	// Revocations must take effect at once, so sessions are never validated on a replica
+	session, err := s.authRepo.GetSession(adapter.UsePrimary(ctx), sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return s.sessionPolicy.Check(session, time.Now()) == nil, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token bound to the same session.
+// The session's idle timeout slides (refreshed_at is set to now) while its absolute lifetime
+// is kept. ErrInvalidSession is returned for unknown or revoked refresh tokens, and
+// ErrSessionExpired or ErrSessionIdle once a limit of the session policy is exceeded.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (*models.RefreshSessionResponse, error) {
+	claims, err := s.newJWTGenerator().ParseAndValidate(ctx, refreshToken)
+	if err != nil || claimString(claims, "typ") != apputils.TokenTypeRefresh {
+		return nil, ErrInvalidSession
+	}
+	rt, active, err := s.activeRefreshToken(ctx, claimString(claims, "jti"), refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if !active || rt.SessionID == nil {
+		return nil, ErrInvalidSession
+	}
+
+	session, err := s.authRepo.GetSession(adapter.UsePrimary(ctx), *rt.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.sessionPolicy.Check(session, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.TouchSession(ctx, session.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+	session.RefreshedAt = &now
+
+	user, err := s.userService.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	orgID, err := s.defaultOrganizationID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessExpiry := s.sessionPolicy.accessTokenExpiry(session, now, s.accessTokenExpiry)
+	accessToken, err := s.signAccessToken(ctx, s.jwtGenerator(accessExpiry, s.refreshTokenExpiry), session.UserID, user.Email, session.ID, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RefreshSessionResponse{
+		AccessToken:   accessToken,
+		SessionID:     session.ID,
+		TokenExpiry:   now.Add(accessExpiry),
+		SessionExpiry: session.ExpiresAt,
+	}, nil
+}
+
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ListUserSessions returns the user's sessions that are still valid under the session policy,
+// newest first.
+func (s *AuthService) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	sessions, err := s.authRepo.ListActiveSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	active := make([]*models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if s.sessionPolicy.Check(session, now) == nil {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// RevokeUserSession revokes one of the user's own sessions, which also invalidates its
+// refresh token and the access tokens bound to it. ErrSessionNotFound is returned for
+// unknown, already revoked or foreign sessions.
+func (s *AuthService) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.authRepo.GetSession(adapter.UsePrimary(ctx), sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	session.RevokedBy = &userID
+	if err := s.authRepo.UpdateSession(ctx, session); err != nil {
+		return err
+	}
+	s.audit(ctx, auditModels.ActionSessionRevoked, userID, auditModels.TargetSession, session.ID, map[string]any{
+		"reason": "user_revoked",
+	})
+	s.publish(ctx, models.SessionRevoked{SessionID: session.ID, UserID: userID, RevokedBy: &userID, Reason: "user_revoked", OccurredAt: now})
+	return nil
 }
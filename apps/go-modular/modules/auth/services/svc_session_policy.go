@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"go-modular/modules/auth/models"
+)
+
+var (
+	// ErrSessionExpired is returned when a session exceeded its absolute lifetime.
+	ErrSessionExpired = errors.New("session expired")
+
+	// ErrSessionIdle is returned when a session was not refreshed within its idle timeout.
+	ErrSessionIdle = errors.New("session idle timeout exceeded")
+
+	// ErrSessionRevoked is returned when a session has been revoked.
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// SessionPolicy controls how long sessions stay valid. A session is rejected once it has not
+// been refreshed for the idle timeout or once its absolute lifetime is over, whichever comes
+// first. Remember-me sessions, chosen per sign-in, use the longer remember-me durations.
+type SessionPolicy struct {
+	IdleTimeout           time.Duration // Maximum time between refreshes
+	AbsoluteLifetime      time.Duration // Maximum session lifetime regardless of activity
+	RememberMeIdleTimeout time.Duration // Idle timeout of remember-me sessions
+	RememberMeLifetime    time.Duration // Absolute lifetime of remember-me sessions
+}
+
+// DefaultSessionPolicy returns the session policy used when none is configured.
+func DefaultSessionPolicy() SessionPolicy {
+	return SessionPolicy{
+		IdleTimeout:           24 * time.Hour,
+		AbsoluteLifetime:      7 * 24 * time.Hour,
+		RememberMeIdleTimeout: 7 * 24 * time.Hour,
+		RememberMeLifetime:    30 * 24 * time.Hour,
+	}
+}
+
+// limits returns the idle timeout and absolute lifetime that apply to a session.
+func (p SessionPolicy) limits(rememberMe bool) (idle, lifetime time.Duration) {
+	if rememberMe {
+		return p.RememberMeIdleTimeout, p.RememberMeLifetime
+	}
+	return p.IdleTimeout, p.AbsoluteLifetime
+}
+
+// Check returns nil when session is still valid at now, or the reason it is not.
+func (p SessionPolicy) Check(session *models.Session, now time.Time) error {
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+	if !now.Before(session.ExpiresAt) {
+		return ErrSessionExpired
+	}
+	idle, _ := p.limits(session.RememberMe)
+	if now.Sub(session.LastActivityAt()) > idle {
+		return ErrSessionIdle
+	}
+	return nil
+}
+
+// accessTokenExpiry returns the lifetime of an access token issued for session at now: the
+// configured access token expiry, capped so it never outlives the idle or absolute deadline.
+func (p SessionPolicy) accessTokenExpiry(session *models.Session, now time.Time, accessTokenExpiry time.Duration) time.Duration {
+	idle, _ := p.limits(session.RememberMe)
+	return min(accessTokenExpiry, idle, session.ExpiresAt.Sub(now))
+}
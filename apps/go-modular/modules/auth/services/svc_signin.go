@@ -9,6 +9,8 @@ import (
 	"go-modular/modules/auth/models"
 	user_models "go-modular/modules/user/models"
 	"go-modular/pkg/apputils"
+
+	auditModels "go-modular/modules/audit/models"
 )
 
 // ErrInvalidCredentials is returned when authentication fails.
@@ -30,6 +32,7 @@ func (s *AuthService) signinWithCredentials(
 	ctx context.Context,
 	identifier string,
 	password string,
+	rememberMe bool,
 	getUser func(context.Context, string) (UserIdentity, error),
 ) (*models.AuthenticatedUser, error) {
 	if identifier == "" || password == "" {
@@ -37,9 +40,11 @@ func (s *AuthService) signinWithCredentials(
 	}
 	user, err := getUser(ctx, identifier)
 	if err != nil {
+		s.auditSignInFailed(ctx, uuid.Nil, identifier, "user_not_found")
 		return nil, err
 	}
 	if user == nil {
+		s.auditSignInFailed(ctx, uuid.Nil, identifier, "user_not_found")
 		return nil, ErrInvalidCredentials
 	}
 
@@ -49,77 +54,158 @@ func (s *AuthService) signinWithCredentials(
 		return nil, err
 	}
 	if !ok {
+		s.auditSignInFailed(ctx, user.GetID(), identifier, "invalid_password")
 		return nil, ErrInvalidCredentials
 	}
 
 	// Then check if the user's email is verified
 	if u, ok := any(user).(interface{ GetEmailVerifiedAt() *time.Time }); ok {
 		if u.GetEmailVerifiedAt() == nil {
+			s.auditSignInFailed(ctx, user.GetID(), identifier, "email_not_verified")
 			return nil, ErrEmailNotVerified
 		}
 	}
 
-	// Prepare JWT generator with configuration from environment or service fields.
-	jwtGen := apputils.NewJWTGenerator(apputils.JWTConfig{
-		SecretKey:          s.secretKey,
-		AccessTokenExpiry:  s.accessTokenExpiry,
-		RefreshTokenExpiry: s.refreshTokenExpiry,
-		SigningAlg:         s.signingAlg,
-		Issuer:             s.baseURL,
-	})
+	authUser, err := s.issueAuthenticatedSession(ctx, user, rememberMe, "password")
+	if err != nil {
+		return nil, err
+	}
+	s.auditSignInSucceeded(ctx, authUser, "password")
+	return authUser, nil
+}
 
-	// Determine audience for the token, default to "client-app"
-	audience := "client-app"
-	if md, ok := ctx.Value("headers").(map[string]string); ok {
-		if aud, exists := md["X-App-Audience"]; exists && aud != "" {
-			audience = aud
-		}
+// auditSignInSucceeded records a successful sign-in; the signed-in user is both actor and target.
+func (s *AuthService) auditSignInSucceeded(ctx context.Context, authUser *models.AuthenticatedUser, method string) {
+	metadata := map[string]any{"method": method}
+	if authUser.SessionID != nil {
+		metadata["session_id"] = authUser.SessionID.String()
 	}
+	s.audit(ctx, auditModels.ActionSignInSucceeded, authUser.User.ID, auditModels.TargetUser, authUser.User.ID, metadata)
+}
 
-	// Generate a new UUID for the refresh token
-	refreshTokenUUID, err := uuid.NewV7()
+// auditSignInFailed records a failed sign-in attempt. userID is uuid.Nil when the user is unknown.
+func (s *AuthService) auditSignInFailed(ctx context.Context, userID uuid.UUID, identifier, reason string) {
+	metadata := map[string]any{"reason": reason}
+	if identifier != "" {
+		metadata["identifier"] = identifier
+	}
+	s.audit(ctx, auditModels.ActionSignInFailed, uuid.Nil, auditModels.TargetUser, userID, metadata)
+}
+
+// issueAuthenticatedSession evaluates the sign-in risk for an already authenticated user,
+// then creates a new session and issues the access and refresh tokens bound to it. Shared by
+// every sign-in method. Flagged sign-ins send a new sign-in alert, or return a
+// *StepUpRequiredError for password sign-ins when step-up verification is enabled.
+func (s *AuthService) issueAuthenticatedSession(ctx context.Context, user UserIdentity, rememberMe bool, method string) (*models.AuthenticatedUser, error) {
+	device := deviceFromContext(ctx)
+	risk, err := s.evaluateSignInRisk(ctx, user.GetID(), device)
 	if err != nil {
 		return nil, err
 	}
-	refreshTokenID := refreshTokenUUID.String()
+	// Passkeys already prove possession of a registered device, so only passwords step up
+	if risk.Flagged() && s.signInRisk.StepUp && method == "password" {
+		return nil, s.beginSignInStepUp(ctx, user, rememberMe, method, risk)
+	}
 
-	// Generate the refresh token JWT and its hash
-	refreshToken, err := jwtGen.GenerateRefreshTokenJWT(ctx, user.GetID().String(), audience, refreshTokenID)
+	authUser, err := s.createAuthenticatedSession(ctx, user, rememberMe, device)
 	if err != nil {
 		return nil, err
 	}
-	refreshTokenHash := jwtGen.GetHash(refreshToken)
 
-	// Create a new session for the user
+	if risk.Flagged() {
+		session, err := s.authRepo.GetSession(ctx, *authUser.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		// The alert is best effort and must not fail the sign-in
+		alertErr := s.sendNewSignInAlert(ctx, user, session, risk)
+		s.audit(ctx, auditModels.ActionSignInFlagged, user.GetID(), auditModels.TargetSession, session.ID, map[string]any{
+			"method":     method,
+			"reasons":    risk.Reasons(),
+			"alert_sent": alertErr == nil,
+		})
+	}
+	return authUser, nil
+}
+
+// createAuthenticatedSession creates a new session from device and issues the access and
+// refresh tokens bound to it. rememberMe selects the remember-me idle timeout and lifetime
+// of the session policy.
+func (s *AuthService) createAuthenticatedSession(ctx context.Context, user UserIdentity, rememberMe bool, device deviceInfo) (*models.AuthenticatedUser, error) {
+	// The session, its refresh token and the refresh JWT share the absolute lifetime, while
+	// the access token never outlives the idle timeout
+	now := time.Now()
+	_, lifetime := s.sessionPolicy.limits(rememberMe)
 	session := &models.Session{
-		UserID:    user.GetID(),
-		TokenHash: refreshTokenHash,
-		ExpiresAt: time.Now().Add(jwtGen.AccessTokenExpiry()),
+		UserID:     user.GetID(),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(lifetime),
+		RememberMe: rememberMe,
+		IPAddress:  device.IPAddress,
 	}
-	if err := s.CreateSession(ctx, session); err != nil {
-		return nil, err
+	if device.UserAgent != "" {
+		deviceName := apputils.SummarizeUserAgent(device.UserAgent)
+		session.UserAgent = &device.UserAgent
+		session.DeviceName = &deviceName
+	}
+	if device.Fingerprint != "" {
+		session.DeviceFingerprint = &device.Fingerprint
 	}
+	accessExpiry := s.sessionPolicy.accessTokenExpiry(session, now, s.accessTokenExpiry)
+	jwtGen := s.jwtGenerator(accessExpiry, lifetime)
 
-	// Prepare the access token payload, including the session ID
-	accessPayload := models.AccessTokenPayload{
-		UserID: user.GetID().String(),
-		Email:  user.GetEmail(),
-		SID:    session.ID.String(),
+	// Determine audience for the token, default to "client-app"
+	audience := "client-app"
+	if aud := requestHeader(ctx, "X-App-Audience"); aud != "" {
+		audience = aud
 	}
-	accessToken, err := jwtGen.Sign(ctx, accessPayload, user.GetID().String())
+
+	// Generate a new UUID for the refresh token
+	refreshTokenUUID, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
 	}
+	refreshTokenID := refreshTokenUUID.String()
 
-	// Store the refresh token in the database
-	refreshTokenModel := &models.RefreshToken{
-		ID:        refreshTokenUUID,
-		UserID:    user.GetID(),
-		SessionID: &session.ID,
-		TokenHash: []byte(refreshTokenHash),
-		ExpiresAt: time.Now().Add(jwtGen.RefreshTokenExpiry()),
+	// Generate the refresh token JWT and its hash
+	refreshToken, err := jwtGen.GenerateRefreshTokenJWT(ctx, user.GetID().String(), audience, refreshTokenID)
+	if err != nil {
+		return nil, err
 	}
-	if err := s.CreateRefreshToken(ctx, refreshTokenModel); err != nil {
+	refreshTokenHash := jwtGen.GetHash(refreshToken)
+
+	// The session and its refresh token are stored together, so a failed sign-in never
+	// leaves a session without refresh token behind
+	session.TokenHash = refreshTokenHash
+	var accessToken string
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Create a new session for the user
+		if err := s.CreateSession(ctx, session); err != nil {
+			return err
+		}
+
+		// Scope the access token to the user's default organization (if any)
+		orgID, err := s.defaultOrganizationID(ctx, user.GetID())
+		if err != nil {
+			return err
+		}
+
+		// Sign the access token bound to the session
+		accessToken, err = s.signAccessToken(ctx, jwtGen, user.GetID(), user.GetEmail(), session.ID, orgID)
+		if err != nil {
+			return err
+		}
+
+		// Store the refresh token in the database
+		return s.CreateRefreshToken(ctx, &models.RefreshToken{
+			ID:        refreshTokenUUID,
+			UserID:    user.GetID(),
+			SessionID: &session.ID,
+			TokenHash: []byte(refreshTokenHash),
+			ExpiresAt: session.ExpiresAt,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -130,16 +216,47 @@ func (s *AuthService) signinWithCredentials(
 			AccessToken:  accessToken,
 			RefreshToken: refreshToken,
 		},
-		SessionID:   &session.ID,
-		TokenExpiry: session.ExpiresAt,
+		SessionID:     &session.ID,
+		TokenExpiry:   now.Add(accessExpiry),
+		SessionExpiry: session.ExpiresAt,
 	}
 
 	return authUser, nil
 }
 
+// newJWTGenerator returns a JWT generator configured from the service fields.
+func (s *AuthService) newJWTGenerator() *apputils.JWTGenerator {
+	return s.jwtGenerator(s.accessTokenExpiry, s.refreshTokenExpiry)
+}
+
+// jwtGenerator returns a JWT generator issuing tokens with the given lifetimes.
+func (s *AuthService) jwtGenerator(accessTokenExpiry, refreshTokenExpiry time.Duration) *apputils.JWTGenerator {
+	return apputils.NewJWTGenerator(apputils.JWTConfig{
+		SecretKey:          s.secretKey,
+		AccessTokenExpiry:  accessTokenExpiry,
+		RefreshTokenExpiry: refreshTokenExpiry,
+		SigningAlg:         s.signingAlg,
+		Issuer:             s.baseURL,
+	})
+}
+
+// signAccessToken signs an access token for the user bound to sessionID. The org_id
+// claim is only set when orgID is not uuid.Nil.
+func (s *AuthService) signAccessToken(ctx context.Context, jwtGen *apputils.JWTGenerator, userID uuid.UUID, email string, sessionID, orgID uuid.UUID) (string, error) {
+	accessPayload := models.AccessTokenPayload{
+		UserID: userID.String(),
+		Email:  email,
+		SID:    sessionID.String(),
+	}
+	if orgID != uuid.Nil {
+		accessPayload.OrgID = orgID.String()
+	}
+	return jwtGen.Sign(ctx, accessPayload, userID.String())
+}
+
 // SignInWithEmail authenticates a user by email and password.
-func (s *AuthService) SignInWithEmail(ctx context.Context, email, password string) (*models.AuthenticatedUser, error) {
-	return s.signinWithCredentials(ctx, email, password, func(ctx context.Context, email string) (UserIdentity, error) {
+func (s *AuthService) SignInWithEmail(ctx context.Context, email, password string, rememberMe bool) (*models.AuthenticatedUser, error) {
+	return s.signinWithCredentials(ctx, email, password, rememberMe, func(ctx context.Context, email string) (UserIdentity, error) {
 		user, err := s.userService.GetUserByEmail(ctx, email)
 		if err != nil {
 			return nil, err
@@ -152,8 +269,8 @@ func (s *AuthService) SignInWithEmail(ctx context.Context, email, password strin
 }
 
 // SignInWithUsername authenticates a user by username and password.
-func (s *AuthService) SignInWithUsername(ctx context.Context, username, password string) (*models.AuthenticatedUser, error) {
-	return s.signinWithCredentials(ctx, username, password, func(ctx context.Context, username string) (UserIdentity, error) {
+func (s *AuthService) SignInWithUsername(ctx context.Context, username, password string, rememberMe bool) (*models.AuthenticatedUser, error) {
+	return s.signinWithCredentials(ctx, username, password, rememberMe, func(ctx context.Context, username string) (UserIdentity, error) {
 		user, err := s.userService.GetUserByUsername(ctx, username)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
+)
+
+var (
+	// ErrInvalidStepUpCode is returned when a step-up challenge is unknown, expired or the code is wrong.
+	ErrInvalidStepUpCode = errors.New("invalid or expired verification code")
+
+	// ErrInvalidAlertToken is returned when a "this wasn't me" link is unknown or expired.
+	ErrInvalidAlertToken = errors.New("invalid or expired link")
+)
+
+const (
+	// DeviceFingerprintHeader carries an optional client-computed device fingerprint on sign-in.
+	DeviceFingerprintHeader = "X-Device-Fingerprint"
+
+	stepUpCodeDigits       = 6
+	stepUpCodeExpiry       = 10 * time.Minute
+	stepUpMaxAttempts      = 5
+	maxFingerprintLength   = 256
+	defaultRiskHistorySize = 20
+)
+
+// SignInRiskPolicy controls how sign-ins are compared to the user's recent sessions. Flagged
+// sign-ins send a "new sign-in" alert email with a link that revokes the new session.
+type SignInRiskPolicy struct {
+	Enabled     bool // Evaluate sign-ins against the user's recent sessions
+	HistorySize int  // Number of recent sessions compared (default: 20)
+	StepUp      bool // Require an emailed verification code for flagged password sign-ins
+}
+
+// SignInRisk reports why a sign-in differs from the user's recent sessions.
+type SignInRisk struct {
+	NewDevice    bool // Device fingerprint never seen before
+	NewUserAgent bool // Browser/OS family never seen before
+	NewNetwork   bool // IP address outside every previously seen network prefix
+}
+
+// Flagged reports whether the sign-in looks unusual.
+func (r SignInRisk) Flagged() bool {
+	return r.NewDevice || r.NewUserAgent || r.NewNetwork
+}
+
+// Reasons returns machine-readable reasons recorded in audit events.
+func (r SignInRisk) Reasons() []string {
+	var reasons []string
+	if r.NewDevice {
+		reasons = append(reasons, "new_device")
+	}
+	if r.NewUserAgent {
+		reasons = append(reasons, "new_user_agent")
+	}
+	if r.NewNetwork {
+		reasons = append(reasons, "new_network")
+	}
+	return reasons
+}
+
+// StepUpRequiredError is returned by sign-in when a flagged sign-in must be completed with
+// the verification code emailed to the user (see VerifySignInStepUp).
+type StepUpRequiredError struct {
+	ChallengeID uuid.UUID
+	ExpiresAt   time.Time
+	Reasons     []string
+}
+
+func (e *StepUpRequiredError) Error() string {
+	return "step-up verification required"
+}
+
+// deviceInfo describes the client a sign-in comes from.
+type deviceInfo struct {
+	IPAddress   *net.IP
+	UserAgent   string
+	Fingerprint string
+}
+
+// deviceFromContext reads the client IP and user agent stored by the audit request context
+// middleware and the optional device fingerprint header.
+func deviceFromContext(ctx context.Context) deviceInfo {
+	var d deviceInfo
+	if info, ok := auditSvc.RequestInfoFromContext(ctx); ok {
+		if ip := net.ParseIP(info.IPAddress); ip != nil {
+			d.IPAddress = &ip
+		}
+		d.UserAgent = info.UserAgent
+	}
+	if fp := requestHeader(ctx, DeviceFingerprintHeader); len(fp) <= maxFingerprintLength {
+		d.Fingerprint = fp
+	}
+	return d
+}
+
+// requestHeader returns a request header propagated by the handlers through
+// apputils.HeadersContextKey, or "" when absent.
+func requestHeader(ctx context.Context, name string) string {
+	if md, ok := ctx.Value(apputils.HeadersContextKey).(map[string]string); ok {
+		return md[name]
+	}
+	return ""
+}
+
+// networkPrefix returns the /24 (IPv4) or /48 (IPv6) network of ip, used to tell a new
+// network apart from a new address within a known network.
+func networkPrefix(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// evaluateSignInRisk compares device with the user's recent sessions. Attributes are only
+// compared when the history contains them, so the first sign-in (or the first one sending a
+// fingerprint) is not flagged.
+func (s *AuthService) evaluateSignInRisk(ctx context.Context, userID uuid.UUID, device deviceInfo) (SignInRisk, error) {
+	var risk SignInRisk
+	if !s.signInRisk.Enabled {
+		return risk, nil
+	}
+	sessions, err := s.authRepo.ListRecentSessionsByUser(ctx, userID, s.signInRisk.HistorySize)
+	if err != nil || len(sessions) == 0 {
+		return risk, err
+	}
+
+	family := apputils.UserAgentFamily(device.UserAgent)
+	var prefix string
+	if device.IPAddress != nil {
+		prefix = networkPrefix(*device.IPAddress)
+	}
+
+	var haveFingerprint, haveUserAgent, haveNetwork bool
+	var knownFingerprint, knownUserAgent, knownNetwork bool
+	for _, session := range sessions {
+		if session.DeviceFingerprint != nil && *session.DeviceFingerprint != "" {
+			haveFingerprint = true
+			knownFingerprint = knownFingerprint || *session.DeviceFingerprint == device.Fingerprint
+		}
+		if session.UserAgent != nil && *session.UserAgent != "" {
+			haveUserAgent = true
+			knownUserAgent = knownUserAgent || apputils.UserAgentFamily(*session.UserAgent) == family
+		}
+		if session.IPAddress != nil {
+			haveNetwork = true
+			knownNetwork = knownNetwork || networkPrefix(*session.IPAddress) == prefix
+		}
+	}
+
+	risk.NewDevice = device.Fingerprint != "" && haveFingerprint && !knownFingerprint
+	risk.NewUserAgent = device.UserAgent != "" && haveUserAgent && !knownUserAgent
+	risk.NewNetwork = prefix != "" && haveNetwork && !knownNetwork
+	return risk, nil
+}
+
+// beginSignInStepUp emails a verification code for a flagged sign-in and returns the
+// *StepUpRequiredError the caller must complete with VerifySignInStepUp.
+func (s *AuthService) beginSignInStepUp(ctx context.Context, user UserIdentity, rememberMe bool, method string, risk SignInRisk) error {
+	code, err := generateNumericCode(stepUpCodeDigits)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	userID := user.GetID()
+	now := time.Now()
+	token := &models.OneTimeToken{
+		UserID:    &userID,
+		Subject:   models.OneTimeTokenSubjectSignInStepUp,
+		TokenHash: hashOneTimeToken(code),
+		RelatesTo: user.GetEmail(),
+		Metadata: map[string]any{
+			"remember_me": rememberMe,
+			"method":      method,
+			"reasons":     risk.Reasons(),
+			"attempts":    0,
+		},
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(stepUpCodeExpiry),
+		LastSentAt: &now,
+	}
+	if err := s.authRepo.CreateOneTimeToken(ctx, token); err != nil {
+		return err
+	}
+	if err := s.sendSignInStepUpEmail(ctx, user, code, token.ExpiresAt); err != nil {
+		_ = s.authRepo.DeleteOneTimeToken(ctx, token.ID)
+		return fmt.Errorf("failed to send verification code: %w", err)
+	}
+
+	s.audit(ctx, auditModels.ActionSignInFlagged, userID, auditModels.TargetUser, userID, map[string]any{
+		"method":  method,
+		"reasons": risk.Reasons(),
+		"step_up": true,
+	})
+	return &StepUpRequiredError{ChallengeID: token.ID, ExpiresAt: token.ExpiresAt, Reasons: risk.Reasons()}
+}
+
+// VerifySignInStepUp completes a flagged sign-in with the emailed verification code and
+// issues the session and tokens. A challenge is discarded after too many wrong codes.
+func (s *AuthService) VerifySignInStepUp(ctx context.Context, challengeID, code string) (*models.AuthenticatedUser, error) {
+	id, err := uuid.FromString(challengeID)
+	if err != nil {
+		return nil, ErrInvalidStepUpCode
+	}
+	token, err := s.authRepo.GetOneTimeTokenByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidStepUpCode
+		}
+		return nil, err
+	}
+	if token.Subject != models.OneTimeTokenSubjectSignInStepUp || token.UserID == nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidStepUpCode
+	}
+
+	// Every attempt is counted atomically before the code is checked, so concurrent guesses
+	// cannot exceed stepUpMaxAttempts
+	attempts, err := s.authRepo.IncrementOneTimeTokenAttempts(ctx, token.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidStepUpCode
+		}
+		return nil, err
+	}
+	if attempts > stepUpMaxAttempts {
+		return nil, ErrInvalidStepUpCode
+	}
+	if subtle.ConstantTimeCompare([]byte(hashOneTimeToken(code)), []byte(token.TokenHash)) != 1 {
+		if attempts == stepUpMaxAttempts {
+			_ = s.authRepo.DeleteOneTimeToken(ctx, token.ID)
+		}
+		s.auditSignInFailed(ctx, *token.UserID, token.RelatesTo, "invalid_step_up_code")
+		return nil, ErrInvalidStepUpCode
+	}
+
+	// The challenge is single use
+	if err := s.authRepo.DeleteOneTimeToken(ctx, token.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidStepUpCode
+		}
+		return nil, err
+	}
+
+	user, err := s.userService.GetUserByID(ctx, *token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	rememberMe, _ := token.Metadata["remember_me"].(bool)
+	authUser, err := s.createAuthenticatedSession(ctx, user, rememberMe, deviceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	method, _ := token.Metadata["method"].(string)
+	s.auditSignInSucceeded(ctx, authUser, method)
+	return authUser, nil
+}
+
+// sendNewSignInAlert emails the user about a flagged sign-in with a link to a page that
+// revokes the new session (see RevokeSessionFromAlert).
+func (s *AuthService) sendNewSignInAlert(ctx context.Context, user UserIdentity, session *models.Session, risk SignInRisk) error {
+	rawToken, err := apputils.GenerateURLSafeToken(48)
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %w", err)
+	}
+	userID := user.GetID()
+	token := &models.OneTimeToken{
+		UserID:    &userID,
+		Subject:   models.OneTimeTokenSubjectSessionAlert,
+		TokenHash: hashOneTimeToken(rawToken),
+		RelatesTo: user.GetEmail(),
+		Metadata:  map[string]any{"session_id": session.ID.String(), "reasons": risk.Reasons()},
+		ExpiresAt: session.ExpiresAt,
+	}
+	if err := s.authRepo.CreateOneTimeToken(ctx, token); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return err
+	}
+	u.Path = "/api/v1/auth/signin/not-me"
+	q := u.Query()
+	q.Set("token", rawToken)
+	u.RawQuery = q.Encode()
+	revokeURL := u.String()
+
+	device := "Unknown device"
+	if session.DeviceName != nil && *session.DeviceName != "" {
+		device = *session.DeviceName
+	}
+	ipAddress := "Unknown"
+	if session.IPAddress != nil {
+		ipAddress = session.IPAddress.String()
+	}
+	reasons := make([]string, 0, 3)
+	if risk.NewDevice {
+		reasons = append(reasons, "a device you have not used before")
+	}
+	if risk.NewUserAgent {
+		reasons = append(reasons, "a browser or operating system you have not used before")
+	}
+	if risk.NewNetwork {
+		reasons = append(reasons, "a network you have not signed in from before")
+	}
+
+	// Template data passed to the email template
+	data := map[string]any{
+		"Email":       user.GetEmail(),
+		"DisplayName": user.AsUserModel().DisplayName,
+		"Device":      device,
+		"IPAddress":   ipAddress,
+		"SignedInAt":  session.CreatedAt.Format(time.RFC1123),
+		"Reasons":     reasons,
+		"RevokeURL":   revokeURL,
+	}
+
+	subject := "New sign-in to your account"
+	templateName := "new_signin.html" // ensure this template exists in templates/emails/
+
+	if s.mailer != nil {
+		return s.mailer.SendEmail(ctx, []string{user.GetEmail()}, subject, templateName, data)
+	}
+
+	// Fallback for development: print the revoke link
+	fmt.Println("No mailer configured, new sign-in alert for", user.GetEmail(), ":", revokeURL)
+	return nil
+}
+
+// sendSignInStepUpEmail emails the verification code of a step-up challenge.
+func (s *AuthService) sendSignInStepUpEmail(ctx context.Context, user UserIdentity, code string, expiresAt time.Time) error {
+	data := map[string]any{
+		"Email":       user.GetEmail(),
+		"DisplayName": user.AsUserModel().DisplayName,
+		"Code":        code,
+		"ExpiresAt":   expiresAt.Format(time.RFC1123),
+	}
+
+	subject := "Your sign-in verification code"
+	templateName := "signin_step_up.html" // ensure this template exists in templates/emails/
+
+	if s.mailer != nil {
+		return s.mailer.SendEmail(ctx, []string{user.GetEmail()}, subject, templateName, data)
+	}
+
+	// Fallback for development: print the code
+	fmt.Println("No mailer configured, sign-in verification code for", user.GetEmail(), ":", code)
+	return nil
+}
+
+// RevokeSessionFromAlert revokes the session reported by a "this wasn't me" link of a new
+// sign-in alert. The link is single use.
+func (s *AuthService) RevokeSessionFromAlert(ctx context.Context, rawToken string) error {
+	if rawToken == "" {
+		return ErrInvalidAlertToken
+	}
+	token, err := s.authRepo.GetOneTimeTokenByTokenHash(ctx, hashOneTimeToken(rawToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidAlertToken
+		}
+		return err
+	}
+	if token.Subject != models.OneTimeTokenSubjectSessionAlert || token.UserID == nil || time.Now().After(token.ExpiresAt) {
+		return ErrInvalidAlertToken
+	}
+	sid, _ := token.Metadata["session_id"].(string)
+	sessionID, err := uuid.FromString(sid)
+	if err != nil {
+		return ErrInvalidAlertToken
+	}
+
+	if err := s.authRepo.DeleteOneTimeToken(ctx, token.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidAlertToken
+		}
+		return err
+	}
+
+	session, err := s.authRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil // already deleted (signed out or pruned)
+		}
+		return err
+	}
+	now := time.Now()
+	if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return nil
+	}
+	session.RevokedAt = &now
+	session.RevokedBy = token.UserID
+	if err := s.authRepo.UpdateSession(ctx, session); err != nil {
+		return err
+	}
+
+	s.audit(ctx, auditModels.ActionSessionRevoked, *token.UserID, auditModels.TargetSession, session.ID, map[string]any{
+		"reason": "not_me",
+	})
+	s.publish(ctx, models.SessionRevoked{SessionID: session.ID, UserID: session.UserID, RevokedBy: token.UserID, Reason: "not_me", OccurredAt: now})
+	return nil
+}
+
+// hashOneTimeToken returns the hex-encoded SHA-256 hash stored for one time tokens.
+func hashOneTimeToken(raw string) string {
+	hash := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(hash[:])
+}
+
+// generateNumericCode returns a random decimal code with the given number of digits.
+func generateNumericCode(digits int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
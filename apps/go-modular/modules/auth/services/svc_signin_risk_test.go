@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go-modular/modules/auth/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_VerifySignInStepUp_Attempts(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, users := newTestAuthService(t)
+	user := users.add("user@example.com")
+
+	newChallenge := func(t *testing.T) *models.OneTimeToken {
+		t.Helper()
+		now := time.Now()
+		token := &models.OneTimeToken{
+			UserID:    &user.ID,
+			Subject:   models.OneTimeTokenSubjectSignInStepUp,
+			TokenHash: hashOneTimeToken("123456"),
+			RelatesTo: user.Email,
+			Metadata:  map[string]any{"attempts": 0},
+			CreatedAt: now,
+			ExpiresAt: now.Add(stepUpCodeExpiry),
+		}
+		require.NoError(t, repo.CreateOneTimeToken(ctx, token))
+		return token
+	}
+
+	t.Run("correct_code_within_the_limit", func(t *testing.T) {
+		token := newChallenge(t)
+		for range stepUpMaxAttempts - 1 {
+			_, err := svc.VerifySignInStepUp(ctx, token.ID.String(), "000000")
+			assert.ErrorIs(t, err, ErrInvalidStepUpCode)
+		}
+		authUser, err := svc.VerifySignInStepUp(ctx, token.ID.String(), "123456")
+		require.NoError(t, err)
+		assert.NotEmpty(t, authUser.AccessToken)
+	})
+
+	t.Run("concurrent_guesses_are_all_counted", func(t *testing.T) {
+		token := newChallenge(t)
+		var wg sync.WaitGroup
+		for range 4 * stepUpMaxAttempts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := svc.VerifySignInStepUp(ctx, token.ID.String(), "000000")
+				assert.ErrorIs(t, err, ErrInvalidStepUpCode)
+			}()
+		}
+		wg.Wait()
+
+		// The challenge is gone once the limit was reached, even for the correct code
+		_, err := repo.GetOneTimeTokenByID(ctx, token.ID)
+		assert.Error(t, err)
+		_, err = svc.VerifySignInStepUp(ctx, token.ID.String(), "123456")
+		assert.ErrorIs(t, err, ErrInvalidStepUpCode)
+	})
+}
@@ -9,10 +9,14 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
 	"go-modular/pkg/apputils"
+
+	auditModels "go-modular/modules/audit/models"
 )
 
 // helper: try to detect if a user struct indicates the email is already verified.
@@ -85,15 +89,12 @@ func (s *AuthService) InitiateEmailVerification(ctx context.Context, email strin
 	userID := user.ID
 
 	// Check for an existing valid token for this user/email
-	tokens, err := s.authRepo.FindAllOneTimeTokens(ctx)
+	tokens, err := s.authRepo.FindOneTimeTokensByUserSubject(ctx, userID, models.OneTimeTokenSubjectEmailVerification)
 	now := time.Now()
 	var existingToken *models.OneTimeToken
 	if err == nil {
 		for _, t := range tokens {
-			if t.UserID != nil && *t.UserID == userID &&
-				t.Subject == models.OneTimeTokenSubjectEmailVerification &&
-				t.RelatesTo == email &&
-				now.Before(t.ExpiresAt) {
+			if strings.EqualFold(t.RelatesTo, email) && now.Before(t.ExpiresAt) {
 				existingToken = t
 				break
 			}
@@ -121,9 +122,7 @@ func (s *AuthService) InitiateEmailVerification(ctx context.Context, email strin
 
 	// Remove any old tokens for this user/email
 	for _, t := range tokens {
-		if t.UserID != nil && *t.UserID == userID && t.Subject == models.OneTimeTokenSubjectEmailVerification {
-			_ = s.authRepo.DeleteOneTimeToken(ctx, t.ID)
-		}
+		_ = s.authRepo.DeleteOneTimeToken(ctx, t.ID)
 	}
 
 	// Prepare metadata and store the new token hash in the database
@@ -158,8 +157,9 @@ func (s *AuthService) InitiateEmailVerification(ctx context.Context, email strin
 }
 
 // ValidateEmailVerification checks if the provided token is valid.
-// It resolves the user from the stored one-time token, marks the user's email as verified,
-// deletes the one-time token (one-time use) and returns true on success.
+// It resolves the user from the stored one-time token, then deletes the one-time token
+// (one-time use) and marks the user's email as verified in one transaction. Returns true on
+// success.
 func (s *AuthService) ValidateEmailVerification(ctx context.Context, token string) (bool, error) {
 	if token == "" {
 		return false, errors.New("token is required")
@@ -185,13 +185,24 @@ func (s *AuthService) ValidateEmailVerification(ctx context.Context, token strin
 	}
 	userID := *oneTimeToken.UserID
 
-	// Delete the token after successful validation (one-time use)
-	_ = s.authRepo.DeleteOneTimeToken(ctx, oneTimeToken.ID)
+	// Consume the token and verify the email together, so a failure keeps the token usable
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Delete the token (one-time use); a concurrent verification already consumed it
+		// when it is gone
+		if err := s.authRepo.DeleteOneTimeToken(ctx, oneTimeToken.ID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("invalid or expired token")
+			}
+			return err
+		}
 
-	// Mark the user's email as verified
-	if err := s.userService.MarkEmailVerified(ctx, userID); err != nil {
+		// Mark the user's email as verified
+		return s.userService.MarkEmailVerified(ctx, userID)
+	})
+	if err != nil {
 		return false, err
 	}
+	s.audit(ctx, auditModels.ActionEmailVerified, userID, auditModels.TargetUser, userID, nil)
 
 	return true, nil
 }
@@ -227,15 +238,12 @@ func (s *AuthService) ResendEmailVerification(ctx context.Context, email string,
 	userID := user.ID
 
 	// Check for an existing valid token for this user/email
-	tokens, err := s.authRepo.FindAllOneTimeTokens(ctx)
+	tokens, err := s.authRepo.FindOneTimeTokensByUserSubject(ctx, userID, models.OneTimeTokenSubjectEmailVerification)
 	now := time.Now()
 	var existingToken *models.OneTimeToken
 	if err == nil {
 		for _, t := range tokens {
-			if t.UserID != nil && *t.UserID == userID &&
-				t.Subject == models.OneTimeTokenSubjectEmailVerification &&
-				t.RelatesTo == email &&
-				now.Before(t.ExpiresAt) {
+			if strings.EqualFold(t.RelatesTo, email) && now.Before(t.ExpiresAt) {
 				existingToken = t
 				break
 			}
@@ -254,9 +262,7 @@ func (s *AuthService) ResendEmailVerification(ctx context.Context, email string,
 
 	// If no valid token exists, revoke all old tokens and generate a new one
 	for _, t := range tokens {
-		if t.UserID != nil && *t.UserID == userID &&
-			t.Subject == models.OneTimeTokenSubjectEmailVerification &&
-			t.RelatesTo == email {
+		if strings.EqualFold(t.RelatesTo, email) {
 			_ = s.authRepo.DeleteOneTimeToken(ctx, t.ID)
 		}
 	}
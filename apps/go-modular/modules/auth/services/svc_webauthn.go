@@ -0,0 +1,379 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofrs/uuid/v5"
+
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+	user_models "go-modular/modules/user/models"
+	"go-modular/pkg/apputils"
+
+	auditModels "go-modular/modules/audit/models"
+)
+
+// ErrInvalidPasskeyChallenge is returned when a passkey ceremony challenge is unknown, expired or already used.
+var ErrInvalidPasskeyChallenge = errors.New("invalid or expired passkey challenge")
+
+// ErrInvalidPasskey is returned when a passkey response cannot be verified.
+var ErrInvalidPasskey = errors.New("invalid passkey")
+
+// ErrPasskeyNotFound is returned when a passkey does not exist or is not owned by the user.
+var ErrPasskeyNotFound = errors.New("passkey not found")
+
+// passkeyChallengeTTL is how long a registration or sign-in challenge stays valid.
+const passkeyChallengeTTL = 5 * time.Minute
+
+// passkeySignInRelatesTo is stored in one_time_tokens.relates_to for discoverable sign-in
+// challenges, which are not bound to a user until the assertion is verified.
+const passkeySignInRelatesTo = "passkey"
+
+// passkeyUser adapts a user and its stored credentials to the webauthn.User interface.
+// The WebAuthn user handle is the raw 16 bytes of the user UUID.
+type passkeyUser struct {
+	user        *user_models.User
+	credentials []*models.WebAuthnCredential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return u.user.ID.Bytes()
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	if u.user.Username != nil && *u.user.Username != "" {
+		return *u.user.Username
+	}
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.user.DisplayName != "" {
+		return u.user.DisplayName
+	}
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+		for _, t := range c.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+		creds = append(creds, webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				UserPresent:    true,
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    c.AAGUID,
+				SignCount: uint32(c.SignCount),
+			},
+		})
+	}
+	return creds
+}
+
+// loadPasskeyUser loads a user together with their registered passkeys.
+func (s *AuthService) loadPasskeyUser(ctx context.Context, userID uuid.UUID) (*passkeyUser, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	credentials, err := s.authRepo.ListWebAuthnCredentialsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &passkeyUser{user: user, credentials: credentials}, nil
+}
+
+// storePasskeyChallenge persists the ceremony session data in one_time_tokens and
+// returns the raw challenge ID the client must echo back when finishing the ceremony.
+func (s *AuthService) storePasskeyChallenge(
+	ctx context.Context,
+	userID *uuid.UUID,
+	subject models.OneTimeTokenSubject,
+	relatesTo string,
+	session *webauthn.SessionData,
+) (string, time.Time, error) {
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var sessionMap map[string]any
+	if err := json.Unmarshal(raw, &sessionMap); err != nil {
+		return "", time.Time{}, err
+	}
+
+	challengeID, err := apputils.GenerateURLSafeToken(48)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate challenge id: %w", err)
+	}
+	hash := sha256.Sum256([]byte(challengeID))
+
+	now := time.Now()
+	expiresAt := now.Add(passkeyChallengeTTL)
+	token := &models.OneTimeToken{
+		UserID:    userID,
+		Subject:   subject,
+		TokenHash: hex.EncodeToString(hash[:]),
+		RelatesTo: relatesTo,
+		Metadata:  map[string]any{"session": sessionMap},
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.authRepo.CreateOneTimeToken(ctx, token); err != nil {
+		return "", time.Time{}, err
+	}
+	return challengeID, expiresAt, nil
+}
+
+// consumePasskeyChallenge looks up and deletes (one-time use) a stored ceremony challenge
+// and returns its session data.
+func (s *AuthService) consumePasskeyChallenge(ctx context.Context, challengeID string, subject models.OneTimeTokenSubject) (*models.OneTimeToken, *webauthn.SessionData, error) {
+	if challengeID == "" {
+		return nil, nil, ErrInvalidPasskeyChallenge
+	}
+	hash := sha256.Sum256([]byte(challengeID))
+	token, err := s.authRepo.GetOneTimeTokenByTokenHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil || token == nil || token.Subject != subject {
+		return nil, nil, ErrInvalidPasskeyChallenge
+	}
+
+	// Delete the challenge before verifying so it can never be replayed; only the request
+	// whose delete removed it may use it
+	if err := s.authRepo.DeleteOneTimeToken(ctx, token.ID); err != nil {
+		return nil, nil, ErrInvalidPasskeyChallenge
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, nil, ErrInvalidPasskeyChallenge
+	}
+
+	raw, err := json.Marshal(token.Metadata["session"])
+	if err != nil {
+		return nil, nil, ErrInvalidPasskeyChallenge
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal(raw, &session); err != nil || session.Challenge == "" {
+		return nil, nil, ErrInvalidPasskeyChallenge
+	}
+	return token, &session, nil
+}
+
+// BeginPasskeyRegistration starts a WebAuthn registration ceremony for the user.
+// Existing passkeys are excluded so the same authenticator cannot be registered twice.
+func (s *AuthService) BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*models.PasskeyChallengeResponse, error) {
+	pu, err := s.loadPasskeyUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(pu.credentials))
+	for _, c := range pu.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(pu,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one pending registration per user (unique user_id + subject)
+	if err := s.authRepo.DeleteOneTimeTokensByUserSubject(ctx, userID, models.OneTimeTokenSubjectPasskeyRegister); err != nil {
+		return nil, err
+	}
+
+	challengeID, expiresAt, err := s.storePasskeyChallenge(ctx, &userID, models.OneTimeTokenSubjectPasskeyRegister, pu.user.Email, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyChallengeResponse{
+		ChallengeID: challengeID,
+		Options:     creation,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// FinishPasskeyRegistration verifies the attestation response and stores the new passkey.
+func (s *AuthService) FinishPasskeyRegistration(ctx context.Context, userID uuid.UUID, challengeID, friendlyName string, credential []byte) (*models.WebAuthnCredential, error) {
+	token, session, err := s.consumePasskeyChallenge(ctx, challengeID, models.OneTimeTokenSubjectPasskeyRegister)
+	if err != nil {
+		return nil, err
+	}
+	if token.UserID == nil || *token.UserID != userID {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(credential)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+
+	pu, err := s.loadPasskeyUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := s.webAuthn.CreateCredential(pu, *session, parsed)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+
+	transports := make([]string, 0, len(cred.Transport))
+	for _, t := range cred.Transport {
+		transports = append(transports, string(t))
+	}
+	passkey := &models.WebAuthnCredential{
+		UserID:          userID,
+		CredentialID:    cred.ID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       int64(cred.Authenticator.SignCount),
+		Transports:      transports,
+		BackupEligible:  cred.Flags.BackupEligible,
+		BackupState:     cred.Flags.BackupState,
+		FriendlyName:    friendlyName,
+	}
+	if passkey.AttestationType == "" {
+		passkey.AttestationType = "none"
+	}
+	if err := s.authRepo.CreateWebAuthnCredential(ctx, passkey); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, auditModels.ActionPasskeyRegistered, uuid.Nil, auditModels.TargetPasskey, passkey.ID, map[string]any{"friendly_name": friendlyName})
+	return passkey, nil
+}
+
+// ListPasskeys returns the passkeys registered by the user.
+func (s *AuthService) ListPasskeys(ctx context.Context, userID uuid.UUID) ([]*models.WebAuthnCredential, error) {
+	return s.authRepo.ListWebAuthnCredentialsByUserID(ctx, userID)
+}
+
+// DeletePasskey removes one of the user's passkeys.
+func (s *AuthService) DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error {
+	err := s.authRepo.DeleteWebAuthnCredential(ctx, userID, passkeyID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPasskeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.audit(ctx, auditModels.ActionPasskeyDeleted, uuid.Nil, auditModels.TargetPasskey, passkeyID, nil)
+	return nil
+}
+
+// BeginPasskeySignIn starts a discoverable (usernameless) WebAuthn assertion ceremony.
+func (s *AuthService) BeginPasskeySignIn(ctx context.Context) (*models.PasskeyChallengeResponse, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationPreferred),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeID, expiresAt, err := s.storePasskeyChallenge(ctx, nil, models.OneTimeTokenSubjectPasskeySignIn, passkeySignInRelatesTo, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyChallengeResponse{
+		ChallengeID: challengeID,
+		Options:     assertion,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// SignInWithPasskey verifies a passkey assertion and issues a session and tokens
+// the same way password sign-in does.
+func (s *AuthService) SignInWithPasskey(ctx context.Context, challengeID string, credential []byte, rememberMe bool) (*models.AuthenticatedUser, error) {
+	_, session, err := s.consumePasskeyChallenge(ctx, challengeID, models.OneTimeTokenSubjectPasskeySignIn)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return nil, ErrInvalidPasskey
+	}
+
+	// Resolve the user from the user handle returned by the authenticator
+	var resolved *passkeyUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := uuid.FromBytes(userHandle)
+		if err != nil {
+			return nil, ErrInvalidPasskey
+		}
+		pu, err := s.loadPasskeyUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		resolved = pu
+		return pu, nil
+	}
+
+	_, cred, err := s.webAuthn.ValidatePasskeyLogin(handler, *session, parsed)
+	if err != nil || resolved == nil {
+		userID := uuid.Nil
+		if resolved != nil {
+			userID = resolved.user.ID
+		}
+		s.auditSignInFailed(ctx, userID, "", "invalid_passkey")
+		return nil, ErrInvalidCredentials
+	}
+
+	// A signature counter that did not increase indicates a possibly cloned authenticator
+	if cred.Authenticator.CloneWarning {
+		s.auditSignInFailed(ctx, resolved.user.ID, "", "passkey_clone_warning")
+		return nil, ErrInvalidCredentials
+	}
+
+	var stored *models.WebAuthnCredential
+	for _, c := range resolved.credentials {
+		if bytes.Equal(c.CredentialID, cred.ID) {
+			stored = c
+			break
+		}
+	}
+	if stored == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if resolved.user.GetEmailVerifiedAt() == nil {
+		s.auditSignInFailed(ctx, resolved.user.ID, "", "email_not_verified")
+		return nil, ErrEmailNotVerified
+	}
+
+	if err := s.authRepo.UpdateWebAuthnCredentialUsage(ctx, stored.CredentialID, int64(cred.Authenticator.SignCount), cred.Flags.BackupState, time.Now()); err != nil {
+		return nil, err
+	}
+
+	authUser, err := s.issueAuthenticatedSession(ctx, resolved.user, rememberMe, "passkey")
+	if err != nil {
+		return nil, err
+	}
+	s.auditSignInSucceeded(ctx, authUser, "passkey")
+	return authUser, nil
+}
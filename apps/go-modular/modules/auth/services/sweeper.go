@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"go-modular/internal/observer/metrics"
+	"go-modular/modules/auth/models"
+	"go-modular/modules/auth/repository"
+)
+
+// TokenSweeper periodically deletes expired one-time tokens, refresh tokens and sessions.
+type TokenSweeper struct {
+	authRepo  repository.AuthRepositoryInterface
+	logger    *slog.Logger
+	interval  time.Duration
+	batchSize int
+}
+
+type TokenSweeperOpts struct {
+	AuthRepo  repository.AuthRepositoryInterface
+	Logger    *slog.Logger  // Slog logger instance (optional)
+	Interval  time.Duration // Time between sweeps (default: 15m)
+	BatchSize int           // Maximum rows deleted per statement (default: 1000)
+}
+
+// NewTokenSweeper creates a new TokenSweeper.
+func NewTokenSweeper(opts TokenSweeperOpts) *TokenSweeper {
+	if opts.AuthRepo == nil {
+		panic("AuthRepo is required")
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Minute
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	return &TokenSweeper{
+		authRepo:  opts.AuthRepo,
+		logger:    opts.Logger,
+		interval:  opts.Interval,
+		batchSize: opts.BatchSize,
+	}
+}
+
+// Run sweeps immediately and then on every interval until ctx is cancelled.
+func (s *TokenSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("token sweep failed", "op", "TokenSweeper.Run", "error", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes all rows that expired before now, batch by batch, and records
+// the number of pruned rows per table.
+func (s *TokenSweeper) Sweep(ctx context.Context) (*models.PruneResult, error) {
+	now := time.Now()
+	result := &models.PruneResult{}
+
+	// Refresh tokens go before sessions, expired sessions cascade to their refresh tokens anyway.
+	steps := []struct {
+		table string
+		count *int64
+		fn    func(context.Context, time.Time, int) (int64, error)
+	}{
+		{models.OneTimeTokenTable, &result.OneTimeTokens, s.authRepo.DeleteExpiredOneTimeTokens},
+		{models.RefreshTokenTable, &result.RefreshTokens, s.authRepo.DeleteExpiredRefreshTokens},
+		{models.SessionTable, &result.Sessions, s.authRepo.DeleteExpiredSessions},
+	}
+	for _, step := range steps {
+		err := s.pruneTable(ctx, now, step.fn, step.count)
+		metrics.RecordPrunedRows(ctx, step.table, *step.count)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if result.Total() > 0 {
+		s.logger.Info("expired tokens pruned", "op", "TokenSweeper.Sweep",
+			"one_time_tokens", result.OneTimeTokens,
+			"refresh_tokens", result.RefreshTokens,
+			"sessions", result.Sessions,
+		)
+	}
+	return result, nil
+}
+
+// pruneTable repeatedly deletes batches until a batch comes back short.
+func (s *TokenSweeper) pruneTable(ctx context.Context, before time.Time, fn func(context.Context, time.Time, int) (int64, error), count *int64) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := fn(ctx, before, s.batchSize)
+		*count += n
+		if err != nil {
+			return err
+		}
+		if n < int64(s.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go-modular/modules/organization/models"
+	"go-modular/modules/organization/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// HandlerInterface defines the contract for organization handlers.
+type HandlerInterface interface {
+	CreateOrganization(c echo.Context) error
+	ListOrganizations(c echo.Context) error
+	GetOrganization(c echo.Context) error
+	GetCurrentOrganization(c echo.Context) error
+	UpdateOrganization(c echo.Context) error
+	DeleteOrganization(c echo.Context) error
+	ListMembers(c echo.Context) error
+	UpdateMemberRole(c echo.Context) error
+	RemoveMember(c echo.Context) error
+	CreateInvitation(c echo.Context) error
+	ListInvitations(c echo.Context) error
+	RevokeInvitation(c echo.Context) error
+	AcceptInvitation(c echo.Context) error
+}
+
+// Ensure Handler implements HandlerInterface
+var _ HandlerInterface = (*Handler)(nil)
+
+// Handler holds dependencies for organization handlers.
+type Handler struct {
+	logger     *slog.Logger
+	orgService services.OrganizationServiceInterface
+	validator  *validator.Validate
+}
+
+type HandlerOpts struct {
+	Logger     *slog.Logger
+	OrgService services.OrganizationServiceInterface
+}
+
+// NewHandler creates a new Handler instance.
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:     opts.Logger,
+		orgService: opts.OrgService,
+		validator:  validator.New(),
+	}
+}
+
+// currentUserID returns the authenticated user ID set by the JWT middleware.
+func currentUserID(c echo.Context) (uuid.UUID, bool) {
+	v := c.Get("user_id")
+	if v == nil {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromString(fmt.Sprint(v))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseUUIDParam parses a UUID path parameter.
+func parseUUIDParam(c echo.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.FromString(c.Param(name))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondServiceError maps organization service errors to HTTP responses.
+func (h *Handler) respondServiceError(c echo.Context, op string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound), errors.Is(err, services.ErrNotMember):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, services.ErrInvitationEmailMismatch):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrLastOwner), errors.Is(err, services.ErrAlreadyMember):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidInvitation), errors.Is(err, services.ErrInvalidRole):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	h.logger.Error("Failed to "+op, slog.String("error", err.Error()))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to " + op + ", please try again later"})
+}
+
+// @Summary      Create organization
+// @Description  Creates a new organization owned by the authenticated user
+// @Tags         Organizations
+// @Security     BearerAuth
+// @Param        Authorization  header    string                              true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateOrganizationRequest  true  "Organization payload"
+// @Success      201      {object}  models.Organization
+// @Failure      400      {object}  map[string]string
+// @Router       /api/v1/organizations [post]
+func (h *Handler) CreateOrganization(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	var req models.CreateOrganizationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	org, err := h.orgService.CreateOrganization(c.Request().Context(), userID, req.Name)
+	if err != nil {
+		return h.respondServiceError(c, "create organization", err)
+	}
+	return c.JSON(http.StatusCreated, org)
+}
+
+// @Summary      List organizations
+// @Description  Lists the organizations the authenticated user belongs to, with the user's role
+// @Tags         Organizations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {array}   models.Membership
+// @Router       /api/v1/organizations [get]
+func (h *Handler) ListOrganizations(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	memberships, err := h.orgService.ListUserOrganizations(c.Request().Context(), userID)
+	if err != nil {
+		return h.respondServiceError(c, "list organizations", err)
+	}
+	return c.JSON(http.StatusOK, memberships)
+}
+
+// @Summary      Get organization
+// @Description  Retrieves an organization the authenticated user belongs to
+// @Tags         Organizations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {object}  models.Membership
+// @Failure      404    {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId [get]
+func (h *Handler) GetOrganization(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	membership, err := h.orgService.GetOrganization(c.Request().Context(), userID, orgID)
+	if err != nil {
+		return h.respondServiceError(c, "get organization", err)
+	}
+	return c.JSON(http.StatusOK, membership)
+}
+
+// @Summary      Get active organization
+// @Description  Retrieves the organization selected by the org_id claim of the access token
+// @Tags         Organizations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  models.Membership
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /api/v1/organizations/current [get]
+func (h *Handler) GetCurrentOrganization(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := c.Get("org_id").(uuid.UUID)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "No active organization"})
+	}
+	membership, err := h.orgService.GetOrganization(c.Request().Context(), userID, orgID)
+	if err != nil {
+		return h.respondServiceError(c, "get organization", err)
+	}
+	return c.JSON(http.StatusOK, membership)
+}
+
+// @Summary      Update organization
+// @Description  Renames an organization (requires admin role)
+// @Tags         Organizations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        orgId    path      string                            true  "Organization ID"
+// @Param        request  body      models.UpdateOrganizationRequest  true  "Organization payload"
+// @Success      200      {object}  models.Organization
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId [put]
+func (h *Handler) UpdateOrganization(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	var req models.UpdateOrganizationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	org, err := h.orgService.UpdateOrganization(c.Request().Context(), userID, orgID, req.Name)
+	if err != nil {
+		return h.respondServiceError(c, "update organization", err)
+	}
+	return c.JSON(http.StatusOK, org)
+}
+
+// @Summary      Delete organization
+// @Description  Deletes an organization with its memberships and invitations (requires owner role)
+// @Tags         Organizations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {object}  map[string]string
+// @Failure      403    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId [delete]
+func (h *Handler) DeleteOrganization(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	if err := h.orgService.DeleteOrganization(c.Request().Context(), userID, orgID); err != nil {
+		return h.respondServiceError(c, "delete organization", err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Organization deleted successfully"})
+}
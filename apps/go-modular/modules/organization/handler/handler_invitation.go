@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-modular/modules/organization/models"
+	"go-modular/pkg/apputils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary      Invite member
+// @Description  Emails an invitation to join the organization (requires admin role; inviting owners requires owner)
+// @Tags         Organizations - Invitations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        orgId    path      string                          true  "Organization ID"
+// @Param        request  body      models.CreateInvitationRequest  true  "Invitation payload"
+// @Success      201      {object}  models.Invitation
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId/invitations [post]
+func (h *Handler) CreateInvitation(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	var req models.CreateInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	invitation, err := h.orgService.InviteMember(c.Request().Context(), userID, orgID, req.Email, req.Role)
+	if err != nil {
+		return h.respondServiceError(c, "send invitation", err)
+	}
+	return c.JSON(http.StatusCreated, invitation)
+}
+
+// @Summary      List invitations
+// @Description  Lists the pending invitations of an organization (requires admin role)
+// @Tags         Organizations - Invitations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {array}   models.Invitation
+// @Failure      403    {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId/invitations [get]
+func (h *Handler) ListInvitations(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	invitations, err := h.orgService.ListInvitations(c.Request().Context(), userID, orgID)
+	if err != nil {
+		return h.respondServiceError(c, "list invitations", err)
+	}
+	return c.JSON(http.StatusOK, invitations)
+}
+
+// @Summary      Revoke invitation
+// @Description  Deletes a pending invitation (requires admin role)
+// @Tags         Organizations - Invitations
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        orgId         path      string  true  "Organization ID"
+// @Param        invitationId  path      string  true  "Invitation ID"
+// @Success      200           {object}  map[string]string
+// @Failure      400           {object}  map[string]string
+// @Failure      403           {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId/invitations/:invitationId [delete]
+func (h *Handler) RevokeInvitation(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	invitationID, ok := parseUUIDParam(c, "invitationId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invitation ID in path must be a valid UUID"})
+	}
+	if err := h.orgService.RevokeInvitation(c.Request().Context(), userID, orgID, invitationID); err != nil {
+		return h.respondServiceError(c, "revoke invitation", err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Invitation revoked successfully"})
+}
+
+// @Summary      Accept invitation
+// @Description  Joins the organization of the invitation. The authenticated user's email must match the invited email.
+// @Description  The token can be sent in the body or as the token query parameter of the emailed link.
+// @Tags         Organizations - Invitations
+// @Security     BearerAuth
+// @Param        Authorization  header    string                          true   "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        token    query     string                          false  "Invitation token"
+// @Param        request  body      models.AcceptInvitationRequest  false  "Invitation token payload"
+// @Success      200      {object}  models.Membership
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Router       /api/v1/organizations/invitations/accept [post]
+func (h *Handler) AcceptInvitation(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	var req models.AcceptInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if req.Token == "" {
+		req.Token = c.QueryParam("token")
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	membership, err := h.orgService.AcceptInvitation(c.Request().Context(), userID, req.Token)
+	if err != nil {
+		return h.respondServiceError(c, "accept invitation", err)
+	}
+	return c.JSON(http.StatusOK, membership)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-modular/modules/organization/models"
+	"go-modular/pkg/apputils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary      List organization members
+// @Description  Lists the members of an organization with their roles
+// @Tags         Organizations - Members
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        orgId  path      string  true  "Organization ID"
+// @Success      200    {array}   models.Member
+// @Failure      404    {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId/members [get]
+func (h *Handler) ListMembers(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	members, err := h.orgService.ListMembers(c.Request().Context(), userID, orgID)
+	if err != nil {
+		return h.respondServiceError(c, "list organization members", err)
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+// @Summary      Update member role
+// @Description  Changes the role of an organization member (requires admin role; owner role changes require owner)
+// @Tags         Organizations - Members
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        orgId    path      string                          true  "Organization ID"
+// @Param        userId   path      string                          true  "Member user ID"
+// @Param        request  body      models.UpdateMemberRoleRequest  true  "Role payload"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId/members/:userId [put]
+func (h *Handler) UpdateMemberRole(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	memberID, ok := parseUUIDParam(c, "userId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User ID in path must be a valid UUID"})
+	}
+	var req models.UpdateMemberRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	if err := h.orgService.UpdateMemberRole(c.Request().Context(), userID, orgID, memberID, req.Role); err != nil {
+		return h.respondServiceError(c, "update member role", err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Member role updated successfully"})
+}
+
+// @Summary      Remove member
+// @Description  Removes a member from an organization. Members can remove themselves (leave); removing others requires admin role
+// @Tags         Organizations - Members
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        orgId   path      string  true  "Organization ID"
+// @Param        userId  path      string  true  "Member user ID"
+// @Success      200     {object}  map[string]string
+// @Failure      403     {object}  map[string]string
+// @Failure      409     {object}  map[string]string
+// @Router       /api/v1/organizations/:orgId/members/:userId [delete]
+func (h *Handler) RemoveMember(c echo.Context) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+	orgID, ok := parseUUIDParam(c, "orgId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Organization ID in path must be a valid UUID"})
+	}
+	memberID, ok := parseUUIDParam(c, "userId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User ID in path must be a valid UUID"})
+	}
+	if err := h.orgService.RemoveMember(c.Request().Context(), userID, orgID, memberID); err != nil {
+		return h.respondServiceError(c, "remove organization member", err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Member removed successfully"})
+}
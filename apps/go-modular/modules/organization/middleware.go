@@ -0,0 +1,107 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-modular/modules/organization/models"
+	"go-modular/modules/organization/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// ScopeMiddleware scopes a request to the active organization taken from the org_id claim
+// of the access token. It must run after the JWT middleware. The caller's membership is
+// verified on every request so removed members lose access before their token expires.
+// On success it stores "org_id" (uuid.UUID) and "org_role" (models.Role) in echo.Context
+// and the organization ID in the request context under apputils.OrgIDContextKey.
+func ScopeMiddleware(orgService services.OrganizationServiceInterface) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, _ := c.Get("jwt_claims").(map[string]any)
+			raw, ok := claims["org_id"]
+			if !ok || fmt.Sprint(raw) == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "no active organization")
+			}
+			orgID, err := uuid.FromString(fmt.Sprint(raw))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid org_id claim")
+			}
+			if err := scope(c, orgService, orgID); err != nil {
+				if errors.Is(err, services.ErrNotMember) {
+					return echo.NewHTTPError(http.StatusForbidden, "not a member of the active organization")
+				}
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// PathScopeMiddleware scopes a request to the organization in the path parameter param, e.g.
+// "orgId" for /organizations/:orgId routes, like ScopeMiddleware does for the active
+// organization. Non-members get 404 so the existence of other organizations is not revealed.
+func PathScopeMiddleware(orgService services.OrganizationServiceInterface, param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			orgID, err := uuid.FromString(c.Param(param))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Organization ID in path must be a valid UUID")
+			}
+			if err := scope(c, orgService, orgID); err != nil {
+				if errors.Is(err, services.ErrNotMember) {
+					return echo.NewHTTPError(http.StatusNotFound, err.Error())
+				}
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// scope verifies that the authenticated user is a member of orgID and stores the organization
+// and role of the request. services.ErrNotMember is returned for non-members.
+func scope(c echo.Context, orgService services.OrganizationServiceInterface, orgID uuid.UUID) error {
+	userID, err := uuid.FromString(fmt.Sprint(c.Get("user_id")))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "missing user in token")
+	}
+
+	member, err := orgService.GetMembership(c.Request().Context(), orgID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotMember) {
+			return err
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify organization membership")
+	}
+
+	c.Set("org_id", orgID)
+	c.Set("org_role", member.Role)
+	ctx := context.WithValue(c.Request().Context(), apputils.OrgIDContextKey, orgID)
+	c.SetRequest(c.Request().WithContext(ctx))
+	return nil
+}
+
+// RequireRole rejects requests whose active organization role (set by ScopeMiddleware)
+// is below the given role.
+func RequireRole(role models.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			current, ok := c.Get("org_role").(models.Role)
+			if !ok || !current.AtLeast(role) {
+				return echo.NewHTTPError(http.StatusForbidden, "insufficient organization role")
+			}
+			return next(c)
+		}
+	}
+}
+
+// GetOrganizationID returns the active organization ID set by ScopeMiddleware.
+func GetOrganizationID(c echo.Context) (uuid.UUID, bool) {
+	id, ok := c.Get("org_id").(uuid.UUID)
+	return id, ok
+}
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create organizations and organization_members tables and indexes
+-- Organizations (workspaces) group users of the same company. Membership roles:
+-- owner (full control), admin (manage members and invitations), member.
+-- Pending invitations are kept in public.one_time_tokens (subject org_invitation).
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.organizations (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    name TEXT NOT NULL CHECK (char_length(name) > 0),
+    slug TEXT NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9][a-z0-9-]*$'),
+    created_by UUID REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.organization_members (
+    organization_id UUID NOT NULL REFERENCES public.organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- Organizations table indexes and updated_at trigger
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_slug ON public.organizations (slug);
+CREATE INDEX IF NOT EXISTS idx_organizations_created_at ON public.organizations (created_at);
+CREATE TRIGGER trg_organizations_updated_at BEFORE UPDATE ON public.organizations FOR EACH ROW EXECUTE FUNCTION fn_updated_at_value();
+
+-- Organization members table indexes and updated_at trigger
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON public.organization_members (user_id);
+CREATE INDEX IF NOT EXISTS idx_organization_members_organization_id_role ON public.organization_members (organization_id, role);
+CREATE TRIGGER trg_organization_members_updated_at BEFORE UPDATE ON public.organization_members FOR EACH ROW EXECUTE FUNCTION fn_updated_at_value();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop triggers, indexes, and table(s) (reverse order of creation)
+DELETE FROM public.one_time_tokens WHERE subject = 'org_invitation';
+DROP TRIGGER IF EXISTS trg_organization_members_updated_at ON public.organization_members;
+DROP INDEX IF EXISTS idx_organization_members_organization_id_role;
+DROP INDEX IF EXISTS idx_organization_members_user_id;
+DROP TRIGGER IF EXISTS trg_organizations_updated_at ON public.organizations;
+DROP INDEX IF EXISTS idx_organizations_created_at;
+DROP INDEX IF EXISTS idx_organizations_slug;
+DROP TABLE IF EXISTS public.organization_members;
+DROP TABLE IF EXISTS public.organizations;
+
+-- +goose StatementEnd
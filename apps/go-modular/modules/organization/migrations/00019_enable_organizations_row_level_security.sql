@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Row-level security for organization-scoped (tenant) transactions
+-- Organization routes run in tenant transactions as app_tenant (see migration
+-- 00011). The role may only use the tables below, each filtered by a policy on
+-- the active organization (app.tenant_id) and the current user (app.user_id).
+-- Policies are dropped first, as databases may already have them from an
+-- earlier revision of 00011.
+-- ============================================================================
+GRANT SELECT, INSERT, UPDATE, DELETE ON public.organizations, public.organization_members, public.users, public.one_time_tokens TO app_tenant;
+
+-- Organizations: only the active organization is visible
+ALTER TABLE public.organizations ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.organizations;
+CREATE POLICY tenant_isolation ON public.organizations TO app_tenant
+    USING (id = app_current_tenant_id())
+    WITH CHECK (id = app_current_tenant_id());
+
+-- Organization members: only memberships of the active organization are visible
+ALTER TABLE public.organization_members ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.organization_members;
+CREATE POLICY tenant_isolation ON public.organization_members TO app_tenant
+    USING (organization_id = app_current_tenant_id())
+    WITH CHECK (organization_id = app_current_tenant_id());
+
+-- Users: the current user and members of the active organization are visible
+ALTER TABLE public.users ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.users;
+CREATE POLICY tenant_isolation ON public.users TO app_tenant
+    USING (
+        id = app_current_user_id()
+        OR EXISTS (
+            SELECT 1 FROM public.organization_members m
+            WHERE m.user_id = users.id AND m.organization_id = app_current_tenant_id()
+        )
+    );
+
+-- Invitations (one-time tokens with subject org_invitation) of the active organization only;
+-- other one-time tokens are bound to the current user
+ALTER TABLE public.one_time_tokens ENABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.one_time_tokens;
+CREATE POLICY tenant_isolation ON public.one_time_tokens TO app_tenant
+    USING (
+        CASE WHEN subject = 'org_invitation'
+            THEN metadata ->> 'organization_id' = app_current_tenant_id()::text
+            ELSE user_id = app_current_user_id()
+        END
+    );
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop policies, disable RLS and revoke privileges (reverse order of creation)
+DROP POLICY IF EXISTS tenant_isolation ON public.one_time_tokens;
+ALTER TABLE public.one_time_tokens DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.users;
+ALTER TABLE public.users DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.organization_members;
+ALTER TABLE public.organization_members DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON public.organizations;
+ALTER TABLE public.organizations DISABLE ROW LEVEL SECURITY;
+REVOKE SELECT, INSERT, UPDATE, DELETE ON public.organizations, public.organization_members, public.users, public.one_time_tokens FROM app_tenant;
+
+-- +goose StatementEnd
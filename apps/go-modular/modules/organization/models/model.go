@@ -0,0 +1,102 @@
+// Package models contains struct definitions related to the database layer for the organization module.
+// This file defines models that map to database tables and are used for database operations.
+
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// -- MARK: Organization section
+
+// Define table name for Organization model
+const OrganizationTable = "public.organizations"
+
+// Organization represents an organization (workspace) in the database
+type Organization struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Slug      string     `json:"slug" db:"slug"`
+	CreatedBy *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// -- MARK: Member section
+
+// Define table name for Member model
+const MemberTable = "public.organization_members"
+
+// Role is the role of a user within an organization
+type Role string
+
+const (
+	RoleOwner  Role = "owner"  // Full control, including deleting the organization
+	RoleAdmin  Role = "admin"  // Manage organization settings, members and invitations
+	RoleMember Role = "member" // Regular member
+)
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	return r.rank() > 0
+}
+
+// AtLeast reports whether r grants at least the permissions of other
+func (r Role) AtLeast(other Role) bool {
+	return r.rank() >= other.rank() && r.IsValid()
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleAdmin:
+		return 2
+	case RoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Member represents a user's membership in an organization
+type Member struct {
+	OrganizationID uuid.UUID  `json:"organization_id" db:"organization_id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Role           Role       `json:"role" db:"role"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at" db:"updated_at"`
+
+	// Populated when listing members (joined from users)
+	Email       string `json:"email,omitempty" db:"-"`
+	DisplayName string `json:"display_name,omitempty" db:"-"`
+}
+
+// Membership is an organization together with the role of the requesting user
+type Membership struct {
+	Organization
+	Role     Role      `json:"role" db:"role"`
+	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// -- MARK: Invitation section
+
+// Invitations are stored in the shared one-time token table with a dedicated subject.
+// relates_to holds the invitee email and metadata holds the organization, role and inviter.
+const (
+	InvitationTable   = "public.one_time_tokens"
+	InvitationSubject = "org_invitation"
+)
+
+// Invitation represents a pending invitation to join an organization
+type Invitation struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Email          string     `json:"email"`
+	Role           Role       `json:"role"`
+	InvitedBy      *uuid.UUID `json:"invited_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+}
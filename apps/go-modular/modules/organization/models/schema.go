@@ -0,0 +1,25 @@
+// Package models contains HTTP request/response schema definitions for the organization module.
+// This file defines struct types used for HTTP payloads, validation, and OpenAPI documentation.
+
+package models
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100" example:"Acme Inc."`
+}
+
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=100" example:"Acme Inc."`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=owner admin member" example:"admin"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email" example:"jane@example.com"`
+	Role  Role   `json:"role" validate:"required,oneof=owner admin member" example:"member"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required" example:"invitation-token"`
+}
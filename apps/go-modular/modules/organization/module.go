@@ -0,0 +1,181 @@
+package organization
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"go-modular/internal/middleware"
+	"go-modular/internal/module"
+	"go-modular/internal/notification"
+	"go-modular/internal/personaldata"
+	"go-modular/modules/organization/handler"
+	"go-modular/modules/organization/repository"
+	"go-modular/modules/organization/services"
+
+	auditSvc "go-modular/modules/audit/services"
+	modAuth "go-modular/modules/auth"
+	svcUser "go-modular/modules/user/services"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func init() {
+	module.Register(&OrganizationModule{})
+}
+
+type Options struct {
+	PgPool      *pgxpool.Pool                // PostgreSQL connection pool (required)
+	Logger      *slog.Logger                 // Slog logger instance (optional)
+	UserService svcUser.UserServiceInterface // User service (required)
+	Mailer      *notification.Mailer         // Mailer for invitation emails (optional)
+	BaseURL     string                       // Base URL used for invitation links (required)
+
+	// InvitationURL overrides the invitation link target, e.g. a frontend page (optional)
+	InvitationURL string
+
+	// Auditor records security audit events (optional)
+	Auditor auditSvc.Recorder
+
+	// TenantTx runs organization-scoped routes in a tenant-scoped (row-level security)
+	// transaction, e.g. middleware.TenantTxMiddleware (optional)
+	TenantTx echo.MiddlewareFunc
+
+	// DataExports receives the module's personal data exporter (optional)
+	DataExports *personaldata.Registry
+}
+
+// OrganizationModule holds dependencies for organization-related handlers.
+type OrganizationModule struct {
+	app         *module.App // set when initialized by the module registry
+	logger      *slog.Logger
+	middlewares []echo.MiddlewareFunc
+	tenantTx    echo.MiddlewareFunc
+	handler     *handler.Handler
+	orgService  services.OrganizationServiceInterface
+}
+
+// NewModule creates a new OrganizationModule.
+func NewModule(opts *Options) *OrganizationModule {
+	if opts.PgPool == nil || opts.UserService == nil {
+		panic("invalid organization module options: PgPool and UserService are required")
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+
+	orgService := services.NewOrganizationService(services.OrganizationServiceOpts{
+		OrgRepo:       repository.NewOrganizationRepository(opts.PgPool, logger),
+		UserService:   opts.UserService,
+		Mailer:        opts.Mailer,
+		BaseURL:       opts.BaseURL,
+		InvitationURL: opts.InvitationURL,
+		Auditor:       opts.Auditor,
+	})
+
+	if opts.DataExports != nil {
+		opts.DataExports.Register(personaldata.NewExporter("organization", orgService.ExportPersonalData))
+	}
+
+	h := handler.NewHandler(&handler.HandlerOpts{
+		Logger:     logger,
+		OrgService: orgService,
+	})
+
+	return &OrganizationModule{
+		logger:     logger,
+		tenantTx:   opts.TenantTx,
+		handler:    h,
+		orgService: orgService,
+	}
+}
+
+// Ensure OrganizationModule implements module.Module
+var _ module.Module = (*OrganizationModule)(nil)
+
+func (m *OrganizationModule) Name() string { return "organization" }
+
+func (m *OrganizationModule) Dependencies() []string { return []string{"audit", "user"} }
+
+// Init builds the module and provides its OrganizationServiceInterface. Scoped routes run
+// under row-level security.
+func (m *OrganizationModule) Init(_ context.Context, app *module.App) error {
+	*m = *NewModule(&Options{
+		PgPool:      app.DB.Pool,
+		Logger:      app.Logger,
+		UserService: module.MustLookup[svcUser.UserServiceInterface](app),
+		Mailer:      app.Mailer,
+		BaseURL:     app.Config.GetAppBaseURL(),
+		Auditor:     module.MustLookup[auditSvc.Recorder](app),
+		TenantTx:    middleware.TenantTxMiddleware(app.DB, app.Logger),
+		DataExports: app.DataExports,
+	})
+	m.app = app
+	module.Provide(app, m.orgService)
+	return nil
+}
+
+func (m *OrganizationModule) Start(context.Context) error { return nil }
+
+func (m *OrganizationModule) Stop(context.Context) error { return nil }
+
+func (m *OrganizationModule) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	return sub
+}
+
+func (m *OrganizationModule) Health() []health.Check { return nil }
+
+// Expose OrganizationService, so it can be used by other modules
+func (m *OrganizationModule) GetOrganizationService() services.OrganizationServiceInterface {
+	return m.orgService
+}
+
+// ScopeMiddleware returns middleware that scopes requests to the active organization
+// of the access token. Other modules can use it for organization-scoped routes.
+func (m *OrganizationModule) ScopeMiddleware() echo.MiddlewareFunc {
+	return ScopeMiddleware(m.orgService)
+}
+
+// Use adds middleware(s) to the OrganizationModule (grouped).
+func (m *OrganizationModule) Use(mw ...echo.MiddlewareFunc) {
+	m.middlewares = append(m.middlewares, mw...)
+}
+
+// RegisterRoutes registers organization endpoints to the given Echo group.
+func (m *OrganizationModule) RegisterRoutes(e *echo.Group) {
+	// Modules initialized by the registry authenticate with the auth module
+	if m.app != nil {
+		m.Use(module.MustLookup[*modAuth.AuthModule](m.app).JWTMiddleware())
+	}
+	g := e.Group("/organizations", m.middlewares...)
+	g.POST("", m.handler.CreateOrganization)
+	g.GET("", m.handler.ListOrganizations)
+	// Scoped routes run in a tenant transaction of the organization they are scoped to
+	scoped := []echo.MiddlewareFunc{m.ScopeMiddleware()}
+	orgScoped := []echo.MiddlewareFunc{PathScopeMiddleware(m.orgService, "orgId")}
+	if m.tenantTx != nil {
+		scoped = append(scoped, m.tenantTx)
+		orgScoped = append(orgScoped, m.tenantTx)
+	}
+	g.GET("/current", m.handler.GetCurrentOrganization, scoped...)
+	g.POST("/invitations/accept", m.handler.AcceptInvitation)
+	g.GET("/:orgId", m.handler.GetOrganization, orgScoped...)
+	g.PUT("/:orgId", m.handler.UpdateOrganization, orgScoped...)
+	g.DELETE("/:orgId", m.handler.DeleteOrganization, orgScoped...)
+	g.GET("/:orgId/members", m.handler.ListMembers, orgScoped...)
+	g.PUT("/:orgId/members/:userId", m.handler.UpdateMemberRole, orgScoped...)
+	g.DELETE("/:orgId/members/:userId", m.handler.RemoveMember, orgScoped...)
+	g.POST("/:orgId/invitations", m.handler.CreateInvitation, orgScoped...)
+	g.GET("/:orgId/invitations", m.handler.ListInvitations, orgScoped...)
+	g.DELETE("/:orgId/invitations/:invitationId", m.handler.RevokeInvitation, orgScoped...)
+}
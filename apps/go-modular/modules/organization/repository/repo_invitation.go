@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go-modular/modules/organization/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// invitationMetadata is the JSONB payload stored with an invitation token.
+type invitationMetadata struct {
+	OrganizationID string `json:"organization_id"`
+	Role           string `json:"role"`
+	InvitedBy      string `json:"invited_by,omitempty"`
+}
+
+// CreateInvitation stores a pending invitation as a one-time token. Any previous pending
+// invitation of the same email to the same organization is replaced.
+func (r *OrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation, tokenHash string) error {
+	if invitation.ID == uuid.Nil {
+		invitation.ID = uuid.Must(uuid.NewV7())
+	}
+	invitation.CreatedAt = time.Now()
+
+	meta := invitationMetadata{
+		OrganizationID: invitation.OrganizationID.String(),
+		Role:           string(invitation.Role),
+	}
+	if invitation.InvitedBy != nil {
+		meta.InvitedBy = invitation.InvitedBy.String()
+	}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		r.logger.Error("failed to marshal invitation metadata", "op", "CreateInvitation", "error", err.Error())
+		return err
+	}
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to begin transaction", "op", "CreateInvitation", "error", err.Error())
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			r.logger.Warn("failed to rollback transaction", "op", "CreateInvitation", "error", rbErr.Error())
+		}
+	}()
+
+	query := `DELETE FROM ` + models.InvitationTable + `
+        WHERE subject = $1 AND lower(relates_to) = lower($2) AND metadata @> jsonb_build_object('organization_id', $3::text)`
+	if _, err := tx.Exec(ctx, query, models.InvitationSubject, invitation.Email, meta.OrganizationID); err != nil {
+		r.logger.Error("failed to delete previous invitation", "op", "CreateInvitation", "org_id", meta.OrganizationID, "error", err.Error())
+		return err
+	}
+
+	query = `INSERT INTO ` + models.InvitationTable + ` (id, user_id, subject, token_hash, relates_to, metadata, created_at, expires_at, last_sent_at)
+        VALUES ($1, NULL, $2, $3, $4, $5, $6, $7, $6)`
+	_, err = tx.Exec(ctx, query, invitation.ID, models.InvitationSubject, tokenHash, invitation.Email, metaBytes, invitation.CreatedAt, invitation.ExpiresAt)
+	if err != nil {
+		r.logger.Error("failed to insert invitation", "op", "CreateInvitation", "org_id", meta.OrganizationID, "error", err.Error())
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", "op", "CreateInvitation", "error", err.Error())
+		return err
+	}
+	r.logger.Info("organization invitation created", "op", "CreateInvitation", "org_id", meta.OrganizationID, "invitation_id", invitation.ID.String())
+	return nil
+}
+
+// GetInvitationByTokenHash retrieves a non-expired invitation by its token hash.
+func (r *OrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	query := `SELECT id, relates_to, metadata, created_at, expires_at FROM ` + models.InvitationTable + `
+        WHERE subject = $1 AND token_hash = $2 AND expires_at > CURRENT_TIMESTAMP`
+	row := r.conn(ctx).QueryRow(ctx, query, models.InvitationSubject, tokenHash)
+	invitation, err := r.scanInvitation(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get invitation", "op", "GetInvitationByTokenHash", "error", err.Error())
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListInvitations returns the pending, non-expired invitations of an organization.
+func (r *OrganizationRepository) ListInvitations(ctx context.Context, orgID uuid.UUID) ([]*models.Invitation, error) {
+	query := `SELECT id, relates_to, metadata, created_at, expires_at FROM ` + models.InvitationTable + `
+        WHERE subject = $1 AND metadata @> jsonb_build_object('organization_id', $2::text) AND expires_at > CURRENT_TIMESTAMP
+        ORDER BY created_at DESC`
+	rows, err := r.conn(ctx).Query(ctx, query, models.InvitationSubject, orgID.String())
+	if err != nil {
+		r.logger.Error("failed to list invitations", "op", "ListInvitations", "org_id", orgID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.Invitation{}
+	for rows.Next() {
+		invitation, err := r.scanInvitation(rows)
+		if err != nil {
+			r.logger.Error("failed to scan invitation", "op", "ListInvitations", "error", err.Error())
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// DeleteInvitation deletes a pending invitation of an organization.
+func (r *OrganizationRepository) DeleteInvitation(ctx context.Context, orgID, id uuid.UUID) error {
+	query := `DELETE FROM ` + models.InvitationTable + `
+        WHERE id = $1 AND subject = $2 AND metadata @> jsonb_build_object('organization_id', $3::text)`
+	cmd, err := r.conn(ctx).Exec(ctx, query, id, models.InvitationSubject, orgID.String())
+	if err != nil {
+		r.logger.Error("failed to delete invitation", "op", "DeleteInvitation", "org_id", orgID.String(), "invitation_id", id.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteInvitationsByEmail deletes all pending invitations of an email to an organization.
+func (r *OrganizationRepository) DeleteInvitationsByEmail(ctx context.Context, orgID uuid.UUID, email string) error {
+	query := `DELETE FROM ` + models.InvitationTable + `
+        WHERE subject = $1 AND lower(relates_to) = lower($2) AND metadata @> jsonb_build_object('organization_id', $3::text)`
+	if _, err := r.conn(ctx).Exec(ctx, query, models.InvitationSubject, email, orgID.String()); err != nil {
+		r.logger.Error("failed to delete invitations", "op", "DeleteInvitationsByEmail", "org_id", orgID.String(), "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// scanInvitation scans a one-time token row into an Invitation.
+func (r *OrganizationRepository) scanInvitation(row pgx.Row) (*models.Invitation, error) {
+	var inv models.Invitation
+	var metaBytes []byte
+	if err := row.Scan(&inv.ID, &inv.Email, &metaBytes, &inv.CreatedAt, &inv.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	var meta invitationMetadata
+	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+		return nil, err
+	}
+	orgID, err := uuid.FromString(meta.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	inv.OrganizationID = orgID
+	inv.Role = models.Role(meta.Role)
+	if meta.InvitedBy != "" {
+		if invitedBy, err := uuid.FromString(meta.InvitedBy); err == nil {
+			inv.InvitedBy = &invitedBy
+		}
+	}
+	return &inv, nil
+}
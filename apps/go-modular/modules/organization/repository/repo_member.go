@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-modular/modules/organization/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddMember inserts a new organization membership.
+func (r *OrganizationRepository) AddMember(ctx context.Context, member *models.Member) error {
+	member.CreatedAt = time.Now()
+	query := `INSERT INTO ` + models.MemberTable + ` (organization_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.conn(ctx).Exec(ctx, query, member.OrganizationID, member.UserID, member.Role, member.CreatedAt)
+	if err != nil {
+		r.logger.Error("failed to insert organization member", "op", "AddMember", "org_id", member.OrganizationID.String(), "user_id", member.UserID.String(), "error", err.Error())
+		return err
+	}
+	r.logger.Info("organization member added", "op", "AddMember", "org_id", member.OrganizationID.String(), "user_id", member.UserID.String())
+	return nil
+}
+
+// GetMember retrieves a user's membership in an organization.
+func (r *OrganizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.Member, error) {
+	query := `SELECT organization_id, user_id, role, created_at, updated_at FROM ` + models.MemberTable + `
+        WHERE organization_id = $1 AND user_id = $2`
+	var m models.Member
+	err := r.conn(ctx).QueryRow(ctx, query, orgID, userID).Scan(&m.OrganizationID, &m.UserID, &m.Role, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get organization member", "op", "GetMember", "org_id", orgID.String(), "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMembers returns the members of an organization including their email and display name.
+func (r *OrganizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*models.Member, error) {
+	query := `SELECT m.organization_id, m.user_id, m.role, m.created_at, m.updated_at, u.email, u.display_name
+        FROM ` + models.MemberTable + ` m
+        JOIN public.users u ON u.id = m.user_id
+        WHERE m.organization_id = $1
+        ORDER BY m.created_at ASC, m.user_id ASC`
+	rows, err := r.conn(ctx).Query(ctx, query, orgID)
+	if err != nil {
+		r.logger.Error("failed to list organization members", "op", "ListMembers", "org_id", orgID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.Member{}
+	for rows.Next() {
+		var m models.Member
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Role, &m.CreatedAt, &m.UpdatedAt, &m.Email, &m.DisplayName); err != nil {
+			r.logger.Error("failed to scan organization member", "op", "ListMembers", "error", err.Error())
+			return nil, err
+		}
+		members = append(members, &m)
+	}
+	return members, rows.Err()
+}
+
+// UpdateMemberRole changes the role of an organization member.
+func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role models.Role) error {
+	query := `UPDATE ` + models.MemberTable + ` SET role = $1 WHERE organization_id = $2 AND user_id = $3`
+	cmd, err := r.conn(ctx).Exec(ctx, query, role, orgID, userID)
+	if err != nil {
+		r.logger.Error("failed to update organization member role", "op", "UpdateMemberRole", "org_id", orgID.String(), "user_id", userID.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RemoveMember deletes a user's membership in an organization.
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	query := `DELETE FROM ` + models.MemberTable + ` WHERE organization_id = $1 AND user_id = $2`
+	cmd, err := r.conn(ctx).Exec(ctx, query, orgID, userID)
+	if err != nil {
+		r.logger.Error("failed to remove organization member", "op", "RemoveMember", "org_id", orgID.String(), "user_id", userID.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	r.logger.Info("organization member removed", "op", "RemoveMember", "org_id", orgID.String(), "user_id", userID.String())
+	return nil
+}
+
+// CountMembersByRole returns the number of members holding the given role in an organization.
+func (r *OrganizationRepository) CountMembersByRole(ctx context.Context, orgID uuid.UUID, role models.Role) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM ` + models.MemberTable + ` WHERE organization_id = $1 AND role = $2`
+	if err := r.conn(ctx).QueryRow(ctx, query, orgID, role).Scan(&count); err != nil {
+		r.logger.Error("failed to count organization members", "op", "CountMembersByRole", "org_id", orgID.String(), "error", err.Error())
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-modular/modules/organization/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateOrganization inserts a new organization together with its owner membership in one transaction.
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization, owner *models.Member) error {
+	if org.ID == uuid.Nil {
+		org.ID = uuid.Must(uuid.NewV7())
+	}
+	org.CreatedAt = time.Now()
+
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to begin transaction", "op", "CreateOrganization", "error", err.Error())
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			r.logger.Warn("failed to rollback transaction", "op", "CreateOrganization", "error", rbErr.Error())
+		}
+	}()
+
+	query := `INSERT INTO ` + models.OrganizationTable + ` (id, name, slug, created_by, created_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(ctx, query, org.ID, org.Name, org.Slug, org.CreatedBy, org.CreatedAt); err != nil {
+		r.logger.Error("failed to insert organization", "op", "CreateOrganization", "slug", org.Slug, "error", err.Error())
+		return err
+	}
+
+	if owner != nil {
+		owner.OrganizationID = org.ID
+		owner.CreatedAt = org.CreatedAt
+		query = `INSERT INTO ` + models.MemberTable + ` (organization_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(ctx, query, owner.OrganizationID, owner.UserID, owner.Role, owner.CreatedAt); err != nil {
+			r.logger.Error("failed to insert organization owner", "op", "CreateOrganization", "org_id", org.ID.String(), "error", err.Error())
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", "op", "CreateOrganization", "error", err.Error())
+		return err
+	}
+	r.logger.Info("organization created", "op", "CreateOrganization", "org_id", org.ID.String())
+	return nil
+}
+
+// GetOrganizationByID retrieves an organization by its ID.
+func (r *OrganizationRepository) GetOrganizationByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	query := `SELECT id, name, slug, created_by, created_at, updated_at FROM ` + models.OrganizationTable + ` WHERE id = $1`
+	var org models.Organization
+	err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get organization", "op", "GetOrganizationByID", "org_id", id.String(), "error", err.Error())
+		return nil, err
+	}
+	return &org, nil
+}
+
+// UpdateOrganization updates the name of an organization.
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, org *models.Organization) error {
+	query := `UPDATE ` + models.OrganizationTable + ` SET name = $1 WHERE id = $2 RETURNING updated_at`
+	err := r.conn(ctx).QueryRow(ctx, query, org.Name, org.ID).Scan(&org.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		r.logger.Error("failed to update organization", "op", "UpdateOrganization", "org_id", org.ID.String(), "error", err.Error())
+		return err
+	}
+	return nil
+}
+
+// DeleteOrganization deletes an organization, its memberships and pending invitations.
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to begin transaction", "op", "DeleteOrganization", "error", err.Error())
+		return err
+	}
+	defer func() {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			r.logger.Warn("failed to rollback transaction", "op", "DeleteOrganization", "error", rbErr.Error())
+		}
+	}()
+
+	query := `DELETE FROM ` + models.InvitationTable + ` WHERE subject = $1 AND metadata @> jsonb_build_object('organization_id', $2::text)`
+	if _, err := tx.Exec(ctx, query, models.InvitationSubject, id.String()); err != nil {
+		r.logger.Error("failed to delete organization invitations", "op", "DeleteOrganization", "org_id", id.String(), "error", err.Error())
+		return err
+	}
+
+	cmd, err := tx.Exec(ctx, `DELETE FROM `+models.OrganizationTable+` WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("failed to delete organization", "op", "DeleteOrganization", "org_id", id.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", "op", "DeleteOrganization", "error", err.Error())
+		return err
+	}
+	r.logger.Info("organization deleted", "op", "DeleteOrganization", "org_id", id.String())
+	return nil
+}
+
+// SlugExists checks whether an organization slug is already taken.
+func (r *OrganizationRepository) SlugExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM ` + models.OrganizationTable + ` WHERE slug = $1)`
+	if err := r.conn(ctx).QueryRow(ctx, query, slug).Scan(&exists); err != nil {
+		r.logger.Error("failed to check organization slug", "op", "SlugExists", "slug", slug, "error", err.Error())
+		return false, err
+	}
+	return exists, nil
+}
+
+// ListMembershipsByUserID returns the organizations a user belongs to with the user's role, oldest membership first.
+func (r *OrganizationRepository) ListMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error) {
+	query := `SELECT o.id, o.name, o.slug, o.created_by, o.created_at, o.updated_at, m.role, m.created_at
+        FROM ` + models.MemberTable + ` m
+        JOIN ` + models.OrganizationTable + ` o ON o.id = m.organization_id
+        WHERE m.user_id = $1
+        ORDER BY m.created_at ASC, o.id ASC`
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		r.logger.Error("failed to list memberships", "op", "ListMembershipsByUserID", "user_id", userID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []*models.Membership{}
+	for rows.Next() {
+		var m models.Membership
+		if err := rows.Scan(&m.ID, &m.Name, &m.Slug, &m.CreatedBy, &m.CreatedAt, &m.UpdatedAt, &m.Role, &m.JoinedAt); err != nil {
+			r.logger.Error("failed to scan membership", "op", "ListMembershipsByUserID", "error", err.Error())
+			return nil, err
+		}
+		memberships = append(memberships, &m)
+	}
+	return memberships, rows.Err()
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"go-modular/internal/adapter"
+	"go-modular/modules/organization/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OrganizationRepositoryInterface defines the contract for organization data access.
+type OrganizationRepositoryInterface interface {
+	// Organization operations
+	CreateOrganization(ctx context.Context, org *models.Organization, owner *models.Member) error
+	GetOrganizationByID(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	UpdateOrganization(ctx context.Context, org *models.Organization) error
+	DeleteOrganization(ctx context.Context, id uuid.UUID) error
+	SlugExists(ctx context.Context, slug string) (bool, error)
+	ListMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error)
+
+	// Member operations
+	AddMember(ctx context.Context, member *models.Member) error
+	GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.Member, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]*models.Member, error)
+	UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role models.Role) error
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+	CountMembersByRole(ctx context.Context, orgID uuid.UUID, role models.Role) (int, error)
+
+	// Invitation operations
+	CreateInvitation(ctx context.Context, invitation *models.Invitation, tokenHash string) error
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	ListInvitations(ctx context.Context, orgID uuid.UUID) ([]*models.Invitation, error)
+	DeleteInvitation(ctx context.Context, orgID, id uuid.UUID) error
+	DeleteInvitationsByEmail(ctx context.Context, orgID uuid.UUID, email string) error
+}
+
+// Ensure OrganizationRepository implements OrganizationRepositoryInterface
+var _ OrganizationRepositoryInterface = (*OrganizationRepository)(nil)
+
+// OrganizationRepository is an implementation of OrganizationRepositoryInterface using pgxpool.
+type OrganizationRepository struct {
+	pgPool *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewOrganizationRepository creates a new OrganizationRepository with pgxpool and slog logger.
+func NewOrganizationRepository(pgPool *pgxpool.Pool, logger *slog.Logger) *OrganizationRepository {
+	return &OrganizationRepository{
+		pgPool: pgPool,
+		logger: logger,
+	}
+}
+
+// Sentinel error for not found
+var ErrNotFound = errors.New("not found")
+
+// conn returns the tenant-scoped transaction of the request when there is one, so row-level
+// security applies, or the pool otherwise.
+func (r *OrganizationRepository) conn(ctx context.Context) adapter.DBTX {
+	return adapter.Conn(ctx, r.pgPool)
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go-modular/internal/adapter"
+	"go-modular/modules/organization/models"
+	"go-modular/pkg/testutils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+}
+
+func setupOrgRepo(t *testing.T) (*OrganizationRepository, uuid.UUID, func()) {
+	t.Helper()
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+
+	// ensure env/config is set for migrations and app code
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	logger := newLogger()
+	repo := NewOrganizationRepository(pool, logger)
+
+	// take any seeded user as the organization owner
+	var uid uuid.UUID
+	err = pool.QueryRow(context.Background(), `SELECT id FROM public.users LIMIT 1`).Scan(&uid)
+	require.NoError(t, err, "failed to find any seeded user required by tests")
+
+	teardown := func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM `+models.InvitationTable+` WHERE subject = $1`, models.InvitationSubject)
+		_, _ = pool.Exec(context.Background(), `TRUNCATE TABLE `+models.OrganizationTable+` CASCADE`)
+		pool.Close()
+	}
+
+	return repo, uid, teardown
+}
+
+func TestOrganization_CRUD_and_Members(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupOrgRepo(t)
+	defer teardown()
+
+	org := &models.Organization{Name: "Acme Inc", Slug: "acme-inc", CreatedBy: &uid}
+	owner := &models.Member{UserID: uid, Role: models.RoleOwner}
+	require.NoError(t, repo.CreateOrganization(ctx, org, owner))
+	require.NotEqual(t, uuid.Nil, org.ID)
+
+	exists, err := repo.SlugExists(ctx, "acme-inc")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	got, err := repo.GetOrganizationByID(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Inc", got.Name)
+
+	// owner membership was created together with the organization
+	member, err := repo.GetMember(ctx, org.ID, uid)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleOwner, member.Role)
+
+	memberships, err := repo.ListMembershipsByUserID(ctx, uid)
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, org.ID, memberships[0].ID)
+	assert.Equal(t, models.RoleOwner, memberships[0].Role)
+
+	members, err := repo.ListMembers(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.NotEmpty(t, members[0].Email)
+
+	count, err := repo.CountMembersByRole(ctx, org.ID, models.RoleOwner)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, repo.UpdateMemberRole(ctx, org.ID, uid, models.RoleAdmin))
+	member, err = repo.GetMember(ctx, org.ID, uid)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, member.Role)
+
+	org.Name = "Acme Corp"
+	require.NoError(t, repo.UpdateOrganization(ctx, org))
+	require.NotNil(t, org.UpdatedAt)
+
+	require.NoError(t, repo.RemoveMember(ctx, org.ID, uid))
+	_, err = repo.GetMember(ctx, org.ID, uid)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.RemoveMember(ctx, org.ID, uid), ErrNotFound)
+
+	require.NoError(t, repo.DeleteOrganization(ctx, org.ID))
+	_, err = repo.GetOrganizationByID(ctx, org.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteOrganization(ctx, org.ID), ErrNotFound)
+}
+
+func TestOrganization_Invitations(t *testing.T) {
+	ctx := context.Background()
+	repo, uid, teardown := setupOrgRepo(t)
+	defer teardown()
+
+	org := &models.Organization{Name: "Globex", Slug: "globex", CreatedBy: &uid}
+	require.NoError(t, repo.CreateOrganization(ctx, org, &models.Member{UserID: uid, Role: models.RoleOwner}))
+
+	inv := &models.Invitation{
+		OrganizationID: org.ID,
+		Email:          "Invitee@Example.com",
+		Role:           models.RoleAdmin,
+		InvitedBy:      &uid,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.CreateInvitation(ctx, inv, "hash-1"))
+
+	got, err := repo.GetInvitationByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, got.OrganizationID)
+	assert.Equal(t, models.RoleAdmin, got.Role)
+	require.NotNil(t, got.InvitedBy)
+	assert.Equal(t, uid, *got.InvitedBy)
+
+	// re-inviting the same email replaces the pending invitation
+	again := &models.Invitation{OrganizationID: org.ID, Email: "invitee@example.com", Role: models.RoleMember, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.CreateInvitation(ctx, again, "hash-2"))
+	_, err = repo.GetInvitationByTokenHash(ctx, "hash-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	invitations, err := repo.ListInvitations(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, again.ID, invitations[0].ID)
+
+	// deleting through another organization does not match
+	assert.ErrorIs(t, repo.DeleteInvitation(ctx, uuid.Must(uuid.NewV7()), again.ID), ErrNotFound)
+	require.NoError(t, repo.DeleteInvitation(ctx, org.ID, again.ID))
+
+	invitations, err = repo.ListInvitations(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, invitations)
+}
+
+func TestOrganization_TenantTx(t *testing.T) {
+	ctx := context.Background()
+	te := testutils.NewTestEnv(t)
+	_, pgURL, err := te.SetupPostgres()
+	require.NoError(t, err)
+
+	// ensure env/config is set for migrations
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	db, err := adapter.NewPostgres(adapter.PostgresConfig{URL: pgURL, MaxConnections: 5, MinConnections: 1})
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewOrganizationRepository(db.Pool, newLogger())
+
+	rows, err := db.Pool.Query(ctx, `SELECT id FROM public.users ORDER BY id LIMIT 2`)
+	require.NoError(t, err)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	require.NoError(t, err)
+	require.Len(t, ids, 2, "two seeded users are required by tests")
+	userA, userB := ids[0], ids[1]
+
+	// Created outside a tenant transaction, as CreateOrganization does
+	orgA := &models.Organization{Name: "Tenant A", Slug: "tenant-a", CreatedBy: &userA}
+	require.NoError(t, repo.CreateOrganization(ctx, orgA, &models.Member{UserID: userA, Role: models.RoleOwner}))
+	orgB := &models.Organization{Name: "Tenant B", Slug: "tenant-b", CreatedBy: &userB}
+	require.NoError(t, repo.CreateOrganization(ctx, orgB, &models.Member{UserID: userB, Role: models.RoleOwner}))
+	defer func() {
+		_, _ = db.Pool.Exec(context.Background(), `TRUNCATE TABLE `+models.OrganizationTable+` CASCADE`)
+	}()
+
+	tenantA := adapter.Tenant{TenantID: orgA.ID.String(), UserID: userA.String()}
+	err = db.WithTenantTx(ctx, tenantA, func(ctx context.Context, _ pgx.Tx) error {
+		got, err := repo.GetOrganizationByID(ctx, orgA.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Tenant A", got.Name)
+		members, err := repo.ListMembers(ctx, orgA.ID)
+		require.NoError(t, err)
+		assert.Len(t, members, 1)
+
+		// the other tenant is invisible even when its ID is passed explicitly
+		_, err = repo.GetOrganizationByID(ctx, orgB.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = repo.GetMember(ctx, orgB.ID, userB)
+		assert.ErrorIs(t, err, ErrNotFound)
+		members, err = repo.ListMembers(ctx, orgB.ID)
+		require.NoError(t, err)
+		assert.Empty(t, members)
+
+		assert.ErrorIs(t, repo.UpdateOrganization(ctx, &models.Organization{ID: orgB.ID, Name: "hijacked"}), ErrNotFound)
+		assert.ErrorIs(t, repo.RemoveMember(ctx, orgB.ID, userB), ErrNotFound)
+		return nil
+	})
+	require.NoError(t, err)
+
+	got, err := repo.GetOrganizationByID(ctx, orgB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Tenant B", got.Name)
+	_, err = repo.GetMember(ctx, orgB.ID, userB)
+	assert.NoError(t, err)
+}
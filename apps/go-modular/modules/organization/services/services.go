@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-modular/internal/notification"
+	"go-modular/modules/organization/models"
+	"go-modular/modules/organization/repository"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
+	svcUser "go-modular/modules/user/services"
+)
+
+// Errors returned by the organization service
+var (
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrNotMember               = errors.New("not a member of this organization")
+	ErrForbidden               = errors.New("insufficient organization role")
+	ErrLastOwner               = errors.New("organization must keep at least one owner")
+	ErrAlreadyMember           = errors.New("user is already a member of this organization")
+	ErrInvalidInvitation       = errors.New("invalid or expired invitation")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to a different email address")
+	ErrInvalidRole             = errors.New("invalid organization role")
+)
+
+// OrganizationServiceInterface defines the contract for organization business logic.
+type OrganizationServiceInterface interface {
+	CreateOrganization(ctx context.Context, userID uuid.UUID, name string) (*models.Organization, error)
+	GetOrganization(ctx context.Context, userID, orgID uuid.UUID) (*models.Membership, error)
+	ListUserOrganizations(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error)
+	UpdateOrganization(ctx context.Context, userID, orgID uuid.UUID, name string) (*models.Organization, error)
+	DeleteOrganization(ctx context.Context, userID, orgID uuid.UUID) error
+
+	ListMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*models.Member, error)
+	UpdateMemberRole(ctx context.Context, userID, orgID, memberID uuid.UUID, role models.Role) error
+	RemoveMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error
+
+	InviteMember(ctx context.Context, userID, orgID uuid.UUID, email string, role models.Role) (*models.Invitation, error)
+	ListInvitations(ctx context.Context, userID, orgID uuid.UUID) ([]*models.Invitation, error)
+	RevokeInvitation(ctx context.Context, userID, orgID, invitationID uuid.UUID) error
+	AcceptInvitation(ctx context.Context, userID uuid.UUID, token string) (*models.Membership, error)
+
+	GetMembership(ctx context.Context, orgID, userID uuid.UUID) (*models.Member, error)
+	IsMember(ctx context.Context, orgID, userID uuid.UUID) (bool, error)
+	DefaultOrganizationID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+}
+
+// Ensure OrganizationService implements OrganizationServiceInterface
+var _ OrganizationServiceInterface = (*OrganizationService)(nil)
+
+// OrganizationService implements organization business logic using an OrganizationRepositoryInterface.
+type OrganizationService struct {
+	orgRepo          repository.OrganizationRepositoryInterface
+	userService      svcUser.UserServiceInterface
+	mailer           *notification.Mailer
+	invitationURL    string
+	invitationExpiry time.Duration
+	auditor          auditSvc.Recorder
+}
+
+type OrganizationServiceOpts struct {
+	OrgRepo          repository.OrganizationRepositoryInterface
+	UserService      svcUser.UserServiceInterface
+	Mailer           *notification.Mailer // Mailer service for sending invitation emails (optional)
+	BaseURL          string               // BaseURL used when constructing invitation links (MANDATORY)
+	InvitationURL    string               // Invitation link target (default: BaseURL + "/api/v1/organizations/invitations/accept")
+	InvitationExpiry time.Duration        // Invitation lifetime (default: 7 days)
+	Auditor          auditSvc.Recorder    // Security audit log recorder (optional)
+}
+
+// NewOrganizationService creates a new OrganizationService.
+func NewOrganizationService(opts OrganizationServiceOpts) *OrganizationService {
+	if opts.OrgRepo == nil {
+		panic("OrgRepo is required")
+	}
+	if opts.UserService == nil {
+		panic("UserService is required")
+	}
+	if opts.InvitationURL == "" {
+		if opts.BaseURL == "" {
+			panic("BaseURL is required")
+		}
+		opts.InvitationURL = strings.TrimRight(opts.BaseURL, "/") + "/api/v1/organizations/invitations/accept"
+	}
+	if _, err := url.Parse(opts.InvitationURL); err != nil {
+		panic("InvitationURL must be a valid URL")
+	}
+	if opts.InvitationExpiry == 0 {
+		opts.InvitationExpiry = 7 * 24 * time.Hour
+	}
+	if opts.Auditor == nil {
+		opts.Auditor = auditSvc.NopRecorder{}
+	}
+	return &OrganizationService{
+		orgRepo:          opts.OrgRepo,
+		userService:      opts.UserService,
+		mailer:           opts.Mailer,
+		invitationURL:    opts.InvitationURL,
+		invitationExpiry: opts.InvitationExpiry,
+		auditor:          opts.Auditor,
+	}
+}
+
+// CreateOrganization creates an organization with a unique slug derived from its name
+// and makes the creating user its owner.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID uuid.UUID, name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+
+	// Generate slug from name: lowercase, non-alphanumeric runs replaced by a dash
+	re := regexp.MustCompile(`[^a-z0-9]+`)
+	base := strings.Trim(re.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "org"
+	}
+	slug := base
+	// Ensure slug is unique, add suffix if needed
+	suffix := 1
+	for {
+		exists, err := s.orgRepo.SlugExists(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			break
+		}
+		slug = base + "-" + strconv.Itoa(suffix)
+		suffix++
+	}
+
+	org := &models.Organization{Name: name, Slug: slug, CreatedBy: &userID}
+	owner := &models.Member{UserID: userID, Role: models.RoleOwner}
+	if err := s.orgRepo.CreateOrganization(ctx, org, owner); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, auditModels.ActionOrgCreated, userID, org.ID, map[string]any{"slug": org.Slug})
+	return org, nil
+}
+
+// GetOrganization returns an organization the user is a member of, with the user's role.
+func (s *OrganizationService) GetOrganization(ctx context.Context, userID, orgID uuid.UUID) (*models.Membership, error) {
+	member, err := s.requireRole(ctx, orgID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.orgRepo.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &models.Membership{Organization: *org, Role: member.Role, JoinedAt: member.CreatedAt}, nil
+}
+
+func (s *OrganizationService) ListUserOrganizations(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error) {
+	return s.orgRepo.ListMembershipsByUserID(ctx, userID)
+}
+
+// UpdateOrganization renames an organization. Requires the admin role.
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, userID, orgID uuid.UUID, name string) (*models.Organization, error) {
+	if _, err := s.requireRole(ctx, orgID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	org, err := s.orgRepo.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	org.Name = strings.TrimSpace(name)
+	if err := s.orgRepo.UpdateOrganization(ctx, org); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, auditModels.ActionOrgUpdated, userID, org.ID, nil)
+	return org, nil
+}
+
+// DeleteOrganization deletes an organization with its memberships and invitations. Requires the owner role.
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, userID, orgID uuid.UUID) error {
+	if _, err := s.requireRole(ctx, orgID, userID, models.RoleOwner); err != nil {
+		return err
+	}
+	if err := s.orgRepo.DeleteOrganization(ctx, orgID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrOrganizationNotFound
+		}
+		return err
+	}
+	s.audit(ctx, auditModels.ActionOrgDeleted, userID, orgID, nil)
+	return nil
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*models.Member, error) {
+	if _, err := s.requireRole(ctx, orgID, userID, models.RoleMember); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.ListMembers(ctx, orgID)
+}
+
+// UpdateMemberRole changes the role of a member. Admins can manage admins and members,
+// only owners can grant or revoke the owner role, and the last owner cannot be demoted.
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, userID, orgID, memberID uuid.UUID, role models.Role) error {
+	if !role.IsValid() {
+		return ErrInvalidRole
+	}
+	actor, err := s.requireRole(ctx, orgID, userID, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	target, err := s.GetMembership(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if target.Role == role {
+		return nil
+	}
+	if (role == models.RoleOwner || target.Role == models.RoleOwner) && actor.Role != models.RoleOwner {
+		return ErrForbidden
+	}
+	if target.Role == models.RoleOwner {
+		if err := s.ensureAnotherOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.orgRepo.UpdateMemberRole(ctx, orgID, memberID, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+	s.audit(ctx, auditModels.ActionOrgMemberRoleChanged, userID, orgID, map[string]any{
+		"user_id": memberID.String(), "from": string(target.Role), "to": string(role),
+	})
+	return nil
+}
+
+// RemoveMember removes a member from an organization. Members can always leave, removing
+// someone else requires the admin role (owner to remove an owner). The last owner cannot be removed.
+func (s *OrganizationService) RemoveMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error {
+	target, err := s.GetMembership(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if userID != memberID {
+		actor, err := s.requireRole(ctx, orgID, userID, models.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if target.Role == models.RoleOwner && actor.Role != models.RoleOwner {
+			return ErrForbidden
+		}
+	}
+	if target.Role == models.RoleOwner {
+		if err := s.ensureAnotherOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.orgRepo.RemoveMember(ctx, orgID, memberID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotMember
+		}
+		return err
+	}
+	s.audit(ctx, auditModels.ActionOrgMemberRemoved, userID, orgID, map[string]any{"user_id": memberID.String()})
+	return nil
+}
+
+func (s *OrganizationService) GetMembership(ctx context.Context, orgID, userID uuid.UUID) (*models.Member, error) {
+	member, err := s.orgRepo.GetMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *OrganizationService) IsMember(ctx context.Context, orgID, userID uuid.UUID) (bool, error) {
+	_, err := s.GetMembership(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotMember) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DefaultOrganizationID returns the organization the user joined first, or uuid.Nil
+// when the user does not belong to any organization.
+func (s *OrganizationService) DefaultOrganizationID(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	memberships, err := s.orgRepo.ListMembershipsByUserID(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(memberships) == 0 {
+		return uuid.Nil, nil
+	}
+	return memberships[0].ID, nil
+}
+
+// requireRole returns the membership of userID in orgID when it grants at least the given role.
+func (s *OrganizationService) requireRole(ctx context.Context, orgID, userID uuid.UUID, role models.Role) (*models.Member, error) {
+	member, err := s.GetMembership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.AtLeast(role) {
+		return nil, ErrForbidden
+	}
+	return member, nil
+}
+
+// ensureAnotherOwner returns ErrLastOwner when the organization has a single owner left.
+func (s *OrganizationService) ensureAnotherOwner(ctx context.Context, orgID uuid.UUID) error {
+	owners, err := s.orgRepo.CountMembersByRole(ctx, orgID, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// audit records an organization event; actorID is the user who performed the action.
+func (s *OrganizationService) audit(ctx context.Context, action auditModels.AuditAction, actorID, orgID uuid.UUID, metadata map[string]any) {
+	event := auditSvc.NewEvent(action, auditModels.TargetOrganization, orgID, metadata)
+	if actorID != uuid.Nil {
+		event.ActorID = &actorID
+	}
+	s.auditor.Record(ctx, event)
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-modular/modules/organization/models"
+	"go-modular/modules/organization/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+)
+
+// InviteMember invites an email address to join an organization with the given role and
+// emails a one-time invitation link. Requires the admin role; only owners can invite owners.
+// Re-inviting the same email replaces the previous pending invitation.
+func (s *OrganizationService) InviteMember(ctx context.Context, userID, orgID uuid.UUID, email string, role models.Role) (*models.Invitation, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	actor, err := s.requireRole(ctx, orgID, userID, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if role == models.RoleOwner && actor.Role != models.RoleOwner {
+		return nil, ErrForbidden
+	}
+	org, err := s.orgRepo.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+	// Reject invitations for users who already belong to the organization
+	if invitee, err := s.userService.GetUserByEmail(ctx, email); err == nil && invitee != nil {
+		if ok, err := s.IsMember(ctx, orgID, invitee.ID); err != nil {
+			return nil, err
+		} else if ok {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	// Generate a new, cryptographically secure, URL-safe token and store only its hash
+	rawToken, err := apputils.GenerateURLSafeToken(48)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	hash := sha256.Sum256([]byte(rawToken))
+
+	invitation := &models.Invitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           role,
+		InvitedBy:      &userID,
+		ExpiresAt:      time.Now().Add(s.invitationExpiry),
+	}
+	if err := s.orgRepo.CreateInvitation(ctx, invitation, hex.EncodeToString(hash[:])); err != nil {
+		return nil, err
+	}
+
+	if err := s.sendInvitationEmail(ctx, org, userID, invitation, rawToken); err != nil {
+		// The invitation is useless without the link, remove it so it can be re-sent
+		_ = s.orgRepo.DeleteInvitation(ctx, orgID, invitation.ID)
+		return nil, fmt.Errorf("failed to send invitation email: %w", err)
+	}
+	s.audit(ctx, auditModels.ActionOrgInvitationSent, userID, orgID, map[string]any{
+		"invitation_id": invitation.ID.String(), "email": email, "role": string(role),
+	})
+	return invitation, nil
+}
+
+func (s *OrganizationService) ListInvitations(ctx context.Context, userID, orgID uuid.UUID) ([]*models.Invitation, error) {
+	if _, err := s.requireRole(ctx, orgID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.ListInvitations(ctx, orgID)
+}
+
+// RevokeInvitation deletes a pending invitation. Requires the admin role.
+func (s *OrganizationService) RevokeInvitation(ctx context.Context, userID, orgID, invitationID uuid.UUID) error {
+	if _, err := s.requireRole(ctx, orgID, userID, models.RoleAdmin); err != nil {
+		return err
+	}
+	if err := s.orgRepo.DeleteInvitation(ctx, orgID, invitationID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidInvitation
+		}
+		return err
+	}
+	s.audit(ctx, auditModels.ActionOrgInvitationRevoked, userID, orgID, map[string]any{"invitation_id": invitationID.String()})
+	return nil
+}
+
+// AcceptInvitation adds the signed-in user to the organization of the invitation. The user's
+// email must match the invited email. The invitation is consumed on success.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, userID uuid.UUID, token string) (*models.Membership, error) {
+	if token == "" {
+		return nil, ErrInvalidInvitation
+	}
+	hash := sha256.Sum256([]byte(token))
+	invitation, err := s.orgRepo.GetInvitationByTokenHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	if ok, err := s.IsMember(ctx, invitation.OrganizationID, userID); err != nil {
+		return nil, err
+	} else if ok {
+		_ = s.orgRepo.DeleteInvitationsByEmail(ctx, invitation.OrganizationID, invitation.Email)
+		return nil, ErrAlreadyMember
+	}
+
+	member := &models.Member{OrganizationID: invitation.OrganizationID, UserID: userID, Role: invitation.Role}
+	if err := s.orgRepo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	// Invitation is one-time use
+	_ = s.orgRepo.DeleteInvitationsByEmail(ctx, invitation.OrganizationID, invitation.Email)
+
+	metadata := map[string]any{"user_id": userID.String(), "role": string(member.Role), "invitation_id": invitation.ID.String()}
+	s.audit(ctx, auditModels.ActionOrgMemberAdded, userID, invitation.OrganizationID, metadata)
+
+	return s.GetOrganization(ctx, userID, invitation.OrganizationID)
+}
+
+// sendInvitationEmail emails the invitation link containing the raw token to the invitee.
+func (s *OrganizationService) sendInvitationEmail(ctx context.Context, org *models.Organization, inviterID uuid.UUID, invitation *models.Invitation, rawToken string) error {
+	u, err := url.Parse(s.invitationURL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("token", rawToken)
+	u.RawQuery = q.Encode()
+	inviteURL := u.String()
+
+	// Try to fetch inviter to pass display name to template
+	var inviterName string
+	if inviter, err := s.userService.GetUserByID(ctx, inviterID); err == nil && inviter != nil {
+		inviterName = inviter.DisplayName
+	}
+
+	// Template data passed to the email template
+	data := map[string]any{
+		"Email":            invitation.Email,
+		"OrganizationName": org.Name,
+		"InviterName":      inviterName,
+		"Role":             string(invitation.Role),
+		"InviteURL":        inviteURL,
+		"ExpiresAt":        invitation.ExpiresAt.Format(time.RFC1123),
+	}
+
+	subject := "You have been invited to join " + org.Name
+	templateName := "organization_invitation.html" // ensure this template exists in templates/emails/
+
+	if s.mailer != nil {
+		return s.mailer.SendEmail(ctx, []string{invitation.Email}, subject, templateName, data)
+	}
+
+	// Fallback for development: print invitation link
+	fmt.Println("No mailer configured, invitation link for", invitation.Email, ":", inviteURL)
+	return nil
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"go-modular/internal/personaldata"
+	"go-modular/modules/organization/models"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// ExportPersonalData writes the user's organization memberships to a personal data export.
+func (s *OrganizationService) ExportPersonalData(ctx context.Context, userID uuid.UUID, w *personaldata.Writer) error {
+	memberships, err := s.orgRepo.ListMembershipsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if memberships == nil {
+		memberships = []*models.Membership{}
+	}
+	return w.WriteJSON("memberships.json", memberships)
+}
@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go-modular/modules/realtime/models"
+	"go-modular/modules/realtime/services"
+
+	"github.com/coder/websocket"
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// HandlerInterface defines the contract for realtime handlers.
+type HandlerInterface interface {
+	Stream(c echo.Context) error
+	WebSocket(c echo.Context) error
+}
+
+// Ensure Handler implements HandlerInterface
+var _ HandlerInterface = (*Handler)(nil)
+
+// retryInterval is the reconnection delay advertised to SSE clients.
+const retryInterval = 5 * time.Second
+
+// Handler holds dependencies for realtime handlers.
+type Handler struct {
+	logger            *slog.Logger
+	hub               *services.Hub
+	originPatterns    []string
+	heartbeatInterval time.Duration
+}
+
+type HandlerOpts struct {
+	Logger            *slog.Logger
+	Hub               *services.Hub
+	AllowedOrigins    []string      // Origins allowed to open WebSockets, besides the API host
+	HeartbeatInterval time.Duration // Time between keep-alive frames (default: 15s)
+}
+
+// NewHandler creates a new Handler instance.
+func NewHandler(opts *HandlerOpts) *Handler {
+	heartbeat := opts.HeartbeatInterval
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &Handler{
+		logger:            opts.Logger,
+		hub:               opts.Hub,
+		originPatterns:    originHosts(opts.AllowedOrigins),
+		heartbeatInterval: heartbeat,
+	}
+}
+
+// originHosts turns allowed origins (https://app.example.com) into the host patterns
+// checked by websocket.Accept (app.example.com).
+func originHosts(origins []string) []string {
+	hosts := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if u, err := url.Parse(origin); err == nil && u.Host != "" {
+			hosts = append(hosts, u.Host)
+		} else if origin != "" {
+			hosts = append(hosts, origin)
+		}
+	}
+	return hosts
+}
+
+// streamAuth is the authenticated user and session of a stream request.
+type streamAuth struct {
+	userID    uuid.UUID
+	sessionID uuid.UUID
+	expiresAt time.Time // zero when the token has no expiry
+}
+
+// authenticate returns the user token of the request set by the JWT middleware. Service
+// tokens carry no user and cannot open streams.
+func authenticate(c echo.Context) (streamAuth, bool) {
+	userID, err := uuid.FromString(fmt.Sprint(c.Get("user_id")))
+	if err != nil || c.Get("user_id") == nil {
+		return streamAuth{}, false
+	}
+	sessionID, err := uuid.FromString(fmt.Sprint(c.Get("session_id")))
+	if err != nil || c.Get("session_id") == nil {
+		return streamAuth{}, false
+	}
+	auth := streamAuth{userID: userID, sessionID: sessionID}
+	if claims, ok := c.Get("jwt_claims").(map[string]any); ok {
+		switch exp := claims["exp"].(type) {
+		case time.Time:
+			auth.expiresAt = exp
+		case float64:
+			auth.expiresAt = time.Unix(int64(exp), 0)
+		case json.Number:
+			if n, err := exp.Int64(); err == nil {
+				auth.expiresAt = time.Unix(n, 0)
+			}
+		}
+	}
+	return auth, true
+}
+
+// expiry returns a channel fired when the token of the stream expires; clients reconnect
+// with a refreshed token. It never fires for tokens without expiry.
+func (a streamAuth) expiry() (<-chan time.Time, func()) {
+	if a.expiresAt.IsZero() {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(time.Until(a.expiresAt))
+	return timer.C, func() { timer.Stop() }
+}
+
+// parseLastEventID parses the ID of the last message received by a reconnecting client.
+func parseLastEventID(values ...string) *uuid.UUID {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if id, err := uuid.FromString(v); err == nil {
+			return &id
+		}
+	}
+	return nil
+}
+
+// Stream godoc
+// @Summary      Stream notifications (SSE)
+// @Description  Opens a Server-Sent Events stream of the notifications of the authenticated user. Each event has the message ID as SSE id and the message type as SSE event; its data is the message. Reconnecting clients send the last received ID in the Last-Event-ID header (or the last_event_id query parameter, like the WebSocket endpoint) to receive the messages they missed in the last minutes. The stream ends when the token expires, the session is revoked or the user is deleted.
+// @Tags         Realtime
+// @Security     BearerAuth
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header  string  false  "ID of the last received message"
+// @Param        last_event_id  query   string  false  "ID of the last received message, for clients that cannot set headers"
+// @Success      200  {object}  models.Message
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/events/stream [get]
+func (h *Handler) Stream(c echo.Context) error {
+	auth, ok := authenticate(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User token required"})
+	}
+	lastEventID := parseLastEventID(c.Request().Header.Get("Last-Event-ID"), c.QueryParam("last_event_id"))
+
+	sub, missed := h.hub.Subscribe(auth.userID, auth.sessionID, lastEventID)
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", retryInterval.Milliseconds()); err != nil {
+		return nil
+	}
+
+	send := func(msg *models.Message) (bool, error) {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return false, err
+		}
+		if _, err := fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data); err != nil {
+			return false, err
+		}
+		res.Flush()
+		return msg.Ends(auth.sessionID), nil
+	}
+	for _, msg := range missed {
+		if end, err := send(msg); err != nil || end {
+			return nil
+		}
+	}
+	res.Flush()
+
+	expired, stop := auth.expiry()
+	defer stop()
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case msg := <-sub.C():
+			if end, err := send(msg); err != nil || end {
+				return nil
+			}
+		case <-sub.Done():
+			drain(sub, func(msg *models.Message) bool {
+				end, err := send(msg)
+				return err == nil && !end
+			})
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-expired:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// WebSocket godoc
+// @Summary      Stream notifications (WebSocket)
+// @Description  Upgrades to a WebSocket sending the notifications of the authenticated user as JSON text messages. Reconnecting clients pass the ID of the last received message in the last_event_id query parameter to receive the messages they missed in the last minutes. The socket is closed with status 1008 when the token expires, the session is revoked or the user is deleted. Messages sent by the client are ignored.
+// @Tags         Realtime
+// @Security     BearerAuth
+// @Param        last_event_id  query  string  false  "ID of the last received message"
+// @Success      101  {object}  models.Message
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/events/ws [get]
+func (h *Handler) WebSocket(c echo.Context) error {
+	auth, ok := authenticate(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "User token required"})
+	}
+
+	conn, err := websocket.Accept(c.Response(), c.Request(), &websocket.AcceptOptions{
+		OriginPatterns: h.originPatterns,
+	})
+	if err != nil {
+		// Accept has already written the error response
+		h.logger.Warn("Failed to accept websocket", slog.String("error", err.Error()))
+		return nil
+	}
+	defer conn.CloseNow()
+
+	sub, missed := h.hub.Subscribe(auth.userID, auth.sessionID, parseLastEventID(c.QueryParam("last_event_id")))
+	defer sub.Close()
+
+	// The client sends nothing; reading is still required to handle control frames, and the
+	// returned context is cancelled when the client goes away.
+	ctx := conn.CloseRead(c.Request().Context())
+
+	send := func(msg *models.Message) (bool, error) {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return false, err
+		}
+		writeCtx, cancel := context.WithTimeout(ctx, h.heartbeatInterval)
+		defer cancel()
+		if err := conn.Write(writeCtx, websocket.MessageText, data); err != nil {
+			return false, err
+		}
+		return msg.Ends(auth.sessionID), nil
+	}
+	for _, msg := range missed {
+		if end, err := send(msg); err != nil {
+			return nil
+		} else if end {
+			return conn.Close(websocket.StatusPolicyViolation, "session ended")
+		}
+	}
+
+	expired, stop := auth.expiry()
+	defer stop()
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case msg := <-sub.C():
+			if end, err := send(msg); err != nil {
+				return nil
+			} else if end {
+				return conn.Close(websocket.StatusPolicyViolation, "session ended")
+			}
+		case <-sub.Done():
+			ended := drain(sub, func(msg *models.Message) bool {
+				end, err := send(msg)
+				return err == nil && !end
+			})
+			if ended {
+				return conn.Close(websocket.StatusGoingAway, "stream closed")
+			}
+			return conn.Close(websocket.StatusPolicyViolation, "session ended")
+		case <-heartbeat.C:
+			pingCtx, cancel := context.WithTimeout(ctx, h.heartbeatInterval)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return nil
+			}
+		case <-expired:
+			return conn.Close(websocket.StatusPolicyViolation, "token expired")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// drain sends the messages buffered in an ended subscription while send returns true. It
+// reports whether all buffered messages were sent.
+func drain(sub *services.Subscription, send func(*models.Message) bool) bool {
+	for {
+		select {
+		case msg := <-sub.C():
+			if !send(msg) {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package models contains struct definitions for the realtime module.
+// Messages are not stored in the database; they are sent to connected clients only.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Message types sent to clients
+const (
+	MessageEmailVerified   = "user.email_verified"
+	MessageUserUpdated     = "user.updated"
+	MessageUserDeleted     = "user.deleted"
+	MessagePasswordChanged = "auth.password_changed"
+	MessageSessionRevoked  = "auth.session_revoked"
+)
+
+// Message is a notification for one user, sent to all of the user's open streams.
+type Message struct {
+	ID        uuid.UUID       `json:"id"` // UUIDv7, ordered by creation; used as the SSE event ID
+	Type      string          `json:"type" example:"user.email_verified"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	UserID       uuid.UUID  `json:"-"` // recipient
+	EndSessionID *uuid.UUID `json:"-"` // streams of this session are closed after the message
+	EndAll       bool       `json:"-"` // all streams of the user are closed after the message
+}
+
+// Ends reports whether a stream of sessionID is closed after m.
+func (m *Message) Ends(sessionID uuid.UUID) bool {
+	return m.EndAll || (m.EndSessionID != nil && *m.EndSessionID == sessionID)
+}
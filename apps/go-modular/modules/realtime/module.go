@@ -0,0 +1,195 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"go-modular/internal/eventbus"
+	"go-modular/internal/module"
+	"go-modular/modules/realtime/handler"
+	"go-modular/modules/realtime/models"
+	"go-modular/modules/realtime/services"
+
+	modAuth "go-modular/modules/auth"
+	authModels "go-modular/modules/auth/models"
+	userModels "go-modular/modules/user/models"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	module.Register(&RealtimeModule{})
+}
+
+// notifySubscriber is the event bus subscriber name of the realtime module.
+const notifySubscriber = "realtime.notify"
+
+type Options struct {
+	PgPool *pgxpool.Pool // PostgreSQL connection pool, used for LISTEN/NOTIFY (required)
+	Logger *slog.Logger  // Slog logger instance (optional)
+
+	// Events is the domain event bus; user and session events published on it are sent to
+	// the streams of the users concerned (required)
+	Events *eventbus.Bus
+
+	AllowedOrigins    []string      // Origins allowed to open WebSockets, usually the CORS origins
+	HeartbeatInterval time.Duration // Time between keep-alive frames (default: 15s)
+}
+
+// RealtimeModule holds dependencies for the notification streams.
+type RealtimeModule struct {
+	app         *module.App // set when initialized by the module registry
+	logger      *slog.Logger
+	middlewares []echo.MiddlewareFunc
+	handler     *handler.Handler
+	hub         *services.Hub
+	broker      *services.PostgresBroker
+	started     bool
+}
+
+// NewModule creates a new RealtimeModule and subscribes it to the user and session events.
+func NewModule(opts *Options) *RealtimeModule {
+	if opts.PgPool == nil || opts.Events == nil {
+		panic("invalid realtime module options: PgPool and Events are required")
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+
+	broker := services.NewPostgresBroker(opts.PgPool, logger)
+	hub := services.NewHub(services.HubOpts{
+		Broker: broker,
+		Logger: logger,
+	})
+
+	eventbus.Subscribe(opts.Events, notifySubscriber, func(ctx context.Context, e userModels.UserEmailVerified) error {
+		return hub.Notify(ctx, e.UserID, models.MessageEmailVerified, e)
+	})
+	eventbus.Subscribe(opts.Events, notifySubscriber, func(ctx context.Context, e userModels.UserUpdated) error {
+		return hub.Notify(ctx, e.UserID, models.MessageUserUpdated, e)
+	})
+	eventbus.Subscribe(opts.Events, notifySubscriber, func(ctx context.Context, e authModels.PasswordChanged) error {
+		return hub.Notify(ctx, e.UserID, models.MessagePasswordChanged, e)
+	})
+	// Ending events close the streams they concern after the message, on every replica
+	eventbus.Subscribe(opts.Events, notifySubscriber, func(ctx context.Context, e authModels.SessionRevoked) error {
+		return publish(ctx, hub, &models.Message{UserID: e.UserID, Type: models.MessageSessionRevoked, EndSessionID: &e.SessionID}, e)
+	})
+	eventbus.Subscribe(opts.Events, notifySubscriber, func(ctx context.Context, e userModels.UserDeleted) error {
+		return publish(ctx, hub, &models.Message{UserID: e.UserID, Type: models.MessageUserDeleted, EndAll: true}, e)
+	})
+
+	h := handler.NewHandler(&handler.HandlerOpts{
+		Logger:            logger,
+		Hub:               hub,
+		AllowedOrigins:    opts.AllowedOrigins,
+		HeartbeatInterval: opts.HeartbeatInterval,
+	})
+
+	return &RealtimeModule{
+		logger:  logger,
+		handler: h,
+		hub:     hub,
+		broker:  broker,
+	}
+}
+
+// Ensure RealtimeModule implements module.Module
+var _ module.Module = (*RealtimeModule)(nil)
+
+func (m *RealtimeModule) Name() string { return "realtime" }
+
+func (m *RealtimeModule) Dependencies() []string { return nil }
+
+// Init builds the module; WebSockets are accepted from the CORS origins. Open streams are
+// ended when the server starts shutting down, so they do not hold up the graceful shutdown.
+func (m *RealtimeModule) Init(_ context.Context, app *module.App) error {
+	*m = *NewModule(&Options{
+		PgPool:         app.DB.Pool,
+		Logger:         app.Logger,
+		Events:         app.Events,
+		AllowedOrigins: app.Config.GetCORSOrigins(),
+	})
+	m.app = app
+	app.Echo.Server.RegisterOnShutdown(m.CloseStreams)
+	return nil
+}
+
+// Start receives the messages of all replicas until ctx is cancelled.
+func (m *RealtimeModule) Start(ctx context.Context) error {
+	m.StartHub(ctx)
+	return nil
+}
+
+// Stop ends the streams still open.
+func (m *RealtimeModule) Stop(context.Context) error {
+	m.CloseStreams()
+	return nil
+}
+
+// The module has no tables: messages are only sent to connected clients.
+func (m *RealtimeModule) Migrations() fs.FS { return nil }
+
+// Health reports whether the hub receives the messages of the other replicas.
+func (m *RealtimeModule) Health() []health.Check {
+	return []health.Check{{
+		Name: "listener",
+		Check: func(context.Context) error {
+			if m.started && !m.broker.Listening() {
+				return errors.New("not listening for realtime messages")
+			}
+			return nil
+		},
+	}}
+}
+
+// publish sends msg with data encoded as JSON.
+func publish(ctx context.Context, hub *services.Hub, msg *models.Message, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	msg.Data = raw
+	return hub.Publish(ctx, msg)
+}
+
+// Expose Hub, so other modules can notify users
+func (m *RealtimeModule) GetHub() *services.Hub {
+	return m.hub
+}
+
+// Use adds middleware(s) to the RealtimeModule (grouped).
+func (m *RealtimeModule) Use(mw ...echo.MiddlewareFunc) {
+	m.middlewares = append(m.middlewares, mw...)
+}
+
+// RegisterRoutes registers the stream endpoints to the given Echo group.
+func (m *RealtimeModule) RegisterRoutes(e *echo.Group) {
+	// Modules initialized by the registry authenticate with the auth module
+	if m.app != nil {
+		m.Use(module.MustLookup[*modAuth.AuthModule](m.app).JWTMiddleware())
+	}
+	g := e.Group("/events", m.middlewares...)
+	g.GET("/stream", m.handler.Stream)
+	g.GET("/ws", m.handler.WebSocket)
+}
+
+// StartHub receives the messages of all replicas in the background until ctx is cancelled.
+func (m *RealtimeModule) StartHub(ctx context.Context) {
+	m.started = true
+	go m.hub.Run(ctx)
+}
+
+// CloseStreams ends all open streams, so the server can shut down without waiting for them.
+func (m *RealtimeModule) CloseStreams() {
+	m.hub.Close()
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"go-modular/modules/realtime/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotifyChannel is the Postgres LISTEN/NOTIFY channel carrying realtime messages.
+const NotifyChannel = "realtime_messages"
+
+// maxNotifyPayload is the largest payload accepted by NOTIFY (8000 bytes by default),
+// with some room for the server's own overhead.
+const maxNotifyPayload = 7900
+
+// wireMessage is the NOTIFY payload of a message, including the fields hidden from clients.
+type wireMessage struct {
+	ID           uuid.UUID       `json:"id"`
+	Type         string          `json:"type"`
+	Data         json.RawMessage `json:"data"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UserID       uuid.UUID       `json:"user_id"`
+	EndSessionID *uuid.UUID      `json:"end_session_id,omitempty"`
+	EndAll       bool            `json:"end_all,omitempty"`
+}
+
+// Ensure PostgresBroker implements Broker
+var _ Broker = (*PostgresBroker)(nil)
+
+// PostgresBroker is a Broker using Postgres LISTEN/NOTIFY, so every replica connected to the
+// same database receives every message. Notifications are not stored: messages published
+// while a replica reconnects its listening connection are lost for that replica.
+type PostgresBroker struct {
+	pgPool    *pgxpool.Pool
+	logger    *slog.Logger
+	listening atomic.Bool
+}
+
+// NewPostgresBroker creates a PostgresBroker. Listen holds one connection of the pool.
+func NewPostgresBroker(pgPool *pgxpool.Pool, logger *slog.Logger) *PostgresBroker {
+	return &PostgresBroker{pgPool: pgPool, logger: logger}
+}
+
+// Publish sends msg with NOTIFY. Messages are small notifications; larger payloads are
+// rejected by Postgres and therefore refused here.
+func (b *PostgresBroker) Publish(ctx context.Context, msg *models.Message) error {
+	payload, err := json.Marshal(wireMessage{
+		ID:           msg.ID,
+		Type:         msg.Type,
+		Data:         msg.Data,
+		CreatedAt:    msg.CreatedAt,
+		UserID:       msg.UserID,
+		EndSessionID: msg.EndSessionID,
+		EndAll:       msg.EndAll,
+	})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("message payload of %d bytes exceeds %d bytes", len(payload), maxNotifyPayload)
+	}
+	_, err = b.pgPool.Exec(ctx, `SELECT pg_notify($1, $2)`, NotifyChannel, string(payload))
+	return err
+}
+
+// Listen delivers the messages notified on NotifyChannel until ctx is cancelled. The
+// listening connection is re-established with backoff when it fails.
+func (b *PostgresBroker) Listen(ctx context.Context, deliver func(*models.Message)) error {
+	delay := time.Second
+	for {
+		err := b.listen(ctx, deliver, func() { delay = time.Second })
+		if ctx.Err() != nil {
+			return nil
+		}
+		b.logger.Error("realtime listener failed, reconnecting", "op", "PostgresBroker.Listen", "retry_in", delay.String(), "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, 30*time.Second)
+	}
+}
+
+// Listening reports whether Listen currently receives notifications.
+func (b *PostgresBroker) Listening() bool {
+	return b.listening.Load()
+}
+
+// listen runs one LISTEN session on a connection taken out of the pool, calling connected
+// once it listens.
+func (b *PostgresBroker) listen(ctx context.Context, deliver func(*models.Message), connected func()) error {
+	pooled, err := b.pgPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// A listening connection must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, `LISTEN `+NotifyChannel); err != nil {
+		return err
+	}
+	connected()
+	b.listening.Store(true)
+	defer b.listening.Store(false)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var w wireMessage
+		if err := json.Unmarshal([]byte(n.Payload), &w); err != nil {
+			b.logger.Warn("invalid realtime notification", "op", "PostgresBroker.listen", "error", err.Error())
+			continue
+		}
+		deliver(&models.Message{
+			ID:           w.ID,
+			Type:         w.Type,
+			Data:         w.Data,
+			CreatedAt:    w.CreatedAt,
+			UserID:       w.UserID,
+			EndSessionID: w.EndSessionID,
+			EndAll:       w.EndAll,
+		})
+	}
+}
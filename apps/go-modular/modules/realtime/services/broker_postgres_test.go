@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go-modular/modules/realtime/models"
+	"go-modular/pkg/testutils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresBroker_PublishListen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	defer pool.Close()
+
+	broker := NewPostgresBroker(pool, slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	received := make(chan *models.Message, 1)
+	go func() { _ = broker.Listen(ctx, func(msg *models.Message) { received <- msg }) }()
+
+	sessionID := uuid.Must(uuid.NewV4())
+	sent := &models.Message{
+		ID:           uuid.Must(uuid.NewV7()),
+		Type:         models.MessageSessionRevoked,
+		Data:         []byte(`{"reason":"signed_out"}`),
+		CreatedAt:    time.Now().UTC(),
+		UserID:       uuid.Must(uuid.NewV4()),
+		EndSessionID: &sessionID,
+	}
+	// The listener may not be connected yet, so publish until the message comes through
+	var got *models.Message
+	require.Eventually(t, func() bool {
+		require.NoError(t, broker.Publish(ctx, sent))
+		select {
+		case got = <-received:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, 200*time.Millisecond)
+
+	assert.Equal(t, sent.ID, got.ID)
+	assert.Equal(t, sent.UserID, got.UserID)
+	assert.JSONEq(t, `{"reason":"signed_out"}`, string(got.Data))
+	require.NotNil(t, got.EndSessionID)
+	assert.Equal(t, sessionID, *got.EndSessionID)
+
+	// Payloads over the NOTIFY limit are refused
+	tooLarge := &models.Message{UserID: sent.UserID, Type: models.MessageUserUpdated, Data: []byte(`"` + strings.Repeat("x", maxNotifyPayload) + `"`)}
+	assert.Error(t, broker.Publish(ctx, tooLarge))
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go-modular/modules/realtime/models"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Broker carries messages between the hubs of all application replicas.
+type Broker interface {
+	// Publish sends msg to the hubs of all replicas, including the publishing one.
+	Publish(ctx context.Context, msg *models.Message) error
+	// Listen calls deliver with the messages published by any replica until ctx is cancelled.
+	Listen(ctx context.Context, deliver func(*models.Message)) error
+}
+
+// Hub fans out per-user messages to the open streams of this replica. It keeps the recent
+// messages of every user, so a client reconnecting with the ID of the last message it
+// received (SSE Last-Event-ID) gets the messages it missed.
+type Hub struct {
+	broker      Broker
+	logger      *slog.Logger
+	historySize int
+	historyTTL  time.Duration
+	bufferSize  int
+
+	mu      sync.Mutex
+	subs    map[uuid.UUID]map[*Subscription]struct{}
+	history map[uuid.UUID][]*models.Message
+	closed  bool
+}
+
+type HubOpts struct {
+	Broker      Broker        // Cross-replica broker (optional, messages stay in-process without it)
+	Logger      *slog.Logger  // Slog logger instance (optional)
+	HistorySize int           // Recent messages kept per user for reconnecting clients (default: 50)
+	HistoryTTL  time.Duration // Time recent messages are kept (default: 5m)
+	BufferSize  int           // Messages buffered per stream before a slow client is disconnected (default: 32)
+}
+
+// NewHub creates a new Hub. Call Run to receive the messages of other replicas.
+func NewHub(opts HubOpts) *Hub {
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+	if opts.HistorySize <= 0 {
+		opts.HistorySize = 50
+	}
+	if opts.HistoryTTL <= 0 {
+		opts.HistoryTTL = 5 * time.Minute
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 32
+	}
+	return &Hub{
+		broker:      opts.Broker,
+		logger:      opts.Logger,
+		historySize: opts.HistorySize,
+		historyTTL:  opts.HistoryTTL,
+		bufferSize:  opts.BufferSize,
+		subs:        make(map[uuid.UUID]map[*Subscription]struct{}),
+		history:     make(map[uuid.UUID][]*models.Message),
+	}
+}
+
+// Subscription is an open stream of a user's messages.
+type Subscription struct {
+	hub       *Hub
+	userID    uuid.UUID
+	sessionID uuid.UUID
+	ch        chan *models.Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// C returns the channel of messages for the stream.
+func (s *Subscription) C() <-chan *models.Message { return s.ch }
+
+// Done is closed when the hub ends the stream: its session was revoked, the client could not
+// keep up or the hub was closed. Messages buffered in C before that should still be sent.
+func (s *Subscription) Done() <-chan struct{} { return s.done }
+
+// Close unsubscribes the stream.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe opens a stream of the messages of userID, for a client authenticated with
+// sessionID. When lastEventID is set, the recent messages created after it are returned;
+// they were published before the subscription and must be sent first.
+func (h *Hub) Subscribe(userID, sessionID uuid.UUID, lastEventID *uuid.UUID) (*Subscription, []*models.Message) {
+	sub := &Subscription{
+		hub:       h,
+		userID:    userID,
+		sessionID: sessionID,
+		ch:        make(chan *models.Message, h.bufferSize),
+		done:      make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		sub.end()
+		return sub, nil
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	var missed []*models.Message
+	if lastEventID != nil {
+		for _, msg := range h.history[userID] {
+			if bytes.Compare(msg.ID.Bytes(), lastEventID.Bytes()) > 0 {
+				missed = append(missed, msg)
+			}
+		}
+	}
+	return sub, missed
+}
+
+// Publish sends msg to all streams of msg.UserID, on every replica. ID and CreatedAt are set
+// when missing.
+func (h *Hub) Publish(ctx context.Context, msg *models.Message) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = uuid.Must(uuid.NewV7())
+	}
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	if h.broker == nil {
+		h.deliver(msg)
+		return nil
+	}
+	if err := h.broker.Publish(ctx, msg); err != nil {
+		return fmt.Errorf("publish %s message: %w", msg.Type, err)
+	}
+	return nil
+}
+
+// Notify publishes a message of type msgType with data encoded as JSON to userID.
+func (h *Hub) Notify(ctx context.Context, userID uuid.UUID, msgType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode %s message: %w", msgType, err)
+	}
+	return h.Publish(ctx, &models.Message{UserID: userID, Type: msgType, Data: raw})
+}
+
+// Run receives the messages published by all replicas and expires recent messages, until
+// ctx is cancelled. Without a broker it only expires recent messages.
+func (h *Hub) Run(ctx context.Context) {
+	if h.broker != nil {
+		go func() {
+			if err := h.broker.Listen(ctx, h.deliver); err != nil && ctx.Err() == nil {
+				h.logger.Error("realtime broker stopped", "op", "Hub.Run", "error", err.Error())
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.expireHistory(time.Now())
+		}
+	}
+}
+
+// Close ends all streams; subscriptions made afterwards end immediately. It is meant to be
+// called when the server shuts down, so long-lived requests do not hold up the shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// deliver records msg in the recent messages of its user and sends it to the user's streams
+// on this replica. Streams whose buffer is full are ended; their clients reconnect and get
+// the messages they missed from the recent messages.
+func (h *Hub) deliver(msg *models.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := append(h.history[msg.UserID], msg)
+	if len(history) > h.historySize {
+		history = history[len(history)-h.historySize:]
+	}
+	h.history[msg.UserID] = history
+
+	for sub := range h.subs[msg.UserID] {
+		select {
+		case sub.ch <- msg:
+			if msg.Ends(sub.sessionID) {
+				h.remove(sub)
+			}
+		default:
+			h.logger.Warn("realtime stream too slow, disconnecting", "op", "Hub.deliver", "user_id", msg.UserID.String())
+			h.remove(sub)
+		}
+	}
+}
+
+// expireHistory drops recent messages older than the history TTL.
+func (h *Hub) expireHistory(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cutoff := now.Add(-h.historyTTL)
+	for userID, history := range h.history {
+		i := 0
+		for i < len(history) && history[i].CreatedAt.Before(cutoff) {
+			i++
+		}
+		if i == len(history) {
+			delete(h.history, userID)
+		} else if i > 0 {
+			h.history[userID] = history[i:]
+		}
+	}
+}
+
+// remove unsubscribes sub and ends it. h.mu must be held.
+func (h *Hub) remove(sub *Subscription) {
+	if subs, ok := h.subs[sub.userID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subs, sub.userID)
+		}
+	}
+	sub.end()
+}
+
+func (s *Subscription) end() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go-modular/modules/realtime/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHub(opts HubOpts) *Hub {
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	return NewHub(opts)
+}
+
+func receive(t *testing.T, sub *Subscription) *models.Message {
+	t.Helper()
+	select {
+	case msg := <-sub.C():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func isDone(sub *Subscription) bool {
+	select {
+	case <-sub.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_FanOutPerUser(t *testing.T) {
+	ctx := context.Background()
+	hub := newTestHub(HubOpts{})
+	alice, bob := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	web, _ := hub.Subscribe(alice, uuid.Must(uuid.NewV4()), nil)
+	defer web.Close()
+	mobile, _ := hub.Subscribe(alice, uuid.Must(uuid.NewV4()), nil)
+	defer mobile.Close()
+	other, _ := hub.Subscribe(bob, uuid.Must(uuid.NewV4()), nil)
+	defer other.Close()
+
+	require.NoError(t, hub.Notify(ctx, alice, models.MessageEmailVerified, map[string]string{"email": "alice@example.com"}))
+
+	for _, sub := range []*Subscription{web, mobile} {
+		msg := receive(t, sub)
+		assert.Equal(t, models.MessageEmailVerified, msg.Type)
+		assert.JSONEq(t, `{"email":"alice@example.com"}`, string(msg.Data))
+		assert.NotEqual(t, uuid.Nil, msg.ID)
+	}
+	assert.Empty(t, other.C())
+}
+
+func TestHub_ReplayAfterLastEventID(t *testing.T) {
+	ctx := context.Background()
+	hub := newTestHub(HubOpts{})
+	userID := uuid.Must(uuid.NewV4())
+
+	first := &models.Message{UserID: userID, Type: models.MessageUserUpdated}
+	second := &models.Message{UserID: userID, Type: models.MessagePasswordChanged}
+	require.NoError(t, hub.Publish(ctx, first))
+	require.NoError(t, hub.Publish(ctx, second))
+
+	// Only the messages after the last received one are replayed
+	sub, missed := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), &first.ID)
+	defer sub.Close()
+	require.Len(t, missed, 1)
+	assert.Equal(t, second.ID, missed[0].ID)
+
+	// New clients get no history
+	fresh, missed := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), nil)
+	defer fresh.Close()
+	assert.Empty(t, missed)
+
+	// Expired messages are not replayed
+	hub.expireHistory(time.Now().Add(time.Hour))
+	late, missed := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), &first.ID)
+	defer late.Close()
+	assert.Empty(t, missed)
+}
+
+func TestHub_EndingMessages(t *testing.T) {
+	ctx := context.Background()
+	hub := newTestHub(HubOpts{})
+	userID := uuid.Must(uuid.NewV4())
+	revoked, kept := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	revokedSub, _ := hub.Subscribe(userID, revoked, nil)
+	defer revokedSub.Close()
+	keptSub, _ := hub.Subscribe(userID, kept, nil)
+	defer keptSub.Close()
+
+	// The revoked session gets the message, then its stream ends
+	require.NoError(t, hub.Publish(ctx, &models.Message{UserID: userID, Type: models.MessageSessionRevoked, EndSessionID: &revoked}))
+	assert.Equal(t, models.MessageSessionRevoked, receive(t, revokedSub).Type)
+	assert.True(t, isDone(revokedSub))
+	assert.Equal(t, models.MessageSessionRevoked, receive(t, keptSub).Type)
+	assert.False(t, isDone(keptSub))
+
+	// Deleting the user ends all of its streams
+	require.NoError(t, hub.Publish(ctx, &models.Message{UserID: userID, Type: models.MessageUserDeleted, EndAll: true}))
+	assert.Equal(t, models.MessageUserDeleted, receive(t, keptSub).Type)
+	assert.True(t, isDone(keptSub))
+}
+
+func TestHub_SlowStreamIsDisconnected(t *testing.T) {
+	ctx := context.Background()
+	hub := newTestHub(HubOpts{BufferSize: 2})
+	userID := uuid.Must(uuid.NewV4())
+
+	sub, _ := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), nil)
+	defer sub.Close()
+	for range 3 {
+		require.NoError(t, hub.Notify(ctx, userID, models.MessageUserUpdated, nil))
+	}
+	assert.True(t, isDone(sub))
+	assert.Len(t, sub.C(), 2)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := newTestHub(HubOpts{})
+	userID := uuid.Must(uuid.NewV4())
+
+	sub, _ := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), nil)
+	hub.Close()
+	assert.True(t, isDone(sub))
+	sub.Close()
+
+	// Streams opened during shutdown end immediately
+	late, _ := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), nil)
+	assert.True(t, isDone(late))
+}
+
+// memoryBroker is a Broker connecting hubs in the same process.
+type memoryBroker struct {
+	messages chan *models.Message
+}
+
+func (b *memoryBroker) Publish(_ context.Context, msg *models.Message) error {
+	b.messages <- msg
+	return nil
+}
+
+func (b *memoryBroker) Listen(ctx context.Context, deliver func(*models.Message)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-b.messages:
+			deliver(msg)
+		}
+	}
+}
+
+func TestHub_PublishThroughBroker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := newTestHub(HubOpts{Broker: &memoryBroker{messages: make(chan *models.Message, 1)}})
+	go hub.Run(ctx)
+	userID := uuid.Must(uuid.NewV4())
+
+	sub, _ := hub.Subscribe(userID, uuid.Must(uuid.NewV4()), nil)
+	defer sub.Close()
+	require.NoError(t, hub.Notify(ctx, userID, models.MessageUserUpdated, map[string]any{"fields": []string{"name"}}))
+	assert.Equal(t, models.MessageUserUpdated, receive(t, sub).Type)
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"go-modular/modules/user/models"
 	"go-modular/modules/user/services"
@@ -18,7 +20,26 @@ type HandlerInterface interface {
 	ListUsers(c echo.Context) error
 	GetUser(c echo.Context) error
 	UpdateUser(c echo.Context) error
+	PatchUser(c echo.Context) error
 	DeleteUser(c echo.Context) error
+	GetMetadataSchema(c echo.Context) error
+
+	// Bulk import and export (admin only)
+	ImportUsers(c echo.Context) error
+	GetImportJob(c echo.Context) error
+	ExportUsers(c echo.Context) error
+	GetExportJob(c echo.Context) error
+	DownloadExport(c echo.Context) error
+
+	// Self-service handlers of the authenticated user (/users/me)
+	GetMe(c echo.Context) error
+	UpdateMe(c echo.Context) error
+	DeleteMe(c echo.Context) error
+
+	// Personal data export of the authenticated user, downloaded with the emailed link
+	RequestMyDataExport(c echo.Context) error
+	GetMyDataExport(c echo.Context) error
+	DownloadDataExport(c echo.Context) error
 }
 
 // Ensure Handler implements HandlerInterface
@@ -46,7 +67,7 @@ func NewHandler(opts *HandlerOpts) *Handler {
 }
 
 // @Summary      Create a new user
-// @Description  Creates a new user in the system
+// @Description  Creates a new user in the system (admin only)
 // @Tags         User Management
 // @Security     BearerAuth
 // @Param        Authorization  header    string                      true  "Bearer {token}"
@@ -83,21 +104,28 @@ func (h *Handler) CreateUser(c echo.Context) error {
 }
 
 // @Summary      List users
-// @Description  Retrieves a list of users
+// @Description  Retrieves a list of users. Filter on metadata fields declared in the user metadata schema with metadata.<field>=<value> query parameters, e.g. metadata.department=sales.
 // @Tags         User Management
 // @Security     BearerAuth
 // @Param        Authorization  header    string                      true  "Bearer {token}"
 // @Produce      json
+// @Param        search            query     string  false  "Only users whose display name or username contains this text"
+// @Param        metadata.{field}  query     string  false  "Only users whose metadata field has this value"
 // @Success      200  {array}   models.User
+// @Failure      400  {object}  map[string]string
 // @Router       /api/v1/users [get]
 func (h *Handler) ListUsers(c echo.Context) error {
 	var filter models.FilterUser
 	if err := c.Bind(&filter); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid filter parameters"})
 	}
+	filter.Metadata = metadataFilter(c)
 
 	users, err := h.userService.ListUsers(c.Request().Context(), &filter)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidMetadataFilter) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		h.logger.Error("Failed to list users", slog.String("error", err.Error()))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve users"})
 	}
@@ -128,20 +156,23 @@ func (h *Handler) GetUser(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
 	}
 
+	setETag(c, user)
 	return c.JSON(http.StatusOK, user)
 }
 
 // @Summary      Update user
-// @Description  Updates an existing user by ID
+// @Description  Updates the name and email of an existing user by ID (admin only)
 // @Tags         User Management
 // @Security     BearerAuth
-// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Param        Authorization  header    string                      true   "Bearer {token}"
+// @Param        If-Match       header    string                      false  "ETag of the user the update is based on"
 // @Accept       json
 // @Produce      json
 // @Param        id    path      string  true  "User ID"
 // @Param        user  body      models.UserCreateRequest  true  "User payload"
 // @Success      200   {object}  map[string]string
 // @Failure      400   {object}  map[string]string
+// @Failure      412   {object}  map[string]string
 // @Router       /api/v1/users/:userId [put]
 func (h *Handler) UpdateUser(c echo.Context) error {
 	idStr := c.Param("userId")
@@ -154,6 +185,8 @@ func (h *Handler) UpdateUser(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
 	}
+	// Validate the display name that will be stored, so a blank one is rejected
+	req.Name = strings.TrimSpace(req.Name)
 	if err := h.validator.Struct(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]any{
 			"error":   "Validation failed",
@@ -161,24 +194,31 @@ func (h *Handler) UpdateUser(c echo.Context) error {
 		})
 	}
 
-	// Map request fields to user model
-	user := &models.User{
-		ID:              id,
-		DisplayName:     req.Name,
-		Email:           req.Email,
-		EmailVerifiedAt: nil,
+	// Apply request fields to the stored user so other columns keep their values
+	user, err := h.userService.GetUserForUpdate(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("User not found", slog.String("error", err.Error()))
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
 	}
+	if !checkIfMatch(c, user) {
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "User was modified by someone else, reload and try again"})
+	}
+	patch := models.NewUserPatch(user)
+	patch.DisplayName = req.Name
+	patch.Email = req.Email
 
-	if err := h.userService.UpdateUser(c.Request().Context(), user); err != nil {
-		h.logger.Error("Failed to update user", slog.String("error", err.Error()))
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user, please try again later"})
+	version := user.Version()
+	updated, err := h.userService.PatchUser(c.Request().Context(), id, &version, patch)
+	if err != nil {
+		return h.respondUpdateError(c, err)
 	}
 
+	setETag(c, updated)
 	return c.JSON(http.StatusOK, map[string]string{"message": "User updated successfully"})
 }
 
 // @Summary      Delete user
-// @Description  Deletes a user by ID
+// @Description  Deletes a user by ID (admin only)
 // @Tags         User Management
 // @Security     BearerAuth
 // @Param        Authorization  header    string                      true  "Bearer {token}"
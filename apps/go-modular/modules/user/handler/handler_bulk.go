@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"go-modular/modules/user/models"
+	"go-modular/modules/user/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportFileSize is the largest import file accepted by the API. Larger files can be
+// imported with the users:import command.
+const maxImportFileSize = 64 << 20
+
+// importFormatFromContentType maps the Content-Type of an import upload to its format.
+func importFormatFromContentType(contentType string) models.BulkFormat {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return models.BulkFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return models.BulkFormatNDJSON
+	}
+	return ""
+}
+
+// importFile returns the uploaded import file: the "file" field of a multipart form, or the
+// raw request body.
+func importFile(c echo.Context) (io.ReadCloser, string, error) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxImportFileSize)
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		return c.Request().Body, contentType, nil
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	// Browsers often send uploads as application/octet-stream, fall back to the extension
+	contentType = fh.Header.Get(echo.HeaderContentType)
+	switch strings.ToLower(path.Ext(fh.Filename)) {
+	case ".csv":
+		contentType = "text/csv"
+	case ".ndjson", ".jsonl":
+		contentType = "application/x-ndjson"
+	}
+	return f, contentType, nil
+}
+
+// @Summary      Import users
+// @Description  Starts an asynchronous import of users from a CSV file (header row with email, display_name and optionally username, avatar_url, timezone) or NDJSON file (one object per line). Send the file as request body or as the "file" field of a multipart form. Every record is validated; invalid records are listed in the report of the job without stopping the import. With dry_run=true nothing is created. With invite=true every created user is emailed a single use link to set their password, see POST /api/v1/auth/invitation/accept (admin only).
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        format   query     string  false  "csv or ndjson (default: from Content-Type)"
+// @Param        dry_run  query     bool    false  "Validate only, create nothing"
+// @Param        invite   query     bool    false  "Email created users a link to set their password"
+// @Success      202      {object}  models.BulkJob
+// @Failure      400      {object}  map[string]string
+// @Failure      413      {object}  map[string]string
+// @Router       /api/v1/users/imports [post]
+func (h *Handler) ImportUsers(c echo.Context) error {
+	var req models.ImportUsersRequest
+	// Only bind the query, the body is the import file
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	file, contentType, err := importFile(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing import file"})
+	}
+	defer file.Close()
+
+	format := models.BulkFormat(req.Format)
+	if format == "" {
+		format = importFormatFromContentType(contentType)
+	}
+	if format == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Format must be given as format=csv|ndjson or by the Content-Type"})
+	}
+
+	job, err := h.userService.StartImportJob(c.Request().Context(), file, models.ImportOptions{
+		Format: format,
+		DryRun: req.DryRun,
+		Invite: req.Invite,
+	})
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "Import file is too large, use the users:import command instead"})
+		}
+		if errors.Is(err, services.ErrInvalidImportFile) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		h.logger.Error("Failed to start user import", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start import, please try again later"})
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// @Summary      Get import job
+// @Description  Retrieves the status of an import job; the report is included once it finished. Jobs are kept for an hour after finishing (admin only).
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Param        jobId  path      string  true  "Job ID"
+// @Success      200    {object}  models.BulkJob
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /api/v1/users/imports/{jobId} [get]
+func (h *Handler) GetImportJob(c echo.Context) error {
+	return h.getBulkJob(c, models.BulkJobImport)
+}
+
+// @Summary      Export users
+// @Description  Starts an asynchronous export of users (newest first) as CSV or NDJSON. Download the file from /users/exports/{jobId}/download once the job succeeded (admin only).
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Param        format  query     string  true   "csv or ndjson"
+// @Param        search            query     string  false  "Only users whose display name or username contains this text"
+// @Param        metadata.{field}  query     string  false  "Only users whose metadata field has this value"
+// @Success      202     {object}  models.BulkJob
+// @Failure      400     {object}  map[string]string
+// @Router       /api/v1/users/exports [post]
+func (h *Handler) ExportUsers(c echo.Context) error {
+	var req models.ExportUsersRequest
+	// Export filters are query parameters, there is no body
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	filter := &models.FilterUser{Search: req.Search, Metadata: metadataFilter(c)}
+	job, err := h.userService.StartExportJob(c.Request().Context(), filter, models.BulkFormat(req.Format))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMetadataFilter) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		h.logger.Error("Failed to start user export", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start export, please try again later"})
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// @Summary      Get export job
+// @Description  Retrieves the status of an export job. Jobs and their files are kept for an hour after finishing (admin only).
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Param        jobId  path      string  true  "Job ID"
+// @Success      200    {object}  models.BulkJob
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /api/v1/users/exports/{jobId} [get]
+func (h *Handler) GetExportJob(c echo.Context) error {
+	return h.getBulkJob(c, models.BulkJobExport)
+}
+
+// @Summary      Download export
+// @Description  Downloads the file of a succeeded export job (admin only).
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        jobId  path      string  true  "Job ID"
+// @Success      200    {file}    file
+// @Failure      404    {object}  map[string]string
+// @Failure      409    {object}  map[string]string
+// @Router       /api/v1/users/exports/{jobId}/download [get]
+func (h *Handler) DownloadExport(c echo.Context) error {
+	id, err := uuid.FromString(c.Param("jobId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Job ID in path must be a valid UUID"})
+	}
+
+	job, file, err := h.userService.OpenExport(c.Request().Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBulkJobNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Export not found"})
+		case errors.Is(err, services.ErrExportNotReady):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Export has not succeeded", "status": string(job.Status)})
+		}
+		h.logger.Error("Failed to open user export", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to download export, please try again later"})
+	}
+	defer file.Close()
+
+	filename := "users-" + job.CreatedAt.UTC().Format("20060102-150405") + "." + string(job.Format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return c.Stream(http.StatusOK, job.Format.ContentType(), file)
+}
+
+func (h *Handler) getBulkJob(c echo.Context, kind models.BulkJobKind) error {
+	id, err := uuid.FromString(c.Param("jobId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Job ID in path must be a valid UUID"})
+	}
+
+	job, err := h.userService.GetBulkJob(c.Request().Context(), kind, id)
+	if err != nil {
+		if errors.Is(err, services.ErrBulkJobNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Job not found"})
+		}
+		h.logger.Error("Failed to get bulk job", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get job, please try again later"})
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"go-modular/modules/user/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary      Request my data export
+// @Description  Starts building a ZIP archive with all personal data held about the authenticated user (profile, sessions, audit events and the data of the other modules). A time-limited download link is emailed once it is ready.
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Success      202  {object}  models.DataExport
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /api/v1/users/me/export [post]
+func (h *Handler) RequestMyDataExport(c echo.Context) error {
+	userID, ok := meUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	export, err := h.userService.RequestDataExport(c.Request().Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDataExportInProgress):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "A data export is already in progress"})
+		case errors.Is(err, services.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		h.logger.Error("Failed to request data export", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to request data export, please try again later"})
+	}
+
+	return c.JSON(http.StatusAccepted, export)
+}
+
+// @Summary      Get my data export
+// @Description  Retrieves the status of the authenticated user's most recent data export
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  models.DataExport
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /api/v1/users/me/export [get]
+func (h *Handler) GetMyDataExport(c echo.Context) error {
+	userID, ok := meUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	export, err := h.userService.GetLatestDataExport(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrDataExportNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "No data export found"})
+		}
+		h.logger.Error("Failed to get data export", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get data export"})
+	}
+
+	return c.JSON(http.StatusOK, export)
+}
+
+// @Summary      Download data export
+// @Description  Downloads a personal data export archive with the token of the emailed link. No authentication is required; the token expires with the link.
+// @Tags         User Profile
+// @Produce      application/zip
+// @Param        token  query     string  true  "Download token from the emailed link"
+// @Success      200    {file}    file
+// @Failure      404    {object}  map[string]string
+// @Router       /api/v1/users/data-exports/download [get]
+func (h *Handler) DownloadDataExport(c echo.Context) error {
+	export, err := h.userService.OpenDataExport(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrDataExportNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Invalid or expired download link"})
+		}
+		h.logger.Error("Failed to open data export", slog.String("error", err.Error()))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to download data export, please try again later"})
+	}
+
+	filename := "personal-data-" + export.CreatedAt.UTC().Format("20060102-150405") + ".zip"
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.Stream(http.StatusOK, "application/zip", bytes.NewReader(export.Archive))
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go-modular/modules/auth"
+	"go-modular/modules/user/models"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// meUserID returns the ID of the authenticated user. Service tokens have no user.
+func meUserID(c echo.Context) (uuid.UUID, bool) {
+	uid, ok := auth.GetUserID(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.FromString(uid)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// bindAllowedFields decodes the JSON body into dst after checking that it only contains the
+// allowed top-level fields. The rejected field names are returned, sorted, when it does not.
+func bindAllowedFields(c echo.Context, allowed []string, dst any) ([]string, error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	var rejected []string
+	for name := range fields {
+		if !slices.Contains(allowed, name) {
+			rejected = append(rejected, name)
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		return rejected, nil
+	}
+	return nil, json.NewDecoder(bytes.NewReader(body)).Decode(dst)
+}
+
+// @Summary      Get my profile
+// @Description  Retrieves the profile of the authenticated user
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  models.User
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/users/me [get]
+func (h *Handler) GetMe(c echo.Context) error {
+	userID, ok := meUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	user, err := h.userService.GetUserByID(c.Request().Context(), userID)
+	if err != nil {
+		h.logger.Error("User not found", slog.String("error", err.Error()))
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	setETag(c, user)
+	return c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Update my profile
+// @Description  Partially updates the profile of the authenticated user. Only display_name, username, avatar_url and metadata can be changed; requests containing other fields are rejected.
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true   "Bearer {token}"
+// @Param        If-Match       header    string                      false  "ETag of the profile the update is based on"
+// @Accept       json
+// @Produce      json
+// @Param        user  body      models.UpdateProfileRequest  true  "Profile fields to change"
+// @Success      200   {object}  models.User
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      412   {object}  map[string]string
+// @Router       /api/v1/users/me [patch]
+func (h *Handler) UpdateMe(c echo.Context) error {
+	userID, ok := meUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	var req models.UpdateProfileRequest
+	rejected, err := bindAllowedFields(c, models.ProfileFields, &req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if len(rejected) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":          "Request contains fields that cannot be changed",
+			"fields":         rejected,
+			"allowed_fields": models.ProfileFields,
+		})
+	}
+	// Validate the display name that will be stored, so a blank one is rejected
+	if req.DisplayName != nil {
+		displayName := strings.TrimSpace(*req.DisplayName)
+		req.DisplayName = &displayName
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	// Resolve an If-Match precondition to the version it refers to
+	var version *time.Time
+	if c.Request().Header.Get("If-Match") != "" {
+		current, err := h.userService.GetUserForUpdate(c.Request().Context(), userID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		if !checkIfMatch(c, current) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "User was modified by someone else, reload and try again"})
+		}
+		v := current.Version()
+		version = &v
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request().Context(), userID, version, &req)
+	if err != nil {
+		return h.respondUpdateError(c, err)
+	}
+
+	setETag(c, user)
+	return c.JSON(http.StatusOK, user)
+}
+
+// @Summary      Delete my account
+// @Description  Deletes the account of the authenticated user together with its sessions, tokens and credentials
+// @Tags         User Profile
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Router       /api/v1/users/me [delete]
+func (h *Handler) DeleteMe(c echo.Context) error {
+	userID, ok := meUserID(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+	}
+
+	if err := h.userService.DeleteUser(c.Request().Context(), userID); err != nil {
+		h.logger.Error("Failed to delete user", slog.String("error", err.Error()))
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Account deleted successfully"})
+}
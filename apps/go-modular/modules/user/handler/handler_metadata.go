@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// metadataFilterPrefix prefixes the query parameters filtering users by metadata fields.
+const metadataFilterPrefix = "metadata."
+
+// metadataFilter collects metadata.<field>=<value> query parameters. Values are strings; the
+// user service converts them to the types declared in the metadata schema.
+func metadataFilter(c echo.Context) map[string]any {
+	var filter map[string]any
+	for name, values := range c.QueryParams() {
+		field, ok := strings.CutPrefix(name, metadataFilterPrefix)
+		if !ok || field == "" || len(values) == 0 {
+			continue
+		}
+		if filter == nil {
+			filter = map[string]any{}
+		}
+		filter[field] = values[0]
+	}
+	return filter
+}
+
+// @Summary      Get user metadata schema
+// @Description  Retrieves the JSON Schema declaring the custom fields of user metadata. Metadata written to users must match it; declared fields can be used as metadata.<field> filters.
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {object}  map[string]any
+// @Router       /api/v1/users/metadata-schema [get]
+func (h *Handler) GetMetadataSchema(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, h.userService.MetadataSchema().Document())
+}
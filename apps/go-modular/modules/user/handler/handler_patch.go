@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-modular/modules/user/models"
+	"go-modular/modules/user/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errUnsupportedPatchType is returned by readMergePatch for bodies that are not JSON Merge Patch.
+var errUnsupportedPatchType = errors.New("unsupported patch content type")
+
+// readMergePatch reads a JSON Merge Patch request body.
+func readMergePatch(c echo.Context) ([]byte, error) {
+	if !apputils.IsMergePatchContentType(c.Request().Header.Get(echo.HeaderContentType)) {
+		return nil, errUnsupportedPatchType
+	}
+	return io.ReadAll(c.Request().Body)
+}
+
+// rejectedFields returns the top-level members of patch that are not in allowed, sorted.
+func rejectedFields(patch []byte, allowed []string) []string {
+	var rejected []string
+	for _, name := range apputils.MergePatchFields(patch) {
+		if !slices.Contains(allowed, name) {
+			rejected = append(rejected, name)
+		}
+	}
+	sort.Strings(rejected)
+	return rejected
+}
+
+// setETag sets the ETag response header of user.
+func setETag(c echo.Context, user *models.User) {
+	c.Response().Header().Set("ETag", apputils.TimeETag(user.Version()))
+}
+
+// checkIfMatch evaluates the If-Match request header against the current version of user.
+func checkIfMatch(c echo.Context, user *models.User) bool {
+	return apputils.IfMatch(c.Request().Header.Get("If-Match"), apputils.TimeETag(user.Version()))
+}
+
+// respondUpdateError maps user update errors to responses.
+func (h *Handler) respondUpdateError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	case errors.Is(err, services.ErrPreconditionFailed):
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "User was modified by someone else, reload and try again"})
+	case errors.Is(err, services.ErrUsernameTaken):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidUsername):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDisplayName):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": map[string]string{"display_name": err.Error()},
+		})
+	}
+	var metadataErr *services.MetadataValidationError
+	if errors.As(err, &metadataErr) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Metadata validation failed",
+			"details": metadataErr.Fields,
+		})
+	}
+	h.logger.Error("Failed to update user", slog.String("error", err.Error()))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user, please try again later"})
+}
+
+// @Summary      Patch user
+// @Description  Partially updates a user with a JSON Merge Patch (RFC 7386): provided members replace the current values, null removes optional ones (avatar_url, metadata). Only display_name, email, username, avatar_url and metadata can be changed. Send the ETag of the user in If-Match to fail with 412 instead of overwriting concurrent changes (admin only).
+// @Tags         User Management
+// @Security     BearerAuth
+// @Param        Authorization  header    string                      true   "Bearer {token}"
+// @Param        If-Match       header    string                      false  "ETag of the user the patch is based on"
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        userId  path      string            true  "User ID"
+// @Param        patch   body      models.UserPatch  true  "Merge patch"
+// @Success      200     {object}  models.User
+// @Failure      400     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      409     {object}  map[string]string
+// @Failure      412     {object}  map[string]string
+// @Failure      415     {object}  map[string]string
+// @Router       /api/v1/users/{userId} [patch]
+func (h *Handler) PatchUser(c echo.Context) error {
+	id, err := models.ParseUserID(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User ID in path must be a valid UUID"})
+	}
+
+	patch, err := readMergePatch(c)
+	if err != nil {
+		if errors.Is(err, errUnsupportedPatchType) {
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": "Content-Type must be " + apputils.MergePatchContentType})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if rejected := rejectedFields(patch, models.UserPatchFields); len(rejected) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":          "Request contains fields that cannot be changed",
+			"fields":         rejected,
+			"allowed_fields": models.UserPatchFields,
+		})
+	}
+
+	user, err := h.userService.GetUserForUpdate(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("User not found", slog.String("error", err.Error()))
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+	if !checkIfMatch(c, user) {
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "User was modified by someone else, reload and try again"})
+	}
+
+	// Apply the merge patch to the current document of the user
+	doc, err := json.Marshal(models.NewUserPatch(user))
+	if err != nil {
+		return err
+	}
+	merged, err := apputils.MergePatch(doc, patch)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	var req models.UserPatch
+	if err := json.Unmarshal(merged, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	// Validate the display name that will be stored, so a blank one is rejected
+	req.DisplayName = strings.TrimSpace(req.DisplayName)
+	if err := h.validator.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":   "Validation failed",
+			"details": apputils.ValidationErrorsToMap(err, req),
+		})
+	}
+
+	version := user.Version()
+	updated, err := h.userService.PatchUser(c.Request().Context(), id, &version, &req)
+	if err != nil {
+		return h.respondUpdateError(c, err)
+	}
+
+	setETag(c, updated)
+	return c.JSON(http.StatusOK, updated)
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Administrators
+-- is_admin grants access to the /admin endpoints (e.g. user impersonation).
+-- It is only changed through the users:set-admin CLI command, never via the API.
+-- ============================================================================
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE public.users DROP COLUMN IF EXISTS is_admin;
+
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create user data exports table and indexes
+-- Personal data exports (GDPR data portability) requested via POST /users/me/export.
+-- The ZIP archive is stored until the emailed download link expires; only the SHA-256
+-- hash of the download token is stored.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.user_data_exports (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    token_hash TEXT DEFAULT NULL, -- hashed download token (SHA256), set once ready
+    archive BYTEA DEFAULT NULL, -- ZIP archive, set once ready
+    size_bytes BIGINT DEFAULT NULL,
+    error TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ DEFAULT NULL,
+    expires_at TIMESTAMPTZ DEFAULT NULL -- download link expiry, set once ready
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_user_id_created_at ON public.user_data_exports (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_expires_at ON public.user_data_exports (expires_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_data_exports_token_hash_unique ON public.user_data_exports (token_hash);
+
+-- At most one export per user is being built at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_data_exports_user_id_pending ON public.user_data_exports (user_id) WHERE status = 'pending';
+
+-- Archives are never needed inside tenant-scoped transactions
+REVOKE ALL ON public.user_data_exports FROM app_tenant;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop indexes and table (reverse order of creation)
+DROP INDEX IF EXISTS idx_user_data_exports_user_id_pending;
+DROP INDEX IF EXISTS idx_user_data_exports_token_hash_unique;
+DROP INDEX IF EXISTS idx_user_data_exports_expires_at;
+DROP INDEX IF EXISTS idx_user_data_exports_user_id_created_at;
+DROP TABLE IF EXISTS public.user_data_exports;
+
+-- +goose StatementEnd
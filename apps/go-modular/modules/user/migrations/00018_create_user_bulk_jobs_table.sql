@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create user bulk jobs table and indexes
+-- Import and export jobs started through the admin API (POST /users/imports and
+-- POST /users/exports). Jobs are stored so that every instance can report their status and
+-- serve export files; the file of a succeeded export is kept until the job expires.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.user_bulk_jobs (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    kind TEXT NOT NULL CHECK (kind IN ('import', 'export')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'succeeded', 'failed')),
+    format TEXT NOT NULL CHECK (format IN ('csv', 'ndjson')),
+    error TEXT DEFAULT NULL,
+    report JSONB DEFAULT NULL, -- import report, set once finished
+    exported INTEGER NOT NULL DEFAULT 0, -- users written by an export
+    file BYTEA DEFAULT NULL, -- export file, set once succeeded
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMPTZ DEFAULT NULL,
+    expires_at TIMESTAMPTZ DEFAULT NULL -- set once finished
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_user_bulk_jobs_expires_at ON public.user_bulk_jobs (expires_at);
+
+-- Bulk jobs are never needed inside tenant-scoped transactions
+REVOKE ALL ON public.user_bulk_jobs FROM app_tenant;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop indexes and table (reverse order of creation)
+DROP INDEX IF EXISTS idx_user_bulk_jobs_expires_at;
+DROP TABLE IF EXISTS public.user_bulk_jobs;
+
+-- +goose StatementEnd
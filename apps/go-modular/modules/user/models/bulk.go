@@ -0,0 +1,127 @@
+// Package models contains HTTP request/response schema definitions for the user module.
+// This file defines the records, reports and jobs of bulk user import and export.
+
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Define table name for BulkJob model
+const BulkJobTable = "public.user_bulk_jobs"
+
+// BulkFormat is the file format of a bulk import or export.
+type BulkFormat string
+
+const (
+	BulkFormatCSV    BulkFormat = "csv"    // Comma separated values with a header row
+	BulkFormatNDJSON BulkFormat = "ndjson" // JSON Lines, one object per line
+)
+
+// IsValid reports whether f is a supported format.
+func (f BulkFormat) IsValid() bool {
+	return f == BulkFormatCSV || f == BulkFormatNDJSON
+}
+
+// ContentType returns the MIME type of files in format f.
+func (f BulkFormat) ContentType() string {
+	if f == BulkFormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// ImportCSVColumns are the CSV columns read by an import. The header row may list them in any
+// order; only email and display_name are required.
+var ImportCSVColumns = []string{"email", "display_name", "username", "avatar_url", "timezone"}
+
+// ExportCSVColumns are the CSV columns written by an export, in order. Files produced by an
+// export can be imported again; the read-only columns are ignored.
+var ExportCSVColumns = []string{
+	"id", "email", "display_name", "username", "avatar_url", "timezone",
+	"created_at", "updated_at", "email_verified_at", "last_login_at", "is_admin",
+}
+
+// ImportUserRecord is one user of an import file, a CSV row or an NDJSON line.
+type ImportUserRecord struct {
+	Email       string        `json:"email" validate:"required,email" example:"johndoe@example.com"`
+	DisplayName string        `json:"display_name" validate:"required,max=100" example:"John Doe"`
+	Username    string        `json:"username,omitempty" validate:"omitempty,min=3,max=32" example:"johndoe"` // Generated from the email when empty
+	AvatarURL   string        `json:"avatar_url,omitempty" validate:"omitempty,url" example:"https://example.com/avatar.png"`
+	Metadata    *UserMetadata `json:"metadata,omitempty"`
+}
+
+// ImportOptions controls a bulk import.
+type ImportOptions struct {
+	Format BulkFormat // File format (required)
+	DryRun bool       // Validate every record without creating users
+	Invite bool       // Invite every created user to set a password, see UserInvited
+}
+
+// ImportRowError reports why a record of an import file was rejected.
+type ImportRowError struct {
+	Row    int               `json:"row" example:"3"` // 1-based record number, not counting the CSV header
+	Email  string            `json:"email,omitempty" example:"johndoe@example.com"`
+	Errors map[string]string `json:"errors"` // Messages keyed by field
+}
+
+// ImportReport summarizes a bulk import.
+type ImportReport struct {
+	DryRun          bool             `json:"dry_run"`
+	Total           int              `json:"total" example:"250"`   // Records read
+	Valid           int              `json:"valid" example:"248"`   // Records that passed validation
+	Created         int              `json:"created" example:"248"` // Users created (0 on dry runs)
+	Failed          int              `json:"failed" example:"2"`    // Records rejected
+	Invited         int              `json:"invited" example:"248"` // Invitations queued
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"` // More records failed than listed in errors
+}
+
+// BulkJobKind distinguishes import from export jobs.
+type BulkJobKind string
+
+const (
+	BulkJobImport BulkJobKind = "import"
+	BulkJobExport BulkJobKind = "export"
+)
+
+// BulkJobStatus is the state of a bulk job.
+type BulkJobStatus string
+
+const (
+	BulkJobPending   BulkJobStatus = "pending"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobSucceeded BulkJobStatus = "succeeded"
+	BulkJobFailed    BulkJobStatus = "failed"
+)
+
+// BulkJob is an asynchronous import or export started through the admin API. Jobs are stored
+// in the database, so any instance reports their status; the file of an export is only loaded
+// when downloading.
+type BulkJob struct {
+	ID         uuid.UUID     `json:"id"`
+	Kind       BulkJobKind   `json:"kind" example:"import"`
+	Status     BulkJobStatus `json:"status" example:"running"`
+	Format     BulkFormat    `json:"format" example:"csv"`
+	CreatedAt  time.Time     `json:"created_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Error      string        `json:"error,omitempty"`      // Why the job failed
+	Report     *ImportReport `json:"report,omitempty"`     // Import jobs, once finished
+	Exported   int           `json:"exported,omitempty"`   // Export jobs: users written, once succeeded
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty"` // When the job and its export file are discarded
+}
+
+// ImportUsersRequest holds the query parameters of POST /users/imports.
+type ImportUsersRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv ndjson" example:"csv"` // Defaults to the format of the Content-Type
+	DryRun bool   `query:"dry_run" example:"false"`
+	Invite bool   `query:"invite" example:"true"`
+}
+
+// ExportUsersRequest holds the query parameters of POST /users/exports.
+type ExportUsersRequest struct {
+	Format string  `query:"format" validate:"required,oneof=csv ndjson" example:"csv"`
+	Search *string `query:"search" example:"john"`
+}
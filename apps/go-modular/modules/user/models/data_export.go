@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Define table name for DataExport model
+const DataExportTable = "public.user_data_exports"
+
+// DataExportStatus is the state of a personal data export.
+type DataExportStatus string
+
+const (
+	DataExportPending DataExportStatus = "pending"
+	DataExportReady   DataExportStatus = "ready"
+	DataExportFailed  DataExportStatus = "failed"
+)
+
+// DataExport is a personal data export (GDPR data portability) of a user. The archive is only
+// loaded when downloading and is never serialized.
+type DataExport struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	UserID      uuid.UUID        `json:"user_id" db:"user_id"`
+	Status      DataExportStatus `json:"status" db:"status"`
+	TokenHash   *string          `json:"-" db:"token_hash"`
+	Archive     []byte           `json:"-" db:"archive"`
+	SizeBytes   *int64           `json:"size_bytes" db:"size_bytes"`
+	Error       *string          `json:"-" db:"error"` // Internal error, not shown to the user
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time       `json:"completed_at" db:"completed_at"`
+	ExpiresAt   *time.Time       `json:"expires_at" db:"expires_at"`
+}
+
+// IsExpired reports whether the download link of a ready export has expired at now.
+func (e *DataExport) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
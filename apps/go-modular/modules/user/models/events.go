@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// Domain events published by the user module on the event bus (see internal/eventbus).
+const (
+	EventUserCreated       = "user.created"
+	EventUserEmailVerified = "user.email_verified"
+	EventUserDeleted       = "user.deleted"
+	EventUserUpdated       = "user.updated"
+	EventUserInvited       = "user.invited"
+)
+
+// UserCreated is published after a user was created, including bulk imports.
+type UserCreated struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Email      string    `json:"email"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (UserCreated) EventName() string { return EventUserCreated }
+
+// UserEmailVerified is published after a user confirmed their email address.
+type UserEmailVerified struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Email      string    `json:"email"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (UserEmailVerified) EventName() string { return EventUserEmailVerified }
+
+// UserDeleted is published after a user was deleted. Rows referencing the user are
+// already gone by then (ON DELETE CASCADE).
+type UserDeleted struct {
+	UserID     uuid.UUID `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (UserDeleted) EventName() string { return EventUserDeleted }
+
+// UserUpdated is published after fields of a user were changed, by the user or by an
+// administrator. UpdatedBy is the acting user, when known.
+type UserUpdated struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Fields     []string   `json:"fields"`
+	UpdatedBy  *uuid.UUID `json:"updated_by,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+func (UserUpdated) EventName() string { return EventUserUpdated }
+
+// UserInvited is published after an import created a user with invitations enabled. The
+// user has no password yet; the auth module emails a link to set one.
+type UserInvited struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	Username    string    `json:"username,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+func (UserInvited) EventName() string { return EventUserInvited }
@@ -4,6 +4,9 @@
 package models
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -27,17 +30,66 @@ type User struct {
 	BannedAt        *time.Time    `json:"banned_at" db:"banned_at"`
 	BanExpires      *time.Time    `json:"ban_expires" db:"ban_expires"`
 	BanReason       *string       `json:"ban_reason" db:"ban_reason"`
+	IsAdmin         bool          `json:"is_admin" db:"is_admin"` // Set via the users:set-admin CLI command only
 }
 
+// BuiltinMetadataFields are the metadata fields every deployment has. Custom fields declared
+// in the user metadata schema are stored next to them and may not reuse their names.
+var BuiltinMetadataFields = []string{"timezone"}
+
+// UserMetadata holds user-specific information stored in the metadata JSONB column. Custom
+// holds the fields declared by the operator in the user metadata schema; they are stored and
+// serialized as top-level members next to the built-in fields, e.g. metadata ->> 'first_name'.
 type UserMetadata struct {
-	Timezone string `json:"timezone,omitempty"`
-	// Add more fields as needed
+	Timezone string         `json:"timezone,omitempty"`
+	Custom   map[string]any `json:"-" swaggerignore:"true"`
+}
+
+// MarshalJSON serializes the built-in and custom fields as a single object.
+func (m UserMetadata) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]any, len(m.Custom)+1)
+	for k, v := range m.Custom {
+		fields[k] = v
+	}
+	if m.Timezone != "" {
+		fields["timezone"] = m.Timezone
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON reads the built-in fields and keeps all other members in Custom. Numbers are
+// kept as json.Number so large integers survive a round trip.
+func (m *UserMetadata) UnmarshalJSON(data []byte) error {
+	var fields map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return err
+	}
+	*m = UserMetadata{}
+	if tz, ok := fields["timezone"]; ok {
+		s, ok := tz.(string)
+		if !ok && tz != nil {
+			return fmt.Errorf("metadata field \"timezone\" must be a string")
+		}
+		m.Timezone = s
+		delete(fields, "timezone")
+	}
+	if len(fields) > 0 {
+		m.Custom = fields
+	}
+	return nil
 }
 
 type FilterUser struct {
 	Search *string `json:"search,omitempty" query:"search"`
 	Limit  int     `json:"limit,omitempty" query:"limit"`
 	Offset int     `json:"offset,omitempty" query:"offset"`
+
+	// Metadata only matches users whose metadata contains these values (JSONB containment,
+	// served by the GIN index on metadata). Handlers fill it from metadata.<field> query
+	// parameters as strings; the user service converts them to the declared field types.
+	Metadata map[string]any `json:"metadata,omitempty" query:"-"`
 }
 
 type UserWithCredential struct {
@@ -63,3 +115,12 @@ func (u *User) AsUserModel() User {
 func (u *User) GetEmailVerifiedAt() *time.Time {
 	return u.EmailVerifiedAt
 }
+
+// Version returns the last modification time of the user (updated_at, or created_at when
+// never updated). It is the basis of the user's ETag and of conditional updates.
+func (u *User) Version() time.Time {
+	if u.UpdatedAt != nil {
+		return *u.UpdatedAt
+	}
+	return u.CreatedAt
+}
@@ -8,3 +8,41 @@ type UserCreateRequest struct {
 	Email    string `json:"email" validate:"required,email" example:"johndoe@example.com"`
 	Username string `json:"-" example:"johndoe"` // username generated by system, not visible to the user
 }
+
+// ProfileFields is the allowlist of user fields that users may change about themselves via
+// PATCH /users/me. Anything else (email, verification, ban or admin state) is rejected.
+var ProfileFields = []string{"display_name", "username", "avatar_url", "metadata"}
+
+// UpdateProfileRequest represents a partial self-service profile update. Omitted fields are
+// left unchanged.
+type UpdateProfileRequest struct {
+	DisplayName *string       `json:"display_name,omitempty" validate:"omitempty,min=1,max=100" example:"John Doe"`
+	Username    *string       `json:"username,omitempty" validate:"omitempty,min=3,max=32" example:"johndoe"`
+	AvatarURL   *string       `json:"avatar_url,omitempty" validate:"omitempty,url" example:"https://example.com/avatar.png"`
+	Metadata    *UserMetadata `json:"metadata,omitempty"`
+}
+
+// UserPatchFields lists the members of UserPatch, the only ones a patch may contain.
+var UserPatchFields = []string{"display_name", "email", "username", "avatar_url", "metadata"}
+
+// UserPatch is the document admins edit with PATCH /users/:userId (JSON Merge Patch, RFC
+// 7386). The patch is applied to the current values of these fields; members outside of it
+// (verification, ban or admin state) cannot be changed and are rejected.
+type UserPatch struct {
+	DisplayName string        `json:"display_name" validate:"required,max=100" example:"John Doe"`
+	Email       string        `json:"email" validate:"required,email" example:"johndoe@example.com"`
+	Username    *string       `json:"username" validate:"required,min=3,max=32" example:"johndoe"`
+	AvatarURL   *string       `json:"avatar_url" validate:"omitempty,url" example:"https://example.com/avatar.png"`
+	Metadata    *UserMetadata `json:"metadata"`
+}
+
+// NewUserPatch returns the patchable document of user.
+func NewUserPatch(user *User) *UserPatch {
+	return &UserPatch{
+		DisplayName: user.DisplayName,
+		Email:       user.Email,
+		Username:    user.Username,
+		AvatarURL:   user.AvatarURL,
+		Metadata:    user.Metadata,
+	}
+}
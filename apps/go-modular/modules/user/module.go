@@ -1,26 +1,74 @@
 package user
 
 import (
+	"context"
+	"embed"
+	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
+	"go-modular/internal/adapter"
+	"go-modular/internal/eventbus"
+	"go-modular/internal/module"
+	"go-modular/internal/notification"
+	"go-modular/internal/personaldata"
+	"go-modular/modules/auth"
 	"go-modular/modules/user/handler"
 	"go-modular/modules/user/repository"
 	"go-modular/modules/user/services"
 
+	auditSvc "go-modular/modules/audit/services"
+
+	"github.com/alexliesenfeld/health"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
 )
 
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func init() {
+	module.Register(&UserModule{})
+}
+
 type Options struct {
 	PgPool *pgxpool.Pool // PostgreSQL connection pool (required)
 	Logger *slog.Logger  // Slog logger instance (optional)
+
+	// Replicas routes user lookups and listings to read replicas (optional)
+	Replicas adapter.ReadRouter
+
+	// Auditor records security audit events (optional)
+	Auditor auditSvc.Recorder
+
+	// Mailer sends data export emails (optional)
+	Mailer *notification.Mailer
+
+	// DataExports collects the personal data exporters of all modules; the user module
+	// registers its own exporter (optional, exports only contain the profile when nil)
+	DataExports *personaldata.Registry
+	// DataExportLinkExpiry is the lifetime of emailed data export download links (default: 24h)
+	DataExportLinkExpiry time.Duration
+	// BaseURL is used when constructing data export download links (optional)
+	BaseURL string
+
+	// MetadataSchema declares the custom user metadata fields, see services.LoadMetadataSchema
+	// (optional, no custom fields are accepted when nil)
+	MetadataSchema *services.MetadataSchema
+
+	// Events is the domain event bus shared by all modules; user events are published on it
+	// (optional, a private bus when nil)
+	Events *eventbus.Bus
 }
 
 // UserModule holds dependencies for user-related handlers.
 type UserModule struct {
+	app         *module.App // set when initialized by the module registry
 	logger      *slog.Logger
 	middlewares []echo.MiddlewareFunc
+	admin       []echo.MiddlewareFunc
 	handler     *handler.Handler
 	userService services.UserServiceInterface
 }
@@ -32,10 +80,29 @@ func NewModule(opts *Options) *UserModule {
 		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
 	}
 
+	dataExports := opts.DataExports
+	if dataExports == nil {
+		dataExports = personaldata.NewRegistry()
+	}
+
+	userRepo := repository.NewUserRepository(opts.PgPool, logger)
+	if opts.Replicas != nil {
+		userRepo.UseReplicas(opts.Replicas)
+	}
+
 	// Initialize required services
 	userService := services.NewUserService(services.UserServiceOpts{
-		UserRepo: repository.NewUserRepository(opts.PgPool, logger),
+		UserRepo:             userRepo,
+		Auditor:              opts.Auditor,
+		Mailer:               opts.Mailer,
+		Logger:               logger,
+		DataExports:          dataExports,
+		DataExportLinkExpiry: opts.DataExportLinkExpiry,
+		BaseURL:              opts.BaseURL,
+		MetadataSchema:       opts.MetadataSchema,
+		Events:               opts.Events,
 	})
+	dataExports.Register(personaldata.NewExporter("user", userService.ExportPersonalData))
 
 	h := handler.NewHandler(&handler.HandlerOpts{
 		Logger:      logger,
@@ -49,6 +116,56 @@ func NewModule(opts *Options) *UserModule {
 	}
 }
 
+// Ensure UserModule implements module.Module
+var _ module.Module = (*UserModule)(nil)
+
+func (m *UserModule) Name() string { return "user" }
+
+func (m *UserModule) Dependencies() []string { return []string{"audit"} }
+
+// Init builds the module from the application config and provides its UserServiceInterface.
+func (m *UserModule) Init(_ context.Context, app *module.App) error {
+	cfg := app.Config
+
+	// Custom user metadata fields are declared by an optional JSON Schema
+	var metadataSchema *services.MetadataSchema
+	if path := cfg.App.UserMetadataSchemaFile; path != "" {
+		schema, err := services.LoadMetadataSchema(path)
+		if err != nil {
+			return err
+		}
+		metadataSchema = schema
+	}
+
+	*m = *NewModule(&Options{
+		PgPool:   app.DB.Pool,
+		Replicas: app.DB,
+		Logger:   app.Logger,
+		Auditor:  module.MustLookup[auditSvc.Recorder](app),
+		Mailer:   app.Mailer,
+
+		DataExports:          app.DataExports,
+		DataExportLinkExpiry: cfg.App.DataExportLinkExpiry,
+		BaseURL:              cfg.GetAppBaseURL(),
+		MetadataSchema:       metadataSchema,
+		Events:               app.Events,
+	})
+	m.app = app
+	module.Provide(app, m.userService)
+	return nil
+}
+
+func (m *UserModule) Start(context.Context) error { return nil }
+
+func (m *UserModule) Stop(context.Context) error { return nil }
+
+func (m *UserModule) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	return sub
+}
+
+func (m *UserModule) Health() []health.Check { return nil }
+
 // Expose UserService, so it can be used by other modules
 func (m *UserModule) GetUserService() services.UserServiceInterface {
 	return m.userService
@@ -59,12 +176,69 @@ func (m *UserModule) Use(mw ...echo.MiddlewareFunc) {
 	m.middlewares = append(m.middlewares, mw...)
 }
 
+// UseAdmin adds middleware(s) that authorize user tokens for the admin-only user management
+// routes, e.g. the auth module's RequireAdmin. They run after the grouped middlewares.
+func (m *UserModule) UseAdmin(mw ...echo.MiddlewareFunc) {
+	m.admin = append(m.admin, mw...)
+}
+
+// requireAdmin guards user management mutations. Service tokens pass, as they are already
+// authorized by their users:write scope; user tokens must pass the UseAdmin middlewares and
+// are rejected when none are configured.
+func (m *UserModule) requireAdmin() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		adminNext := next
+		for i := len(m.admin) - 1; i >= 0; i-- {
+			adminNext = m.admin[i](adminNext)
+		}
+		return func(c echo.Context) error {
+			if auth.IsServiceToken(c) {
+				return next(c)
+			}
+			if len(m.admin) == 0 {
+				return echo.NewHTTPError(http.StatusForbidden, "administrator privileges required")
+			}
+			return adminNext(c)
+		}
+	}
+}
+
 // RegisterRoutes registers user endpoints to the given Echo group.
 func (m *UserModule) RegisterRoutes(e *echo.Group) {
+	// Modules initialized by the registry authenticate with the auth module. Service tokens
+	// (client_credentials) need the users:read/write scope.
+	if m.app != nil {
+		authModule := module.MustLookup[*auth.AuthModule](m.app)
+		m.Use(authModule.JWTMiddleware(), auth.RequireResourceScopes("users"))
+		m.UseAdmin(authModule.RequireAdmin())
+	}
+
+	// Public: data export downloads are authorized by the token of the emailed link
+	e.GET("/users/data-exports/download", m.handler.DownloadDataExport)
+
 	g := e.Group("/users", m.middlewares...)
-	g.POST("", m.handler.CreateUser)
 	g.GET("", m.handler.ListUsers)
+	g.GET("/metadata-schema", m.handler.GetMetadataSchema)
 	g.GET("/:userId", m.handler.GetUser)
-	g.PUT("/:userId", m.handler.UpdateUser)
-	g.DELETE("/:userId", m.handler.DeleteUser)
+
+	// Self-service routes of the authenticated user (sessions are served by the auth module)
+	g.GET("/me", m.handler.GetMe)
+	g.PATCH("/me", m.handler.UpdateMe)
+	g.DELETE("/me", m.handler.DeleteMe, auth.DenyImpersonation())
+	g.POST("/me/export", m.handler.RequestMyDataExport, auth.DenyImpersonation())
+	g.GET("/me/export", m.handler.GetMyDataExport)
+
+	// User management (admin only)
+	admin := m.requireAdmin()
+	g.POST("", m.handler.CreateUser, admin)
+	g.PUT("/:userId", m.handler.UpdateUser, admin)
+	g.PATCH("/:userId", m.handler.PatchUser, admin)
+	g.DELETE("/:userId", m.handler.DeleteUser, admin)
+
+	// Bulk import and export (admin only)
+	g.POST("/imports", m.handler.ImportUsers, admin)
+	g.GET("/imports/:jobId", m.handler.GetImportJob, admin)
+	g.POST("/exports", m.handler.ExportUsers, admin)
+	g.GET("/exports/:jobId", m.handler.GetExportJob, admin)
+	g.GET("/exports/:jobId/download", m.handler.DownloadExport, admin)
 }
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go-modular/modules/user/models"
+)
+
+const bulkJobColumns = `id, kind, status, format, COALESCE(error, ''), report, exported, created_at, finished_at, expires_at`
+
+func scanBulkJob(row pgx.Row) (*models.BulkJob, error) {
+	var j models.BulkJob
+	if err := row.Scan(&j.ID, &j.Kind, &j.Status, &j.Format, &j.Error, &j.Report, &j.Exported, &j.CreatedAt, &j.FinishedAt, &j.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// CreateBulkJob stores a new pending import or export job.
+func (r *UserRepository) CreateBulkJob(ctx context.Context, job *models.BulkJob) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.Must(uuid.NewV7())
+	}
+	job.Status = models.BulkJobPending
+	job.CreatedAt = time.Now()
+	query := `INSERT INTO ` + models.BulkJobTable + ` (id, kind, status, format, created_at)
+        VALUES ($1, $2, $3, $4, $5)`
+	if _, err := r.conn(ctx).Exec(ctx, query, job.ID, job.Kind, job.Status, job.Format, job.CreatedAt); err != nil {
+		r.logger.Error("failed to create bulk job", slog.String("op", "CreateBulkJob"), slog.String("kind", string(job.Kind)), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// StartBulkJob marks a pending job as running.
+func (r *UserRepository) StartBulkJob(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE ` + models.BulkJobTable + ` SET status = 'running' WHERE id = $1 AND status = 'pending'`
+	cmd, err := r.conn(ctx).Exec(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to start bulk job", slog.String("op", "StartBulkJob"), slog.String("job_id", id.String()), slog.String("error", err.Error()))
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FinishBulkJob stores the outcome of an unfinished job: its status, error, report, export
+// count, finish and expiry times, and the file of an export. ErrNotFound is returned when the
+// job already finished.
+func (r *UserRepository) FinishBulkJob(ctx context.Context, job *models.BulkJob, file []byte) error {
+	query := `UPDATE ` + models.BulkJobTable + `
+        SET status = $1, error = NULLIF($2, ''), report = $3, exported = $4, file = $5, finished_at = $6, expires_at = $7
+        WHERE id = $8 AND status IN ('pending', 'running')`
+	cmd, err := r.conn(ctx).Exec(ctx, query, job.Status, job.Error, job.Report, job.Exported, file, job.FinishedAt, job.ExpiresAt, job.ID)
+	if err != nil {
+		r.logger.Error("failed to finish bulk job", slog.String("op", "FinishBulkJob"), slog.String("job_id", job.ID.String()), slog.String("error", err.Error()))
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetBulkJob returns the job with the given ID, without its file.
+func (r *UserRepository) GetBulkJob(ctx context.Context, id uuid.UUID) (*models.BulkJob, error) {
+	query := `SELECT ` + bulkJobColumns + ` FROM ` + models.BulkJobTable + ` WHERE id = $1`
+	job, err := scanBulkJob(r.conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get bulk job", slog.String("op", "GetBulkJob"), slog.String("job_id", id.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetBulkJobFile returns the file of the succeeded export job with the given ID.
+func (r *UserRepository) GetBulkJobFile(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	query := `SELECT file FROM ` + models.BulkJobTable + `
+        WHERE id = $1 AND kind = 'export' AND status = 'succeeded' AND file IS NOT NULL`
+	var file []byte
+	if err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&file); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get bulk job file", slog.String("op", "GetBulkJobFile"), slog.String("job_id", id.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+	return file, nil
+}
+
+// DeleteExpiredBulkJobs deletes jobs (and their files) that expired before now and returns the
+// number of deleted jobs.
+func (r *UserRepository) DeleteExpiredBulkJobs(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM ` + models.BulkJobTable + ` WHERE expires_at <= $1`
+	cmd, err := r.conn(ctx).Exec(ctx, query, now)
+	if err != nil {
+		r.logger.Error("failed to delete expired bulk jobs", slog.String("op", "DeleteExpiredBulkJobs"), slog.String("error", err.Error()))
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
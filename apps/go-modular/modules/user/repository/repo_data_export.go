@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go-modular/modules/user/models"
+)
+
+const dataExportColumns = `id, user_id, status, token_hash, size_bytes, error, created_at, completed_at, expires_at`
+
+func scanDataExport(row pgx.Row, extra ...any) (*models.DataExport, error) {
+	var e models.DataExport
+	dest := append([]any{&e.ID, &e.UserID, &e.Status, &e.TokenHash, &e.SizeBytes, &e.Error, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateDataExport stores a new pending data export. ErrConflict is returned when the user
+// already has a pending export.
+func (r *UserRepository) CreateDataExport(ctx context.Context, e *models.DataExport) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.Must(uuid.NewV7())
+	}
+	e.Status = models.DataExportPending
+	e.CreatedAt = time.Now()
+	query := `INSERT INTO ` + models.DataExportTable + ` (id, user_id, status, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id) WHERE status = 'pending' DO NOTHING`
+	cmd, err := r.conn(ctx).Exec(ctx, query, e.ID, e.UserID, e.Status, e.CreatedAt)
+	if err != nil {
+		r.logger.Error("failed to create data export", slog.String("op", "CreateDataExport"), slog.String("user_id", e.UserID.String()), slog.String("error", err.Error()))
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+// GetLatestDataExport returns the most recent data export of a user, without its archive.
+func (r *UserRepository) GetLatestDataExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM ` + models.DataExportTable + `
+        WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT 1`
+	e, err := scanDataExport(r.conn(ctx).QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get data export", slog.String("op", "GetLatestDataExport"), slog.String("user_id", userID.String()), slog.String("error", err.Error()))
+		return nil, err
+	}
+	return e, nil
+}
+
+// CompleteDataExport stores the archive of a pending export and marks it ready for download
+// with the given token hash until expiresAt.
+func (r *UserRepository) CompleteDataExport(ctx context.Context, id uuid.UUID, tokenHash string, archive []byte, expiresAt time.Time) error {
+	query := `UPDATE ` + models.DataExportTable + `
+        SET status = 'ready', token_hash = $1, archive = $2, size_bytes = $3, completed_at = $4, expires_at = $5
+        WHERE id = $6 AND status = 'pending'`
+	cmd, err := r.conn(ctx).Exec(ctx, query, tokenHash, archive, int64(len(archive)), time.Now(), expiresAt, id)
+	if err != nil {
+		r.logger.Error("failed to complete data export", slog.String("op", "CompleteDataExport"), slog.String("export_id", id.String()), slog.String("error", err.Error()))
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FailDataExport marks a pending export as failed. Failed exports are kept until expiresAt so
+// the user can see that the export did not succeed.
+func (r *UserRepository) FailDataExport(ctx context.Context, id uuid.UUID, reason string, expiresAt time.Time) error {
+	query := `UPDATE ` + models.DataExportTable + `
+        SET status = 'failed', error = $1, completed_at = $2, expires_at = $3
+        WHERE id = $4 AND status = 'pending'`
+	cmd, err := r.conn(ctx).Exec(ctx, query, reason, time.Now(), expiresAt, id)
+	if err != nil {
+		r.logger.Error("failed to fail data export", slog.String("op", "FailDataExport"), slog.String("export_id", id.String()), slog.String("error", err.Error()))
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetDataExportByTokenHash returns the ready export with the given download token hash,
+// including its archive.
+func (r *UserRepository) GetDataExportByTokenHash(ctx context.Context, tokenHash string) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + `, archive FROM ` + models.DataExportTable + `
+        WHERE token_hash = $1 AND status = 'ready'`
+	var archive []byte
+	e, err := scanDataExport(r.conn(ctx).QueryRow(ctx, query, tokenHash), &archive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get data export", slog.String("op", "GetDataExportByTokenHash"), slog.String("error", err.Error()))
+		return nil, err
+	}
+	e.Archive = archive
+	return e, nil
+}
+
+// DeleteExpiredDataExports deletes exports (and their archives) that expired before now and
+// returns the number of deleted exports.
+func (r *UserRepository) DeleteExpiredDataExports(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM ` + models.DataExportTable + ` WHERE expires_at <= $1`
+	cmd, err := r.conn(ctx).Exec(ctx, query, now)
+	if err != nil {
+		r.logger.Error("failed to delete expired data exports", slog.String("op", "DeleteExpiredDataExports"), slog.String("error", err.Error()))
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
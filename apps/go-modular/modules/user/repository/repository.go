@@ -6,28 +6,52 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go-modular/internal/adapter"
 	"go-modular/modules/user/models"
 )
 
 // Sentinel error for not found
 var ErrNotFound = errors.New("not found")
 
+// ErrConflict is returned by conditional updates when the row was modified concurrently, and
+// when creating a data export while another one is pending.
+var ErrConflict = errors.New("conflict: modified concurrently")
+
 // UserRepositoryInterface defines the contract for user data access.
 type UserRepositoryInterface interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	ListUsers(ctx context.Context, filter *models.FilterUser) ([]*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
+	UpdateUserIfUnmodified(ctx context.Context, user *models.User, version time.Time) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	UsernameExists(ctx context.Context, username string) (bool, error)
 	EmailExists(ctx context.Context, email string) (bool, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	SetUserAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error
+	IterateUsers(ctx context.Context, filter *models.FilterUser, fn func(*models.User) error) error
+	CopyUsers(ctx context.Context, users []*models.User) (int64, error)
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
+	ExistingUsernames(ctx context.Context, usernames []string) ([]string, error)
+	CreateDataExport(ctx context.Context, e *models.DataExport) error
+	GetLatestDataExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error)
+	CompleteDataExport(ctx context.Context, id uuid.UUID, tokenHash string, archive []byte, expiresAt time.Time) error
+	FailDataExport(ctx context.Context, id uuid.UUID, reason string, expiresAt time.Time) error
+	GetDataExportByTokenHash(ctx context.Context, tokenHash string) (*models.DataExport, error)
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) (int64, error)
+	CreateBulkJob(ctx context.Context, job *models.BulkJob) error
+	StartBulkJob(ctx context.Context, id uuid.UUID) error
+	FinishBulkJob(ctx context.Context, job *models.BulkJob, file []byte) error
+	GetBulkJob(ctx context.Context, id uuid.UUID) (*models.BulkJob, error)
+	GetBulkJobFile(ctx context.Context, id uuid.UUID) ([]byte, error)
+	DeleteExpiredBulkJobs(ctx context.Context, now time.Time) (int64, error)
 }
 
 // Ensure UserRepository implements UserRepositoryInterface
@@ -35,8 +59,9 @@ var _ UserRepositoryInterface = (*UserRepository)(nil)
 
 // UserRepository is an implementation of UserRepositoryInterface using pgxpool.
 type UserRepository struct {
-	pgPool *pgxpool.Pool
-	logger *slog.Logger
+	pgPool   *pgxpool.Pool
+	replicas adapter.ReadRouter // Routes read-only queries, see UseReplicas (optional)
+	logger   *slog.Logger
 }
 
 // NewUserRepository creates a new UserRepository with pgxpool and slog logger.
@@ -47,13 +72,31 @@ func NewUserRepository(pgPool *pgxpool.Pool, logger *slog.Logger) *UserRepositor
 	}
 }
 
+// conn returns the transaction of the context when there is one, so queries join the
+// surrounding unit of work, or the pool otherwise.
+func (r *UserRepository) conn(ctx context.Context) adapter.DBTX {
+	return adapter.Conn(ctx, r.pgPool)
+}
+
+// readConn returns the connection of a read-only query that tolerates replication lag: a
+// read replica when the repository uses replicas, or conn otherwise.
+func (r *UserRepository) readConn(ctx context.Context) adapter.DBTX {
+	return adapter.ReadConn(ctx, r.pgPool, r.replicas)
+}
+
+// UseReplicas routes the read-only queries that tolerate replication lag with router, e.g.
+// an *adapter.PostgresDB with read replicas.
+func (r *UserRepository) UseReplicas(router adapter.ReadRouter) {
+	r.replicas = router
+}
+
 func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.Must(uuid.NewV7())
 	}
 	user.CreatedAt = time.Now()
 
-	tx, err := r.pgPool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.conn(ctx).Begin(ctx)
 	if err != nil {
 		r.logger.Error("failed to begin transaction", slog.String("op", "CreateUser"), slog.String("error", err.Error()))
 		return err
@@ -67,12 +110,13 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) erro
 	query := `
 		INSERT INTO ` + models.UserTable + ` (
 			id, display_name, email, username, avatar_url, metadata, created_at, updated_at, email_verified_at,
-            last_login_at, banned_at, ban_expires, ban_reason
+            last_login_at, banned_at, ban_expires, ban_reason, is_admin
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)
+		RETURNING created_at
 	`
-	_, err = tx.Exec(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		user.ID,
 		user.DisplayName,
 		user.Email,
@@ -86,7 +130,8 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) erro
 		user.BannedAt,
 		user.BanExpires,
 		user.BanReason,
-	)
+		user.IsAdmin,
+	).Scan(&user.CreatedAt)
 	if err != nil {
 		r.logger.Error("failed to insert user", slog.String("op", "CreateUser"), slog.String("error", err.Error()))
 		return err
@@ -103,11 +148,11 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) erro
 func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
 		SELECT id, display_name, email, username, avatar_url, metadata, created_at, updated_at, email_verified_at,
-        last_login_at, banned_at, ban_expires, ban_reason
+        last_login_at, banned_at, ban_expires, ban_reason, is_admin
 		FROM ` + models.UserTable + `
 		WHERE id = $1
 	`
-	row := r.pgPool.QueryRow(ctx, query, id)
+	row := r.readConn(ctx).QueryRow(ctx, query, id)
 	var user models.User
 	var metadataBytes []byte
 	err := row.Scan(
@@ -124,6 +169,7 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models
 		&user.BannedAt,
 		&user.BanExpires,
 		&user.BanReason,
+		&user.IsAdmin,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -143,9 +189,24 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*models
 }
 
 func (r *UserRepository) ListUsers(ctx context.Context, filter *models.FilterUser) ([]*models.User, error) {
+	var users []*models.User
+	err := r.IterateUsers(ctx, filter, func(user *models.User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.logger.Info("users listed", slog.String("op", "ListUsers"), slog.Int("count", len(users)))
+	return users, nil
+}
+
+// IterateUsers streams the users matching the filter (newest first) to fn without loading
+// them all into memory. Iteration stops at the first error from fn.
+func (r *UserRepository) IterateUsers(ctx context.Context, filter *models.FilterUser, fn func(*models.User) error) error {
 	query := `
 		SELECT id, display_name, email, username, avatar_url, metadata, created_at, updated_at, email_verified_at,
-        last_login_at, banned_at, ban_expires, ban_reason
+        last_login_at, banned_at, ban_expires, ban_reason, is_admin
 		FROM ` + models.UserTable
 	args := []any{}
 	whereClauses := []string{}
@@ -156,6 +217,16 @@ func (r *UserRepository) ListUsers(ctx context.Context, filter *models.FilterUse
 		args = append(args, "%"+*filter.Search+"%")
 		argIdx++
 	}
+	if filter != nil && len(filter.Metadata) > 0 {
+		// Containment is served by the GIN index on metadata
+		contains, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return err
+		}
+		whereClauses = append(whereClauses, "metadata @> $"+itoa(argIdx)+"::jsonb")
+		args = append(args, string(contains))
+		argIdx++
+	}
 	if len(whereClauses) > 0 {
 		query += " WHERE " + joinClauses(whereClauses, " AND ")
 	}
@@ -170,14 +241,13 @@ func (r *UserRepository) ListUsers(ctx context.Context, filter *models.FilterUse
 		args = append(args, filter.Offset)
 	}
 
-	rows, err := r.pgPool.Query(ctx, query, args...)
+	rows, err := r.readConn(ctx).Query(ctx, query, args...)
 	if err != nil {
-		r.logger.Error("failed to list users", slog.String("op", "ListUsers"), slog.String("error", err.Error()))
-		return nil, err
+		r.logger.Error("failed to list users", slog.String("op", "IterateUsers"), slog.String("error", err.Error()))
+		return err
 	}
 	defer rows.Close()
 
-	var users []*models.User
 	for rows.Next() {
 		var user models.User
 		var metadataBytes []byte
@@ -195,10 +265,11 @@ func (r *UserRepository) ListUsers(ctx context.Context, filter *models.FilterUse
 			&user.BannedAt,
 			&user.BanExpires,
 			&user.BanReason,
+			&user.IsAdmin,
 		)
 		if err != nil {
-			r.logger.Error("failed to scan user row", slog.String("op", "ListUsers"), slog.String("error", err.Error()))
-			return nil, err
+			r.logger.Error("failed to scan user row", slog.String("op", "IterateUsers"), slog.String("error", err.Error()))
+			return err
 		}
 		if len(metadataBytes) > 0 {
 			var meta models.UserMetadata
@@ -206,23 +277,106 @@ func (r *UserRepository) ListUsers(ctx context.Context, filter *models.FilterUse
 				user.Metadata = &meta
 			}
 		}
-		users = append(users, &user)
+		if err := fn(&user); err != nil {
+			return err
+		}
 	}
-	r.logger.Info("users listed", slog.String("op", "ListUsers"), slog.Int("count", len(users)))
-	return users, nil
+	if err := rows.Err(); err != nil {
+		r.logger.Error("failed to iterate users", slog.String("op", "IterateUsers"), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// CopyUsers inserts users with a single COPY, which is much faster than one INSERT per user
+// for bulk imports. Either all users are inserted or none; a unique violation on email or
+// username fails the whole batch. IDs and creation times are set when missing.
+func (r *UserRepository) CopyUsers(ctx context.Context, users []*models.User) (int64, error) {
+	now := time.Now()
+	rows := make([][]any, 0, len(users))
+	for _, user := range users {
+		if user.ID == uuid.Nil {
+			user.ID = uuid.Must(uuid.NewV7())
+		}
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = now
+		}
+		rows = append(rows, []any{
+			user.ID, user.DisplayName, user.Email, user.Username, user.AvatarURL, user.Metadata,
+			user.CreatedAt, user.EmailVerifiedAt,
+		})
+	}
+
+	count, err := r.conn(ctx).CopyFrom(ctx,
+		pgx.Identifier{"public", "users"},
+		[]string{"id", "display_name", "email", "username", "avatar_url", "metadata", "created_at", "email_verified_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		r.logger.Error("failed to copy users", slog.String("op", "CopyUsers"), slog.Int("count", len(users)), slog.String("error", err.Error()))
+		return 0, err
+	}
+	r.logger.Info("users copied", slog.String("op", "CopyUsers"), slog.Int64("count", count))
+	return count, nil
+}
+
+// ExistingEmails returns which of emails are already used, compared case-insensitively.
+// The returned addresses are lower-cased.
+func (r *UserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	return r.existingValues(ctx, "email", emails, "ExistingEmails")
+}
+
+// ExistingUsernames returns which of usernames are already taken, compared case-insensitively.
+// The returned usernames are lower-cased.
+func (r *UserRepository) ExistingUsernames(ctx context.Context, usernames []string) ([]string, error) {
+	return r.existingValues(ctx, "username", usernames, "ExistingUsernames")
+}
+
+func (r *UserRepository) existingValues(ctx context.Context, column string, values []string, op string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	query := `SELECT LOWER(` + column + `) FROM ` + models.UserTable + ` WHERE LOWER(` + column + `) = ANY($1)`
+	rows, err := r.conn(ctx).Query(ctx, query, lowered)
+	if err != nil {
+		r.logger.Error("failed to check existing values", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, err
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		r.logger.Error("failed to scan existing values", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, err
+	}
+	return existing, nil
 }
 
 func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) error {
-	user.UpdatedAt = ptrTime(time.Now())
+	return r.updateUser(ctx, user, nil, "UpdateUser")
+}
 
-	tx, err := r.pgPool.BeginTx(ctx, pgx.TxOptions{})
+// UpdateUserIfUnmodified updates the user like UpdateUser, but only when its stored version
+// (see models.User.Version) still equals version. ErrConflict is returned when the user was
+// modified in the meantime, so concurrent edits cannot silently overwrite each other.
+func (r *UserRepository) UpdateUserIfUnmodified(ctx context.Context, user *models.User, version time.Time) error {
+	return r.updateUser(ctx, user, &version, "UpdateUserIfUnmodified")
+}
+
+// updateUser writes all mutable columns of user. When version is set the update is
+// conditional on the stored version. user.UpdatedAt is set to the value stored by the
+// updated_at trigger.
+func (r *UserRepository) updateUser(ctx context.Context, user *models.User, version *time.Time, op string) error {
+	tx, err := r.conn(ctx).Begin(ctx)
 	if err != nil {
-		r.logger.Error("failed to begin transaction", slog.String("op", "UpdateUser"), slog.String("error", err.Error()))
+		r.logger.Error("failed to begin transaction", slog.String("op", op), slog.String("error", err.Error()))
 		return err
 	}
 	defer func() {
 		if rbErr := tx.Rollback(ctx); rbErr != nil && rbErr != pgx.ErrTxClosed {
-			r.logger.Warn("failed to rollback transaction", slog.String("op", "UpdateUser"), slog.String("error", rbErr.Error()))
+			r.logger.Warn("failed to rollback transaction", slog.String("op", op), slog.String("error", rbErr.Error()))
 		}
 	}()
 
@@ -230,42 +384,56 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) erro
 		UPDATE ` + models.UserTable + `
 		SET display_name = $1, email = $2, username = $3, avatar_url = $4, metadata = $5, updated_at = $6,
         email_verified_at = $7, last_login_at = $8, banned_at = $9, ban_expires = $10, ban_reason = $11
-		WHERE id = $12
+		WHERE id = $12 AND ($13::timestamptz IS NULL OR COALESCE(updated_at, created_at) = $13)
+		RETURNING updated_at
 	`
-	cmd, err := tx.Exec(ctx, query,
+	var updatedAt *time.Time
+	err = tx.QueryRow(ctx, query,
 		user.DisplayName,
 		user.Email,
 		user.Username,
 		user.AvatarURL,
 		user.Metadata,
-		user.UpdatedAt,
+		time.Now(),
 		user.EmailVerifiedAt,
 		user.LastLoginAt,
 		user.BannedAt,
 		user.BanExpires,
 		user.BanReason,
 		user.ID,
-	)
+		version,
+	).Scan(&updatedAt)
 	if err != nil {
-		r.logger.Error("failed to update user", slog.String("op", "UpdateUser"), slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
-		return err
-	}
-	if cmd.RowsAffected() == 0 {
-		r.logger.Warn("user not found for update", slog.String("op", "UpdateUser"), slog.String("user_id", user.ID.String()))
-		return ErrNotFound
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Error("failed to update user", slog.String("op", op), slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
+			return err
+		}
+		// Nothing updated: either the user does not exist or its version changed
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+models.UserTable+` WHERE id = $1)`, user.ID).Scan(&exists); err != nil {
+			r.logger.Error("failed to check user exists", slog.String("op", op), slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
+			return err
+		}
+		if !exists {
+			r.logger.Warn("user not found for update", slog.String("op", op), slog.String("user_id", user.ID.String()))
+			return ErrNotFound
+		}
+		r.logger.Warn("user modified concurrently", slog.String("op", op), slog.String("user_id", user.ID.String()))
+		return ErrConflict
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		r.logger.Error("failed to commit transaction", slog.String("op", "UpdateUser"), slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
+		r.logger.Error("failed to commit transaction", slog.String("op", op), slog.String("user_id", user.ID.String()), slog.String("error", err.Error()))
 		return err
 	}
-	r.logger.Info("user updated", slog.String("op", "UpdateUser"), slog.String("user_id", user.ID.String()))
+	user.UpdatedAt = updatedAt
+	r.logger.Info("user updated", slog.String("op", op), slog.String("user_id", user.ID.String()))
 	return nil
 }
 
 func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM ` + models.UserTable + ` WHERE id = $1`
-	cmd, err := r.pgPool.Exec(ctx, query, id)
+	cmd, err := r.conn(ctx).Exec(ctx, query, id)
 	if err != nil {
 		r.logger.Error("failed to delete user", slog.String("op", "DeleteUser"), slog.String("user_id", id.String()), slog.String("error", err.Error()))
 		return err
@@ -278,11 +446,28 @@ func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// SetUserAdmin grants or revokes the administrator flag of a user. It is kept out of
+// UpdateUser so that profile updates can never change it.
+func (r *UserRepository) SetUserAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error {
+	query := `UPDATE ` + models.UserTable + ` SET is_admin = $1, updated_at = $2 WHERE id = $3`
+	cmd, err := r.conn(ctx).Exec(ctx, query, isAdmin, time.Now(), id)
+	if err != nil {
+		r.logger.Error("failed to set user admin", slog.String("op", "SetUserAdmin"), slog.String("user_id", id.String()), slog.String("error", err.Error()))
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("user not found for set admin", slog.String("op", "SetUserAdmin"), slog.String("user_id", id.String()))
+		return ErrNotFound
+	}
+	r.logger.Info("user admin flag updated", slog.String("op", "SetUserAdmin"), slog.String("user_id", id.String()), slog.Bool("is_admin", isAdmin))
+	return nil
+}
+
 // Check if a username exists (case-insensitive)
 func (r *UserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
 	query := `SELECT 1 FROM ` + models.UserTable + ` WHERE LOWER(username) = LOWER($1) LIMIT 1`
 	var exists int
-	err := r.pgPool.QueryRow(ctx, query, username).Scan(&exists)
+	err := r.conn(ctx).QueryRow(ctx, query, username).Scan(&exists)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
@@ -297,7 +482,7 @@ func (r *UserRepository) UsernameExists(ctx context.Context, username string) (b
 func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	query := `SELECT 1 FROM ` + models.UserTable + ` WHERE LOWER(email) = LOWER($1) LIMIT 1`
 	var exists int
-	err := r.pgPool.QueryRow(ctx, query, email).Scan(&exists)
+	err := r.conn(ctx).QueryRow(ctx, query, email).Scan(&exists)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
@@ -311,12 +496,12 @@ func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, e
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
         SELECT id, display_name, email, username, avatar_url, metadata, created_at, updated_at,
-        email_verified_at, last_login_at, banned_at, ban_expires, ban_reason
+        email_verified_at, last_login_at, banned_at, ban_expires, ban_reason, is_admin
         FROM ` + models.UserTable + `
         WHERE LOWER(email) = LOWER($1)
         LIMIT 1
     `
-	row := r.pgPool.QueryRow(ctx, query, email)
+	row := r.conn(ctx).QueryRow(ctx, query, email)
 	var user models.User
 	var metadataBytes []byte
 	err := row.Scan(
@@ -333,6 +518,7 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 		&user.BannedAt,
 		&user.BanExpires,
 		&user.BanReason,
+		&user.IsAdmin,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -354,12 +540,12 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
         SELECT id, display_name, email, username, avatar_url, metadata, created_at, updated_at,
-        email_verified_at, last_login_at, banned_at, ban_expires, ban_reason
+        email_verified_at, last_login_at, banned_at, ban_expires, ban_reason, is_admin
         FROM ` + models.UserTable + `
         WHERE LOWER(username) = LOWER($1)
         LIMIT 1
     `
-	row := r.pgPool.QueryRow(ctx, query, username)
+	row := r.conn(ctx).QueryRow(ctx, query, username)
 	var user models.User
 	var metadataBytes []byte
 	err := row.Scan(
@@ -376,6 +562,7 @@ func (r *UserRepository) GetUserByUsername(ctx context.Context, username string)
 		&user.BannedAt,
 		&user.BanExpires,
 		&user.BanReason,
+		&user.IsAdmin,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -394,10 +581,6 @@ func (r *UserRepository) GetUserByUsername(ctx context.Context, username string)
 	return &user, nil
 }
 
-func ptrTime(t time.Time) *time.Time {
-	return &t
-}
-
 // Helper functions for dynamic query building
 func itoa(i int) string {
 	return fmt.Sprintf("%d", i)
@@ -160,6 +160,161 @@ func TestUserRepository_List_FilterSearchAndPaging(t *testing.T) {
 	assert.LessOrEqual(t, len(paged), 2)
 }
 
+func TestUserRepository_List_FilterMetadata(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	sales := userFromMap(t, map[string]any{
+		"display_name": "Sales User",
+		"email":        "sales@example.com",
+		"username":     "sales_user",
+		"metadata":     map[string]any{"timezone": "UTC", "department": "sales", "tags": []string{"emea", "lead"}},
+	})
+	require.NoError(t, repo.CreateUser(ctx, sales))
+	support := userFromMap(t, map[string]any{
+		"display_name": "Support User",
+		"email":        "support@example.com",
+		"username":     "support_user",
+		"metadata":     map[string]any{"department": "support", "tags": []string{"emea"}},
+	})
+	require.NoError(t, repo.CreateUser(ctx, support))
+
+	got, err := repo.ListUsers(ctx, &models.FilterUser{Metadata: map[string]any{"department": "sales"}})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, sales.ID, got[0].ID)
+	require.NotNil(t, got[0].Metadata)
+	assert.Equal(t, "UTC", got[0].Metadata.Timezone)
+	assert.Equal(t, "sales", got[0].Metadata.Custom["department"])
+
+	got, err = repo.ListUsers(ctx, &models.FilterUser{Metadata: map[string]any{"tags": []any{"emea"}}})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
+
+func TestUserRepository_SetUserAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	username := "bob"
+	u := &models.User{DisplayName: "Bob", Email: "bob@example.com", Username: &username}
+	require.NoError(t, repo.CreateUser(ctx, u))
+
+	got, err := repo.GetUserByID(ctx, u.ID)
+	require.NoError(t, err)
+	assert.False(t, got.IsAdmin, "users are not admins by default")
+
+	require.NoError(t, repo.SetUserAdmin(ctx, u.ID, true))
+	got, err = repo.GetUserByEmail(ctx, "bob@example.com")
+	require.NoError(t, err)
+	assert.True(t, got.IsAdmin)
+
+	// profile updates never change the admin flag
+	got.IsAdmin = false
+	require.NoError(t, repo.UpdateUser(ctx, got))
+	got, err = repo.GetUserByUsername(ctx, "bob")
+	require.NoError(t, err)
+	assert.True(t, got.IsAdmin)
+
+	require.NoError(t, repo.SetUserAdmin(ctx, u.ID, false))
+	got, err = repo.GetUserByID(ctx, u.ID)
+	require.NoError(t, err)
+	assert.False(t, got.IsAdmin)
+
+	// unknown user
+	err = repo.SetUserAdmin(ctx, uuid.Must(uuid.NewV7()), true)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUserRepository_UpdateUserIfUnmodified(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	username := "carol"
+	u := &models.User{DisplayName: "Carol", Email: "carol@example.com", Username: &username}
+	require.NoError(t, repo.CreateUser(ctx, u))
+
+	first, err := repo.GetUserByID(ctx, u.ID)
+	require.NoError(t, err)
+	second, err := repo.GetUserByID(ctx, u.ID)
+	require.NoError(t, err)
+
+	// the first writer wins and gets the new version back
+	version := first.Version()
+	first.DisplayName = "Carol A."
+	require.NoError(t, repo.UpdateUserIfUnmodified(ctx, first, version))
+	require.NotNil(t, first.UpdatedAt)
+	assert.False(t, first.Version().Equal(version))
+
+	// the second writer based its change on the old version
+	second.DisplayName = "Carol B."
+	err = repo.UpdateUserIfUnmodified(ctx, second, second.Version())
+	assert.ErrorIs(t, err, ErrConflict)
+
+	got, err := repo.GetUserByID(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Carol A.", got.DisplayName)
+	assert.True(t, got.Version().Equal(first.Version()), "returned version matches the stored one")
+
+	// unknown user
+	ghost := &models.User{ID: uuid.Must(uuid.NewV7()), DisplayName: "Ghost", Email: "ghost@example.com"}
+	err = repo.UpdateUserIfUnmodified(ctx, ghost, time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUserRepository_CopyUsers_and_Existing(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	dave, erin := "dave", "erin"
+	users := []*models.User{
+		{DisplayName: "Dave", Email: "dave@example.com", Username: &dave, Metadata: &models.UserMetadata{Timezone: "Europe/Berlin"}},
+		{DisplayName: "Erin", Email: "erin@example.com", Username: &erin},
+	}
+	count, err := repo.CopyUsers(ctx, users)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	for _, u := range users {
+		assert.NotEqual(t, uuid.Nil, u.ID, "ids are assigned before copying")
+	}
+
+	got, err := repo.GetUserByID(ctx, users[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "dave@example.com", got.Email)
+	require.NotNil(t, got.Metadata)
+	assert.Equal(t, "Europe/Berlin", got.Metadata.Timezone)
+
+	emails, err := repo.ExistingEmails(ctx, []string{"DAVE@example.com", "nobody@example.com", "erin@example.com"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dave@example.com", "erin@example.com"}, emails)
+
+	usernames, err := repo.ExistingUsernames(ctx, []string{"Erin", "frank"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"erin"}, usernames)
+
+	// a conflicting row fails the whole batch
+	frank := "frank"
+	_, err = repo.CopyUsers(ctx, []*models.User{
+		{DisplayName: "Frank", Email: "frank@example.com", Username: &frank},
+		{DisplayName: "Dave 2", Email: "Dave@example.com"},
+	})
+	assert.Error(t, err)
+	exists, err := repo.EmailExists(ctx, "frank@example.com")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	var iterated []string
+	require.NoError(t, repo.IterateUsers(ctx, nil, func(u *models.User) error {
+		iterated = append(iterated, u.Email)
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"dave@example.com", "erin@example.com"}, iterated)
+}
+
 // normalizeString helps assertions work whether the model field is a string or *string.
 func normalizeString(v any) string {
 	switch x := v.(type) {
@@ -174,3 +329,123 @@ func normalizeString(v any) string {
 		return ""
 	}
 }
+
+func TestUserRepository_DataExports(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	username := "dora"
+	u := &models.User{DisplayName: "Dora", Email: "dora@example.com", Username: &username}
+	require.NoError(t, repo.CreateUser(ctx, u))
+
+	_, err := repo.GetLatestDataExport(ctx, u.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	e := &models.DataExport{UserID: u.ID}
+	require.NoError(t, repo.CreateDataExport(ctx, e))
+
+	// only one pending export per user
+	err = repo.CreateDataExport(ctx, &models.DataExport{UserID: u.ID})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	latest, err := repo.GetLatestDataExport(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e.ID, latest.ID)
+	assert.Equal(t, models.DataExportPending, latest.Status)
+
+	expiresAt := time.Now().Add(time.Hour)
+	archive := []byte("PK\x03\x04archive")
+	require.NoError(t, repo.CompleteDataExport(ctx, e.ID, "hash-1", archive, expiresAt))
+	assert.ErrorIs(t, repo.CompleteDataExport(ctx, e.ID, "hash-1", archive, expiresAt), ErrNotFound, "only pending exports complete")
+
+	got, err := repo.GetDataExportByTokenHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.DataExportReady, got.Status)
+	assert.Equal(t, archive, got.Archive)
+	require.NotNil(t, got.SizeBytes)
+	assert.Equal(t, int64(len(archive)), *got.SizeBytes)
+
+	_, err = repo.GetDataExportByTokenHash(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// a new export can be requested once the previous one finished
+	failed := &models.DataExport{UserID: u.ID}
+	require.NoError(t, repo.CreateDataExport(ctx, failed))
+	require.NoError(t, repo.FailDataExport(ctx, failed.ID, "boom", time.Now().Add(-time.Minute)))
+	latest, err = repo.GetLatestDataExport(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, failed.ID, latest.ID)
+	assert.Equal(t, models.DataExportFailed, latest.Status)
+
+	// only the expired (failed) export is deleted
+	n, err := repo.DeleteExpiredDataExports(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	latest, err = repo.GetLatestDataExport(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, e.ID, latest.ID)
+}
+
+func TestUserRepository_BulkJobs(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupRepo(t)
+	defer teardown()
+
+	_, err := repo.GetBulkJob(ctx, uuid.Must(uuid.NewV7()))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	imp := &models.BulkJob{Kind: models.BulkJobImport, Format: models.BulkFormatCSV}
+	require.NoError(t, repo.CreateBulkJob(ctx, imp))
+	require.NoError(t, repo.StartBulkJob(ctx, imp.ID))
+	assert.ErrorIs(t, repo.StartBulkJob(ctx, imp.ID), ErrNotFound, "only pending jobs start")
+
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	imp.Status = models.BulkJobFailed
+	imp.Error = "boom"
+	imp.Report = &models.ImportReport{Total: 2, Valid: 1, Created: 1, Failed: 1, Errors: []models.ImportRowError{{Row: 2, Errors: map[string]string{"email": "Email already exists"}}}}
+	imp.FinishedAt = &now
+	imp.ExpiresAt = &expired
+	require.NoError(t, repo.FinishBulkJob(ctx, imp, nil))
+
+	got, err := repo.GetBulkJob(ctx, imp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BulkJobImport, got.Kind)
+	assert.Equal(t, models.BulkJobFailed, got.Status)
+	assert.Equal(t, "boom", got.Error)
+	assert.Equal(t, imp.Report, got.Report)
+
+	exp := &models.BulkJob{Kind: models.BulkJobExport, Format: models.BulkFormatNDJSON}
+	require.NoError(t, repo.CreateBulkJob(ctx, exp))
+	_, err = repo.GetBulkJobFile(ctx, exp.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "unfinished exports have no file")
+
+	expiresAt := now.Add(time.Hour)
+	exp.Status = models.BulkJobSucceeded
+	exp.Exported = 3
+	exp.FinishedAt = &now
+	exp.ExpiresAt = &expiresAt
+	file := []byte("{\"email\":\"dora@example.com\"}\n")
+	require.NoError(t, repo.FinishBulkJob(ctx, exp, file))
+	assert.ErrorIs(t, repo.FinishBulkJob(ctx, exp, file), ErrNotFound, "only unfinished jobs finish")
+
+	got, err = repo.GetBulkJob(ctx, exp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BulkJobSucceeded, got.Status)
+	assert.Equal(t, 3, got.Exported)
+	assert.Empty(t, got.Error)
+	assert.Nil(t, got.Report)
+	gotFile, err := repo.GetBulkJobFile(ctx, exp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, file, gotFile)
+
+	// only the expired import is deleted
+	n, err := repo.DeleteExpiredBulkJobs(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = repo.GetBulkJob(ctx, imp.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.GetBulkJob(ctx, exp.ID)
+	assert.NoError(t, err)
+}
@@ -3,27 +3,78 @@ package services
 import (
 	"context"
 	"errors"
+	"io"
+	"log/slog"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"go-modular/internal/adapter"
+	"go-modular/internal/eventbus"
+	"go-modular/internal/notification"
+	"go-modular/internal/personaldata"
 	"go-modular/modules/user/models"
 	"go-modular/modules/user/repository"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
 )
 
+var (
+	// ErrUserNotFound is returned when the user does not exist.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUsernameTaken is returned when a profile update picks a username already in use.
+	ErrUsernameTaken = errors.New("username is already taken")
+
+	// ErrInvalidUsername is returned when a username contains characters other than letters,
+	// digits and underscores.
+	ErrInvalidUsername = errors.New("username may only contain letters, digits and underscores")
+
+	// ErrInvalidDisplayName is returned when a display name is empty or only white space.
+	ErrInvalidDisplayName = errors.New("display name must not be blank")
+
+	// ErrPreconditionFailed is returned when the user was modified since the version the
+	// caller based its update on (If-Match / optimistic concurrency).
+	ErrPreconditionFailed = errors.New("user was modified concurrently")
+)
+
+// usernameRegex matches usernames chosen by users; generated usernames follow the same rules.
+var usernameRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// usernameSanitizer matches the characters removed when generating a username from an email.
+var usernameSanitizer = regexp.MustCompile(`[^a-z0-9_]+`)
+
 // UserServiceInterface defines the contract for user business logic.
 type UserServiceInterface interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetUserForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error)
 	ListUsers(ctx context.Context, filter *models.FilterUser) ([]*models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
 	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+	SetUserAdmin(ctx context.Context, userID uuid.UUID, isAdmin bool) error
+	UpdateProfile(ctx context.Context, userID uuid.UUID, version *time.Time, req *models.UpdateProfileRequest) (*models.User, error)
+	PatchUser(ctx context.Context, id uuid.UUID, version *time.Time, patch *models.UserPatch) (*models.User, error)
+	ImportUsers(ctx context.Context, r io.Reader, opts models.ImportOptions) (*models.ImportReport, error)
+	ExportUsers(ctx context.Context, filter *models.FilterUser, format models.BulkFormat, w io.Writer) (int, error)
+	StartImportJob(ctx context.Context, r io.Reader, opts models.ImportOptions) (*models.BulkJob, error)
+	StartExportJob(ctx context.Context, filter *models.FilterUser, format models.BulkFormat) (*models.BulkJob, error)
+	GetBulkJob(ctx context.Context, kind models.BulkJobKind, id uuid.UUID) (*models.BulkJob, error)
+	OpenExport(ctx context.Context, id uuid.UUID) (*models.BulkJob, io.ReadCloser, error)
+	ExportPersonalData(ctx context.Context, userID uuid.UUID, w *personaldata.Writer) error
+	RequestDataExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error)
+	GetLatestDataExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error)
+	OpenDataExport(ctx context.Context, token string) (*models.DataExport, error)
+	MetadataSchema() *MetadataSchema
 }
 
 // Ensure UserService implements UserServiceInterface
@@ -32,24 +83,85 @@ var _ UserServiceInterface = (*UserService)(nil)
 // UserService implements user business logic using a UserRepositoryInterface.
 type UserService struct {
 	userRepo repository.UserRepositoryInterface
+	auditor  auditSvc.Recorder
+	validate *validator.Validate
+	mailer   *notification.Mailer
+	logger   *slog.Logger
+
+	dataExports          *personaldata.Registry
+	dataExportLinkExpiry time.Duration
+	baseURL              string
+
+	metadataSchema *MetadataSchema
+
+	events *eventbus.Bus
 }
 
 type UserServiceOpts struct {
 	UserRepo repository.UserRepositoryInterface
+	Auditor  auditSvc.Recorder    // Security audit log recorder (optional)
+	Mailer   *notification.Mailer // Sends data export emails (optional, links are printed when nil)
+	Logger   *slog.Logger         // Logs background work such as data exports (optional)
+
+	DataExports          *personaldata.Registry // Exporters of all modules included in personal data exports (optional, empty when nil)
+	DataExportLinkExpiry time.Duration          // Lifetime of emailed data export download links (default: 24h)
+	BaseURL              string                 // Base URL of data export download links (optional)
+
+	MetadataSchema *MetadataSchema // Declares the custom metadata fields (optional, none when nil)
+
+	Events *eventbus.Bus // Domain event bus (optional, events reach no other module when nil)
 }
 
 // NewUserService creates a new UserService.
 func NewUserService(opts UserServiceOpts) *UserService {
+	if opts.Auditor == nil {
+		opts.Auditor = auditSvc.NopRecorder{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.DataExports == nil {
+		opts.DataExports = personaldata.NewRegistry()
+	}
+	if opts.DataExportLinkExpiry <= 0 {
+		opts.DataExportLinkExpiry = defaultDataExportLinkExpiry
+	}
+	if opts.MetadataSchema == nil {
+		opts.MetadataSchema = mustEmptyMetadataSchema()
+	}
+	if opts.Events == nil {
+		opts.Events = eventbus.NewBus(eventbus.BusOpts{Logger: opts.Logger})
+	}
 	return &UserService{
 		userRepo: opts.UserRepo,
+		auditor:  opts.Auditor,
+		validate: validator.New(),
+		mailer:   opts.Mailer,
+		logger:   opts.Logger,
+
+		dataExports:          opts.DataExports,
+		dataExportLinkExpiry: opts.DataExportLinkExpiry,
+		baseURL:              opts.BaseURL,
+
+		metadataSchema: opts.MetadataSchema,
+
+		events: opts.Events,
 	}
 }
 
+// MetadataSchema returns the schema declaring the custom metadata fields.
+func (s *UserService) MetadataSchema() *MetadataSchema {
+	return s.metadataSchema
+}
+
 func (s *UserService) CreateUser(ctx context.Context, user *models.User) error {
 	if user.ID == uuid.Nil {
 		user.ID = uuid.Must(uuid.NewV7())
 	}
 
+	if err := s.metadataSchema.Validate(user.Metadata); err != nil {
+		return err
+	}
 	if user.Metadata == nil {
 		user.Metadata = &models.UserMetadata{
 			Timezone: "UTC", // Set default timezone if not provided
@@ -58,47 +170,71 @@ func (s *UserService) CreateUser(ctx context.Context, user *models.User) error {
 
 	// Generate username from email if not provided
 	if user.Email != "" && (user.Username == nil || *user.Username == "") {
-		base := strings.SplitN(user.Email, "@", 2)[0]
-		// Remove non-alphanumeric and non-underscore, make lowercase
-		re := regexp.MustCompile(`[^a-z0-9_]+`)
-		sanitized := re.ReplaceAllString(strings.ToLower(base), "")
-		if sanitized == "" {
-			sanitized = "user"
-		}
-		username := sanitized
-		// Ensure username is unique, add suffix if needed
-		suffix := 1
-		for {
-			exists, err := s.userRepo.UsernameExists(ctx, username)
-			if err != nil {
-				return err
-			}
-			if !exists {
-				break
-			}
-			username = sanitized + "_" + strconv.Itoa(suffix)
-			suffix++
+		username, err := s.generateUsername(ctx, user.Email, nil)
+		if err != nil {
+			return err
 		}
 		user.Username = &username
 	}
 
-	return s.userRepo.CreateUser(ctx, user)
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionUserCreated, auditModels.TargetUser, user.ID, map[string]any{"email": user.Email}))
+	s.publish(ctx, models.UserCreated{UserID: user.ID, Email: user.Email, OccurredAt: time.Now()})
+	return nil
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	return s.userRepo.GetUserByID(ctx, id)
 }
 
+// GetUserForUpdate returns the user from the primary database. Updates read the user with
+// it, since preconditions are checked against the primary and a lagging replica would fail
+// them.
+func (s *UserService) GetUserForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return s.userRepo.GetUserByID(adapter.UsePrimary(ctx), id)
+}
+
+// ListUsers returns the users matching the filter. Metadata filters must name declared
+// fields; ErrInvalidMetadataFilter is returned otherwise.
 func (s *UserService) ListUsers(ctx context.Context, filter *models.FilterUser) ([]*models.User, error) {
+	filter, err := s.resolveFilter(filter)
+	if err != nil {
+		return nil, err
+	}
 	return s.userRepo.ListUsers(ctx, filter)
 }
 
+// resolveFilter returns filter with its metadata values converted to the declared types.
+func (s *UserService) resolveFilter(filter *models.FilterUser) (*models.FilterUser, error) {
+	if filter == nil || len(filter.Metadata) == 0 {
+		return filter, nil
+	}
+	metadata, err := s.metadataSchema.FilterValues(filter.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *filter
+	resolved.Metadata = metadata
+	return &resolved, nil
+}
+
 func (s *UserService) UpdateUser(ctx context.Context, user *models.User) error {
-	return s.userRepo.UpdateUser(ctx, user)
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionUserUpdated, auditModels.TargetUser, user.ID, nil))
+	return nil
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	return s.userRepo.DeleteUser(ctx, id)
+	if err := s.userRepo.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionUserDeleted, auditModels.TargetUser, id, nil))
+	s.publish(ctx, models.UserDeleted{UserID: id, OccurredAt: time.Now()})
+	return nil
 }
 
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
@@ -110,7 +246,8 @@ func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*
 }
 
 func (s *UserService) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
-	user, err := s.userRepo.GetUserByID(ctx, userID)
+	// All columns are written back, so they must not come from a lagging replica
+	user, err := s.userRepo.GetUserByID(adapter.UsePrimary(ctx), userID)
 	if err != nil {
 		return err
 	}
@@ -119,5 +256,258 @@ func (s *UserService) MarkEmailVerified(ctx context.Context, userID uuid.UUID) e
 	}
 	now := time.Now()
 	user.EmailVerifiedAt = &now
-	return s.userRepo.UpdateUser(ctx, user)
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	s.publish(ctx, models.UserEmailVerified{UserID: user.ID, Email: user.Email, OccurredAt: now})
+	return nil
+}
+
+// publish publishes domain events. The changes they describe are already stored, so
+// failing handlers are logged instead of failing the caller.
+func (s *UserService) publish(ctx context.Context, events ...eventbus.Event) {
+	if err := s.events.Publish(ctx, events...); err != nil {
+		s.logger.Error("failed to publish events", slog.String("op", "UserService.publish"), slog.String("error", err.Error()))
+	}
+}
+
+// publishUpdated publishes a UserUpdated event attributed to the actor of ctx.
+func (s *UserService) publishUpdated(ctx context.Context, userID uuid.UUID, fields []string) {
+	event := models.UserUpdated{UserID: userID, Fields: fields, OccurredAt: time.Now()}
+	if actorID, ok := auditSvc.ActorFromContext(ctx); ok {
+		event.UpdatedBy = &actorID
+	}
+	s.publish(ctx, event)
+}
+
+// SetUserAdmin grants or revokes the administrator flag of a user.
+func (s *UserService) SetUserAdmin(ctx context.Context, userID uuid.UUID, isAdmin bool) error {
+	if err := s.userRepo.SetUserAdmin(ctx, userID, isAdmin); err != nil {
+		return err
+	}
+	action := auditModels.ActionUserAdminGranted
+	if !isAdmin {
+		action = auditModels.ActionUserAdminRevoked
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(action, auditModels.TargetUser, userID, nil))
+	s.publishUpdated(ctx, userID, []string{"is_admin"})
+	return nil
+}
+
+// UpdateProfile applies a self-service profile update. Only the fields of
+// models.UpdateProfileRequest can be changed; all other columns keep their stored values.
+// When version is set, ErrPreconditionFailed is returned unless the user is still at it.
+func (s *UserService) UpdateProfile(ctx context.Context, userID uuid.UUID, version *time.Time, req *models.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.loadForUpdate(ctx, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	loadedVersion := user.Version()
+
+	changed := []string{}
+	if req.DisplayName != nil {
+		displayName, err := normalizeDisplayName(*req.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		user.DisplayName = displayName
+		changed = append(changed, "display_name")
+	}
+	if req.Username != nil {
+		ok, err := s.changeUsername(ctx, user, *req.Username)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed = append(changed, "username")
+		}
+	}
+	if req.AvatarURL != nil {
+		if *req.AvatarURL == "" {
+			user.AvatarURL = nil
+		} else {
+			avatarURL := *req.AvatarURL
+			user.AvatarURL = &avatarURL
+		}
+		changed = append(changed, "avatar_url")
+	}
+	if req.Metadata != nil {
+		if err := s.metadataSchema.Validate(req.Metadata); err != nil {
+			return nil, err
+		}
+		user.Metadata = req.Metadata
+		changed = append(changed, "metadata")
+	}
+	if err := s.saveUser(ctx, user, loadedVersion, changed); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// PatchUser replaces the patchable fields of a user with patch, typically the result of
+// applying a JSON Merge Patch to models.NewUserPatch. Changing the email address resets its
+// verification. When version is set, ErrPreconditionFailed is returned unless the user is
+// still at it; the write itself is always conditional on the version that was read, so
+// concurrent edits fail instead of overwriting each other.
+func (s *UserService) PatchUser(ctx context.Context, id uuid.UUID, version *time.Time, patch *models.UserPatch) (*models.User, error) {
+	user, err := s.loadForUpdate(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	loadedVersion := user.Version()
+
+	changed := []string{}
+	displayName, err := normalizeDisplayName(patch.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+	if displayName != user.DisplayName {
+		user.DisplayName = displayName
+		changed = append(changed, "display_name")
+	}
+	if !strings.EqualFold(patch.Email, user.Email) {
+		// A new address has to be verified again
+		user.Email = patch.Email
+		user.EmailVerifiedAt = nil
+		changed = append(changed, "email")
+	}
+	if patch.Username != nil {
+		ok, err := s.changeUsername(ctx, user, *patch.Username)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed = append(changed, "username")
+		}
+	}
+	if !equalStringPtr(patch.AvatarURL, user.AvatarURL) {
+		user.AvatarURL = patch.AvatarURL
+		changed = append(changed, "avatar_url")
+	}
+	if !equalMetadata(patch.Metadata, user.Metadata) {
+		if err := s.metadataSchema.Validate(patch.Metadata); err != nil {
+			return nil, err
+		}
+		user.Metadata = patch.Metadata
+		changed = append(changed, "metadata")
+	}
+	if err := s.saveUser(ctx, user, loadedVersion, changed); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// generateUsername derives a username from the local part of email: lower-cased, stripped to
+// letters, digits and underscores, padded or cut to a valid length, with a numeric suffix
+// added until it is neither taken nor in reserved (lower-cased usernames claimed by records
+// that are not stored yet).
+func (s *UserService) generateUsername(ctx context.Context, email string, reserved map[string]int) (string, error) {
+	base := strings.SplitN(email, "@", 2)[0]
+	sanitized := usernameSanitizer.ReplaceAllString(strings.ToLower(base), "")
+	if sanitized == "" {
+		sanitized = "user"
+	}
+	// Usernames are 3 to 32 characters; leave room for the suffix
+	if len(sanitized) < 3 {
+		sanitized += "_user"
+	}
+	if len(sanitized) > 24 {
+		sanitized = sanitized[:24]
+	}
+	username := sanitized
+	// Ensure username is unique, add suffix if needed
+	suffix := 1
+	for {
+		if _, ok := reserved[username]; !ok {
+			exists, err := s.userRepo.UsernameExists(ctx, username)
+			if err != nil {
+				return "", err
+			}
+			if !exists {
+				return username, nil
+			}
+		}
+		username = sanitized + "_" + strconv.Itoa(suffix)
+		suffix++
+	}
+}
+
+// loadForUpdate loads a user about to be modified and checks the optional version precondition.
+func (s *UserService) loadForUpdate(ctx context.Context, id uuid.UUID, version *time.Time) (*models.User, error) {
+	// A lagging replica would fail the version precondition of fresh updates
+	user, err := s.userRepo.GetUserByID(adapter.UsePrimary(ctx), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if version != nil && !user.Version().Equal(*version) {
+		return nil, ErrPreconditionFailed
+	}
+	return user, nil
+}
+
+// normalizeDisplayName trims white space around a display name, returning
+// ErrInvalidDisplayName when nothing is left.
+func normalizeDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrInvalidDisplayName
+	}
+	return name, nil
+}
+
+// changeUsername sets a new username after validating it and checking it is free. It reports
+// false when username only differs from the current one in case.
+func (s *UserService) changeUsername(ctx context.Context, user *models.User, username string) (bool, error) {
+	if user.Username != nil && strings.EqualFold(*user.Username, username) {
+		return false, nil
+	}
+	if !usernameRegex.MatchString(username) {
+		return false, ErrInvalidUsername
+	}
+	exists, err := s.userRepo.UsernameExists(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, ErrUsernameTaken
+	}
+	user.Username = &username
+	return true, nil
+}
+
+// saveUser writes user if any field changed, on condition that it is still at version, and
+// records the changed fields in the audit log.
+func (s *UserService) saveUser(ctx context.Context, user *models.User, version time.Time, changed []string) error {
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := s.userRepo.UpdateUserIfUnmodified(ctx, user, version); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrConflict):
+			return ErrPreconditionFailed
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrUserNotFound
+		}
+		return err
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionUserUpdated, auditModels.TargetUser, user.ID, map[string]any{"fields": changed}))
+	s.publishUpdated(ctx, user.ID, changed)
+	return nil
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalMetadata(a, b *models.UserMetadata) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.DeepEqual(*a, *b)
 }
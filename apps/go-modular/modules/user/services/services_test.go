@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-modular/modules/user/models"
+	"go-modular/modules/user/repository"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// profileRepo serves a single user from memory. Other UserRepositoryInterface methods are
+// not implemented and panic when called.
+type profileRepo struct {
+	repository.UserRepositoryInterface
+
+	user    *models.User
+	updates int
+}
+
+func (r *profileRepo) GetUserByID(_ context.Context, id uuid.UUID) (*models.User, error) {
+	if r.user == nil || r.user.ID != id {
+		return nil, repository.ErrNotFound
+	}
+	c := *r.user
+	return &c, nil
+}
+
+func (r *profileRepo) UpdateUserIfUnmodified(_ context.Context, user *models.User, _ time.Time) error {
+	r.updates++
+	c := *user
+	r.user = &c
+	return nil
+}
+
+func newProfileService() (*UserService, *profileRepo) {
+	repo := &profileRepo{user: &models.User{
+		ID:          uuid.Must(uuid.NewV7()),
+		Email:       "johndoe@example.com",
+		DisplayName: "John Doe",
+		CreatedAt:   time.Now(),
+	}}
+	return NewUserService(UserServiceOpts{UserRepo: repo}), repo
+}
+
+func TestUpdateProfile_DisplayName(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("trimmed", func(t *testing.T) {
+		svc, repo := newProfileService()
+		name := "  Jane Doe  "
+		user, err := svc.UpdateProfile(ctx, repo.user.ID, nil, &models.UpdateProfileRequest{DisplayName: &name})
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", user.DisplayName)
+		assert.Equal(t, "Jane Doe", repo.user.DisplayName)
+	})
+
+	t.Run("blank_is_rejected", func(t *testing.T) {
+		svc, repo := newProfileService()
+		name := " \t "
+		_, err := svc.UpdateProfile(ctx, repo.user.ID, nil, &models.UpdateProfileRequest{DisplayName: &name})
+		assert.ErrorIs(t, err, ErrInvalidDisplayName)
+		assert.Zero(t, repo.updates, "nothing may be written")
+		assert.Equal(t, "John Doe", repo.user.DisplayName)
+	})
+}
+
+func TestPatchUser_DisplayName(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("trimmed", func(t *testing.T) {
+		svc, repo := newProfileService()
+		patch := models.NewUserPatch(repo.user)
+		patch.DisplayName = "  Jane Doe  "
+		user, err := svc.PatchUser(ctx, repo.user.ID, nil, patch)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", user.DisplayName)
+		assert.Equal(t, "Jane Doe", repo.user.DisplayName)
+	})
+
+	t.Run("unchanged_after_trimming", func(t *testing.T) {
+		svc, repo := newProfileService()
+		patch := models.NewUserPatch(repo.user)
+		patch.DisplayName = " John Doe "
+		_, err := svc.PatchUser(ctx, repo.user.ID, nil, patch)
+		require.NoError(t, err)
+		assert.Zero(t, repo.updates, "nothing changed")
+	})
+
+	t.Run("blank_is_rejected", func(t *testing.T) {
+		svc, repo := newProfileService()
+		patch := models.NewUserPatch(repo.user)
+		patch.DisplayName = "   "
+		_, err := svc.PatchUser(ctx, repo.user.ID, nil, patch)
+		assert.ErrorIs(t, err, ErrInvalidDisplayName)
+		assert.Zero(t, repo.updates, "nothing may be written")
+		assert.Equal(t, "John Doe", repo.user.DisplayName)
+	})
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"go-modular/modules/user/models"
+	"go-modular/modules/user/repository"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	// bulkJobRetention is how long finished bulk jobs, and the files of exports, are kept.
+	bulkJobRetention = time.Hour
+
+	// bulkJobTimeout bounds running a bulk job. Unfinished jobs older than this were
+	// interrupted (e.g. by a restart) and are reported as failed.
+	bulkJobTimeout = time.Hour
+)
+
+var (
+	// ErrBulkJobNotFound is returned for unknown or expired bulk jobs.
+	ErrBulkJobNotFound = errors.New("bulk job not found")
+
+	// ErrExportNotReady is returned when downloading an export that has not succeeded (yet).
+	ErrExportNotReady = errors.New("export is not ready")
+
+	// errBulkJobInterrupted is the error of jobs that did not finish within bulkJobTimeout.
+	errBulkJobInterrupted = errors.New("interrupted")
+)
+
+// StartImportJob spools the import file r to a temporary file and imports it in the
+// background with ImportUsers. The returned job is polled with GetBulkJob; its report is
+// set once the import finished. The import keeps the values of ctx (e.g. the audit actor)
+// but is not canceled with it.
+func (s *UserService) StartImportJob(ctx context.Context, r io.Reader, opts models.ImportOptions) (*models.BulkJob, error) {
+	if !opts.Format.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImportFile, opts.Format)
+	}
+	f, err := os.CreateTemp("", "user-import-*")
+	if err != nil {
+		return nil, err
+	}
+	discard := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		discard()
+		return nil, fmt.Errorf("failed to store import file: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		discard()
+		return nil, err
+	}
+
+	job, err := s.createBulkJob(ctx, models.BulkJobImport, opts.Format)
+	if err != nil {
+		discard()
+		return nil, err
+	}
+	go func(ctx context.Context, job models.BulkJob) {
+		defer discard()
+		s.runBulkJob(ctx, job, func(ctx context.Context, job *models.BulkJob) ([]byte, error) {
+			report, err := s.ImportUsers(ctx, f, opts)
+			job.Report = report
+			return nil, err
+		})
+	}(context.WithoutCancel(ctx), *job)
+	return job, nil
+}
+
+// StartExportJob exports the users matching the filter in the background with ExportUsers.
+// Once the job succeeded the file is downloaded with OpenExport until the job expires.
+func (s *UserService) StartExportJob(ctx context.Context, filter *models.FilterUser, format models.BulkFormat) (*models.BulkJob, error) {
+	if !format.IsValid() {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	filter, err := s.resolveFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.createBulkJob(ctx, models.BulkJobExport, format)
+	if err != nil {
+		return nil, err
+	}
+	go s.runBulkJob(context.WithoutCancel(ctx), *job, func(ctx context.Context, job *models.BulkJob) ([]byte, error) {
+		var buf bytes.Buffer
+		count, err := s.ExportUsers(ctx, filter, format, &buf)
+		job.Exported = count
+		return buf.Bytes(), err
+	})
+	return job, nil
+}
+
+// GetBulkJob returns the import or export job with the given ID.
+func (s *UserService) GetBulkJob(ctx context.Context, kind models.BulkJobKind, id uuid.UUID) (*models.BulkJob, error) {
+	job, err := s.getBulkJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Kind != kind {
+		return nil, ErrBulkJobNotFound
+	}
+	return job, nil
+}
+
+// OpenExport opens the file of a succeeded export job. The caller must close it.
+func (s *UserService) OpenExport(ctx context.Context, id uuid.UUID) (*models.BulkJob, io.ReadCloser, error) {
+	job, err := s.getBulkJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Kind != models.BulkJobExport {
+		return nil, nil, ErrBulkJobNotFound
+	}
+	if job.Status != models.BulkJobSucceeded {
+		return job, nil, ErrExportNotReady
+	}
+	file, err := s.userRepo.GetBulkJobFile(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Expired in the meantime
+			return nil, nil, ErrBulkJobNotFound
+		}
+		return nil, nil, err
+	}
+	return job, io.NopCloser(bytes.NewReader(file)), nil
+}
+
+// createBulkJob stores a new pending job, discarding expired ones first.
+func (s *UserService) createBulkJob(ctx context.Context, kind models.BulkJobKind, format models.BulkFormat) (*models.BulkJob, error) {
+	if _, err := s.userRepo.DeleteExpiredBulkJobs(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	job := &models.BulkJob{Kind: kind, Format: format}
+	if err := s.userRepo.CreateBulkJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// runBulkJob runs a pending job with fn and stores its outcome. fn sets the results of the
+// job (e.g. the import report) and returns the file of an export.
+func (s *UserService) runBulkJob(ctx context.Context, job models.BulkJob, fn func(ctx context.Context, job *models.BulkJob) ([]byte, error)) {
+	logger := s.logger.With(slog.String("job_id", job.ID.String()), slog.String("kind", string(job.Kind)))
+	if err := s.userRepo.StartBulkJob(ctx, job.ID); err != nil {
+		logger.Error("Failed to start bulk job", slog.String("error", err.Error()))
+		return
+	}
+	job.Status = models.BulkJobRunning
+
+	runCtx, cancel := context.WithTimeout(ctx, bulkJobTimeout)
+	file, err := fn(runCtx, &job)
+	cancel()
+	if err != nil {
+		logger.Error("Bulk job failed", slog.String("error", err.Error()))
+	}
+	if err := s.finishBulkJob(ctx, &job, err, file); err != nil {
+		logger.Error("Failed to finish bulk job", slog.String("error", err.Error()))
+	}
+}
+
+// finishBulkJob marks a job as succeeded, or failed when err is set, and starts its retention
+// period. The file of a failed export is discarded.
+func (s *UserService) finishBulkJob(ctx context.Context, job *models.BulkJob, err error, file []byte) error {
+	now := time.Now()
+	expiresAt := now.Add(bulkJobRetention)
+	job.FinishedAt = &now
+	job.ExpiresAt = &expiresAt
+	job.Status = models.BulkJobSucceeded
+	if err != nil {
+		job.Status = models.BulkJobFailed
+		job.Error = err.Error()
+		file = nil
+	}
+	return s.userRepo.FinishBulkJob(ctx, job, file)
+}
+
+// getBulkJob returns the job with the given ID. Expired jobs are not found, and unfinished
+// jobs older than bulkJobTimeout are marked as failed.
+func (s *UserService) getBulkJob(ctx context.Context, id uuid.UUID) (*models.BulkJob, error) {
+	job, err := s.userRepo.GetBulkJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBulkJobNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if job.ExpiresAt != nil && !now.Before(*job.ExpiresAt) {
+		return nil, ErrBulkJobNotFound
+	}
+	unfinished := job.Status == models.BulkJobPending || job.Status == models.BulkJobRunning
+	if unfinished && now.Sub(job.CreatedAt) >= bulkJobTimeout {
+		if err := s.finishBulkJob(ctx, job, errBulkJobInterrupted, nil); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				// Finished in the meantime
+				return s.getBulkJob(ctx, id)
+			}
+			return nil, err
+		}
+	}
+	return job, nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-modular/internal/personaldata"
+	"go-modular/modules/user/models"
+	"go-modular/modules/user/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
+)
+
+const (
+	// defaultDataExportLinkExpiry is the lifetime of download links when none is configured.
+	defaultDataExportLinkExpiry = 24 * time.Hour
+
+	// dataExportBuildTimeout bounds building an archive. Pending exports older than this were
+	// interrupted (e.g. by a restart) and no longer block new requests.
+	dataExportBuildTimeout = 30 * time.Minute
+
+	// DataExportDownloadPath is the route serving archives by download token.
+	DataExportDownloadPath = "/api/v1/users/data-exports/download"
+)
+
+var (
+	// ErrDataExportInProgress is returned when the user requests an export while one is being built.
+	ErrDataExportInProgress = errors.New("a data export is already in progress")
+
+	// ErrDataExportNotFound is returned when the user never requested an export, or for
+	// unknown, expired or not yet ready download tokens.
+	ErrDataExportNotFound = errors.New("data export not found")
+)
+
+// ExportPersonalData writes the user's profile, including its metadata, to a personal data
+// export. It is the user module's personaldata.Exporter.
+func (s *UserService) ExportPersonalData(ctx context.Context, userID uuid.UUID, w *personaldata.Writer) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return w.WriteJSON("profile.json", user)
+}
+
+// RequestDataExport starts building a personal data export of the user in the background
+// with the exporters of all modules. Once ready, a time-limited download link is emailed to
+// the user. ErrDataExportInProgress is returned while another export is being built.
+func (s *UserService) RequestDataExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if _, err := s.userRepo.DeleteExpiredDataExports(ctx, now); err != nil {
+		return nil, err
+	}
+	latest, err := s.userRepo.GetLatestDataExport(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.Status == models.DataExportPending {
+		if now.Sub(latest.CreatedAt) < dataExportBuildTimeout {
+			return nil, ErrDataExportInProgress
+		}
+		if err := s.userRepo.FailDataExport(ctx, latest.ID, "interrupted", now.Add(s.dataExportLinkExpiry)); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	export := &models.DataExport{UserID: userID}
+	if err := s.userRepo.CreateDataExport(ctx, export); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrDataExportInProgress
+		}
+		return nil, err
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionDataExportRequested, auditModels.TargetUser, userID, map[string]any{
+		"export_id": export.ID.String(),
+	}))
+
+	// Keep the values of ctx (e.g. the audit actor) but outlive the request
+	go s.buildDataExport(context.WithoutCancel(ctx), export, user)
+	return export, nil
+}
+
+// buildDataExport builds and stores the archive of a pending export and emails the download link.
+func (s *UserService) buildDataExport(ctx context.Context, export *models.DataExport, user *models.User) {
+	ctx, cancel := context.WithTimeout(ctx, dataExportBuildTimeout)
+	defer cancel()
+	logger := s.logger.With(slog.String("export_id", export.ID.String()), slog.String("user_id", user.ID.String()))
+
+	fail := func(err error) {
+		logger.Error("Failed to build data export", slog.String("error", err.Error()))
+		if err := s.userRepo.FailDataExport(ctx, export.ID, err.Error(), time.Now().Add(s.dataExportLinkExpiry)); err != nil {
+			logger.Error("Failed to mark data export as failed", slog.String("error", err.Error()))
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.dataExports.Build(ctx, user.ID, &buf); err != nil {
+		fail(err)
+		return
+	}
+
+	token, err := apputils.GenerateURLSafeToken(48)
+	if err != nil {
+		fail(fmt.Errorf("failed to generate token: %w", err))
+		return
+	}
+	hash := sha256.Sum256([]byte(token))
+	expiresAt := time.Now().Add(s.dataExportLinkExpiry)
+	if err := s.userRepo.CompleteDataExport(ctx, export.ID, hex.EncodeToString(hash[:]), buf.Bytes(), expiresAt); err != nil {
+		fail(err)
+		return
+	}
+
+	if err := s.sendDataExportEmail(ctx, user, token, expiresAt); err != nil {
+		logger.Error("Failed to send data export email", slog.String("error", err.Error()))
+	}
+}
+
+// GetLatestDataExport returns the status of the user's most recent data export.
+func (s *UserService) GetLatestDataExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	export, err := s.userRepo.GetLatestDataExport(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	if export.IsExpired(time.Now()) {
+		return nil, ErrDataExportNotFound
+	}
+	return export, nil
+}
+
+// OpenDataExport returns the ready export, including its archive, for an emailed download
+// token. ErrDataExportNotFound is returned for unknown and expired tokens.
+func (s *UserService) OpenDataExport(ctx context.Context, token string) (*models.DataExport, error) {
+	if token == "" {
+		return nil, ErrDataExportNotFound
+	}
+	hash := sha256.Sum256([]byte(token))
+	export, err := s.userRepo.GetDataExportByTokenHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	if export.IsExpired(time.Now()) {
+		return nil, ErrDataExportNotFound
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionDataExportDownloaded, auditModels.TargetUser, export.UserID, map[string]any{
+		"export_id": export.ID.String(),
+	}))
+	return export, nil
+}
+
+func (s *UserService) sendDataExportEmail(ctx context.Context, user *models.User, token string, expiresAt time.Time) error {
+	link := strings.TrimRight(s.baseURL, "/") + DataExportDownloadPath + "?token=" + url.QueryEscape(token)
+	data := map[string]any{
+		"DisplayName": user.DisplayName,
+		"Email":       user.Email,
+		"DownloadURL": link,
+		"ExpiresAt":   expiresAt.UTC().Format(time.RFC1123),
+	}
+
+	subject := "Your personal data export is ready"
+	templateName := "data_export_ready.html" // ensure this template exists in templates/emails/
+
+	if s.mailer != nil {
+		return s.mailer.SendEmail(ctx, []string{user.Email}, subject, templateName, data)
+	}
+
+	// Fallback for development: print the download link
+	fmt.Println("No mailer configured, data export link for", user.Email, ":", link)
+	return nil
+}
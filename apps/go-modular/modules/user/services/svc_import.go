@@ -0,0 +1,435 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"go-modular/modules/user/models"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
+)
+
+const (
+	// importBatchSize is the number of valid records inserted with one COPY.
+	importBatchSize = 500
+
+	// maxImportErrors caps the row errors listed in an import report.
+	maxImportErrors = 1000
+
+	// maxImportLineSize is the longest NDJSON line accepted.
+	maxImportLineSize = 1 << 20
+)
+
+// ErrInvalidImportFile is returned when an import file cannot be read at all, e.g. a CSV
+// header without an email column. Problems with single records are reported per row instead.
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// errImportRecord wraps errors that only affect the current record of an import file.
+type errImportRecord struct{ err error }
+
+func (e *errImportRecord) Error() string { return e.err.Error() }
+
+// importRecordReader returns the next record of an import file, io.EOF after the last one,
+// or an *errImportRecord for a record that cannot be decoded.
+type importRecordReader func() (*models.ImportUserRecord, error)
+
+func newImportRecordReader(format models.BulkFormat, r io.Reader) (importRecordReader, error) {
+	switch format {
+	case models.BulkFormatCSV:
+		return newCSVRecordReader(r)
+	case models.BulkFormatNDJSON:
+		return newNDJSONRecordReader(r), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImportFile, format)
+}
+
+// newCSVRecordReader reads CSV files with a header row naming the columns. Columns written by
+// an export but not importable (id, created_at, ...) are ignored; unknown columns are rejected
+// so that typos in the header are not silently dropped.
+func newCSVRecordReader(r io.Reader) (importRecordReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: missing header row", ErrInvalidImportFile)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(models.ExportCSVColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q, expected some of %s", ErrInvalidImportFile, name, strings.Join(models.ImportCSVColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidImportFile, name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("%w: missing email column", ErrInvalidImportFile)
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	return func() (*models.ImportUserRecord, error) {
+		row, err := reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, &errImportRecord{parseErr.Err}
+			}
+			return nil, err
+		}
+		rec := &models.ImportUserRecord{
+			Email:       field(row, "email"),
+			DisplayName: field(row, "display_name"),
+			Username:    field(row, "username"),
+			AvatarURL:   field(row, "avatar_url"),
+		}
+		if tz := strings.TrimSpace(field(row, "timezone")); tz != "" {
+			rec.Metadata = &models.UserMetadata{Timezone: tz}
+		}
+		return rec, nil
+	}, nil
+}
+
+// newNDJSONRecordReader reads one JSON object per line. Blank lines are skipped and unknown
+// members ignored, so files produced by an export can be imported again.
+func newNDJSONRecordReader(r io.Reader) importRecordReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	return func() (*models.ImportUserRecord, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var rec models.ImportUserRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				return nil, &errImportRecord{errors.New("invalid JSON object")}
+			}
+			return &rec, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// pendingImport is a valid record waiting for its batch to be inserted.
+type pendingImport struct {
+	row  int
+	rec  *models.ImportUserRecord
+	user *models.User
+}
+
+// importer keeps the state of one import: the report and the emails and usernames claimed
+// by earlier records of the file, so duplicates within the file are reported.
+type importer struct {
+	s         *UserService
+	opts      models.ImportOptions
+	report    *models.ImportReport
+	emails    map[string]int // lower-cased email -> row
+	usernames map[string]int // lower-cased username -> row
+	batch     []*pendingImport
+}
+
+// ImportUsers creates the users of an import file in CSV or NDJSON format. Every record is
+// validated like a single user creation; records that fail are listed in the returned report
+// and do not stop the import. Valid records are inserted in batches with COPY. Usernames are
+// generated from the email, like CreateUser does, when the record has none. With
+// opts.DryRun nothing is written and the report shows what an import would do. With
+// opts.Invite a UserInvited event is published for every created user; the auth module then
+// emails them a link to set their password.
+//
+// An error is only returned when the file cannot be read or the database fails; the report
+// then covers the records processed so far.
+func (s *UserService) ImportUsers(ctx context.Context, r io.Reader, opts models.ImportOptions) (*models.ImportReport, error) {
+	report := &models.ImportReport{DryRun: opts.DryRun, Errors: []models.ImportRowError{}}
+	next, err := newImportRecordReader(opts.Format, r)
+	if err != nil {
+		return report, err
+	}
+
+	imp := &importer{
+		s:         s,
+		opts:      opts,
+		report:    report,
+		emails:    map[string]int{},
+		usernames: map[string]int{},
+	}
+	for row := 1; ; row++ {
+		rec, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recErr *errImportRecord
+		if errors.As(err, &recErr) {
+			report.Total++
+			imp.reject(row, "", map[string]string{"record": recErr.Error()})
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to read import file: %w", err)
+		}
+		report.Total++
+		imp.add(row, rec)
+		if len(imp.batch) >= importBatchSize {
+			if err := imp.flush(ctx); err != nil {
+				return report, err
+			}
+		}
+	}
+	if err := imp.flush(ctx); err != nil {
+		return report, err
+	}
+	// Records rejected when their batch was flushed are appended late
+	slices.SortStableFunc(report.Errors, func(a, b models.ImportRowError) int { return a.Row - b.Row })
+
+	if !opts.DryRun {
+		s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionUsersImported, auditModels.TargetUser, uuid.Nil, map[string]any{
+			"format":  string(opts.Format),
+			"total":   report.Total,
+			"created": report.Created,
+			"failed":  report.Failed,
+			"invited": report.Invited,
+		}))
+	}
+	return report, nil
+}
+
+// reject records a failed row in the report.
+func (imp *importer) reject(row int, email string, errs map[string]string) {
+	imp.report.Failed++
+	if len(imp.report.Errors) >= maxImportErrors {
+		imp.report.ErrorsTruncated = true
+		return
+	}
+	imp.report.Errors = append(imp.report.Errors, models.ImportRowError{Row: row, Email: email, Errors: errs})
+}
+
+// add validates a record and queues it for the next batch.
+func (imp *importer) add(row int, rec *models.ImportUserRecord) {
+	rec.Email = strings.TrimSpace(rec.Email)
+	rec.DisplayName = strings.TrimSpace(rec.DisplayName)
+	rec.Username = strings.TrimSpace(rec.Username)
+	rec.AvatarURL = strings.TrimSpace(rec.AvatarURL)
+
+	errs := map[string]string{}
+	if err := imp.s.validate.Struct(rec); err != nil {
+		errs = apputils.ValidationErrorsToMap(err, rec)
+	}
+	if _, ok := errs["username"]; !ok && rec.Username != "" && !usernameRegex.MatchString(rec.Username) {
+		errs["username"] = ErrInvalidUsername.Error()
+	}
+	if err := imp.s.metadataSchema.Validate(rec.Metadata); err != nil {
+		var verr *MetadataValidationError
+		if !errors.As(err, &verr) {
+			verr = &MetadataValidationError{Fields: map[string]string{"metadata": err.Error()}}
+		}
+		for field, msg := range verr.Fields {
+			switch field {
+			case "timezone":
+				errs["timezone"] = "Unknown time zone"
+			case "metadata":
+				errs["metadata"] = msg
+			default:
+				errs["metadata."+field] = msg
+			}
+		}
+	}
+
+	email := strings.ToLower(rec.Email)
+	if first, ok := imp.emails[email]; ok && email != "" {
+		errs["email"] = fmt.Sprintf("Duplicate of row %d", first)
+	}
+	username := strings.ToLower(rec.Username)
+	if first, ok := imp.usernames[username]; ok && username != "" {
+		errs["username"] = fmt.Sprintf("Duplicate of row %d", first)
+	}
+	if len(errs) > 0 {
+		imp.reject(row, rec.Email, errs)
+		return
+	}
+
+	imp.emails[email] = row
+	if username != "" {
+		imp.usernames[username] = row
+	}
+	imp.batch = append(imp.batch, &pendingImport{row: row, rec: rec})
+}
+
+// flush checks the queued records against existing users, generates missing usernames and,
+// unless this is a dry run, inserts the batch.
+func (imp *importer) flush(ctx context.Context) error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+	batch := imp.batch
+	imp.batch = nil
+
+	emails := make([]string, 0, len(batch))
+	var usernames []string
+	for _, p := range batch {
+		emails = append(emails, p.rec.Email)
+		if p.rec.Username != "" {
+			usernames = append(usernames, p.rec.Username)
+		}
+	}
+	takenEmails, err := imp.s.userRepo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return err
+	}
+	takenUsernames, err := imp.s.userRepo.ExistingUsernames(ctx, usernames)
+	if err != nil {
+		return err
+	}
+
+	valid := batch[:0]
+	for _, p := range batch {
+		errs := map[string]string{}
+		if slices.Contains(takenEmails, strings.ToLower(p.rec.Email)) {
+			errs["email"] = "Email is already registered"
+		}
+		if p.rec.Username != "" && slices.Contains(takenUsernames, strings.ToLower(p.rec.Username)) {
+			errs["username"] = ErrUsernameTaken.Error()
+		}
+		if len(errs) > 0 {
+			imp.reject(p.row, p.rec.Email, errs)
+			continue
+		}
+		if p.user, err = imp.newUser(ctx, p.row, p.rec); err != nil {
+			return err
+		}
+		valid = append(valid, p)
+	}
+	imp.report.Valid += len(valid)
+	if imp.opts.DryRun || len(valid) == 0 {
+		return nil
+	}
+
+	users := make([]*models.User, len(valid))
+	for i, p := range valid {
+		users[i] = p.user
+	}
+	created, err := imp.s.userRepo.CopyUsers(ctx, users)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			return err
+		}
+		// A constraint rejected the batch, e.g. a user registered concurrently; only the
+		// conflicting rows fail when they are inserted one by one
+		valid, err = imp.insertEach(ctx, valid)
+		created = int64(len(valid))
+		if err != nil {
+			imp.report.Created += int(created)
+			return err
+		}
+	}
+	imp.report.Created += int(created)
+
+	now := time.Now()
+	for _, p := range valid {
+		imp.s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionUserCreated, auditModels.TargetUser, p.user.ID, map[string]any{
+			"email": p.user.Email, "source": "import",
+		}))
+		imp.s.publish(ctx, models.UserCreated{UserID: p.user.ID, Email: p.user.Email, OccurredAt: now})
+		if !imp.opts.Invite {
+			continue
+		}
+		if err := imp.s.events.Publish(ctx, newUserInvited(p.user, now)); err != nil {
+			// The user exists, only the invitation failed; list it without counting the row as failed
+			imp.s.logger.Error("failed to invite imported user", slog.String("user_id", p.user.ID.String()), slog.String("error", err.Error()))
+			if len(imp.report.Errors) < maxImportErrors {
+				imp.report.Errors = append(imp.report.Errors, models.ImportRowError{
+					Row: p.row, Email: p.user.Email, Errors: map[string]string{"invitation": "Invitation could not be sent"},
+				})
+			} else {
+				imp.report.ErrorsTruncated = true
+			}
+			continue
+		}
+		imp.report.Invited++
+	}
+	return nil
+}
+
+// insertEach inserts the users of a batch one at a time, rejecting the rows a constraint
+// fails. It returns the inserted rows.
+func (imp *importer) insertEach(ctx context.Context, batch []*pendingImport) ([]*pendingImport, error) {
+	inserted := batch[:0]
+	for _, p := range batch {
+		if _, err := imp.s.userRepo.CopyUsers(ctx, []*models.User{p.user}); err != nil {
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) {
+				return inserted, err
+			}
+			imp.report.Valid--
+			imp.reject(p.row, p.rec.Email, map[string]string{"record": "Could not be inserted: " + pgErr.Message})
+			continue
+		}
+		inserted = append(inserted, p)
+	}
+	return inserted, nil
+}
+
+// newUser builds the user of a valid record, generating its username when missing.
+func (imp *importer) newUser(ctx context.Context, row int, rec *models.ImportUserRecord) (*models.User, error) {
+	user := &models.User{
+		DisplayName: rec.DisplayName,
+		Email:       rec.Email,
+		Metadata:    rec.Metadata,
+	}
+	if user.Metadata == nil {
+		user.Metadata = &models.UserMetadata{Timezone: "UTC"}
+	}
+	if rec.AvatarURL != "" {
+		avatarURL := rec.AvatarURL
+		user.AvatarURL = &avatarURL
+	}
+
+	username := rec.Username
+	if username == "" {
+		generated, err := imp.s.generateUsername(ctx, rec.Email, imp.usernames)
+		if err != nil {
+			return nil, err
+		}
+		username = generated
+		imp.usernames[username] = row
+	}
+	user.Username = &username
+	return user, nil
+}
+
+// newUserInvited returns the invitation event of an imported user.
+func newUserInvited(user *models.User, now time.Time) models.UserInvited {
+	event := models.UserInvited{UserID: user.ID, Email: user.Email, DisplayName: user.DisplayName, OccurredAt: now}
+	if user.Username != nil {
+		event.Username = *user.Username
+	}
+	return event
+}
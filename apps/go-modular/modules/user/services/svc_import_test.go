@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-modular/modules/user/models"
+	"go-modular/modules/user/repository"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importRepo stores imported users in memory. Batches containing a taken email fail as a
+// whole, like a COPY hitting a unique violation. Other UserRepositoryInterface methods are
+// not implemented and panic when called.
+type importRepo struct {
+	repository.UserRepositoryInterface
+
+	mu     sync.Mutex
+	taken  map[string]bool
+	copies int
+	users  []*models.User
+}
+
+func (r *importRepo) ExistingEmails(context.Context, []string) ([]string, error) { return nil, nil }
+
+func (r *importRepo) ExistingUsernames(context.Context, []string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *importRepo) CopyUsers(_ context.Context, users []*models.User) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.copies++
+	for _, u := range users {
+		if r.taken[u.Email] {
+			return 0, &pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "users_email_key"`}
+		}
+	}
+	r.users = append(r.users, users...)
+	return int64(len(users)), nil
+}
+
+func TestImportUsers_ConstraintViolationOnlyFailsConflictingRows(t *testing.T) {
+	// Registered after the batch was checked against existing users
+	repo := &importRepo{taken: map[string]bool{"bob@example.com": true}}
+	svc := NewUserService(UserServiceOpts{UserRepo: repo})
+
+	csv := strings.Join([]string{
+		"email,display_name,username",
+		"alice@example.com,Alice,alice",
+		"bob@example.com,Bob,bob",
+		"carol@example.com,Carol,carol",
+	}, "\n")
+	report, err := svc.ImportUsers(context.Background(), strings.NewReader(csv), models.ImportOptions{Format: models.BulkFormatCSV})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Valid)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 2, report.Errors[0].Row)
+	assert.Contains(t, report.Errors[0].Errors["record"], "users_email_key")
+
+	emails := []string{}
+	for _, u := range repo.users {
+		emails = append(emails, u.Email)
+	}
+	assert.ElementsMatch(t, []string{"alice@example.com", "carol@example.com"}, emails)
+	assert.Equal(t, 4, repo.copies, "one batch COPY, then one per row")
+}
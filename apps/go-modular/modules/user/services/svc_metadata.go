@@ -0,0 +1,283 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-modular/modules/user/models"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+var (
+	// ErrInvalidMetadata is returned (wrapped in a *MetadataValidationError) when user metadata
+	// does not match the metadata schema.
+	ErrInvalidMetadata = errors.New("invalid user metadata")
+
+	// ErrInvalidMetadataFilter is returned when filtering on undeclared metadata fields or with
+	// values that do not match the declared type.
+	ErrInvalidMetadataFilter = errors.New("invalid metadata filter")
+)
+
+// metadataFieldNameRegex matches the names of custom metadata fields. They are used as query
+// parameter names (metadata.<field>), so they are kept simple.
+var metadataFieldNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// metadataSchemaURL is the location the metadata schema is compiled under.
+const metadataSchemaURL = "user-metadata.schema.json"
+
+// emptyMetadataSchema declares no custom fields; it is used when no schema is configured.
+const emptyMetadataSchema = `{"type": "object", "properties": {}}`
+
+// MetadataValidationError lists the metadata fields that failed validation with the reasons.
+type MetadataValidationError struct {
+	Fields map[string]string
+}
+
+func (e *MetadataValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+": "+e.Fields[name])
+	}
+	return ErrInvalidMetadata.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *MetadataValidationError) Unwrap() error { return ErrInvalidMetadata }
+
+func (e *MetadataValidationError) add(field, msg string) {
+	if prev, ok := e.Fields[field]; ok {
+		msg = prev + "; " + msg
+	}
+	e.Fields[field] = msg
+}
+
+// MetadataSchema is the JSON Schema operators use to declare the custom fields of user
+// metadata. It must describe an object; its properties are the custom fields. Metadata
+// writes are validated against it, undeclared fields are rejected and declared fields can be
+// used in user filters.
+type MetadataSchema struct {
+	doc    json.RawMessage
+	schema *jsonschema.Schema
+	fields map[string]*jsonschema.Schema
+}
+
+// LoadMetadataSchema reads and compiles the metadata schema file at path.
+func LoadMetadataSchema(path string) (*MetadataSchema, error) {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user metadata schema: %w", err)
+	}
+	return NewMetadataSchema(doc)
+}
+
+// NewMetadataSchema compiles a metadata schema document. Formats (e.g. "email", "date") are
+// asserted, not just annotations.
+func NewMetadataSchema(doc []byte) (*MetadataSchema, error) {
+	parsed, err := jsonschema.UnmarshalJSON(bytes.NewReader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("invalid user metadata schema: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource(metadataSchemaURL, parsed); err != nil {
+		return nil, fmt.Errorf("invalid user metadata schema: %w", err)
+	}
+	schema, err := c.Compile(metadataSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user metadata schema: %w", err)
+	}
+	if schema.Types == nil || !slices.Equal(schema.Types.ToStrings(), []string{"object"}) {
+		return nil, errors.New(`invalid user metadata schema: root must be {"type": "object"}`)
+	}
+	for name := range schema.Properties {
+		if slices.Contains(models.BuiltinMetadataFields, name) {
+			return nil, fmt.Errorf("invalid user metadata schema: property %q is a built-in metadata field", name)
+		}
+		if !metadataFieldNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid user metadata schema: property name %q must match %s", name, metadataFieldNameRegex)
+		}
+	}
+	return &MetadataSchema{doc: json.RawMessage(doc), schema: schema, fields: schema.Properties}, nil
+}
+
+// mustEmptyMetadataSchema returns a schema without custom fields.
+func mustEmptyMetadataSchema() *MetadataSchema {
+	s, err := NewMetadataSchema([]byte(emptyMetadataSchema))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Document returns the schema document as configured.
+func (m *MetadataSchema) Document() json.RawMessage {
+	return m.doc
+}
+
+// Validate checks metadata against the schema. A *MetadataValidationError listing every
+// invalid field is returned when it does not match.
+func (m *MetadataSchema) Validate(meta *models.UserMetadata) error {
+	if meta == nil {
+		return nil
+	}
+	verr := &MetadataValidationError{Fields: map[string]string{}}
+	if meta.Timezone != "" {
+		if _, err := time.LoadLocation(meta.Timezone); err != nil {
+			verr.add("timezone", "unknown time zone")
+		}
+	}
+	for name := range meta.Custom {
+		if _, ok := m.fields[name]; !ok {
+			verr.add(name, "field is not declared in the user metadata schema")
+		}
+	}
+
+	// Validate the JSON form, so values have the types the validator expects
+	custom := meta.Custom
+	if custom == nil {
+		custom = map[string]any{}
+	}
+	b, err := json.Marshal(custom)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	if err := m.schema.Validate(doc); err != nil {
+		var ve *jsonschema.ValidationError
+		if !errors.As(err, &ve) {
+			return err
+		}
+		for _, unit := range ve.BasicOutput().Errors {
+			if unit.Error == nil {
+				continue
+			}
+			field := strings.ReplaceAll(strings.TrimPrefix(unit.InstanceLocation, "/"), "/", ".")
+			if field == "" {
+				field = "metadata"
+			}
+			verr.add(field, unit.Error.String())
+		}
+		if len(verr.Fields) == 0 {
+			verr.add("metadata", ve.Error())
+		}
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// FilterValues converts metadata filter values given as strings (e.g. from query parameters)
+// to the types declared in the schema, so they match the stored JSON values. Arrays of
+// scalars match users whose array contains the value. Values that are not strings are kept.
+func (m *MetadataSchema) FilterValues(filter map[string]any) (map[string]any, error) {
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	typed := make(map[string]any, len(filter))
+	for name, value := range filter {
+		raw, ok := value.(string)
+		if !ok {
+			typed[name] = value
+			continue
+		}
+		if slices.Contains(models.BuiltinMetadataFields, name) {
+			typed[name] = raw
+			continue
+		}
+		field, ok := m.fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: field %q is not declared in the user metadata schema", ErrInvalidMetadataFilter, name)
+		}
+		field = resolveRef(field)
+		types := schemaTypes(field)
+		if slices.Contains(types, "array") {
+			item, err := convertFilterValue(raw, schemaTypes(arrayItems(field)))
+			if err != nil {
+				return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidMetadataFilter, name, err)
+			}
+			typed[name] = []any{item}
+			continue
+		}
+		v, err := convertFilterValue(raw, types)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidMetadataFilter, name, err)
+		}
+		typed[name] = v
+	}
+	return typed, nil
+}
+
+// convertFilterValue converts raw to the first of types it parses as. Strings are preferred,
+// and without declared types the value is matched as a string.
+func convertFilterValue(raw string, types []string) (any, error) {
+	if len(types) == 0 || slices.Contains(types, "string") {
+		return raw, nil
+	}
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return v, nil
+			}
+		case "number":
+			if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				return v, nil
+			}
+		case "boolean":
+			if v, err := strconv.ParseBool(raw); err == nil {
+				return v, nil
+			}
+		case "null":
+			if raw == "null" {
+				return nil, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("value %q is not a valid %s", raw, strings.Join(types, " or "))
+}
+
+// resolveRef follows $ref chains to the schema defining the type.
+func resolveRef(s *jsonschema.Schema) *jsonschema.Schema {
+	for s != nil && s.Ref != nil && s.Types == nil {
+		s = s.Ref
+	}
+	return s
+}
+
+func schemaTypes(s *jsonschema.Schema) []string {
+	s = resolveRef(s)
+	if s == nil || s.Types == nil {
+		return nil
+	}
+	return s.Types.ToStrings()
+}
+
+// arrayItems returns the schema of the items of an array schema (draft 2020-12 or earlier).
+func arrayItems(s *jsonschema.Schema) *jsonschema.Schema {
+	if s.Items2020 != nil {
+		return s.Items2020
+	}
+	if items, ok := s.Items.(*jsonschema.Schema); ok {
+		return items
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go-modular/modules/webhook/models"
+	"go-modular/modules/webhook/services"
+	"go-modular/pkg/apputils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// HandlerInterface defines the contract for webhook handlers.
+type HandlerInterface interface {
+	ListEventTypes(c echo.Context) error
+	CreateEndpoint(c echo.Context) error
+	ListEndpoints(c echo.Context) error
+	GetEndpoint(c echo.Context) error
+	UpdateEndpoint(c echo.Context) error
+	DeleteEndpoint(c echo.Context) error
+	ListDeliveries(c echo.Context) error
+	GetDelivery(c echo.Context) error
+	ReplayDelivery(c echo.Context) error
+}
+
+// Ensure Handler implements HandlerInterface
+var _ HandlerInterface = (*Handler)(nil)
+
+// Handler holds dependencies for webhook handlers.
+type Handler struct {
+	logger         *slog.Logger
+	webhookService services.WebhookServiceInterface
+	validator      *validator.Validate
+}
+
+type HandlerOpts struct {
+	Logger         *slog.Logger
+	WebhookService services.WebhookServiceInterface
+}
+
+// NewHandler creates a new Handler instance.
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:         opts.Logger,
+		webhookService: opts.WebhookService,
+		validator:      validator.New(),
+	}
+}
+
+// parseUUIDParam parses a UUID path parameter.
+func parseUUIDParam(c echo.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.FromString(c.Param(name))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondServiceError maps webhook service errors to HTTP responses.
+func (h *Handler) respondServiceError(c echo.Context, op string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrEndpointNotFound), errors.Is(err, services.ErrDeliveryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrEndpointDisabled), errors.Is(err, services.ErrDeliveryPending):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidURL), errors.Is(err, services.ErrInvalidEventType), errors.Is(err, services.ErrInvalidStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	h.logger.Error("Failed to "+op, slog.String("error", err.Error()))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to " + op + ", please try again later"})
+}
+
+// validationError responds with the validation errors of req.
+func validationError(c echo.Context, err error, req any) error {
+	return c.JSON(http.StatusBadRequest, map[string]any{
+		"error":   "Validation failed",
+		"details": apputils.ValidationErrorsToMap(err, req),
+	})
+}
+
+// @Summary      List webhook event types
+// @Description  Lists the event types webhook endpoints can subscribe to (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {array}   string
+// @Failure      403  {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/event-types [get]
+func (h *Handler) ListEventTypes(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.EventTypes)
+}
+
+// @Summary      Create webhook endpoint
+// @Description  Registers an endpoint receiving signed POST requests for the given event types (admin only). The signing secret is only returned in this response.
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string                         true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.CreateEndpointRequest  true  "Endpoint payload"
+// @Success      201      {object}  models.CreateEndpointResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/endpoints [post]
+func (h *Handler) CreateEndpoint(c echo.Context) error {
+	var req models.CreateEndpointRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return validationError(c, err, req)
+	}
+
+	endpoint, secret, err := h.webhookService.CreateEndpoint(c.Request().Context(), &req)
+	if err != nil {
+		return h.respondServiceError(c, "create webhook endpoint", err)
+	}
+	return c.JSON(http.StatusCreated, models.CreateEndpointResponse{Endpoint: endpoint, Secret: secret})
+}
+
+// @Summary      List webhook endpoints
+// @Description  Lists all webhook endpoints, including disabled ones (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Success      200  {array}   models.Endpoint
+// @Failure      403  {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/endpoints [get]
+func (h *Handler) ListEndpoints(c echo.Context) error {
+	endpoints, err := h.webhookService.ListEndpoints(c.Request().Context())
+	if err != nil {
+		return h.respondServiceError(c, "list webhook endpoints", err)
+	}
+	return c.JSON(http.StatusOK, endpoints)
+}
+
+// @Summary      Get webhook endpoint
+// @Description  Returns a webhook endpoint, including its failure count and why it was disabled (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        endpointId  path      string  true  "Endpoint ID"
+// @Success      200         {object}  models.Endpoint
+// @Failure      404         {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/endpoints/{endpointId} [get]
+func (h *Handler) GetEndpoint(c echo.Context) error {
+	id, ok := parseUUIDParam(c, "endpointId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid endpoint ID"})
+	}
+	endpoint, err := h.webhookService.GetEndpoint(c.Request().Context(), id)
+	if err != nil {
+		return h.respondServiceError(c, "get webhook endpoint", err)
+	}
+	return c.JSON(http.StatusOK, endpoint)
+}
+
+// @Summary      Update webhook endpoint
+// @Description  Changes the URL, description, event types or enabled state of an endpoint (admin only). Enabling a disabled endpoint resets its failure count and resumes its pending deliveries.
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string                         true  "Bearer {token}"
+// @Accept       json
+// @Produce      json
+// @Param        endpointId  path      string                        true  "Endpoint ID"
+// @Param        request     body      models.UpdateEndpointRequest  true  "Fields to change"
+// @Success      200         {object}  models.Endpoint
+// @Failure      400         {object}  map[string]string
+// @Failure      404         {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/endpoints/{endpointId} [patch]
+func (h *Handler) UpdateEndpoint(c echo.Context) error {
+	id, ok := parseUUIDParam(c, "endpointId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid endpoint ID"})
+	}
+	var req models.UpdateEndpointRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := h.validator.Struct(req); err != nil {
+		return validationError(c, err, req)
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(c.Request().Context(), id, &req)
+	if err != nil {
+		return h.respondServiceError(c, "update webhook endpoint", err)
+	}
+	return c.JSON(http.StatusOK, endpoint)
+}
+
+// @Summary      Delete webhook endpoint
+// @Description  Deletes a webhook endpoint together with its delivery log (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Param        endpointId  path  string  true  "Endpoint ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/endpoints/{endpointId} [delete]
+func (h *Handler) DeleteEndpoint(c echo.Context) error {
+	id, ok := parseUUIDParam(c, "endpointId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid endpoint ID"})
+	}
+	if err := h.webhookService.DeleteEndpoint(c.Request().Context(), id); err != nil {
+		return h.respondServiceError(c, "delete webhook endpoint", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// @Summary      List webhook deliveries
+// @Description  Returns the delivery log of an endpoint, newest first, with the outcome of the last attempt of each delivery (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        endpointId  path      string  true   "Endpoint ID"
+// @Param        status      query     string  false  "Delivery status"  Enums(pending, succeeded, failed)
+// @Param        limit       query     int     false  "Maximum number of deliveries (default and max: 100)"
+// @Param        offset      query     int     false  "Number of deliveries to skip"
+// @Success      200         {array}   models.Delivery
+// @Failure      400         {object}  map[string]string
+// @Failure      404         {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/endpoints/{endpointId}/deliveries [get]
+func (h *Handler) ListDeliveries(c echo.Context) error {
+	id, ok := parseUUIDParam(c, "endpointId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid endpoint ID"})
+	}
+	var filter models.FilterDelivery
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &filter); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request().Context(), id, &filter)
+	if err != nil {
+		return h.respondServiceError(c, "list webhook deliveries", err)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary      Get webhook delivery
+// @Description  Returns a delivery with its payload and the outcome of its last attempt (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        deliveryId  path      string  true  "Delivery ID"
+// @Success      200         {object}  models.Delivery
+// @Failure      404         {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/deliveries/{deliveryId} [get]
+func (h *Handler) GetDelivery(c echo.Context) error {
+	id, ok := parseUUIDParam(c, "deliveryId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+	delivery, err := h.webhookService.GetDelivery(c.Request().Context(), id)
+	if err != nil {
+		return h.respondServiceError(c, "get webhook delivery", err)
+	}
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// @Summary      Replay webhook delivery
+// @Description  Queues a completed delivery again, as a new delivery with the same event ID and payload (admin only)
+// @Tags         Webhooks
+// @Security     BearerAuth
+// @Param        Authorization  header    string  true  "Bearer {token}"
+// @Produce      json
+// @Param        deliveryId  path      string  true  "Delivery ID"
+// @Success      202         {object}  models.Delivery
+// @Failure      404         {object}  map[string]string
+// @Failure      409         {object}  map[string]string
+// @Router       /api/v1/admin/webhooks/deliveries/{deliveryId}/replay [post]
+func (h *Handler) ReplayDelivery(c echo.Context) error {
+	id, ok := parseUUIDParam(c, "deliveryId")
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+	delivery, err := h.webhookService.ReplayDelivery(c.Request().Context(), id)
+	if err != nil {
+		return h.respondServiceError(c, "replay webhook delivery", err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
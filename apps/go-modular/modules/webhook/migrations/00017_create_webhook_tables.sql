@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- ============================================================================
+-- Create webhook endpoints and deliveries tables and indexes
+-- Outbound webhooks: partner endpoints subscribe to event types and receive HMAC-SHA256
+-- signed POST requests. The signing secret must be stored in plain text, as it is needed
+-- to compute signatures.
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS public.webhook_endpoints (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    url TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMPTZ DEFAULT NULL,
+    disabled_reason TEXT DEFAULT NULL,
+    created_by UUID REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT NULL
+);
+
+-- Delivery log: one row per event and endpoint (plus one per replay). Attempts of a
+-- delivery update its row, keeping the outcome of the last one.
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries (
+    id UUID NOT NULL PRIMARY KEY DEFAULT uuidv7(),
+    endpoint_id UUID NOT NULL REFERENCES public.webhook_endpoints(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL, -- stable across retries and replays, so receivers can deduplicate
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP, -- next attempt (or end of the claim lease) while pending
+    last_attempt_at TIMESTAMPTZ DEFAULT NULL,
+    response_status INT DEFAULT NULL,
+    response_body TEXT DEFAULT NULL, -- truncated
+    error TEXT DEFAULT NULL,
+    replay_of UUID DEFAULT NULL, -- delivery this one replays (no foreign key, originals are pruned independently)
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ DEFAULT NULL
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_event_types ON public.webhook_endpoints USING GIN (event_types);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_id_created_at ON public.webhook_deliveries (endpoint_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON public.webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_completed_at ON public.webhook_deliveries (completed_at) WHERE completed_at IS NOT NULL;
+
+-- An event is delivered once per endpoint, even when the event bus delivers it again
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_event_unique ON public.webhook_deliveries (endpoint_id, event_id) WHERE replay_of IS NULL;
+
+-- Trigger
+CREATE TRIGGER trg_webhook_endpoints_updated_at BEFORE UPDATE ON public.webhook_endpoints FOR EACH ROW EXECUTE FUNCTION fn_updated_at_value();
+
+-- Webhooks are managed by administrators, never inside tenant-scoped transactions
+REVOKE ALL ON public.webhook_endpoints FROM app_tenant;
+REVOKE ALL ON public.webhook_deliveries FROM app_tenant;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Drop trigger, indexes and tables (reverse order of creation)
+DROP TRIGGER IF EXISTS trg_webhook_endpoints_updated_at ON public.webhook_endpoints;
+DROP INDEX IF EXISTS idx_webhook_deliveries_endpoint_event_unique;
+DROP INDEX IF EXISTS idx_webhook_deliveries_completed_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP INDEX IF EXISTS idx_webhook_deliveries_endpoint_id_created_at;
+DROP INDEX IF EXISTS idx_webhook_endpoints_event_types;
+DROP TABLE IF EXISTS public.webhook_deliveries;
+DROP TABLE IF EXISTS public.webhook_endpoints;
+
+-- +goose StatementEnd
@@ -0,0 +1,119 @@
+// Package models contains struct definitions related to the database layer for the webhook module.
+// This file defines models that map to database tables and are used for database operations.
+
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	authModels "go-modular/modules/auth/models"
+	userModels "go-modular/modules/user/models"
+)
+
+// EventTypes lists the domain events endpoints can subscribe to.
+var EventTypes = []string{
+	userModels.EventUserCreated,
+	userModels.EventUserEmailVerified,
+	userModels.EventUserDeleted,
+	authModels.EventSessionRevoked,
+	authModels.EventPasswordChanged,
+}
+
+// IsEventType reports whether t is an event type endpoints can subscribe to.
+func IsEventType(t string) bool {
+	return slices.Contains(EventTypes, t)
+}
+
+// -- MARK: Endpoint section
+
+// Define table name for Endpoint model
+const EndpointTable = "public.webhook_endpoints"
+
+// Endpoint is a partner URL receiving the events it subscribed to.
+type Endpoint struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	URL                 string     `json:"url" db:"url"`
+	Description         string     `json:"description" db:"description"`
+	EventTypes          []string   `json:"event_types" db:"event_types"`
+	Secret              string     `json:"-" db:"secret"` // HMAC-SHA256 signing secret, only returned on creation
+	Enabled             bool       `json:"enabled" db:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"` // failed attempts since the last success
+	DisabledAt          *time.Time `json:"disabled_at" db:"disabled_at"`
+	DisabledReason      *string    `json:"disabled_reason" db:"disabled_reason"`
+	CreatedBy           *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// -- MARK: Delivery section
+
+// Define table name for Delivery model
+const DeliveryTable = "public.webhook_deliveries"
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // Waiting for its next attempt
+	DeliverySucceeded DeliveryStatus = "succeeded" // The endpoint answered with a 2xx status
+	DeliveryFailed    DeliveryStatus = "failed"    // Given up after the maximum number of attempts
+)
+
+// IsValid reports whether s is a known delivery status
+func (s DeliveryStatus) IsValid() bool {
+	return s == DeliveryPending || s == DeliverySucceeded || s == DeliveryFailed
+}
+
+// Delivery is an event sent, or to be sent, to an endpoint. It records the outcome of
+// its last attempt.
+type Delivery struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id" db:"endpoint_id"`
+	EventID        uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status         DeliveryStatus  `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at" db:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status" db:"response_status"`
+	ResponseBody   *string         `json:"response_body" db:"response_body"`
+	Error          *string         `json:"error" db:"error"`
+	ReplayOf       *uuid.UUID      `json:"replay_of" db:"replay_of"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	CompletedAt    *time.Time      `json:"completed_at" db:"completed_at"`
+}
+
+// DueDelivery is a claimed delivery together with the target of its endpoint.
+type DueDelivery struct {
+	Delivery
+	URL    string
+	Secret string
+}
+
+// AttemptResult is the outcome of one delivery attempt.
+type AttemptResult struct {
+	ResponseStatus *int
+	ResponseBody   *string
+	Error          *string
+	AttemptedAt    time.Time
+}
+
+// FilterDelivery narrows down delivery queries. Zero fields are ignored.
+type FilterDelivery struct {
+	Status DeliveryStatus `query:"status"`
+	Limit  int            `query:"limit"`
+	Offset int            `query:"offset"`
+}
+
+// Envelope is the JSON body POSTed to endpoints.
+type Envelope struct {
+	ID        uuid.UUID       `json:"id"` // event ID, stable across retries and replays
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
@@ -0,0 +1,25 @@
+// Package models contains HTTP request/response schema definitions for the webhook module.
+// This file defines struct types used for HTTP payloads, validation, and OpenAPI documentation.
+
+package models
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048" example:"https://partner.example.com/webhooks"`
+	Description string   `json:"description" validate:"max=255" example:"Partner CRM sync"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,required" example:"user.created,user.deleted"`
+}
+
+// UpdateEndpointRequest changes the fields that are set. Enabling a disabled endpoint resets
+// its failure count and resumes its pending deliveries.
+type UpdateEndpointRequest struct {
+	URL         *string  `json:"url,omitempty" validate:"omitempty,url,max=2048" example:"https://partner.example.com/webhooks"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=255" example:"Partner CRM sync"`
+	EventTypes  []string `json:"event_types,omitempty" validate:"omitempty,min=1,dive,required" example:"user.created"`
+	Enabled     *bool    `json:"enabled,omitempty" example:"true"`
+}
+
+// CreateEndpointResponse returns the signing secret, which cannot be retrieved later.
+type CreateEndpointResponse struct {
+	Endpoint *Endpoint `json:"endpoint"`
+	Secret   string    `json:"secret" example:"whsec_3q2-7wEXAMPLEy8Zr0"`
+}
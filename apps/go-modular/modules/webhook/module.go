@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"go-modular/internal/eventbus"
+	"go-modular/internal/module"
+	"go-modular/modules/webhook/handler"
+	"go-modular/modules/webhook/repository"
+	"go-modular/modules/webhook/services"
+
+	auditSvc "go-modular/modules/audit/services"
+	modAuth "go-modular/modules/auth"
+	authModels "go-modular/modules/auth/models"
+	userModels "go-modular/modules/user/models"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+func init() {
+	module.Register(&WebhookModule{})
+}
+
+// enqueueSubscriber is the event bus subscriber name of the webhook module.
+const enqueueSubscriber = "webhook.enqueue"
+
+type Options struct {
+	PgPool *pgxpool.Pool // PostgreSQL connection pool (required)
+	Logger *slog.Logger  // Slog logger instance (optional)
+
+	// Auditor records security audit events (optional)
+	Auditor auditSvc.Recorder
+
+	// Events is the domain event bus; the events of models.EventTypes published on it are
+	// delivered to the subscribed endpoints (required)
+	Events *eventbus.Bus
+
+	HTTPClient        *http.Client  // Client used for deliveries (optional)
+	Timeout           time.Duration // Timeout of a delivery request (default: 10s)
+	MaxAttempts       int           // Attempts before a delivery is marked failed (default: 8)
+	DisableAfter      int           // Consecutive failed attempts before an endpoint is disabled (default: 20)
+	PollInterval      time.Duration // Time between polls for due deliveries (default: 5s)
+	AllowInsecureURLs bool          // Accept http:// endpoint URLs, never in production
+}
+
+// WebhookModule holds dependencies for webhook-related handlers and the delivery worker.
+type WebhookModule struct {
+	app            *module.App // set when initialized by the module registry
+	logger         *slog.Logger
+	middlewares    []echo.MiddlewareFunc
+	handler        *handler.Handler
+	webhookService services.WebhookServiceInterface
+}
+
+// NewModule creates a new WebhookModule and subscribes it to the webhook event types.
+func NewModule(opts *Options) *WebhookModule {
+	if opts.PgPool == nil || opts.Events == nil {
+		panic("invalid webhook module options: PgPool and Events are required")
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+
+	webhookService := services.NewWebhookService(services.WebhookServiceOpts{
+		Repo:              repository.NewWebhookRepository(opts.PgPool, logger),
+		Auditor:           opts.Auditor,
+		Logger:            logger,
+		HTTPClient:        opts.HTTPClient,
+		Timeout:           opts.Timeout,
+		MaxAttempts:       opts.MaxAttempts,
+		DisableAfter:      opts.DisableAfter,
+		PollInterval:      opts.PollInterval,
+		AllowInsecureURLs: opts.AllowInsecureURLs,
+	})
+
+	// Keep in sync with models.EventTypes
+	subscribe[userModels.UserCreated](opts.Events, webhookService)
+	subscribe[userModels.UserEmailVerified](opts.Events, webhookService)
+	subscribe[userModels.UserDeleted](opts.Events, webhookService)
+	subscribe[authModels.SessionRevoked](opts.Events, webhookService)
+	subscribe[authModels.PasswordChanged](opts.Events, webhookService)
+
+	h := handler.NewHandler(&handler.HandlerOpts{
+		Logger:         logger,
+		WebhookService: webhookService,
+	})
+
+	return &WebhookModule{
+		logger:         logger,
+		handler:        h,
+		webhookService: webhookService,
+	}
+}
+
+// Ensure WebhookModule implements module.Module
+var _ module.Module = (*WebhookModule)(nil)
+
+func (m *WebhookModule) Name() string { return "webhook" }
+
+func (m *WebhookModule) Dependencies() []string { return []string{"audit"} }
+
+// Init builds the module from the application config. Plain http:// endpoints are only
+// accepted outside of production.
+func (m *WebhookModule) Init(_ context.Context, app *module.App) error {
+	cfg := app.Config
+	*m = *NewModule(&Options{
+		PgPool:            app.DB.Pool,
+		Logger:            app.Logger,
+		Auditor:           module.MustLookup[auditSvc.Recorder](app),
+		Events:            app.Events,
+		Timeout:           cfg.Webhook.DeliveryTimeout,
+		MaxAttempts:       cfg.Webhook.MaxAttempts,
+		DisableAfter:      cfg.Webhook.DisableAfterFailures,
+		PollInterval:      cfg.Webhook.PollInterval,
+		AllowInsecureURLs: !cfg.IsProduction(),
+	})
+	m.app = app
+	return nil
+}
+
+// Start runs the delivery worker until ctx is cancelled.
+func (m *WebhookModule) Start(ctx context.Context) error {
+	m.StartDeliveryWorker(ctx)
+	return nil
+}
+
+func (m *WebhookModule) Stop(context.Context) error { return nil }
+
+func (m *WebhookModule) Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	return sub
+}
+
+func (m *WebhookModule) Health() []health.Check { return nil }
+
+// subscribe queues deliveries of events of type E. The subscription is asynchronous, so
+// publishers never wait for endpoints and the bus retries enqueueing when it fails.
+func subscribe[E eventbus.Event](bus *eventbus.Bus, svc services.WebhookServiceInterface) {
+	eventbus.Subscribe(bus, enqueueSubscriber, func(ctx context.Context, event E) error {
+		return svc.EnqueueEvent(ctx, event)
+	}, eventbus.Async())
+}
+
+// Expose WebhookService, so it can be used by other modules
+func (m *WebhookModule) GetWebhookService() services.WebhookServiceInterface {
+	return m.webhookService
+}
+
+// Use adds middleware(s) to the WebhookModule (grouped).
+func (m *WebhookModule) Use(mw ...echo.MiddlewareFunc) {
+	m.middlewares = append(m.middlewares, mw...)
+}
+
+// RegisterRoutes registers webhook administration endpoints to the given Echo group.
+func (m *WebhookModule) RegisterRoutes(e *echo.Group) {
+	// Modules initialized by the registry authenticate with the auth module
+	if m.app != nil {
+		authModule := module.MustLookup[*modAuth.AuthModule](m.app)
+		m.Use(authModule.JWTMiddleware(), modAuth.DenyImpersonation(), authModule.RequireAdmin())
+	}
+	g := e.Group("/admin/webhooks", m.middlewares...)
+	g.GET("/event-types", m.handler.ListEventTypes)
+	g.POST("/endpoints", m.handler.CreateEndpoint)
+	g.GET("/endpoints", m.handler.ListEndpoints)
+	g.GET("/endpoints/:endpointId", m.handler.GetEndpoint)
+	g.PATCH("/endpoints/:endpointId", m.handler.UpdateEndpoint)
+	g.DELETE("/endpoints/:endpointId", m.handler.DeleteEndpoint)
+	g.GET("/endpoints/:endpointId/deliveries", m.handler.ListDeliveries)
+	g.GET("/deliveries/:deliveryId", m.handler.GetDelivery)
+	g.POST("/deliveries/:deliveryId/replay", m.handler.ReplayDelivery)
+}
+
+// StartDeliveryWorker sends due deliveries in the background until ctx is cancelled.
+func (m *WebhookModule) StartDeliveryWorker(ctx context.Context) {
+	go m.webhookService.Run(ctx)
+}
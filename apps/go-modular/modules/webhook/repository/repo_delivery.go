@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go-modular/modules/webhook/models"
+)
+
+const deliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_attempt_at, response_status, response_body, error, replay_of, created_at, completed_at`
+
+// claimedDeliveryColumns are deliveryColumns qualified for ClaimDueDeliveries, which joins endpoints.
+const claimedDeliveryColumns = `d.id, d.endpoint_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_attempt_at, d.response_status, d.response_body, d.error, d.replay_of, d.created_at, d.completed_at`
+
+// scanDelivery scans a single webhook_deliveries row selected with deliveryColumns, followed
+// by the extra destinations.
+func scanDelivery(row pgx.Row, extra ...any) (*models.Delivery, error) {
+	var d models.Delivery
+	dest := []any{
+		&d.ID,
+		&d.EndpointID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastAttemptAt,
+		&d.ResponseStatus,
+		&d.ResponseBody,
+		&d.Error,
+		&d.ReplayOf,
+		&d.CreatedAt,
+		&d.CompletedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// CreateDeliveries inserts pending deliveries. Deliveries of an event an endpoint already
+// received are skipped, so redelivered events are not sent twice. It returns the number of
+// deliveries created.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*models.Delivery) (int64, error) {
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	query := `INSERT INTO ` + models.DeliveryTable + `
+        (id, endpoint_id, event_id, event_type, payload, status, next_attempt_at, replay_of, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        ON CONFLICT (endpoint_id, event_id) WHERE replay_of IS NULL DO NOTHING`
+
+	now := time.Now()
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		if d.ID == uuid.Nil {
+			d.ID = uuid.Must(uuid.NewV7())
+		}
+		if d.CreatedAt.IsZero() {
+			d.CreatedAt = now
+		}
+		if d.NextAttemptAt == nil {
+			d.NextAttemptAt = &d.CreatedAt
+		}
+		d.Status = models.DeliveryPending
+		batch.Queue(query, d.ID, d.EndpointID, d.EventID, d.EventType, d.Payload, d.Status, d.NextAttemptAt, d.ReplayOf, d.CreatedAt)
+	}
+
+	results := r.pgPool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	var created int64
+	for range deliveries {
+		cmd, err := results.Exec()
+		if err != nil {
+			r.logger.Error("failed to insert webhook deliveries", "op", "CreateDeliveries", "error", err.Error())
+			return created, err
+		}
+		created += cmd.RowsAffected()
+	}
+	return created, nil
+}
+
+// GetDelivery retrieves a delivery by its ID.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (*models.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM ` + models.DeliveryTable + ` WHERE id = $1`
+	d, err := scanDelivery(r.pgPool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.logger.Warn("webhook delivery not found", "op", "GetDelivery", "id", id.String())
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get webhook delivery", "op", "GetDelivery", "id", id.String(), "error", err.Error())
+		return nil, err
+	}
+	return d, nil
+}
+
+// ListDeliveries returns the delivery log of an endpoint, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter *models.FilterDelivery) ([]*models.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM ` + models.DeliveryTable + ` WHERE endpoint_id = $1`
+	args := []any{endpointID}
+	if filter != nil && filter.Status != "" {
+		args = append(args, filter.Status)
+		query += ` AND status = $` + strconv.Itoa(len(args))
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if filter != nil && filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT $` + strconv.Itoa(len(args))
+	}
+	if filter != nil && filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += ` OFFSET $` + strconv.Itoa(len(args))
+	}
+
+	rows, err := r.pgPool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("failed to query webhook deliveries", "op", "ListDeliveries", "endpoint_id", endpointID.String(), "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			r.logger.Error("failed to scan webhook delivery", "op", "ListDeliveries", "endpoint_id", endpointID.String(), "error", err.Error())
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries of enabled endpoints whose next
+// attempt is due, oldest first. A claimed delivery is not claimed again before the lease
+// expires, so a crashed worker's deliveries are retried by the next one. Deliveries of
+// disabled endpoints stay pending until the endpoint is enabled again.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.DueDelivery, error) {
+	query := `UPDATE ` + models.DeliveryTable + ` d SET next_attempt_at = $2
+        FROM ` + models.EndpointTable + ` e
+        WHERE d.endpoint_id = e.id AND d.id IN (
+            SELECT dd.id FROM ` + models.DeliveryTable + ` dd
+            JOIN ` + models.EndpointTable + ` ee ON ee.id = dd.endpoint_id
+            WHERE dd.status = 'pending' AND dd.next_attempt_at <= CURRENT_TIMESTAMP AND ee.enabled
+            ORDER BY dd.next_attempt_at ASC
+            LIMIT $1
+            FOR UPDATE OF dd SKIP LOCKED
+        )
+        RETURNING ` + claimedDeliveryColumns + `, e.url, e.secret`
+	rows, err := r.pgPool.Query(ctx, query, limit, time.Now().Add(lease))
+	if err != nil {
+		r.logger.Error("failed to claim webhook deliveries", "op", "ClaimDueDeliveries", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []*models.DueDelivery{}
+	for rows.Next() {
+		var url, secret string
+		d, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			r.logger.Error("failed to scan webhook delivery", "op", "ClaimDueDeliveries", "error", err.Error())
+			return nil, err
+		}
+		due = append(due, &models.DueDelivery{Delivery: *d, URL: url, Secret: secret})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the order of the subquery; UUIDv7 IDs sort by creation
+	slices.SortFunc(due, func(a, b *models.DueDelivery) int { return bytes.Compare(a.ID.Bytes(), b.ID.Bytes()) })
+	return due, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt. nextAttemptAt is the time of the
+// next attempt while the delivery stays pending, and is ignored otherwise.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id uuid.UUID, status models.DeliveryStatus, nextAttemptAt *time.Time, result *models.AttemptResult) error {
+	query := `UPDATE ` + models.DeliveryTable + ` SET
+            status = $1,
+            attempts = attempts + 1,
+            next_attempt_at = CASE WHEN $1 = 'pending' THEN $2::timestamptz ELSE NULL END,
+            last_attempt_at = $3,
+            response_status = $4,
+            response_body = $5,
+            error = $6,
+            completed_at = CASE WHEN $1 = 'pending' THEN NULL ELSE $3 END
+        WHERE id = $7`
+	cmd, err := r.pgPool.Exec(ctx, query,
+		status,
+		nextAttemptAt,
+		result.AttemptedAt,
+		result.ResponseStatus,
+		result.ResponseBody,
+		result.Error,
+		id,
+	)
+	if err != nil {
+		r.logger.Error("failed to record webhook delivery attempt", "op", "RecordAttempt", "id", id.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCompletedDeliveries removes up to limit deliveries completed before the given time.
+func (r *WebhookRepository) DeleteCompletedDeliveries(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `DELETE FROM ` + models.DeliveryTable + ` WHERE id IN (
+            SELECT id FROM ` + models.DeliveryTable + ` WHERE completed_at < $1 LIMIT $2
+        )`
+	cmd, err := r.pgPool.Exec(ctx, query, before, limit)
+	if err != nil {
+		r.logger.Error("failed to delete completed webhook deliveries", "op", "DeleteCompletedDeliveries", "error", err.Error())
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"go-modular/modules/webhook/models"
+)
+
+const endpointColumns = `id, url, description, event_types, secret, enabled, consecutive_failures, disabled_at, disabled_reason, created_by, created_at, updated_at`
+
+// scanEndpoint scans a single webhook_endpoints row selected with endpointColumns.
+func scanEndpoint(row pgx.Row) (*models.Endpoint, error) {
+	var e models.Endpoint
+	err := row.Scan(
+		&e.ID,
+		&e.URL,
+		&e.Description,
+		&e.EventTypes,
+		&e.Secret,
+		&e.Enabled,
+		&e.ConsecutiveFailures,
+		&e.DisabledAt,
+		&e.DisabledReason,
+		&e.CreatedBy,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// collectEndpoints scans all rows selected with endpointColumns.
+func collectEndpoints(rows pgx.Rows) ([]*models.Endpoint, error) {
+	defer rows.Close()
+	endpoints := []*models.Endpoint{}
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, rows.Err()
+}
+
+// CreateEndpoint inserts a new webhook endpoint into the database.
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.Endpoint) error {
+	if endpoint.ID == uuid.Nil {
+		endpoint.ID = uuid.Must(uuid.NewV7())
+	}
+	if endpoint.CreatedAt.IsZero() {
+		endpoint.CreatedAt = time.Now()
+	}
+
+	query := `INSERT INTO ` + models.EndpointTable + `
+        (id, url, description, event_types, secret, enabled, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.pgPool.Exec(ctx, query,
+		endpoint.ID,
+		endpoint.URL,
+		endpoint.Description,
+		endpoint.EventTypes,
+		endpoint.Secret,
+		endpoint.Enabled,
+		endpoint.CreatedBy,
+		endpoint.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error("failed to insert webhook endpoint", "op", "CreateEndpoint", "id", endpoint.ID.String(), "error", err.Error())
+		return err
+	}
+	r.logger.Info("webhook endpoint created", "op", "CreateEndpoint", "id", endpoint.ID.String())
+	return nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by its ID.
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*models.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM ` + models.EndpointTable + ` WHERE id = $1`
+	e, err := scanEndpoint(r.pgPool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.logger.Warn("webhook endpoint not found", "op", "GetEndpoint", "id", id.String())
+			return nil, ErrNotFound
+		}
+		r.logger.Error("failed to get webhook endpoint", "op", "GetEndpoint", "id", id.String(), "error", err.Error())
+		return nil, err
+	}
+	return e, nil
+}
+
+// ListEndpoints returns all webhook endpoints, including disabled ones.
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]*models.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM ` + models.EndpointTable + ` ORDER BY created_at ASC`
+	rows, err := r.pgPool.Query(ctx, query)
+	if err != nil {
+		r.logger.Error("failed to query webhook endpoints", "op", "ListEndpoints", "error", err.Error())
+		return nil, err
+	}
+	endpoints, err := collectEndpoints(rows)
+	if err != nil {
+		r.logger.Error("failed to scan webhook endpoints", "op", "ListEndpoints", "error", err.Error())
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// ListEndpointsForEvent returns the enabled endpoints subscribed to eventType.
+func (r *WebhookRepository) ListEndpointsForEvent(ctx context.Context, eventType string) ([]*models.Endpoint, error) {
+	query := `SELECT ` + endpointColumns + ` FROM ` + models.EndpointTable + `
+        WHERE enabled AND event_types @> ARRAY[$1::text] ORDER BY created_at ASC`
+	rows, err := r.pgPool.Query(ctx, query, eventType)
+	if err != nil {
+		r.logger.Error("failed to query webhook endpoints", "op", "ListEndpointsForEvent", "event_type", eventType, "error", err.Error())
+		return nil, err
+	}
+	endpoints, err := collectEndpoints(rows)
+	if err != nil {
+		r.logger.Error("failed to scan webhook endpoints", "op", "ListEndpointsForEvent", "event_type", eventType, "error", err.Error())
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// UpdateEndpoint saves the editable fields and the enabled state of an endpoint. Enabling
+// an endpoint clears its failure count and disabled reason.
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *models.Endpoint) error {
+	query := `UPDATE ` + models.EndpointTable + ` SET
+            url = $1,
+            description = $2,
+            event_types = $3,
+            enabled = $4,
+            consecutive_failures = CASE WHEN $4 THEN 0 ELSE consecutive_failures END,
+            disabled_at = CASE WHEN $4 THEN NULL ELSE COALESCE(disabled_at, CURRENT_TIMESTAMP) END,
+            disabled_reason = CASE WHEN $4 THEN NULL ELSE COALESCE(disabled_reason, 'disabled by an administrator') END
+        WHERE id = $5
+        RETURNING consecutive_failures, disabled_at, disabled_reason, updated_at`
+	err := r.pgPool.QueryRow(ctx, query,
+		endpoint.URL,
+		endpoint.Description,
+		endpoint.EventTypes,
+		endpoint.Enabled,
+		endpoint.ID,
+	).Scan(&endpoint.ConsecutiveFailures, &endpoint.DisabledAt, &endpoint.DisabledReason, &endpoint.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			r.logger.Warn("webhook endpoint not found for update", "op", "UpdateEndpoint", "id", endpoint.ID.String())
+			return ErrNotFound
+		}
+		r.logger.Error("failed to update webhook endpoint", "op", "UpdateEndpoint", "id", endpoint.ID.String(), "error", err.Error())
+		return err
+	}
+	r.logger.Info("webhook endpoint updated", "op", "UpdateEndpoint", "id", endpoint.ID.String())
+	return nil
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log.
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM ` + models.EndpointTable + ` WHERE id = $1`
+	cmd, err := r.pgPool.Exec(ctx, query, id)
+	if err != nil {
+		r.logger.Error("failed to delete webhook endpoint", "op", "DeleteEndpoint", "id", id.String(), "error", err.Error())
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		r.logger.Warn("webhook endpoint not found for delete", "op", "DeleteEndpoint", "id", id.String())
+		return ErrNotFound
+	}
+	r.logger.Info("webhook endpoint deleted", "op", "DeleteEndpoint", "id", id.String())
+	return nil
+}
+
+// RecordEndpointFailure increments the failure count of an endpoint and disables it once
+// the count reaches disableAfter. It reports whether this call disabled the endpoint.
+func (r *WebhookRepository) RecordEndpointFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error) {
+	query := `WITH old AS (
+            SELECT enabled FROM ` + models.EndpointTable + ` WHERE id = $1 FOR UPDATE
+        )
+        UPDATE ` + models.EndpointTable + ` e SET
+            consecutive_failures = e.consecutive_failures + 1,
+            enabled = e.enabled AND e.consecutive_failures + 1 < $2,
+            disabled_at = CASE WHEN e.enabled AND e.consecutive_failures + 1 >= $2 THEN CURRENT_TIMESTAMP ELSE e.disabled_at END,
+            disabled_reason = CASE WHEN e.enabled AND e.consecutive_failures + 1 >= $2 THEN $3 ELSE e.disabled_reason END
+        FROM old
+        WHERE e.id = $1
+        RETURNING old.enabled AND NOT e.enabled`
+	var disabled bool
+	err := r.pgPool.QueryRow(ctx, query, id, disableAfter, reason).Scan(&disabled)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// The endpoint was deleted while a delivery was in flight
+			return false, ErrNotFound
+		}
+		r.logger.Error("failed to record webhook endpoint failure", "op", "RecordEndpointFailure", "id", id.String(), "error", err.Error())
+		return false, err
+	}
+	return disabled, nil
+}
+
+// ResetEndpointFailures clears the failure count of an endpoint after a successful delivery.
+func (r *WebhookRepository) ResetEndpointFailures(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE ` + models.EndpointTable + ` SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures > 0`
+	if _, err := r.pgPool.Exec(ctx, query, id); err != nil {
+		r.logger.Error("failed to reset webhook endpoint failures", "op", "ResetEndpointFailures", "id", id.String(), "error", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go-modular/modules/webhook/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookRepositoryInterface defines the contract for webhook data access.
+type WebhookRepositoryInterface interface {
+	// Endpoint operations
+	CreateEndpoint(ctx context.Context, endpoint *models.Endpoint) error
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*models.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*models.Endpoint, error)
+	ListEndpointsForEvent(ctx context.Context, eventType string) ([]*models.Endpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *models.Endpoint) error
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	RecordEndpointFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error)
+	ResetEndpointFailures(ctx context.Context, id uuid.UUID) error
+
+	// Delivery operations
+	CreateDeliveries(ctx context.Context, deliveries []*models.Delivery) (int64, error)
+	GetDelivery(ctx context.Context, id uuid.UUID) (*models.Delivery, error)
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter *models.FilterDelivery) ([]*models.Delivery, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.DueDelivery, error)
+	RecordAttempt(ctx context.Context, id uuid.UUID, status models.DeliveryStatus, nextAttemptAt *time.Time, result *models.AttemptResult) error
+	DeleteCompletedDeliveries(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Ensure WebhookRepository implements WebhookRepositoryInterface
+var _ WebhookRepositoryInterface = (*WebhookRepository)(nil)
+
+// WebhookRepository is an implementation of WebhookRepositoryInterface using pgxpool.
+type WebhookRepository struct {
+	pgPool *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewWebhookRepository creates a new WebhookRepository with pgxpool and slog logger.
+func NewWebhookRepository(pgPool *pgxpool.Pool, logger *slog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		pgPool: pgPool,
+		logger: logger,
+	}
+}
+
+// Sentinel error for not found
+var ErrNotFound = errors.New("not found")
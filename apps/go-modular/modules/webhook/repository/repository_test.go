@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"go-modular/modules/webhook/models"
+	"go-modular/pkg/testutils"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+}
+
+func setupWebhookRepo(t *testing.T) (*WebhookRepository, func()) {
+	t.Helper()
+	te := testutils.NewTestEnv(t)
+	pool, _, err := te.SetupPostgres()
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+
+	// ensure env/config is set for migrations and app code
+	te.SetupConfig()
+	te.RunAppMigrations()
+
+	repo := NewWebhookRepository(pool, newLogger())
+	teardown := func() {
+		_, _ = pool.Exec(context.Background(), `TRUNCATE TABLE `+models.EndpointTable+` CASCADE`)
+		pool.Close()
+	}
+	return repo, teardown
+}
+
+func TestWebhook_Endpoints(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupWebhookRepo(t)
+	defer teardown()
+
+	users := &models.Endpoint{URL: "https://a.example.com/hook", EventTypes: []string{"user.created", "user.deleted"}, Secret: "whsec_a", Enabled: true}
+	sessions := &models.Endpoint{URL: "https://b.example.com/hook", EventTypes: []string{"auth.session_revoked"}, Secret: "whsec_b", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, users))
+	require.NoError(t, repo.CreateEndpoint(ctx, sessions))
+
+	got, err := repo.GetEndpoint(ctx, users.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_a", got.Secret)
+	assert.Equal(t, []string{"user.created", "user.deleted"}, got.EventTypes)
+
+	subscribed, err := repo.ListEndpointsForEvent(ctx, "user.deleted")
+	require.NoError(t, err)
+	require.Len(t, subscribed, 1)
+	assert.Equal(t, users.ID, subscribed[0].ID)
+
+	// The endpoint is disabled by the failure reaching the threshold, and only once
+	disabled, err := repo.RecordEndpointFailure(ctx, users.ID, 2, "too many failures")
+	require.NoError(t, err)
+	assert.False(t, disabled)
+	disabled, err = repo.RecordEndpointFailure(ctx, users.ID, 2, "too many failures")
+	require.NoError(t, err)
+	assert.True(t, disabled)
+	disabled, err = repo.RecordEndpointFailure(ctx, users.ID, 2, "too many failures")
+	require.NoError(t, err)
+	assert.False(t, disabled)
+
+	got, err = repo.GetEndpoint(ctx, users.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Enabled)
+	assert.Equal(t, 3, got.ConsecutiveFailures)
+	require.NotNil(t, got.DisabledReason)
+	assert.Equal(t, "too many failures", *got.DisabledReason)
+
+	// Disabled endpoints receive no new deliveries
+	subscribed, err = repo.ListEndpointsForEvent(ctx, "user.deleted")
+	require.NoError(t, err)
+	assert.Empty(t, subscribed)
+
+	// Enabling an endpoint clears its failures
+	got.Enabled = true
+	require.NoError(t, repo.UpdateEndpoint(ctx, got))
+	assert.Zero(t, got.ConsecutiveFailures)
+	assert.Nil(t, got.DisabledAt)
+	assert.NotNil(t, got.UpdatedAt)
+
+	require.NoError(t, repo.DeleteEndpoint(ctx, sessions.ID))
+	_, err = repo.GetEndpoint(ctx, sessions.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestWebhook_Deliveries(t *testing.T) {
+	ctx := context.Background()
+	repo, teardown := setupWebhookRepo(t)
+	defer teardown()
+
+	endpoint := &models.Endpoint{URL: "https://a.example.com/hook", EventTypes: []string{"user.created"}, Secret: "whsec_a", Enabled: true}
+	require.NoError(t, repo.CreateEndpoint(ctx, endpoint))
+
+	eventID := uuid.Must(uuid.NewV4())
+	newDelivery := func() *models.Delivery {
+		return &models.Delivery{EndpointID: endpoint.ID, EventID: eventID, EventType: "user.created", Payload: []byte(`{"id":1}`)}
+	}
+	first := newDelivery()
+	n, err := repo.CreateDeliveries(ctx, []*models.Delivery{first})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// An event is delivered once per endpoint
+	n, err = repo.CreateDeliveries(ctx, []*models.Delivery{newDelivery()})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Claimed deliveries come with their target and are leased
+	due, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, first.ID, due[0].ID)
+	assert.Equal(t, endpoint.URL, due[0].URL)
+	assert.Equal(t, "whsec_a", due[0].Secret)
+	due, err = repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	// A failed attempt is retried at the given time
+	status, msg := 500, "unexpected response status 500"
+	now := time.Now()
+	retryAt := now.Add(-time.Second)
+	require.NoError(t, repo.RecordAttempt(ctx, first.ID, models.DeliveryPending, &retryAt, &models.AttemptResult{ResponseStatus: &status, Error: &msg, AttemptedAt: now}))
+	due, err = repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+
+	// A successful attempt completes the delivery
+	status = 204
+	require.NoError(t, repo.RecordAttempt(ctx, first.ID, models.DeliverySucceeded, nil, &models.AttemptResult{ResponseStatus: &status, AttemptedAt: now}))
+	got, err := repo.GetDelivery(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliverySucceeded, got.Status)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Nil(t, got.Error)
+	assert.NotNil(t, got.CompletedAt)
+	assert.Nil(t, got.NextAttemptAt)
+
+	// Replays share the event ID of the original delivery
+	replay := newDelivery()
+	replay.ReplayOf = &first.ID
+	n, err = repo.CreateDeliveries(ctx, []*models.Delivery{replay})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	succeeded, err := repo.ListDeliveries(ctx, endpoint.ID, &models.FilterDelivery{Status: models.DeliverySucceeded})
+	require.NoError(t, err)
+	require.Len(t, succeeded, 1)
+	all, err := repo.ListDeliveries(ctx, endpoint.ID, &models.FilterDelivery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, replay.ID, all[0].ID)
+
+	// Pending deliveries of disabled endpoints are not claimed
+	_, err = repo.RecordEndpointFailure(ctx, endpoint.ID, 1, "disabled")
+	require.NoError(t, err)
+	due, err = repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	// Only completed deliveries are pruned
+	n, err = repo.DeleteCompletedDeliveries(ctx, time.Now().Add(time.Minute), 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = repo.GetDelivery(ctx, replay.ID)
+	require.NoError(t, err)
+}
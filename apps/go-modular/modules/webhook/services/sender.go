@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-modular/modules/webhook/models"
+)
+
+// Headers sent with every webhook request. Receivers verify the signature with VerifySignature
+// (or its equivalent) and should reject timestamps outside of a few minutes to prevent replays.
+const (
+	HeaderEventID    = "X-Webhook-Id"        // event ID, stable across retries and replays
+	HeaderDeliveryID = "X-Webhook-Delivery"  // delivery ID, differs for replays
+	HeaderEventType  = "X-Webhook-Event"     // event type, e.g. "user.created"
+	HeaderTimestamp  = "X-Webhook-Timestamp" // unix time in seconds when the request was signed
+	HeaderSignature  = "X-Webhook-Signature" // "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"
+)
+
+// signaturePrefix identifies the signing scheme in HeaderSignature.
+const signaturePrefix = "sha256="
+
+// maxResponseBody is the number of response body bytes kept in the delivery log.
+const maxResponseBody = 1024
+
+// Sign returns the HeaderSignature value for body signed at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature and timestamp headers of a received webhook request.
+// Timestamps further than tolerance from now are rejected.
+func VerifySignature(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid webhook timestamp")
+	}
+	timestamp := time.Unix(ts, 0)
+	if d := time.Since(timestamp); d > tolerance || d < -tolerance {
+		return errors.New("webhook timestamp outside of tolerance")
+	}
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
+
+// Sender POSTs signed webhook requests.
+type Sender struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewSender creates a Sender. Redirects are never followed: endpoints must be configured
+// with their final URL, and a redirect must not move a signed payload to another host.
+func NewSender(client *http.Client, timeout time.Duration) *Sender {
+	if client == nil {
+		client = &http.Client{}
+	}
+	c := *client
+	if c.Timeout == 0 {
+		c.Timeout = timeout
+	}
+	c.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return &Sender{client: &c, userAgent: "go-modular-webhooks/1.0"}
+}
+
+// Send delivers a claimed delivery to its endpoint. Non-2xx responses are reported in the
+// result error, like transport errors.
+func (s *Sender) Send(ctx context.Context, d *models.DueDelivery) *models.AttemptResult {
+	now := time.Now()
+	result := &models.AttemptResult{AttemptedAt: now}
+	fail := func(msg string) *models.AttemptResult {
+		result.Error = &msg
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return fail("invalid request: " + err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set(HeaderEventID, d.EventID.String())
+	req.Header.Set(HeaderDeliveryID, d.ID.String())
+	req.Header.Set(HeaderEventType, d.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, now, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fail(err.Error())
+	}
+	defer resp.Body.Close()
+
+	status := resp.StatusCode
+	result.ResponseStatus = &status
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if len(body) > 0 {
+		// Postgres text columns accept neither invalid UTF-8 nor NUL bytes
+		text := strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+		result.ResponseBody = &text
+	}
+	// Drain a little more so the connection can be reused
+	_, _ = io.CopyN(io.Discard, resp.Body, 64*1024)
+
+	if status < 200 || status > 299 {
+		return fail("unexpected response status " + strconv.Itoa(status))
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-modular/modules/webhook/models"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDueDelivery(url string) *models.DueDelivery {
+	return &models.DueDelivery{
+		Delivery: models.Delivery{
+			ID:        uuid.Must(uuid.NewV7()),
+			EventID:   uuid.Must(uuid.NewV4()),
+			EventType: "user.created",
+			Payload:   []byte(`{"type":"user.created"}`),
+		},
+		URL:    url,
+		Secret: "whsec_test",
+	}
+}
+
+func TestSender_Send_Signed(t *testing.T) {
+	d := newDueDelivery("")
+	var verifyErr error
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = VerifySignature("whsec_test", r.Header, body, 5*time.Minute)
+		assert.Equal(t, d.EventID.String(), r.Header.Get(HeaderEventID))
+		assert.Equal(t, d.ID.String(), r.Header.Get(HeaderDeliveryID))
+		assert.Equal(t, "user.created", r.Header.Get(HeaderEventType))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	d.URL = receiver.URL
+
+	result := NewSender(nil, time.Second).Send(context.Background(), d)
+	require.NoError(t, verifyErr)
+	assert.Nil(t, result.Error)
+	require.NotNil(t, result.ResponseStatus)
+	assert.Equal(t, http.StatusNoContent, *result.ResponseStatus)
+}
+
+func TestSender_Send_Failures(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/ok", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("database\x00 unavailable"))
+	}))
+	defer receiver.Close()
+	sender := NewSender(nil, time.Second)
+
+	// Error responses are kept in the result
+	result := sender.Send(context.Background(), newDueDelivery(receiver.URL))
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "unexpected response status 500", *result.Error)
+	require.NotNil(t, result.ResponseBody)
+	assert.Equal(t, "database unavailable", *result.ResponseBody)
+
+	// Redirects are not followed
+	result = sender.Send(context.Background(), newDueDelivery(receiver.URL+"/redirect"))
+	require.NotNil(t, result.Error)
+	assert.Equal(t, http.StatusFound, *result.ResponseStatus)
+
+	// Transport errors have no response
+	result = sender.Send(context.Background(), newDueDelivery("http://127.0.0.1:1"))
+	require.NotNil(t, result.Error)
+	assert.Nil(t, result.ResponseStatus)
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, Sign("whsec_test", now, body))
+	require.NoError(t, VerifySignature("whsec_test", header, body, time.Minute))
+
+	assert.EqualError(t, VerifySignature("whsec_other", header, body, time.Minute), "invalid webhook signature")
+	assert.EqualError(t, VerifySignature("whsec_test", header, []byte(`{"id":2}`), time.Minute), "invalid webhook signature")
+
+	// Old signatures are rejected, even when valid
+	old := now.Add(-10 * time.Minute)
+	header.Set(HeaderTimestamp, strconv.FormatInt(old.Unix(), 10))
+	header.Set(HeaderSignature, Sign("whsec_test", old, body))
+	assert.EqualError(t, VerifySignature("whsec_test", header, body, 5*time.Minute), "webhook timestamp outside of tolerance")
+}
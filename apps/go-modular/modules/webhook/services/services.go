@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go-modular/internal/eventbus"
+	"go-modular/modules/webhook/models"
+	"go-modular/modules/webhook/repository"
+	"go-modular/pkg/apputils"
+
+	"github.com/gofrs/uuid/v5"
+
+	auditModels "go-modular/modules/audit/models"
+	auditSvc "go-modular/modules/audit/services"
+)
+
+// Errors returned by the webhook service
+var (
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrEndpointDisabled = errors.New("webhook endpoint is disabled")
+	ErrInvalidURL       = errors.New("webhook URL must be an absolute https URL")
+	ErrInvalidEventType = errors.New("unknown webhook event type")
+	ErrInvalidStatus    = errors.New("invalid webhook delivery status")
+	ErrDeliveryPending  = errors.New("webhook delivery is still pending")
+)
+
+// secretPrefix makes webhook signing secrets easy to recognize.
+const secretPrefix = "whsec_"
+
+// eventIDNamespace derives stable event IDs from event payloads, so an event redelivered by
+// the event bus gets the same ID and is not delivered twice.
+var eventIDNamespace = uuid.NewV5(uuid.NamespaceURL, "go-modular:webhook-events")
+
+// WebhookServiceInterface defines the contract for webhook business logic.
+type WebhookServiceInterface interface {
+	CreateEndpoint(ctx context.Context, req *models.CreateEndpointRequest) (*models.Endpoint, string, error)
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*models.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]*models.Endpoint, error)
+	UpdateEndpoint(ctx context.Context, id uuid.UUID, req *models.UpdateEndpointRequest) (*models.Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter *models.FilterDelivery) ([]*models.Delivery, error)
+	GetDelivery(ctx context.Context, id uuid.UUID) (*models.Delivery, error)
+	ReplayDelivery(ctx context.Context, id uuid.UUID) (*models.Delivery, error)
+
+	EnqueueEvent(ctx context.Context, event eventbus.Event) error
+	DeliverDue(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
+
+// Ensure WebhookService implements WebhookServiceInterface
+var _ WebhookServiceInterface = (*WebhookService)(nil)
+
+// WebhookService manages webhook endpoints and delivers events to them.
+type WebhookService struct {
+	repo              repository.WebhookRepositoryInterface
+	auditor           auditSvc.Recorder
+	logger            *slog.Logger
+	sender            *Sender
+	maxAttempts       int
+	disableAfter      int
+	pollInterval      time.Duration
+	batchSize         int
+	concurrency       int
+	lease             time.Duration
+	retention         time.Duration
+	allowInsecureURLs bool
+}
+
+type WebhookServiceOpts struct {
+	Repo              repository.WebhookRepositoryInterface
+	Auditor           auditSvc.Recorder // Security audit log recorder (optional)
+	Logger            *slog.Logger      // Slog logger instance (optional)
+	HTTPClient        *http.Client      // Client used for deliveries (optional, redirects are never followed)
+	Timeout           time.Duration     // Timeout of a delivery request (default: 10s)
+	MaxAttempts       int               // Attempts before a delivery is marked failed (default: 8)
+	DisableAfter      int               // Consecutive failed attempts before an endpoint is disabled (default: 20)
+	PollInterval      time.Duration     // Time between polls for due deliveries (default: 5s)
+	BatchSize         int               // Deliveries claimed per poll (default: 50)
+	Concurrency       int               // Deliveries sent in parallel (default: 8)
+	Retention         time.Duration     // Time completed deliveries are kept in the log (default: 30 days)
+	AllowInsecureURLs bool              // Accept http:// endpoint URLs, for development and tests
+}
+
+// NewWebhookService creates a new WebhookService.
+func NewWebhookService(opts WebhookServiceOpts) *WebhookService {
+	if opts.Repo == nil {
+		panic("Repo is required")
+	}
+	if opts.Auditor == nil {
+		opts.Auditor = auditSvc.NopRecorder{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.DisableAfter <= 0 {
+		opts.DisableAfter = 20
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = 30 * 24 * time.Hour
+	}
+	return &WebhookService{
+		repo:              opts.Repo,
+		auditor:           opts.Auditor,
+		logger:            opts.Logger,
+		sender:            NewSender(opts.HTTPClient, opts.Timeout),
+		maxAttempts:       opts.MaxAttempts,
+		disableAfter:      opts.DisableAfter,
+		pollInterval:      opts.PollInterval,
+		batchSize:         opts.BatchSize,
+		concurrency:       opts.Concurrency,
+		lease:             2*opts.Timeout + time.Minute, // longer than a batch of requests takes
+		retention:         opts.Retention,
+		allowInsecureURLs: opts.AllowInsecureURLs,
+	}
+}
+
+// -- MARK: Endpoints
+
+// validateEndpoint checks the URL and event types of an endpoint.
+func (s *WebhookService) validateEndpoint(rawURL string, eventTypes []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && (u.Scheme != "http" || !s.allowInsecureURLs)) {
+		return ErrInvalidURL
+	}
+	for _, t := range eventTypes {
+		if !models.IsEventType(t) {
+			return fmt.Errorf("%w: %q", ErrInvalidEventType, t)
+		}
+	}
+	return nil
+}
+
+// CreateEndpoint registers an endpoint for the given event types. The signing secret is
+// returned once and cannot be retrieved later.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req *models.CreateEndpointRequest) (*models.Endpoint, string, error) {
+	if err := s.validateEndpoint(req.URL, req.EventTypes); err != nil {
+		return nil, "", err
+	}
+	token, err := apputils.GenerateURLSafeToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	endpoint := &models.Endpoint{
+		URL:         req.URL,
+		Description: strings.TrimSpace(req.Description),
+		EventTypes:  slices.Compact(slices.Sorted(slices.Values(req.EventTypes))),
+		Secret:      secretPrefix + token,
+		Enabled:     true,
+	}
+	if actorID, ok := auditSvc.ActorFromContext(ctx); ok {
+		endpoint.CreatedBy = &actorID
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, "", err
+	}
+
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionWebhookCreated, auditModels.TargetWebhook, endpoint.ID, map[string]any{
+		"url":         endpoint.URL,
+		"event_types": endpoint.EventTypes,
+	}))
+	return endpoint, endpoint.Secret, nil
+}
+
+// GetEndpoint returns an endpoint by ID.
+func (s *WebhookService) GetEndpoint(ctx context.Context, id uuid.UUID) (*models.Endpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrEndpointNotFound
+	}
+	return endpoint, err
+}
+
+// ListEndpoints returns all endpoints, including disabled ones.
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]*models.Endpoint, error) {
+	return s.repo.ListEndpoints(ctx)
+}
+
+// UpdateEndpoint changes the fields set in req. Enabling a disabled endpoint resets its
+// failure count; its pending deliveries are then sent again.
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, id uuid.UUID, req *models.UpdateEndpointRequest) (*models.Endpoint, error) {
+	endpoint, err := s.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	if req.URL != nil && *req.URL != endpoint.URL {
+		endpoint.URL = *req.URL
+		changed = append(changed, "url")
+	}
+	if req.Description != nil {
+		endpoint.Description = strings.TrimSpace(*req.Description)
+		changed = append(changed, "description")
+	}
+	if req.EventTypes != nil {
+		endpoint.EventTypes = slices.Compact(slices.Sorted(slices.Values(req.EventTypes)))
+		changed = append(changed, "event_types")
+	}
+	if req.Enabled != nil && *req.Enabled != endpoint.Enabled {
+		endpoint.Enabled = *req.Enabled
+		changed = append(changed, "enabled")
+	}
+	if err := s.validateEndpoint(endpoint.URL, endpoint.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrEndpointNotFound
+		}
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionWebhookUpdated, auditModels.TargetWebhook, endpoint.ID, map[string]any{
+		"fields":  changed,
+		"enabled": endpoint.Enabled,
+	}))
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log.
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrEndpointNotFound
+		}
+		return err
+	}
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionWebhookDeleted, auditModels.TargetWebhook, id, nil))
+	return nil
+}
+
+// -- MARK: Deliveries
+
+// ListDeliveries returns the delivery log of an endpoint, newest first.
+func (s *WebhookService) ListDeliveries(ctx context.Context, endpointID uuid.UUID, filter *models.FilterDelivery) ([]*models.Delivery, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	if _, err := s.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, endpointID, filter)
+}
+
+// GetDelivery returns a delivery by ID.
+func (s *WebhookService) GetDelivery(ctx context.Context, id uuid.UUID) (*models.Delivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// ReplayDelivery sends a completed delivery again, as a new delivery with the same event ID
+// and payload, so receivers that already processed the event can recognize it.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, id uuid.UUID) (*models.Delivery, error) {
+	original, err := s.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status == models.DeliveryPending {
+		return nil, ErrDeliveryPending
+	}
+	endpoint, err := s.GetEndpoint(ctx, original.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Enabled {
+		return nil, ErrEndpointDisabled
+	}
+
+	replay := &models.Delivery{
+		EndpointID: original.EndpointID,
+		EventID:    original.EventID,
+		EventType:  original.EventType,
+		Payload:    original.Payload,
+		ReplayOf:   &original.ID,
+	}
+	if _, err := s.repo.CreateDeliveries(ctx, []*models.Delivery{replay}); err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionWebhookReplayed, auditModels.TargetDelivery, replay.ID, map[string]any{
+		"replay_of":   original.ID.String(),
+		"endpoint_id": original.EndpointID.String(),
+		"event_type":  original.EventType,
+	}))
+	return replay, nil
+}
+
+// EnqueueEvent creates a pending delivery of event for every enabled endpoint subscribed
+// to its type. It is subscribed to the event bus, which retries it when it fails.
+func (s *WebhookService) EnqueueEvent(ctx context.Context, event eventbus.Event) error {
+	eventType := event.EventName()
+	endpoints, err := s.repo.ListEndpointsForEvent(ctx, eventType)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	envelope := models.Envelope{
+		ID:        uuid.NewV5(eventIDNamespace, eventType+":"+string(data)),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	// Events carry the time they occurred, which is more accurate than the time they are queued
+	var occurred struct {
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+	if json.Unmarshal(data, &occurred) == nil && !occurred.OccurredAt.IsZero() {
+		envelope.CreatedAt = occurred.OccurredAt.UTC()
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s webhook payload: %w", eventType, err)
+	}
+
+	deliveries := make([]*models.Delivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, &models.Delivery{
+			EndpointID: endpoint.ID,
+			EventID:    envelope.ID,
+			EventType:  eventType,
+			Payload:    payload,
+		})
+	}
+	_, err = s.repo.CreateDeliveries(ctx, deliveries)
+	return err
+}
+
+// -- MARK: Delivery worker
+
+// Run delivers due deliveries on every poll interval and prunes the delivery log hourly,
+// until ctx is cancelled. Several processes may run it; claims keep them from sending the
+// same delivery concurrently.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	var lastPrune time.Time
+
+	for {
+		// Keep going while batches come back full
+		for {
+			n, err := s.DeliverDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.Error("webhook delivery failed", "op", "WebhookService.Run", "error", err.Error())
+			}
+			if err != nil || n < s.batchSize {
+				break
+			}
+		}
+		if time.Since(lastPrune) >= time.Hour {
+			lastPrune = time.Now()
+			if err := s.prune(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("webhook delivery pruning failed", "op", "WebhookService.Run", "error", err.Error())
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims one batch of due deliveries, sends them and records the outcomes. It
+// returns the number of deliveries attempted.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	due, err := s.repo.ClaimDueDeliveries(ctx, s.batchSize, s.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	sem := make(chan struct{}, s.concurrency)
+	errs := make([]error, len(due))
+	for i, d := range due {
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem }()
+			errs[i] = s.deliver(ctx, d)
+		}()
+	}
+	for range s.concurrency {
+		sem <- struct{}{}
+	}
+	return len(due), errors.Join(errs...)
+}
+
+// deliver sends one delivery and records the outcome on the delivery and its endpoint.
+func (s *WebhookService) deliver(ctx context.Context, d *models.DueDelivery) error {
+	result := s.sender.Send(ctx, d)
+	attempt := d.Attempts + 1
+
+	if result.Error == nil {
+		if err := s.repo.RecordAttempt(ctx, d.ID, models.DeliverySucceeded, nil, result); err != nil {
+			return err
+		}
+		return s.repo.ResetEndpointFailures(ctx, d.EndpointID)
+	}
+
+	status := models.DeliveryPending
+	var next *time.Time
+	if attempt >= s.maxAttempts {
+		status = models.DeliveryFailed
+	} else {
+		at := result.AttemptedAt.Add(backoff(attempt))
+		next = &at
+	}
+	if err := s.repo.RecordAttempt(ctx, d.ID, status, next, result); err != nil {
+		return err
+	}
+	s.logger.Warn("webhook delivery attempt failed", "op", "WebhookService.deliver",
+		"delivery_id", d.ID.String(), "endpoint_id", d.EndpointID.String(),
+		"attempt", attempt, "status", string(status), "error", *result.Error)
+
+	reason := fmt.Sprintf("disabled after %d consecutive failed deliveries, last error: %s", s.disableAfter, *result.Error)
+	disabled, err := s.repo.RecordEndpointFailure(ctx, d.EndpointID, s.disableAfter, reason)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if disabled {
+		s.logger.Warn("webhook endpoint disabled", "op", "WebhookService.deliver", "endpoint_id", d.EndpointID.String(), "url", d.URL)
+		s.auditor.Record(ctx, auditSvc.NewEvent(auditModels.ActionWebhookDisabled, auditModels.TargetWebhook, d.EndpointID, map[string]any{
+			"reason": reason,
+		}))
+	}
+	return nil
+}
+
+// backoff returns the delay before the attempt following the given one: 30s, 2m, 8m, 32m,
+// ~2h, ~8.5h and 12h from then on.
+func backoff(attempt int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempt && d < 12*time.Hour; i++ {
+		d *= 4
+	}
+	return min(d, 12*time.Hour)
+}
+
+// prune deletes deliveries completed before the retention period, batch by batch.
+func (s *WebhookService) prune(ctx context.Context) error {
+	before := time.Now().Add(-s.retention)
+	for {
+		n, err := s.repo.DeleteCompletedDeliveries(ctx, before, 1000)
+		if err != nil {
+			return err
+		}
+		if n < 1000 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go-modular/modules/webhook/models"
+	"go-modular/modules/webhook/repository"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	userModels "go-modular/modules/user/models"
+)
+
+// memoryRepo is an in-memory WebhookRepositoryInterface; due deliveries are claimed
+// regardless of their next attempt time.
+type memoryRepo struct {
+	mu         sync.Mutex
+	endpoints  []*models.Endpoint
+	deliveries []*models.Delivery
+}
+
+var _ repository.WebhookRepositoryInterface = (*memoryRepo)(nil)
+
+func (r *memoryRepo) endpoint(id uuid.UUID) *models.Endpoint {
+	for _, e := range r.endpoints {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepo) CreateEndpoint(_ context.Context, e *models.Endpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.ID = uuid.Must(uuid.NewV7())
+	r.endpoints = append(r.endpoints, e)
+	return nil
+}
+
+func (r *memoryRepo) GetEndpoint(_ context.Context, id uuid.UUID) (*models.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e := r.endpoint(id); e != nil {
+		c := *e
+		return &c, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRepo) ListEndpoints(context.Context) ([]*models.Endpoint, error) {
+	return r.endpoints, nil
+}
+
+func (r *memoryRepo) ListEndpointsForEvent(_ context.Context, eventType string) ([]*models.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var endpoints []*models.Endpoint
+	for _, e := range r.endpoints {
+		if e.Enabled && slices.Contains(e.EventTypes, eventType) {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints, nil
+}
+
+func (r *memoryRepo) UpdateEndpoint(_ context.Context, e *models.Endpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e.Enabled {
+		e.ConsecutiveFailures = 0
+	}
+	*r.endpoint(e.ID) = *e
+	return nil
+}
+
+func (r *memoryRepo) DeleteEndpoint(context.Context, uuid.UUID) error { return nil }
+
+func (r *memoryRepo) RecordEndpointFailure(_ context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.endpoint(id)
+	e.ConsecutiveFailures++
+	if e.Enabled && e.ConsecutiveFailures >= disableAfter {
+		e.Enabled = false
+		e.DisabledReason = &reason
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *memoryRepo) ResetEndpointFailures(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endpoint(id).ConsecutiveFailures = 0
+	return nil
+}
+
+func (r *memoryRepo) CreateDeliveries(_ context.Context, deliveries []*models.Delivery) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var created int64
+	for _, d := range deliveries {
+		duplicate := d.ReplayOf == nil && slices.ContainsFunc(r.deliveries, func(o *models.Delivery) bool {
+			return o.EndpointID == d.EndpointID && o.EventID == d.EventID && o.ReplayOf == nil
+		})
+		if duplicate {
+			continue
+		}
+		d.ID = uuid.Must(uuid.NewV7())
+		d.Status = models.DeliveryPending
+		r.deliveries = append(r.deliveries, d)
+		created++
+	}
+	return created, nil
+}
+
+func (r *memoryRepo) GetDelivery(_ context.Context, id uuid.UUID) (*models.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRepo) ListDeliveries(context.Context, uuid.UUID, *models.FilterDelivery) ([]*models.Delivery, error) {
+	return r.deliveries, nil
+}
+
+func (r *memoryRepo) ClaimDueDeliveries(_ context.Context, limit int, _ time.Duration) ([]*models.DueDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*models.DueDelivery
+	for _, d := range r.deliveries {
+		e := r.endpoint(d.EndpointID)
+		if d.Status != models.DeliveryPending || !e.Enabled || len(due) == limit {
+			continue
+		}
+		due = append(due, &models.DueDelivery{Delivery: *d, URL: e.URL, Secret: e.Secret})
+	}
+	return due, nil
+}
+
+func (r *memoryRepo) RecordAttempt(_ context.Context, id uuid.UUID, status models.DeliveryStatus, next *time.Time, result *models.AttemptResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			d.Status = status
+			d.Attempts++
+			d.NextAttemptAt = next
+			d.ResponseStatus = result.ResponseStatus
+			d.Error = result.Error
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepo) DeleteCompletedDeliveries(context.Context, time.Time, int) (int64, error) {
+	return 0, nil
+}
+
+// receiver is an httptest webhook receiver answering with the configured status.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	received []models.Envelope
+}
+
+func newReceiver(t *testing.T, secret *string) *receiver {
+	rc := &receiver{status: http.StatusOK}
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifySignature(*secret, r.Header, body, time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		var envelope models.Envelope
+		require.NoError(t, json.Unmarshal(body, &envelope))
+		rc.received = append(rc.received, envelope)
+		w.WriteHeader(rc.status)
+	}))
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func newTestService(repo *memoryRepo) *WebhookService {
+	return NewWebhookService(WebhookServiceOpts{
+		Repo:              repo,
+		Logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+		MaxAttempts:       3,
+		DisableAfter:      5,
+		AllowInsecureURLs: true,
+	})
+}
+
+func TestWebhookService_DeliverEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRepo{}
+	svc := newTestService(repo)
+
+	var secret string
+	rc := newReceiver(t, &secret)
+	endpoint, secret, err := svc.CreateEndpoint(ctx, &models.CreateEndpointRequest{URL: rc.URL, EventTypes: []string{userModels.EventUserCreated}})
+	require.NoError(t, err)
+	assert.True(t, endpoint.Enabled)
+	assert.Regexp(t, `^whsec_`, secret)
+
+	occurredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := userModels.UserCreated{UserID: uuid.Must(uuid.NewV4()), Email: "jane@example.com", OccurredAt: occurredAt}
+	require.NoError(t, svc.EnqueueEvent(ctx, event))
+	// Events redelivered by the event bus are not queued twice, other event types not at all
+	require.NoError(t, svc.EnqueueEvent(ctx, event))
+	require.NoError(t, svc.EnqueueEvent(ctx, userModels.UserDeleted{UserID: event.UserID}))
+	require.Len(t, repo.deliveries, 1)
+
+	n, err := svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, rc.received, 1)
+	got := rc.received[0]
+	assert.Equal(t, repo.deliveries[0].EventID, got.ID)
+	assert.Equal(t, userModels.EventUserCreated, got.Type)
+	assert.True(t, occurredAt.Equal(got.CreatedAt))
+	assert.JSONEq(t, `{"user_id":"`+event.UserID.String()+`","email":"jane@example.com","occurred_at":"2026-01-02T03:04:05Z"}`, string(got.Data))
+	assert.Equal(t, models.DeliverySucceeded, repo.deliveries[0].Status)
+
+	// Replays are new deliveries of the same event
+	replay, err := svc.ReplayDelivery(ctx, repo.deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, repo.deliveries[0].ID, *replay.ReplayOf)
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	require.Len(t, rc.received, 2)
+	assert.Equal(t, got.ID, rc.received[1].ID)
+}
+
+func TestWebhookService_RetriesAndDisabling(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRepo{}
+	svc := newTestService(repo)
+
+	var secret string
+	rc := newReceiver(t, &secret)
+	rc.status = http.StatusServiceUnavailable
+	endpoint, secret, err := svc.CreateEndpoint(ctx, &models.CreateEndpointRequest{URL: rc.URL, EventTypes: []string{userModels.EventUserDeleted}})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.EnqueueEvent(ctx, userModels.UserDeleted{UserID: uuid.Must(uuid.NewV4())}))
+
+	// Failed attempts are scheduled with backoff until MaxAttempts
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	first := repo.deliveries[0]
+	assert.Equal(t, models.DeliveryPending, first.Status)
+	require.NotNil(t, first.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), *first.NextAttemptAt, 5*time.Second)
+	require.NotNil(t, first.Error)
+	assert.Equal(t, "unexpected response status 503", *first.Error)
+
+	for range 2 {
+		_, err = svc.DeliverDue(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, models.DeliveryFailed, first.Status)
+	assert.Equal(t, 3, first.Attempts)
+	assert.Nil(t, first.NextAttemptAt)
+
+	// The endpoint is disabled after 5 consecutive failed attempts, across deliveries
+	require.NoError(t, svc.EnqueueEvent(ctx, userModels.UserDeleted{UserID: uuid.Must(uuid.NewV4())}))
+	for range 2 {
+		_, err = svc.DeliverDue(ctx)
+		require.NoError(t, err)
+	}
+	ep, err := svc.GetEndpoint(ctx, endpoint.ID)
+	require.NoError(t, err)
+	assert.False(t, ep.Enabled)
+	require.NotNil(t, ep.DisabledReason)
+	assert.Contains(t, *ep.DisabledReason, "disabled after 5 consecutive failed deliveries")
+
+	// Deliveries of disabled endpoints wait, and cannot be replayed
+	second := repo.deliveries[1]
+	assert.Equal(t, models.DeliveryPending, second.Status)
+	n, err := svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	_, err = svc.ReplayDelivery(ctx, first.ID)
+	assert.ErrorIs(t, err, ErrEndpointDisabled)
+
+	// Enabling the endpoint resumes its pending deliveries
+	rc.status = http.StatusOK
+	enabled := true
+	_, err = svc.UpdateEndpoint(ctx, endpoint.ID, &models.UpdateEndpointRequest{Enabled: &enabled})
+	require.NoError(t, err)
+	_, err = svc.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliverySucceeded, second.Status)
+}
+
+func TestWebhookService_ValidateEndpoint(t *testing.T) {
+	svc := NewWebhookService(WebhookServiceOpts{Repo: &memoryRepo{}})
+	ctx := context.Background()
+
+	_, _, err := svc.CreateEndpoint(ctx, &models.CreateEndpointRequest{URL: "http://example.com/hook", EventTypes: []string{"user.created"}})
+	assert.ErrorIs(t, err, ErrInvalidURL)
+	_, _, err = svc.CreateEndpoint(ctx, &models.CreateEndpointRequest{URL: "https://example.com/hook", EventTypes: []string{"user.renamed"}})
+	assert.ErrorIs(t, err, ErrInvalidEventType)
+	_, _, err = svc.CreateEndpoint(ctx, &models.CreateEndpointRequest{URL: "https://example.com/hook", EventTypes: []string{"user.created"}})
+	assert.NoError(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, 2*time.Minute, backoff(2))
+	assert.Equal(t, 32*time.Minute, backoff(4))
+	assert.Equal(t, 12*time.Hour, backoff(7))
+	assert.Equal(t, 12*time.Hour, backoff(30))
+}
@@ -0,0 +1,17 @@
+package apputils
+
+// Cookie names used by the browser (cookie-based) session mode. They are shared by the
+// auth module, which issues the cookies, and the HTTP middlewares that read them.
+const (
+	// AccessTokenCookieName holds the access JWT (HttpOnly).
+	AccessTokenCookieName = "access_token"
+
+	// RefreshTokenCookieName holds the refresh JWT (HttpOnly, scoped to the auth routes).
+	RefreshTokenCookieName = "refresh_token"
+
+	// CSRFCookieName holds the double-submit CSRF token (readable by JavaScript).
+	CSRFCookieName = "csrf_token"
+
+	// CSRFHeaderName is the request header that must echo the CSRF cookie value.
+	CSRFHeaderName = "X-CSRF-Token"
+)
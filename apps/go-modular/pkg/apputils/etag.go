@@ -0,0 +1,35 @@
+package apputils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeETag returns a strong entity tag derived from the last modification time of a resource
+// (e.g. its updated_at column). Microsecond precision matches PostgreSQL timestamps, so the
+// tag of a value read back from the database equals the tag computed after writing it.
+func TimeETag(modified time.Time) string {
+	return `"` + strconv.FormatInt(modified.UnixMicro(), 36) + `"`
+}
+
+// IfMatch evaluates an If-Match request header against the current entity tag of a resource
+// (RFC 9110 section 13.1.1). An empty header is no precondition and always passes, "*"
+// matches any existing resource and otherwise one of the listed tags must equal etag using
+// the strong comparison, so weak tags never match.
+func IfMatch(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return true
+	}
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package apputils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeETag(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+	etag := TimeETag(modified)
+	assert.Regexp(t, `^"[0-9a-z]+"$`, etag)
+	// sub-microsecond differences are below database precision
+	assert.Equal(t, etag, TimeETag(modified.Truncate(time.Microsecond)))
+	assert.NotEqual(t, etag, TimeETag(modified.Add(time.Microsecond)))
+}
+
+func TestIfMatch(t *testing.T) {
+	etag := TimeETag(time.Now())
+	other := TimeETag(time.Now().Add(time.Second))
+
+	assert.True(t, IfMatch("", etag), "no precondition")
+	assert.True(t, IfMatch("*", etag))
+	assert.True(t, IfMatch(etag, etag))
+	assert.True(t, IfMatch(other+", "+etag, etag))
+	assert.False(t, IfMatch(other, etag))
+	assert.False(t, IfMatch("W/"+etag, etag), "weak tags never match")
+}
@@ -22,6 +22,16 @@ const (
 
 	// JWTClaimsContextKey is used to store parsed JWT claims in context (map[string]any).
 	JWTClaimsContextKey ContextKey = "go-modular.jwt_claims"
+
+	// OrgIDContextKey is used to store the active organization ID in context (uuid.UUID).
+	OrgIDContextKey ContextKey = "go-modular.org_id"
+)
+
+// Token types stored in the "typ" claim.
+const (
+	TokenTypeAccess  = "access"  // User access token bound to a session
+	TokenTypeRefresh = "refresh" // User refresh token
+	TokenTypeService = "service" // Service (client_credentials) access token, no user or session
 )
 
 // JWTConfig holds configuration for JWT generation and validation.
@@ -48,7 +58,8 @@ func NewJWTGenerator(config JWTConfig) *JWTGenerator {
 }
 
 // Sign generates a JWT string with the given payload (struct or map) and optional subject.
-// The payload is flattened into the JWT claims. The "typ" claim is set to "access".
+// The payload is flattened into the JWT claims. The "typ" claim is set to "access" unless
+// the payload overrides it (e.g. "service").
 func (j *JWTGenerator) Sign(ctx context.Context, payload any, subject string) (string, error) {
 	if len(j.config.SecretKey) == 0 {
 		return "", errors.New("secret key is required")
@@ -58,7 +69,7 @@ func (j *JWTGenerator) Sign(ctx context.Context, payload any, subject string) (s
 	_ = token.Set(jwt.IssuerKey, j.config.Issuer)
 	_ = token.Set(jwt.IssuedAtKey, now)
 	_ = token.Set(jwt.ExpirationKey, now.Add(j.config.AccessTokenExpiry))
-	_ = token.Set("typ", "access")
+	_ = token.Set("typ", TokenTypeAccess)
 	if subject != "" {
 		_ = token.Set(jwt.SubjectKey, subject)
 	}
@@ -96,7 +107,7 @@ func (j *JWTGenerator) GenerateRefreshTokenJWT(ctx context.Context, uid, audienc
 	_ = token.Set(jwt.ExpirationKey, now.Add(j.config.RefreshTokenExpiry))
 	_ = token.Set(jwt.SubjectKey, uid)
 	_ = token.Set(jwt.AudienceKey, audience)
-	_ = token.Set("typ", "refresh")
+	_ = token.Set("typ", TokenTypeRefresh)
 	_ = token.Set(jwt.JwtIDKey, refreshTokenID)
 
 	signed, err := jwt.Sign(token, j.config.SigningAlg, j.config.SecretKey)
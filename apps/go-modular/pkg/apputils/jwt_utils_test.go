@@ -120,6 +120,19 @@ func TestJWTGenerator(t *testing.T) {
 		assert.Equal(t, "refresh-issuer", claims["iss"])
 	})
 
+	t.Run("Sign_payload_overrides_typ", func(t *testing.T) {
+		gen := NewJWTGenerator(JWTConfig{SecretKey: []byte("service-secret"), AccessTokenExpiry: time.Minute})
+
+		tokenStr, err := gen.Sign(ctx, map[string]any{"typ": TokenTypeService, "scope": "users:read"}, "svc_worker")
+		require.NoError(t, err)
+
+		claims, err := gen.ParseAndValidate(ctx, tokenStr)
+		require.NoError(t, err)
+		assert.Equal(t, TokenTypeService, claims["typ"])
+		assert.Equal(t, "users:read", claims["scope"])
+		assert.Equal(t, "svc_worker", claims["sub"])
+	})
+
 	t.Run("ErrorsAndHelpers", func(t *testing.T) {
 		// Missing secret key should cause Sign and GenerateRefreshTokenJWT to error
 		genNoKey := NewJWTGenerator(JWTConfig{
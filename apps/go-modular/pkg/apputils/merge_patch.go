@@ -0,0 +1,72 @@
+package apputils
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents (RFC 7386).
+const MergePatchContentType = "application/merge-patch+json"
+
+// ErrInvalidMergePatch is returned when a merge patch or its target is not valid JSON.
+var ErrInvalidMergePatch = errors.New("invalid JSON merge patch")
+
+// IsMergePatchContentType reports whether contentType is JSON Merge Patch. Plain JSON is
+// accepted as well, since clients often send merge patches as application/json.
+func IsMergePatchContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == MergePatchContentType || mediaType == "application/json"
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to the JSON document doc and returns the
+// patched document: members of patch replace those of doc, null members are removed and
+// nested objects are merged recursively. A patch that is not an object replaces doc.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var patchValue any
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, ErrInvalidMergePatch
+	}
+	var docValue any
+	if len(doc) > 0 {
+		if err := json.Unmarshal(doc, &docValue); err != nil {
+			return nil, ErrInvalidMergePatch
+		}
+	}
+	return json.Marshal(mergeValue(docValue, patchValue))
+}
+
+// MergePatchFields returns the top-level member names of a merge patch, or nil when it is
+// not a JSON object. It lets callers allowlist the fields a patch may touch.
+func MergePatchFields(patch []byte) []string {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(members))
+	for name := range members {
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// mergeValue implements the MergePatch algorithm of RFC 7386 section 2.
+func mergeValue(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergeValue(targetObj[name], value)
+	}
+	return targetObj
+}
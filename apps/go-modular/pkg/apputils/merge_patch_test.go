@@ -0,0 +1,56 @@
+package apputils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Test cases from RFC 7386 Appendix A
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		got, err := MergePatch([]byte(tc.doc), []byte(tc.patch))
+		require.NoError(t, err)
+		assert.JSONEq(t, tc.want, string(got), "doc=%s patch=%s", tc.doc, tc.patch)
+	}
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := MergePatch([]byte(`{"a":1}`), []byte(`{`))
+		assert.ErrorIs(t, err, ErrInvalidMergePatch)
+		_, err = MergePatch([]byte(`{`), []byte(`{}`))
+		assert.ErrorIs(t, err, ErrInvalidMergePatch)
+	})
+}
+
+func TestMergePatchFields(t *testing.T) {
+	assert.ElementsMatch(t, []string{"a", "b"}, MergePatchFields([]byte(`{"a":1,"b":null}`)))
+	assert.Nil(t, MergePatchFields([]byte(`["a"]`)))
+}
+
+func TestIsMergePatchContentType(t *testing.T) {
+	assert.True(t, IsMergePatchContentType("application/merge-patch+json"))
+	assert.True(t, IsMergePatchContentType("application/merge-patch+json; charset=utf-8"))
+	assert.True(t, IsMergePatchContentType("application/json"))
+	assert.False(t, IsMergePatchContentType("application/json-patch+json"))
+	assert.False(t, IsMergePatchContentType("text/plain"))
+}
@@ -39,6 +39,7 @@ import (
 	"strings"
 
 	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Argon2Params holds the parameters for argon2id hashing
@@ -61,7 +62,7 @@ func DefaultArgon2Params() Argon2Params {
 		Memory:      16384, // 16 MB
 		Iterations:  4,     // 4 iterations
 		Parallelism: 2,     // 2 threads
-		SaltLength:  8,     // 8 bytes salt
+		SaltLength:  16,    // 16 bytes salt
 		KeyLength:   32,    // 32 bytes key length
 	}
 }
@@ -93,35 +94,92 @@ func (h *PasswordHasher) Hash(password string) (string, error) {
 	return phc, nil
 }
 
-// Validate compares a plain password with a PHC argon2id hash
+// Validate compares a plain password with a PHC argon2id hash or a legacy bcrypt hash
 func (h *PasswordHasher) Validate(password, hash string) (bool, error) {
-	parts := strings.Split(hash, "$")
-	if len(parts) != 6 {
-		return false, errors.New("invalid hash format")
+	if IsBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
 	}
 
-	var memory uint32
-	var iterations uint32
-	var parallelism uint8
-	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism)
+	params, salt, expectedHash, err := decodeArgon2Hash(hash)
 	if err != nil {
 		return false, err
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	computedHash := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtleCompare(computedHash, expectedHash), nil
+}
+
+// NeedsRehash reports whether hash was produced with a different algorithm or
+// weaker parameters than the hasher's, so it should be replaced after the next
+// successful validation. Unparseable hashes also report true.
+func (h *PasswordHasher) NeedsRehash(hash string) bool {
+	if IsBcryptHash(hash) {
+		return true
+	}
+
+	params, _, _, err := decodeArgon2Hash(hash)
 	if err != nil {
-		return false, err
+		return true
+	}
+
+	return params.Memory < h.params.Memory ||
+		params.Iterations < h.params.Iterations ||
+		params.Parallelism < h.params.Parallelism ||
+		params.SaltLength < h.params.SaltLength ||
+		params.KeyLength < h.params.KeyLength
+}
+
+// IsBcryptHash reports whether hash is a bcrypt hash ($2a$, $2b$ or $2y$), as
+// produced by legacy services that are imported into argon2id on next sign in.
+func IsBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
+
+// decodeArgon2Hash parses a PHC argon2id string into its params, salt and key
+func decodeArgon2Hash(hash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errors.New("invalid hash format")
 	}
 
-	expectedHash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, err
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
 	if err != nil {
-		return false, err
+		return params, nil, nil, err
 	}
 
-	keyLen := uint32(len(expectedHash))
-	computedHash := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, keyLen)
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
 
-	return subtleCompare(computedHash, expectedHash), nil
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }
 
 // subtleCompare does a constant-time comparison of two byte slices
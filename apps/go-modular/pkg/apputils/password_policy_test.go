@@ -0,0 +1,55 @@
+package apputils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	t.Run("StrongPassword_Passes", func(t *testing.T) {
+		policy := PasswordPolicy{MinLength: 12, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, CheckBlocklist: true}
+		assert.Empty(t, policy.Check("Corr3ct-Horse-Battery", "jane@example.com", "jane"))
+	})
+
+	t.Run("LengthAndCharacterClasses", func(t *testing.T) {
+		policy := PasswordPolicy{MinLength: 12, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+		violations := policy.Check("short")
+		assert.Contains(t, violations, "Minimum length is 12")
+		assert.Contains(t, violations, "Must contain an uppercase letter")
+		assert.Contains(t, violations, "Must contain a digit")
+		assert.Contains(t, violations, "Must contain a symbol")
+		assert.NotContains(t, violations, "Must contain a lowercase letter")
+	})
+
+	t.Run("ContainsEmailOrUsername", func(t *testing.T) {
+		policy := DefaultPasswordPolicy()
+		assert.Contains(t, policy.Check("my-JaneDoe-secret", "jane.doe@example.com", "janedoe"), "Must not contain your email or username")
+		assert.Contains(t, policy.Check("x-jane.doe-1987", "jane.doe@example.com"), "Must not contain your email or username")
+		assert.Empty(t, policy.Check("unrelated-phrase-42", "jo@example.com", "jo"))
+	})
+
+	t.Run("Blocklisted", func(t *testing.T) {
+		assert.True(t, IsBlocklistedPassword("Password123"))
+		// ranked around 6,500 of the list, so not only the top entries are bundled
+		assert.True(t, IsBlocklistedPassword("Thriller"))
+		assert.Contains(t, DefaultPasswordPolicy().Check("firehawk"), "Password is too common or has appeared in a data breach")
+		assert.False(t, IsBlocklistedPassword("unrelated-phrase-42"))
+		assert.Contains(t, DefaultPasswordPolicy().Check("qwerty123"), "Password is too common or has appeared in a data breach")
+
+		policy := DefaultPasswordPolicy()
+		policy.CheckBlocklist = false
+		assert.Empty(t, policy.Check("qwerty123"))
+	})
+}
+
+func TestValidationErrorsToMap_PolicyError(t *testing.T) {
+	type req struct {
+		NewPassword string `json:"new_password"`
+	}
+	err := fmt.Errorf("wrapped: %w", &PolicyError{Field: "NewPassword", Violations: []string{"Minimum length is 12", "Must contain a digit"}})
+	m := ValidationErrorsToMap(err, req{})
+	assert.Equal(t, "Minimum length is 12; Must contain a digit", m["new_password"])
+
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPasswordHasher(t *testing.T) {
@@ -57,3 +58,57 @@ func TestPasswordHasher(t *testing.T) {
 		assert.True(t, ok, "expected validation to succeed with custom params")
 	})
 }
+
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	t.Run("DefaultHash_IsCurrent", func(t *testing.T) {
+		hasher := NewPasswordHasher()
+		hash, err := hasher.Hash("myS3cretP@ss")
+		require.NoError(t, err)
+		assert.False(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("ShortSalt_NeedsRehash", func(t *testing.T) {
+		legacy := DefaultArgon2Params()
+		legacy.SaltLength = 8
+		hash, err := NewPasswordHasherWithParams(legacy).Hash("myS3cretP@ss")
+		require.NoError(t, err)
+
+		hasher := NewPasswordHasher()
+		ok, err := hasher.Validate("myS3cretP@ss", hash)
+		require.NoError(t, err)
+		assert.True(t, ok, "expected legacy hash to still validate")
+		assert.True(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("WeakerCost_NeedsRehash", func(t *testing.T) {
+		weak := DefaultArgon2Params()
+		weak.Iterations = 1
+		hash, err := NewPasswordHasherWithParams(weak).Hash("myS3cretP@ss")
+		require.NoError(t, err)
+		assert.True(t, NewPasswordHasher().NeedsRehash(hash))
+	})
+
+	t.Run("InvalidHash_NeedsRehash", func(t *testing.T) {
+		assert.True(t, NewPasswordHasher().NeedsRehash("not-a-valid-phc"))
+	})
+}
+
+func TestPasswordHasher_Bcrypt(t *testing.T) {
+	pass := "legacyP@ssw0rd"
+	legacy, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+	require.NoError(t, err)
+	hash := string(legacy)
+
+	hasher := NewPasswordHasher()
+	assert.True(t, IsBcryptHash(hash))
+
+	ok, err := hasher.Validate(pass, hash)
+	require.NoError(t, err)
+	assert.True(t, ok, "expected bcrypt hash to validate")
+
+	ok, err = hasher.Validate("wrong-password", hash)
+	require.NoError(t, err)
+	assert.False(t, ok, "expected bcrypt validation to fail for wrong password")
+
+	assert.True(t, hasher.NeedsRehash(hash), "expected bcrypt hash to be upgraded")
+}
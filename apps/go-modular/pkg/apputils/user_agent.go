@@ -79,3 +79,24 @@ func SummarizeUserAgent(uaString string) string {
 	}
 	return strings.TrimSpace(result)
 }
+
+// UserAgentFamily returns the browser and OS names of a user agent without versions, e.g.
+// "Chrome on macOS", so that routine browser or OS updates do not look like a new device.
+func UserAgentFamily(uaString string) string {
+	summary := SummarizeUserAgent(uaString)
+	if summary == "Unknown" {
+		return summary
+	}
+	browser, osName, _ := strings.Cut(summary, " on ")
+	if i := strings.Index(browser, " v"); i >= 0 {
+		browser = browser[:i]
+	}
+	fields := strings.Fields(osName)
+	for len(fields) > 1 && strings.ContainsAny(fields[len(fields)-1][:1], "0123456789") {
+		fields = fields[:len(fields)-1]
+	}
+	if osName = strings.Join(fields, " "); osName == "" {
+		return strings.TrimSpace(browser)
+	}
+	return strings.TrimSpace(browser + " on " + osName)
+}
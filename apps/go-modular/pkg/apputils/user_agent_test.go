@@ -41,3 +41,23 @@ func TestSummarizeUserAgent(t *testing.T) {
 		assert.Equal(t, "Unknown", got)
 	})
 }
+
+func TestUserAgentFamily(t *testing.T) {
+	t.Run("Drops browser and OS versions", func(t *testing.T) {
+		older := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.5790.170 Safari/537.36"
+		newer := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36"
+		assert.Equal(t, "Chrome on macOS", UserAgentFamily(older))
+		assert.Equal(t, UserAgentFamily(older), UserAgentFamily(newer))
+	})
+
+	t.Run("Different OS is a different family", func(t *testing.T) {
+		windows := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/115.0.5790.170 Safari/537.36"
+		android := "Mozilla/5.0 (Linux; Android 11; Pixel 4) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.210 Mobile Safari/537.36"
+		assert.Equal(t, "Chrome on Windows", UserAgentFamily(windows))
+		assert.Equal(t, "Chrome on Android", UserAgentFamily(android))
+	})
+
+	t.Run("Unknown user agent", func(t *testing.T) {
+		assert.Equal(t, "Unknown", UserAgentFamily(""))
+	})
+}
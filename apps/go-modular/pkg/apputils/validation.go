@@ -1,6 +1,7 @@
 package apputils
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -43,8 +44,29 @@ func ValidationErrorsToMap(err error, obj any) map[string]string {
 			}
 			errs[field] = msg
 		}
+	} else if pe := (*PolicyError)(nil); errors.As(err, &pe) {
+		errs[jsonFieldName(obj, pe.Field)] = pe.Error()
 	} else {
 		errs["error"] = err.Error()
 	}
 	return errs
 }
+
+// jsonFieldName resolves a struct field name to its json tag name on obj
+func jsonFieldName(obj any, field string) string {
+	t := reflect.TypeOf(obj)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		if structField, found := t.FieldByName(field); found {
+			if jsonTag := structField.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+				return strings.Split(jsonTag, ",")[0]
+			}
+		}
+	}
+	if field == "" {
+		return "error"
+	}
+	return strings.ToLower(field[:1]) + field[1:]
+}